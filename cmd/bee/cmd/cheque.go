@@ -0,0 +1,254 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/holisticode/bee/pkg/bigint"
+	"github.com/holisticode/bee/pkg/settlement/swap"
+	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
+	"github.com/holisticode/bee/pkg/statestore/leveldb"
+	"github.com/spf13/cobra"
+)
+
+const optionNameChainID = "chain-id"
+
+func (c *command) initChequeCmd() {
+	cmd := &cobra.Command{
+		Use:   "cheque",
+		Short: "Inspect the cheques in the state store of a stopped node",
+	}
+
+	chequeListCmd(cmd)
+	chequeVerifyCmd(cmd)
+	chequeExportCmd(cmd)
+
+	c.root.AddCommand(cmd)
+}
+
+type chequeExport struct {
+	Peer             string         `json:"peer,omitempty"`
+	Chequebook       string         `json:"chequebook"`
+	Beneficiary      string         `json:"beneficiary"`
+	CumulativePayout *bigint.BigInt `json:"cumulativePayout"`
+	Signature        string         `json:"signature"`
+	Issuer           string         `json:"issuer"`
+	Error            string         `json:"error,omitempty"`
+}
+
+type chequesExport struct {
+	ChainID                  int64          `json:"chainID"`
+	Chequebook               string         `json:"chequebook"`
+	TotalIssued              *bigint.BigInt `json:"totalIssued"`
+	SentCumulativePayout     *bigint.BigInt `json:"sentCumulativePayout"`
+	ReceivedCumulativePayout *bigint.BigInt `json:"receivedCumulativePayout"`
+	Valid                    bool           `json:"valid"`
+	Sent                     []chequeExport `json:"sent"`
+	Received                 []chequeExport `json:"received"`
+}
+
+// auditCheques audits the cheques in the state store of the data directory
+// given by the flags of the command.
+func auditCheques(cmd *cobra.Command) (*chequesExport, error) {
+	v, err := cmd.Flags().GetString(optionNameVerbosity)
+	if err != nil {
+		return nil, fmt.Errorf("get verbosity: %w", err)
+	}
+	logger, err := newLogger(cmd, strings.ToLower(v))
+	if err != nil {
+		return nil, fmt.Errorf("new logger: %w", err)
+	}
+
+	dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+	if err != nil {
+		return nil, fmt.Errorf("get data-dir: %w", err)
+	}
+	if dataDir == "" {
+		return nil, errors.New("no data-dir provided")
+	}
+
+	chainID, err := cmd.Flags().GetInt64(optionNameChainID)
+	if err != nil {
+		return nil, fmt.Errorf("get chain-id: %w", err)
+	}
+	if chainID == -1 {
+		networkID, err := cmd.Flags().GetUint64(optionNameNetworkID)
+		if err != nil {
+			return nil, fmt.Errorf("get network-id: %w", err)
+		}
+		chainID = getConfigByNetworkID(networkID, 0).chainID
+		if chainID == -1 {
+			return nil, fmt.Errorf("no chain known for network %d, provide the chain id", networkID)
+		}
+	}
+
+	stateStore, err := leveldb.NewStateStore(filepath.Join(dataDir, "statestore"), logger)
+	if err != nil {
+		return nil, fmt.Errorf("statestore: %w", err)
+	}
+	defer stateStore.Close()
+
+	audit, err := chequebook.AuditCheques(stateStore, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("audit cheques: %w", err)
+	}
+
+	addressbook := swap.NewAddressbook(stateStore)
+	export := &chequesExport{
+		ChainID:                  chainID,
+		Chequebook:               audit.Chequebook.String(),
+		TotalIssued:              bigint.Wrap(audit.TotalIssued),
+		SentCumulativePayout:     bigint.Wrap(audit.SentCumulativePayout),
+		ReceivedCumulativePayout: bigint.Wrap(audit.ReceivedCumulativePayout),
+		Valid:                    audit.Valid(),
+		Sent:                     make([]chequeExport, 0, len(audit.Sent)),
+		Received:                 make([]chequeExport, 0, len(audit.Received)),
+	}
+	for _, c := range audit.Sent {
+		e := newChequeExport(c)
+		if peer, known, err := addressbook.BeneficiaryPeer(c.Beneficiary); err == nil && known {
+			e.Peer = peer.String()
+		}
+		export.Sent = append(export.Sent, e)
+	}
+	for _, c := range audit.Received {
+		e := newChequeExport(c)
+		if peer, known, err := addressbook.ChequebookPeer(c.Chequebook); err == nil && known {
+			e.Peer = peer.String()
+		}
+		export.Received = append(export.Received, e)
+	}
+	return export, nil
+}
+
+func newChequeExport(c chequebook.ChequeAudit) chequeExport {
+	e := chequeExport{
+		Chequebook:       c.Chequebook.String(),
+		Beneficiary:      c.Beneficiary.String(),
+		CumulativePayout: bigint.Wrap(c.CumulativePayout),
+		Signature:        hexutil.Encode(c.Signature),
+		Issuer:           c.Issuer.String(),
+	}
+	if c.Err != nil {
+		e.Error = c.Err.Error()
+	}
+	return e
+}
+
+func chequeFlags(c *cobra.Command) {
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	c.Flags().Uint64(optionNameNetworkID, 10, "ID of the Swarm network, used to determine the chain id")
+	c.Flags().Int64(optionNameChainID, -1, "ID of the chain the cheques are for, overrides the network id")
+}
+
+func chequeListCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "list",
+		Short: "List the last sent and received cheques",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			export, err := auditCheques(cmd)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "chequebook %s\n", export.Chequebook)
+			fmt.Fprintf(out, "sent cheques (total issued %s):\n", export.TotalIssued)
+			for _, e := range export.Sent {
+				fmt.Fprintf(out, "  beneficiary %s peer %s cumulative payout %s\n", e.Beneficiary, e.Peer, e.CumulativePayout)
+			}
+			fmt.Fprintf(out, "received cheques (cumulative payout %s):\n", export.ReceivedCumulativePayout)
+			for _, e := range export.Received {
+				fmt.Fprintf(out, "  chequebook %s peer %s cumulative payout %s\n", e.Chequebook, e.Peer, e.CumulativePayout)
+			}
+			return nil
+		},
+	}
+	chequeFlags(c)
+	cmd.AddCommand(c)
+}
+
+func chequeVerifyCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the signatures and cumulative payouts of the last sent and received cheques",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			export, err := auditCheques(cmd)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			for _, e := range export.Sent {
+				if e.Error != "" {
+					fmt.Fprintf(out, "sent cheque to %s: %s\n", e.Beneficiary, e.Error)
+				}
+			}
+			for _, e := range export.Received {
+				if e.Error != "" {
+					fmt.Fprintf(out, "received cheque from %s: %s\n", e.Chequebook, e.Error)
+				}
+			}
+			if export.TotalIssued.Cmp(export.SentCumulativePayout.Int) != 0 {
+				fmt.Fprintf(out, "total issued %s does not match the cumulative payout %s of the sent cheques\n", export.TotalIssued, export.SentCumulativePayout)
+			}
+			if !export.Valid {
+				return errors.New("cheque verification failed")
+			}
+
+			fmt.Fprintf(out, "verified %d sent and %d received cheques\n", len(export.Sent), len(export.Received))
+			return nil
+		},
+	}
+	chequeFlags(c)
+	cmd.AddCommand(c)
+}
+
+func chequeExportCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "export <filename>",
+		Short: "Export the last sent and received cheques as JSON. Use \"-\" as filename in order to write to STDOUT",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if (len(args)) != 1 {
+				return cmd.Help()
+			}
+
+			export, err := auditCheques(cmd)
+			if err != nil {
+				return err
+			}
+
+			var out io.Writer
+			if args[0] == "-" {
+				out = cmd.OutOrStdout()
+			} else {
+				f, err := os.Create(args[0])
+				if err != nil {
+					return fmt.Errorf("error opening output file: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(export); err != nil {
+				return fmt.Errorf("encode cheques: %w", err)
+			}
+			return nil
+		},
+	}
+	chequeFlags(c)
+	cmd.AddCommand(c)
+}
@@ -33,6 +33,17 @@ const (
 	optionNameP2PAddr                    = "p2p-addr"
 	optionNameNATAddr                    = "nat-addr"
 	optionNameP2PWSEnable                = "p2p-ws-enable"
+	optionNameP2PQUICEnable              = "p2p-quic-enable"
+	optionNameP2PQUICAddr                = "p2p-quic-addr"
+	optionNameP2PMDNSEnable              = "p2p-mdns-enable"
+	optionNameP2PInboundLimit            = "p2p-inbound-limit"
+	optionNameP2POutboundLimit           = "p2p-outbound-limit"
+	optionNameP2PIPLimit                 = "p2p-ip-limit"
+	optionNameP2PSubnetLimit             = "p2p-subnet-limit"
+	optionNameP2PNetworkLimits           = "p2p-network-limits"
+	optionNameP2PNetworkKeyFile          = "p2p-network-key-file"
+	optionNameP2PBandwidthLimit          = "p2p-bandwidth-limit"
+	optionNameP2PProtocolBandwidthLimits = "p2p-protocol-bandwidth-limits"
 	optionNameDebugAPIEnable             = "debug-api-enable"
 	optionNameDebugAPIAddr               = "debug-api-addr"
 	optionNameBootnodes                  = "bootnode"
@@ -80,6 +91,32 @@ const (
 	optionNameRestrictedAPI              = "restricted"
 	optionNameTokenEncryptionKey         = "token-encryption-key"
 	optionNameAdminPasswordHash          = "admin-password"
+	optionNameBatchSelection             = "postage-batch-selection"
+	optionNameDefaultBatch               = "postage-default-batch"
+	optionNameEventWebhooks              = "event-webhooks"
+	optionNameEventWebhookRetries        = "event-webhook-retries"
+	optionNameBatchExpiryWarning         = "postage-expiry-warning"
+	optionNameChequebookLowBalance       = "chequebook-low-balance"
+	optionNameWalletLowBalance           = "wallet-low-balance"
+	optionNameDynamicPricing             = "dynamic-pricing"
+	optionNameBudgetHourly               = "budget-hourly"
+	optionNameBudgetDaily                = "budget-daily"
+	optionNameBudgetTotal                = "budget-total"
+	optionNameKeyBudgetHourly            = "api-key-budget-hourly"
+	optionNameKeyBudgetDaily             = "api-key-budget-daily"
+	optionNameKeyBudgetTotal             = "api-key-budget-total"
+	optionNameAutoCashout                = "auto-cashout"
+	optionNameAutoCashoutGasMultiple     = "auto-cashout-gas-multiple"
+	optionNameAutoCashoutInterval        = "auto-cashout-interval"
+	optionNameNativeExchangeRate         = "native-exchange-rate"
+	optionNameChequebookRefillFloor      = "chequebook-refill-floor"
+	optionNameChequebookRefillTarget     = "chequebook-refill-target"
+	optionNameChequebookRefillCap        = "chequebook-refill-cap"
+	optionNameChequebookRefillPeriod     = "chequebook-refill-period"
+	optionNameSettlementDrivers          = "settlement-drivers"
+	optionNameConsortiumMembers          = "consortium-members"
+	optionNameTrustedPeers               = "trusted-peers"
+	optionNameSettlementHistoryRetention = "settlement-history-retention"
 )
 
 func init() {
@@ -141,6 +178,8 @@ func newCommand(opts ...option) (c *command, err error) {
 
 	c.initVersionCmd()
 	c.initDBCmd()
+	c.initChequeCmd()
+	c.initTopologyCmd()
 
 	if err := c.initConfigurateOptionsCmd(); err != nil {
 		return nil, err
@@ -224,6 +263,17 @@ func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().String(optionNameP2PAddr, ":1634", "P2P listen address")
 	cmd.Flags().String(optionNameNATAddr, "", "NAT exposed address")
 	cmd.Flags().Bool(optionNameP2PWSEnable, false, "enable P2P WebSocket transport")
+	cmd.Flags().Bool(optionNameP2PQUICEnable, false, "enable P2P QUIC transport")
+	cmd.Flags().String(optionNameP2PQUICAddr, "", "P2P QUIC listen address, the P2P listen address over UDP if empty")
+	cmd.Flags().Bool(optionNameP2PMDNSEnable, false, "discover peers on the local network with mDNS, private addresses require allow-private-cidrs")
+	cmd.Flags().Int(optionNameP2PInboundLimit, 0, "maximum number of inbound P2P connections, 0 for no limit")
+	cmd.Flags().Int(optionNameP2POutboundLimit, 0, "maximum number of outbound P2P connections, 0 for no limit")
+	cmd.Flags().Int(optionNameP2PIPLimit, 0, "maximum number of P2P connections to a single IP address, 0 for no limit")
+	cmd.Flags().Int(optionNameP2PSubnetLimit, 0, "maximum number of P2P connections to a single /24 IPv4 or /48 IPv6 subnet, 0 for no limit")
+	cmd.Flags().StringSlice(optionNameP2PNetworkLimits, nil, "maximum number of P2P connections to a network, can be repeated, format cidr=limit")
+	cmd.Flags().Int(optionNameP2PBandwidthLimit, 0, "maximum P2P traffic of all protocols in bytes per second, 0 for no limit")
+	cmd.Flags().StringSlice(optionNameP2PProtocolBandwidthLimits, nil, "maximum P2P traffic of a protocol in bytes per second, can be repeated, format protocol=limit")
+	cmd.Flags().String(optionNameP2PNetworkKeyFile, "", "path to the pre-shared key file of a private network, generated by init if missing")
 	cmd.Flags().StringSlice(optionNameBootnodes, []string{"/dnsaddr/testnet.ethswarm.org"}, "initial nodes to connect to")
 	cmd.Flags().Bool(optionNameDebugAPIEnable, false, "enable debug HTTP API")
 	cmd.Flags().String(optionNameDebugAPIAddr, ":1635", "debug HTTP API listen address")
@@ -269,6 +319,32 @@ func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().Bool(optionNameRestrictedAPI, false, "enable permission check on the http APIs")
 	cmd.Flags().String(optionNameTokenEncryptionKey, "", "admin username to get the security token")
 	cmd.Flags().String(optionNameAdminPasswordHash, "", "bcrypt hash of the admin password to get the security token")
+	cmd.Flags().String(optionNameBatchSelection, "none", "postage batch selection for uploads without a batch header: none, default, capacity or ttl")
+	cmd.Flags().String(optionNameDefaultBatch, "", "postage batch ID preferred by the default batch selection")
+	cmd.Flags().StringSlice(optionNameEventWebhooks, []string{}, "URLs to post node events to, can be repeated")
+	cmd.Flags().Int(optionNameEventWebhookRetries, 3, "number of retries of a failed event webhook delivery")
+	cmd.Flags().Uint64(optionNameBatchExpiryWarning, 17280, "number of blocks before expiry at which owned postage batches are announced, 0 to disable")
+	cmd.Flags().String(optionNameChequebookLowBalance, "", "available chequebook balance in BZZ below which an event is emitted")
+	cmd.Flags().String(optionNameWalletLowBalance, "", "native wallet balance in wei below which an event is emitted")
+	cmd.Flags().Bool(optionNameDynamicPricing, false, "adjust chunk prices to load and demand and announce them to peers")
+	cmd.Flags().String(optionNameBudgetHourly, "", "maximum amount in BZZ base units spent on originated traffic per hour")
+	cmd.Flags().String(optionNameBudgetDaily, "", "maximum amount in BZZ base units spent on originated traffic per day")
+	cmd.Flags().String(optionNameBudgetTotal, "", "maximum amount in BZZ base units spent on originated traffic in total")
+	cmd.Flags().String(optionNameKeyBudgetHourly, "", "maximum amount in BZZ base units spent on originated traffic of a single security token per hour")
+	cmd.Flags().String(optionNameKeyBudgetDaily, "", "maximum amount in BZZ base units spent on originated traffic of a single security token per day")
+	cmd.Flags().String(optionNameKeyBudgetTotal, "", "maximum amount in BZZ base units spent on originated traffic of a single security token in total")
+	cmd.Flags().Bool(optionNameAutoCashout, false, "cash received cheques automatically")
+	cmd.Flags().Float64(optionNameAutoCashoutGasMultiple, 10, "multiple of the estimated cashout gas cost valued in BZZ base units the uncashed amount has to exceed to cash a cheque")
+	cmd.Flags().Duration(optionNameAutoCashoutInterval, time.Hour, "interval in which received cheques are checked for automatic cashout")
+	cmd.Flags().String(optionNameNativeExchangeRate, "", "BZZ base units one wei of the native chain currency is worth, used to value cashout gas costs")
+	cmd.Flags().String(optionNameChequebookRefillFloor, "", "available chequebook balance in BZZ below which the chequebook is refilled from the wallet")
+	cmd.Flags().String(optionNameChequebookRefillTarget, "", "available chequebook balance in BZZ the chequebook is refilled to")
+	cmd.Flags().String(optionNameChequebookRefillCap, "", "maximum amount in BZZ deposited by refills within the refill period")
+	cmd.Flags().Duration(optionNameChequebookRefillPeriod, 24*time.Hour, "period the chequebook refill cap applies to")
+	cmd.Flags().StringSlice(optionNameSettlementDrivers, []string{"swap"}, "settlement drivers payments are made with, in order of preference (swap, consortium)")
+	cmd.Flags().StringSlice(optionNameConsortiumMembers, nil, "ethereum addresses of the operators IOUs of the consortium settlement driver are accepted from")
+	cmd.Flags().StringSlice(optionNameTrustedPeers, nil, "overlays or public keys of peers traffic with which is free")
+	cmd.Flags().Duration(optionNameSettlementHistoryRetention, 90*24*time.Hour, "period settlement events are kept in the settlement history, 0 to keep them forever")
 }
 
 func newLogger(cmd *cobra.Command, verbosity string) (logging.Logger, error) {
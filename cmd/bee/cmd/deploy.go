@@ -60,6 +60,8 @@ func (c *command) initDeployCmd() error {
 				swapEndpoint,
 				signer,
 				blocktime,
+				nil,
+				nil,
 			)
 			if err != nil {
 				return err
@@ -91,6 +93,8 @@ func (c *command) initDeployCmd() error {
 				chequebookFactory,
 				swapInitialDeposit,
 				deployGasPrice,
+				nil,
+				nil,
 			)
 			if err != nil {
 				return err
@@ -39,6 +39,15 @@ func (c *command) initInitCmd() (err error) {
 
 			defer stateStore.Close()
 
+			keyFile := c.config.GetString(optionNameP2PNetworkKeyFile)
+			created, err := generateNetworkKey(keyFile)
+			if err != nil {
+				return err
+			}
+			if created {
+				logger.Infof("new private network key created in %s", keyFile)
+			}
+
 			return nil
 		},
 		PreRunE: func(cmd *cobra.Command, args []string) error {
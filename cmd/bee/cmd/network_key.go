@@ -0,0 +1,53 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/holisticode/bee/pkg/p2p/libp2p"
+)
+
+// readNetworkKey reads the pre-shared private network key from the file. It
+// returns a nil key if no file is configured.
+func readNetworkKey(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("private network key: %w", err)
+	}
+	defer f.Close()
+
+	return libp2p.ReadNetworkKey(f)
+}
+
+// generateNetworkKey writes a new pre-shared private network key to the file
+// if it does not exist yet. It returns true if a new key was written.
+func generateNetworkKey(path string) (created bool, err error) {
+	if path == "" {
+		return false, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("private network key: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if err := libp2p.GenerateNetworkKey(f); err != nil {
+		return false, fmt.Errorf("private network key: %w", err)
+	}
+	return true, nil
+}
@@ -9,6 +9,7 @@ import (
 	"context"
 	"crypto/ecdsa"
 	_ "embed"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
@@ -29,6 +30,7 @@ import (
 	memkeystore "github.com/holisticode/bee/pkg/keystore/mem"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/node"
+	"github.com/holisticode/bee/pkg/postage"
 	"github.com/holisticode/bee/pkg/resolver/multiresolver"
 	"github.com/holisticode/bee/pkg/swarm"
 	"github.com/kardianos/service"
@@ -151,6 +153,27 @@ func (c *command) initStartCmd() (err error) {
 				return errors.New("static nodes can only be configured on bootnodes")
 			}
 
+			batchSelection, err := postage.ParseSelectionPolicy(c.config.GetString(optionNameBatchSelection))
+			if err != nil {
+				return err
+			}
+
+			var defaultBatchID []byte
+			if v := c.config.GetString(optionNameDefaultBatch); v != "" {
+				defaultBatchID, err = hex.DecodeString(v)
+				if err != nil || len(defaultBatchID) != 32 {
+					return fmt.Errorf("invalid postage batch ID %q configured as default batch", v)
+				}
+			}
+			if batchSelection == postage.SelectDefault && defaultBatchID == nil {
+				return errors.New("default batch selection requires a default postage batch")
+			}
+
+			networkKey, err := readNetworkKey(c.config.GetString(optionNameP2PNetworkKeyFile))
+			if err != nil {
+				return err
+			}
+
 			b, err := node.NewBee(c.config.GetString(optionNameP2PAddr), signerConfig.publicKey, signerConfig.signer, networkID, logger, signerConfig.libp2pPrivateKey, signerConfig.pssPrivateKey, &node.Options{
 				DataDir:                    c.config.GetString(optionNameDataDir),
 				CacheCapacity:              c.config.GetUint64(optionNameCacheCapacity),
@@ -163,6 +186,17 @@ func (c *command) initStartCmd() (err error) {
 				Addr:                       c.config.GetString(optionNameP2PAddr),
 				NATAddr:                    c.config.GetString(optionNameNATAddr),
 				EnableWS:                   c.config.GetBool(optionNameP2PWSEnable),
+				EnableQUIC:                 c.config.GetBool(optionNameP2PQUICEnable),
+				QUICAddr:                   c.config.GetString(optionNameP2PQUICAddr),
+				EnableMDNS:                 c.config.GetBool(optionNameP2PMDNSEnable),
+				P2PInboundLimit:            c.config.GetInt(optionNameP2PInboundLimit),
+				P2POutboundLimit:           c.config.GetInt(optionNameP2POutboundLimit),
+				P2PIPLimit:                 c.config.GetInt(optionNameP2PIPLimit),
+				P2PSubnetLimit:             c.config.GetInt(optionNameP2PSubnetLimit),
+				P2PNetworkLimits:           c.config.GetStringSlice(optionNameP2PNetworkLimits),
+				P2PBandwidthLimit:          c.config.GetInt(optionNameP2PBandwidthLimit),
+				P2PProtocolBandwidthLimits: c.config.GetStringSlice(optionNameP2PProtocolBandwidthLimits),
+				P2PNetworkKey:              networkKey,
 				WelcomeMessage:             c.config.GetString(optionWelcomeMessage),
 				Bootnodes:                  networkConfig.bootNodes,
 				CORSAllowedOrigins:         c.config.GetStringSlice(optionCORSAllowedOrigins),
@@ -200,6 +234,32 @@ func (c *command) initStartCmd() (err error) {
 				Restricted:                 c.config.GetBool(optionNameRestrictedAPI),
 				TokenEncryptionKey:         c.config.GetString(optionNameTokenEncryptionKey),
 				AdminPasswordHash:          c.config.GetString(optionNameAdminPasswordHash),
+				BatchSelectionPolicy:       batchSelection,
+				DefaultBatchID:             defaultBatchID,
+				EventWebhooks:              c.config.GetStringSlice(optionNameEventWebhooks),
+				EventWebhookRetries:        c.config.GetInt(optionNameEventWebhookRetries),
+				BatchExpiryWarning:         c.config.GetUint64(optionNameBatchExpiryWarning),
+				ChequebookLowBalance:       c.config.GetString(optionNameChequebookLowBalance),
+				WalletLowBalance:           c.config.GetString(optionNameWalletLowBalance),
+				DynamicPricing:             c.config.GetBool(optionNameDynamicPricing),
+				BudgetHourly:               c.config.GetString(optionNameBudgetHourly),
+				BudgetDaily:                c.config.GetString(optionNameBudgetDaily),
+				BudgetTotal:                c.config.GetString(optionNameBudgetTotal),
+				KeyBudgetHourly:            c.config.GetString(optionNameKeyBudgetHourly),
+				KeyBudgetDaily:             c.config.GetString(optionNameKeyBudgetDaily),
+				KeyBudgetTotal:             c.config.GetString(optionNameKeyBudgetTotal),
+				AutoCashout:                c.config.GetBool(optionNameAutoCashout),
+				AutoCashoutGasMultiple:     c.config.GetFloat64(optionNameAutoCashoutGasMultiple),
+				AutoCashoutInterval:        c.config.GetDuration(optionNameAutoCashoutInterval),
+				NativeExchangeRate:         c.config.GetString(optionNameNativeExchangeRate),
+				ChequebookRefillFloor:      c.config.GetString(optionNameChequebookRefillFloor),
+				ChequebookRefillTarget:     c.config.GetString(optionNameChequebookRefillTarget),
+				ChequebookRefillCap:        c.config.GetString(optionNameChequebookRefillCap),
+				ChequebookRefillPeriod:     c.config.GetDuration(optionNameChequebookRefillPeriod),
+				SettlementDrivers:          c.config.GetStringSlice(optionNameSettlementDrivers),
+				ConsortiumMembers:          c.config.GetStringSlice(optionNameConsortiumMembers),
+				TrustedPeers:               c.config.GetStringSlice(optionNameTrustedPeers),
+				SettlementHistoryRetention: c.config.GetDuration(optionNameSettlementHistoryRetention),
 			})
 			if err != nil {
 				return err
@@ -68,6 +68,11 @@ func (c *command) initStartDevCmd() (err error) {
 				debugAPIAddr = ""
 			}
 
+			networkKey, err := readNetworkKey(c.config.GetString(optionNameP2PNetworkKeyFile))
+			if err != nil {
+				return err
+			}
+
 			// generate signer in here
 			b, err := node.NewDevBee(logger, &node.DevOptions{
 				APIAddr:                  c.config.GetString(optionNameAPIAddr),
@@ -82,6 +87,7 @@ func (c *command) initStartDevCmd() (err error) {
 				Restricted:               c.config.GetBool(optionNameRestrictedAPI),
 				TokenEncryptionKey:       c.config.GetString(optionNameTokenEncryptionKey),
 				AdminPasswordHash:        c.config.GetString(optionNameAdminPasswordHash),
+				P2PNetworkKey:            networkKey,
 			})
 			if err != nil {
 				return err
@@ -163,6 +169,7 @@ func (c *command) initStartDevCmd() (err error) {
 	cmd.Flags().Bool(optionNameRestrictedAPI, false, "enable permission check on the http APIs")
 	cmd.Flags().String(optionNameTokenEncryptionKey, "", "security token encryption hash")
 	cmd.Flags().String(optionNameAdminPasswordHash, "", "bcrypt hash of the admin password to get the security token")
+	cmd.Flags().String(optionNameP2PNetworkKeyFile, "", "path to the pre-shared key file of a private network")
 
 	c.root.AddCommand(cmd)
 	return nil
@@ -0,0 +1,112 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/holisticode/bee/pkg/topology/graph"
+	"github.com/spf13/cobra"
+)
+
+const (
+	optionNameGraphFormat  = "format"
+	optionNameGraphTimeout = "timeout"
+)
+
+func (c *command) initTopologyCmd() {
+	cmd := &cobra.Command{
+		Use:   "topology",
+		Short: "Inspect the topology of a network of nodes",
+	}
+
+	topologyMergeCmd(cmd)
+
+	c.root.AddCommand(cmd)
+}
+
+func topologyMergeCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "merge <source>...",
+		Short: "Merge the topology graphs of nodes into a network-wide graph written to STDOUT. Sources are debug API URLs, JSON graph files or \"-\" for STDIN",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) == 0 {
+				return cmd.Help()
+			}
+
+			f, err := cmd.Flags().GetString(optionNameGraphFormat)
+			if err != nil {
+				return fmt.Errorf("get format: %w", err)
+			}
+			format, err := graph.ParseFormat(f)
+			if err != nil {
+				return err
+			}
+			timeout, err := cmd.Flags().GetDuration(optionNameGraphTimeout)
+			if err != nil {
+				return fmt.Errorf("get timeout: %w", err)
+			}
+			client := &http.Client{Timeout: timeout}
+
+			graphs := make([]*graph.Graph, 0, len(args))
+			for _, source := range args {
+				g, err := readTopologyGraph(cmd, client, source)
+				if err != nil {
+					return fmt.Errorf("topology graph %s: %w", source, err)
+				}
+				graphs = append(graphs, g)
+			}
+
+			return graph.Merge(graphs...).Write(cmd.OutOrStdout(), format)
+		},
+	}
+	c.Flags().String(optionNameGraphFormat, string(graph.FormatDOT), "format of the merged graph, one of json, dot or graphml")
+	c.Flags().Duration(optionNameGraphTimeout, 30*time.Second, "timeout of the requests to the debug APIs")
+	cmd.AddCommand(c)
+}
+
+// readTopologyGraph reads the JSON topology graph from the debug API with the
+// source URL, from the source file or from STDIN if the source is "-".
+func readTopologyGraph(cmd *cobra.Command, client *http.Client, source string) (*graph.Graph, error) {
+	var r io.Reader
+	switch {
+	case source == "-":
+		r = cmd.InOrStdin()
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		url := strings.TrimSuffix(source, "/") + "/topology/graph?format=" + string(graph.FormatJSON)
+		req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+		}
+		r = resp.Body
+	default:
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var g graph.Graph
+	if err := json.NewDecoder(r).Decode(&g); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	return &g, nil
+}
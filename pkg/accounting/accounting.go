@@ -37,9 +37,9 @@ var (
 // Interface is the Accounting interface.
 type Interface interface {
 	// Credit action to prevent overspending in case of concurrent requests.
-	PrepareCredit(peer swarm.Address, price uint64, originated bool) (Action, error)
+	PrepareCredit(peer swarm.Address, price uint64, originated bool, cause Cause) (Action, error)
 	// PrepareDebit returns an accounting Action for the later debit to be executed on and to implement shadowing a possibly credited part of reserve on the other side.
-	PrepareDebit(peer swarm.Address, price uint64) (Action, error)
+	PrepareDebit(peer swarm.Address, price uint64, cause Cause) (Action, error)
 	// Balance returns the current balance for the given peer.
 	Balance(peer swarm.Address) (*big.Int, error)
 	// SurplusBalance returns the current surplus balance for the given peer.
@@ -50,6 +50,12 @@ type Interface interface {
 	CompensatedBalance(peer swarm.Address) (*big.Int, error)
 	// CompensatedBalances returns the compensated balances for all known peers.
 	CompensatedBalances() (map[string]*big.Int, error)
+	// History returns the recorded balance changes with the given peer.
+	History(peer swarm.Address) ([]LedgerEntry, error)
+	// Budgets returns the state of the spending budgets of originated traffic.
+	Budgets() ([]BudgetStatus, error)
+	// Trusted reports whether traffic with the peer is exempt from accounting.
+	Trusted(peer swarm.Address) bool
 }
 
 // Action represents an accounting action that can be applied
@@ -66,6 +72,7 @@ type debitAction struct {
 	price          *big.Int
 	peer           swarm.Address
 	accountingPeer *accountingPeer
+	cause          Cause
 	applied        bool
 }
 
@@ -76,6 +83,7 @@ type creditAction struct {
 	peer           swarm.Address
 	accountingPeer *accountingPeer
 	originated     bool
+	cause          Cause
 	applied        bool
 }
 
@@ -85,6 +93,9 @@ type PayFunc func(context.Context, swarm.Address, *big.Int)
 // RefreshFunc is the function used for sync time-based settlement
 type RefreshFunc func(context.Context, swarm.Address, *big.Int, *big.Int) (*big.Int, int64, error)
 
+// DisputeFunc is the function notified of accounting disputes with a peer
+type DisputeFunc func(peer swarm.Address, reason string)
+
 // accountingPeer holds all in-memory accounting information for one peer.
 type accountingPeer struct {
 	lock                           sync.Mutex // lock to be held during any accounting action for this peer
@@ -97,6 +108,7 @@ type accountingPeer struct {
 	paymentOngoing                 bool  // indicate if we are currently settling with the peer
 	lastSettlementFailureTimestamp int64 // time of last unsuccessful attempt to issue a cheque
 	connected                      bool
+	trusted                        bool // traffic with the peer is free
 }
 
 // Accounting is the main implementation of the accounting interface.
@@ -119,6 +131,8 @@ type Accounting struct {
 	payFunction PayFunc
 	// function used for time settlement
 	refreshFunction RefreshFunc
+	// function notified of disputes, may be nil
+	disputeFunction DisputeFunc
 	// allowance based on time used in pseudo settle
 	refreshRate *big.Int
 	// lower bound for the value of issued cheques
@@ -128,6 +142,12 @@ type Accounting struct {
 	wg             sync.WaitGroup
 	p2p            p2p.Service
 	timeNow        func() time.Time
+	// append-only log of the balance changes per peer
+	ledger *ledger
+	// spending limits of originated traffic
+	budgets *budgets
+	// peers exempt from accounting
+	trustedPeers *trustedPeers
 }
 
 var (
@@ -153,7 +173,7 @@ func NewAccounting(
 	p2pService p2p.Service,
 
 ) (*Accounting, error) {
-	return &Accounting{
+	a := &Accounting{
 		accountingPeers:  make(map[string]*accountingPeer),
 		paymentThreshold: new(big.Int).Set(PaymentThreshold),
 		paymentTolerance: PaymentTolerance,
@@ -167,7 +187,10 @@ func NewAccounting(
 		timeNow:          time.Now,
 		minimumPayment:   new(big.Int).Div(refreshRate, big.NewInt(minimumPaymentDivisor)),
 		p2p:              p2pService,
-	}, nil
+		ledger:           newLedger(Store, ledgerSize, ledgerBatchSize),
+	}
+	a.budgets = newBudgets(Store, func() time.Time { return a.timeNow() })
+	return a, nil
 }
 
 func (a *Accounting) getIncreasedExpectedDebt(peer swarm.Address, accountingPeer *accountingPeer, bigPrice *big.Int) (*big.Int, *big.Int, error) {
@@ -195,7 +218,7 @@ func (a *Accounting) getIncreasedExpectedDebt(peer swarm.Address, accountingPeer
 	return new(big.Int).Add(expectedDebt, additionalDebt), currentBalance, nil
 }
 
-func (a *Accounting) PrepareCredit(peer swarm.Address, price uint64, originated bool) (Action, error) {
+func (a *Accounting) PrepareCredit(peer swarm.Address, price uint64, originated bool, cause Cause) (Action, error) {
 	accountingPeer := a.getAccountingPeer(peer)
 
 	accountingPeer.lock.Lock()
@@ -205,6 +228,11 @@ func (a *Accounting) PrepareCredit(peer swarm.Address, price uint64, originated
 		return nil, fmt.Errorf("connection not initialized yet")
 	}
 
+	if accountingPeer.trusted {
+		a.metrics.TrustedEventsCount.Inc()
+		return freeAction{}, nil
+	}
+
 	a.metrics.AccountingReserveCount.Inc()
 	bigPrice := new(big.Int).SetUint64(price)
 
@@ -242,6 +270,15 @@ func (a *Accounting) PrepareCredit(peer swarm.Address, price uint64, originated
 		return nil, ErrOverdraft
 	}
 
+	if originated {
+		if err := a.budgets.reserve(cause.Budget, bigPrice); err != nil {
+			if errors.Is(err, ErrBudgetExceeded) {
+				a.metrics.BudgetExceededCount.Inc()
+			}
+			return nil, err
+		}
+	}
+
 	accountingPeer.reservedBalance = new(big.Int).Add(accountingPeer.reservedBalance, bigPrice)
 	return &creditAction{
 		accounting:     a,
@@ -249,6 +286,7 @@ func (a *Accounting) PrepareCredit(peer swarm.Address, price uint64, originated
 		peer:           peer,
 		accountingPeer: accountingPeer,
 		originated:     originated,
+		cause:          cause,
 	}, nil
 }
 
@@ -273,6 +311,8 @@ func (c *creditAction) Apply() error {
 		return fmt.Errorf("failed to persist balance: %w", err)
 	}
 
+	c.accounting.recordLedger(c.peer, LedgerCredit, c.price, nextBalance, c.cause)
+
 	c.accounting.metrics.TotalCreditedAmount.Add(float64(c.price.Int64()))
 	c.accounting.metrics.CreditEventsCount.Inc()
 
@@ -318,6 +358,10 @@ func (c *creditAction) Apply() error {
 	}
 
 	c.applied = true
+
+	if err := c.accounting.budgets.release(c.cause.Budget, c.price, true); err != nil {
+		c.accounting.logger.Errorf("accounting: charge budget: %v", err)
+	}
 	return nil
 }
 
@@ -335,6 +379,12 @@ func (c *creditAction) Cleanup() {
 	} else {
 		c.accountingPeer.reservedBalance.Sub(c.accountingPeer.reservedBalance, c.price)
 	}
+
+	if c.originated {
+		if err := c.accounting.budgets.release(c.cause.Budget, c.price, false); err != nil {
+			c.accounting.logger.Errorf("accounting: release budget: %v", err)
+		}
+	}
 }
 
 // Settle all debt with a peer. The lock on the accountingPeer must be held when
@@ -396,6 +446,8 @@ func (a *Accounting) settle(peer swarm.Address, balance *accountingPeer) error {
 			return fmt.Errorf("settle: failed to persist balance: %w", err)
 		}
 
+		a.recordLedger(peer, LedgerRefreshmentSent, acceptedAmount, oldBalance, Cause{})
+
 		err = a.decreaseOriginatedBalanceTo(peer, oldBalance)
 		if err != nil {
 			return fmt.Errorf("settle: failed to decrease originated balance: %w", err)
@@ -781,6 +833,8 @@ func (a *Accounting) NotifyPaymentSent(peer swarm.Address, amount *big.Int, rece
 		return
 	}
 
+	a.recordLedger(peer, LedgerPaymentSent, amount, nextBalance, Cause{})
+
 	err = a.decreaseOriginatedBalanceBy(peer, amount)
 	if err != nil {
 		a.logger.Warningf("accounting: notifypaymentsent failed to decrease originated balance: %v", err)
@@ -829,6 +883,8 @@ func (a *Accounting) NotifyPaymentReceived(peer swarm.Address, amount *big.Int)
 			return fmt.Errorf("failed to persist surplus balance: %w", err)
 		}
 
+		a.recordLedger(peer, LedgerPaymentReceived, amount, currentBalance, Cause{})
+
 		return nil
 	}
 
@@ -850,6 +906,8 @@ func (a *Accounting) NotifyPaymentReceived(peer swarm.Address, amount *big.Int)
 		return fmt.Errorf("failed to persist balance: %w", err)
 	}
 
+	a.recordLedger(peer, LedgerPaymentReceived, amount, nextBalance, Cause{})
+
 	// If payment would have put us into debt, rather, let's add to surplusBalance,
 	// so as that an oversettlement attempt creates balance for future forwarding services
 	// charges to be deducted of
@@ -898,11 +956,13 @@ func (a *Accounting) NotifyRefreshmentReceived(peer swarm.Address, amount *big.I
 		return fmt.Errorf("failed to persist balance: %w", err)
 	}
 
+	a.recordLedger(peer, LedgerRefreshmentReceived, amount, nextBalance, Cause{})
+
 	return nil
 }
 
 // PrepareDebit prepares a debit operation by increasing the shadowReservedBalance
-func (a *Accounting) PrepareDebit(peer swarm.Address, price uint64) (Action, error) {
+func (a *Accounting) PrepareDebit(peer swarm.Address, price uint64, cause Cause) (Action, error) {
 	accountingPeer := a.getAccountingPeer(peer)
 
 	accountingPeer.lock.Lock()
@@ -912,6 +972,11 @@ func (a *Accounting) PrepareDebit(peer swarm.Address, price uint64) (Action, err
 		return nil, fmt.Errorf("connection not initialized yet")
 	}
 
+	if accountingPeer.trusted {
+		a.metrics.TrustedEventsCount.Inc()
+		return freeAction{}, nil
+	}
+
 	bigPrice := new(big.Int).SetUint64(price)
 
 	accountingPeer.shadowReservedBalance = new(big.Int).Add(accountingPeer.shadowReservedBalance, bigPrice)
@@ -921,6 +986,7 @@ func (a *Accounting) PrepareDebit(peer swarm.Address, price uint64) (Action, err
 		price:          bigPrice,
 		peer:           peer,
 		accountingPeer: accountingPeer,
+		cause:          cause,
 		applied:        false,
 	}, nil
 }
@@ -1008,6 +1074,8 @@ func (d *debitAction) Apply() error {
 		return err
 	}
 
+	a.recordLedger(d.peer, LedgerDebit, d.price, nextBalance, d.cause)
+
 	d.applied = true
 	d.accountingPeer.shadowReservedBalance = new(big.Int).Sub(d.accountingPeer.shadowReservedBalance, d.price)
 
@@ -1019,6 +1087,7 @@ func (d *debitAction) Apply() error {
 	if nextBalance.Cmp(a.disconnectLimit) >= 0 {
 		// peer too much in debt
 		a.metrics.AccountingDisconnectsOverdrawCount.Inc()
+		a.dispute(d.peer, "disconnect threshold exceeded")
 
 		disconnectFor, err := a.blocklistUntil(d.peer, 1)
 		if err != nil {
@@ -1071,16 +1140,23 @@ func (a *Accounting) blocklistUntil(peer swarm.Address, multiplier int64) (int64
 }
 
 func (a *Accounting) blocklist(peer swarm.Address, multiplier int64, reason string) error {
+	if a.Trusted(peer) {
+		a.logger.Debugf("accounting: not blocklisting trusted peer %v: %s", peer, reason)
+		return nil
+	}
+
+	a.dispute(peer, reason)
 
 	disconnectFor, err := a.blocklistUntil(peer, multiplier)
 	if err != nil {
-		return a.p2p.Blocklist(peer, 1*time.Minute, reason)
+		return p2p.BlocklistBy(a.p2p, "accounting", peer, 1*time.Minute, reason)
 	}
 
-	return a.p2p.Blocklist(peer, time.Duration(disconnectFor)*time.Second, reason)
+	return p2p.BlocklistBy(a.p2p, "accounting", peer, time.Duration(disconnectFor)*time.Second, reason)
 }
 
-func (a *Accounting) Connect(peer swarm.Address) {
+func (a *Accounting) Connect(p p2p.Peer) {
+	peer := p.Address
 	accountingPeer := a.getAccountingPeer(peer)
 	zero := big.NewInt(0)
 
@@ -1088,6 +1164,7 @@ func (a *Accounting) Connect(peer swarm.Address) {
 	defer accountingPeer.lock.Unlock()
 
 	accountingPeer.connected = true
+	accountingPeer.trusted = a.trustedPeers != nil && a.trustedPeers.resolve(p)
 	accountingPeer.shadowReservedBalance.Set(zero)
 	accountingPeer.ghostBalance.Set(zero)
 	accountingPeer.reservedBalance.Set(zero)
@@ -1158,12 +1235,15 @@ func (a *Accounting) Disconnect(peer swarm.Address) {
 	defer accountingPeer.lock.Unlock()
 
 	if accountingPeer.connected {
+		accountingPeer.connected = false
+		if accountingPeer.trusted {
+			return
+		}
 		disconnectFor, err := a.blocklistUntil(peer, 1)
 		if err != nil {
 			disconnectFor = int64(60)
 		}
-		accountingPeer.connected = false
-		_ = a.p2p.Blocklist(peer, time.Duration(disconnectFor)*time.Second, "disconnected")
+		_ = p2p.BlocklistBy(a.p2p, "accounting", peer, time.Duration(disconnectFor)*time.Second, "disconnected")
 	}
 }
 
@@ -1175,8 +1255,21 @@ func (a *Accounting) SetPayFunc(f PayFunc) {
 	a.payFunction = f
 }
 
-// Close hangs up running websockets on shutdown.
+// SetDisputeFunc sets the function notified of accounting disputes.
+func (a *Accounting) SetDisputeFunc(f DisputeFunc) {
+	a.disputeFunction = f
+}
+
+// dispute notifies the dispute function of a dispute with the peer.
+func (a *Accounting) dispute(peer swarm.Address, reason string) {
+	if a.disputeFunction != nil {
+		a.disputeFunction(peer, reason)
+	}
+}
+
+// Close hangs up running websockets on shutdown and persists the buffered
+// ledger entries.
 func (a *Accounting) Close() error {
 	a.wg.Wait()
-	return nil
+	return a.ledger.flush()
 }
@@ -7,6 +7,7 @@ package accounting_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
 	"testing"
@@ -16,6 +17,7 @@ import (
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/p2p"
 	p2pmock "github.com/holisticode/bee/pkg/p2p/mock"
+	"github.com/holisticode/bee/pkg/statestore/leveldb"
 	"github.com/holisticode/bee/pkg/statestore/mock"
 
 	"github.com/holisticode/bee/pkg/swarm"
@@ -70,8 +72,8 @@ func TestAccountingAddBalance(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	acc.Connect(peer1Addr)
-	acc.Connect(peer2Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
+	acc.Connect(p2p.Peer{Address: peer2Addr})
 
 	bookings := []booking{
 		{peer: peer1Addr, price: 100, expectedBalance: 100},
@@ -83,7 +85,7 @@ func TestAccountingAddBalance(t *testing.T) {
 
 	for i, booking := range bookings {
 		if booking.price < 0 {
-			creditAction, err := acc.PrepareCredit(booking.peer, uint64(-booking.price), true)
+			creditAction, err := acc.PrepareCredit(booking.peer, uint64(-booking.price), true, accounting.Cause{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -93,7 +95,7 @@ func TestAccountingAddBalance(t *testing.T) {
 			}
 			creditAction.Cleanup()
 		} else {
-			debitAction, err := acc.PrepareDebit(booking.peer, uint64(booking.price))
+			debitAction, err := acc.PrepareDebit(booking.peer, uint64(booking.price), accounting.Cause{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -138,7 +140,7 @@ func TestAccountingAddOriginatedBalance(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	acc.Connect(peer1Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
 	bookings := []booking{
 		// originated credit
@@ -165,7 +167,7 @@ func TestAccountingAddOriginatedBalance(t *testing.T) {
 
 		pay := func(ctx context.Context, peer swarm.Address, amount *big.Int) {
 			if booking.overpay != 0 {
-				debitAction, err := acc.PrepareDebit(peer, booking.overpay)
+				debitAction, err := acc.PrepareDebit(peer, booking.overpay, accounting.Cause{})
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -179,7 +181,7 @@ func TestAccountingAddOriginatedBalance(t *testing.T) {
 		acc.SetPayFunc(pay)
 
 		if booking.price < 0 {
-			creditAction, err := acc.PrepareCredit(booking.peer, uint64(-booking.price), booking.originatedCredit)
+			creditAction, err := acc.PrepareCredit(booking.peer, uint64(-booking.price), booking.originatedCredit, accounting.Cause{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -198,7 +200,7 @@ func TestAccountingAddOriginatedBalance(t *testing.T) {
 			}
 			creditAction.Cleanup()
 		} else {
-			debitAction, err := acc.PrepareDebit(booking.peer, uint64(booking.price))
+			debitAction, err := acc.PrepareDebit(booking.peer, uint64(booking.price), accounting.Cause{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -253,11 +255,11 @@ func TestAccountingAdd_persistentBalances(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	acc.Connect(peer1Addr)
-	acc.Connect(peer2Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
+	acc.Connect(p2p.Peer{Address: peer2Addr})
 
 	peer1DebitAmount := testPrice
-	debitAction, err := acc.PrepareDebit(peer1Addr, peer1DebitAmount)
+	debitAction, err := acc.PrepareDebit(peer1Addr, peer1DebitAmount, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -268,7 +270,7 @@ func TestAccountingAdd_persistentBalances(t *testing.T) {
 	debitAction.Cleanup()
 
 	peer2CreditAmount := 2 * testPrice
-	creditAction, err := acc.PrepareCredit(peer2Addr, peer2CreditAmount, true)
+	creditAction, err := acc.PrepareCredit(peer2Addr, peer2CreditAmount, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -318,9 +320,9 @@ func TestAccountingReserve(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	acc.Connect(peer1Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
-	_, err = acc.PrepareCredit(peer1Addr, testPaymentThreshold.Uint64()+1, true)
+	_, err = acc.PrepareCredit(peer1Addr, testPaymentThreshold.Uint64()+1, true, accounting.Cause{})
 	if err == nil {
 		t.Fatal("expected error from reserve")
 	}
@@ -347,10 +349,15 @@ func TestAccountingDisconnect(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	acc.Connect(peer1Addr)
+	var disputed []swarm.Address
+	acc.SetDisputeFunc(func(peer swarm.Address, _ string) {
+		disputed = append(disputed, peer)
+	})
+
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
 	// put the peer 1 unit away from disconnect
-	debitAction, err := acc.PrepareDebit(peer1Addr, (testPaymentThreshold.Uint64()*(100+uint64(testPaymentTolerance))/100)-1)
+	debitAction, err := acc.PrepareDebit(peer1Addr, (testPaymentThreshold.Uint64()*(100+uint64(testPaymentTolerance))/100)-1, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -360,8 +367,12 @@ func TestAccountingDisconnect(t *testing.T) {
 	}
 	debitAction.Cleanup()
 
+	if len(disputed) != 0 {
+		t.Fatalf("got disputes %v within tolerance", disputed)
+	}
+
 	// put the peer over thee threshold
-	debitAction, err = acc.PrepareDebit(peer1Addr, 1)
+	debitAction, err = acc.PrepareDebit(peer1Addr, 1, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -375,6 +386,10 @@ func TestAccountingDisconnect(t *testing.T) {
 	if !errors.As(err, &e) {
 		t.Fatalf("expected BlockPeerError, got %v", err)
 	}
+
+	if len(disputed) != 1 || !disputed[0].Equal(peer1Addr) {
+		t.Fatalf("got disputes %v, want %v", disputed, peer1Addr)
+	}
 }
 
 // TestAccountingCallSettlement tests that settlement is called correctly if the payment threshold is hit
@@ -407,11 +422,11 @@ func TestAccountingCallSettlement(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	acc.Connect(peer1Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
 	requestPrice := testPaymentThreshold.Uint64() - 1000
 
-	creditAction, err := acc.PrepareCredit(peer1Addr, requestPrice, true)
+	creditAction, err := acc.PrepareCredit(peer1Addr, requestPrice, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -425,7 +440,7 @@ func TestAccountingCallSettlement(t *testing.T) {
 	creditAction.Cleanup()
 
 	// try another request
-	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true)
+	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -457,14 +472,14 @@ func TestAccountingCallSettlement(t *testing.T) {
 	}
 
 	// Assume 100 is reserved by some other request
-	creditActionLong, err := acc.PrepareCredit(peer1Addr, 100, true)
+	creditActionLong, err := acc.PrepareCredit(peer1Addr, 100, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// Credit until the expected debt exceeds payment threshold
 	expectedAmount := testPaymentThreshold.Uint64() - 101
-	creditAction, err = acc.PrepareCredit(peer1Addr, expectedAmount, true)
+	creditAction, err = acc.PrepareCredit(peer1Addr, expectedAmount, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -477,7 +492,7 @@ func TestAccountingCallSettlement(t *testing.T) {
 	creditAction.Cleanup()
 
 	// try another request to trigger settlement
-	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true)
+	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -532,11 +547,11 @@ func TestAccountingCallSettlementMonetary(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	acc.Connect(peer1Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
 	requestPrice := testPaymentThreshold.Uint64() - 1000
 
-	creditAction, err := acc.PrepareCredit(peer1Addr, requestPrice, true)
+	creditAction, err := acc.PrepareCredit(peer1Addr, requestPrice, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -550,7 +565,7 @@ func TestAccountingCallSettlementMonetary(t *testing.T) {
 	creditAction.Cleanup()
 
 	// try another request
-	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true)
+	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -597,7 +612,7 @@ func TestAccountingCallSettlementMonetary(t *testing.T) {
 	// Credit until the expected debt exceeds payment threshold
 	expectedAmount := testPaymentThreshold.Uint64()
 
-	_, err = acc.PrepareCredit(peer1Addr, expectedAmount, true)
+	_, err = acc.PrepareCredit(peer1Addr, expectedAmount, true, accounting.Cause{})
 	if !errors.Is(err, accounting.ErrOverdraft) {
 		t.Fatalf("expected overdraft, got %v", err)
 	}
@@ -651,11 +666,11 @@ func TestAccountingCallSettlementTooSoon(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	acc.Connect(peer1Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
 	requestPrice := testPaymentThreshold.Uint64() - 1000
 
-	creditAction, err := acc.PrepareCredit(peer1Addr, requestPrice, true)
+	creditAction, err := acc.PrepareCredit(peer1Addr, requestPrice, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -669,7 +684,7 @@ func TestAccountingCallSettlementTooSoon(t *testing.T) {
 	creditAction.Cleanup()
 
 	// try another request
-	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true)
+	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -698,7 +713,7 @@ func TestAccountingCallSettlementTooSoon(t *testing.T) {
 
 	acc.SetTime(ts)
 
-	creditAction, err = acc.PrepareCredit(peer1Addr, requestPrice, true)
+	creditAction, err = acc.PrepareCredit(peer1Addr, requestPrice, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -712,7 +727,7 @@ func TestAccountingCallSettlementTooSoon(t *testing.T) {
 	creditAction.Cleanup()
 
 	// try another request
-	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true)
+	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -741,7 +756,7 @@ func TestAccountingCallSettlementTooSoon(t *testing.T) {
 	acc.SetTime(ts + 1)
 
 	// try another request
-	_, err = acc.PrepareCredit(peer1Addr, 1, true)
+	_, err = acc.PrepareCredit(peer1Addr, 1, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -788,9 +803,9 @@ func TestAccountingCallSettlementEarly(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	acc.Connect(peer1Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
-	creditAction, err := acc.PrepareCredit(peer1Addr, debt, true)
+	creditAction, err := acc.PrepareCredit(peer1Addr, debt, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -800,7 +815,7 @@ func TestAccountingCallSettlementEarly(t *testing.T) {
 	creditAction.Cleanup()
 
 	payment := testPaymentThreshold.Uint64() * (100 - uint64(earlyPayment)) / 100
-	creditAction, err = acc.PrepareCredit(peer1Addr, payment, true)
+	creditAction, err = acc.PrepareCredit(peer1Addr, payment, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -842,10 +857,10 @@ func TestAccountingSurplusBalance(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	acc.Connect(peer1Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
 	// Try Debiting a large amount to peer so balance is large positive
-	debitAction, err := acc.PrepareDebit(peer1Addr, testPaymentThreshold.Uint64()-1)
+	debitAction, err := acc.PrepareDebit(peer1Addr, testPaymentThreshold.Uint64()-1, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -897,7 +912,7 @@ func TestAccountingSurplusBalance(t *testing.T) {
 		t.Fatal("Not expected balance, expected 0")
 	}
 	// Debit for same peer, so balance stays 0 with surplusbalance decreasing to 2
-	debitAction, err = acc.PrepareDebit(peer1Addr, testPaymentThreshold.Uint64())
+	debitAction, err = acc.PrepareDebit(peer1Addr, testPaymentThreshold.Uint64(), accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -923,7 +938,7 @@ func TestAccountingSurplusBalance(t *testing.T) {
 		t.Fatal("Not expected balance, expected 0")
 	}
 	// Debit for same peer, so balance goes to 9998 (testpaymentthreshold - 2) with surplusbalance decreasing to 0
-	debitAction, err = acc.PrepareDebit(peer1Addr, testPaymentThreshold.Uint64())
+	debitAction, err = acc.PrepareDebit(peer1Addr, testPaymentThreshold.Uint64(), accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -967,10 +982,10 @@ func TestAccountingNotifyPaymentReceived(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	acc.Connect(peer1Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
 	debtAmount := uint64(100)
-	debitAction, err := acc.PrepareDebit(peer1Addr, debtAmount)
+	debitAction, err := acc.PrepareDebit(peer1Addr, debtAmount, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -985,7 +1000,7 @@ func TestAccountingNotifyPaymentReceived(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	debitAction, err = acc.PrepareDebit(peer1Addr, debtAmount)
+	debitAction, err = acc.PrepareDebit(peer1Addr, debtAmount, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1077,7 +1092,7 @@ func TestAccountingNotifyPaymentThreshold(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	acc.Connect(peer1Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
 	debt := uint64(50)
 	lowerThreshold := uint64(100)
@@ -1087,7 +1102,7 @@ func TestAccountingNotifyPaymentThreshold(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	creditAction, err := acc.PrepareCredit(peer1Addr, debt, true)
+	creditAction, err := acc.PrepareCredit(peer1Addr, debt, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1096,7 +1111,7 @@ func TestAccountingNotifyPaymentThreshold(t *testing.T) {
 	}
 	creditAction.Cleanup()
 
-	_, err = acc.PrepareCredit(peer1Addr, lowerThreshold, true)
+	_, err = acc.PrepareCredit(peer1Addr, lowerThreshold, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1128,10 +1143,10 @@ func TestAccountingPeerDebt(t *testing.T) {
 	}
 
 	peer1Addr := swarm.MustParseHexAddress("00112233")
-	acc.Connect(peer1Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
 	debt := uint64(1000)
-	debitAction, err := acc.PrepareDebit(peer1Addr, debt)
+	debitAction, err := acc.PrepareDebit(peer1Addr, debt, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1149,8 +1164,8 @@ func TestAccountingPeerDebt(t *testing.T) {
 	}
 
 	peer2Addr := swarm.MustParseHexAddress("11112233")
-	acc.Connect(peer2Addr)
-	creditAction, err := acc.PrepareCredit(peer2Addr, 500, true)
+	acc.Connect(p2p.Peer{Address: peer2Addr})
+	creditAction, err := acc.PrepareCredit(peer2Addr, 500, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1206,12 +1221,12 @@ func TestAccountingCallPaymentFailureRetries(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	acc.Connect(peer1Addr)
+	acc.Connect(p2p.Peer{Address: peer1Addr})
 
 	requestPrice := testPaymentThreshold.Uint64() - 100
 
 	// Credit until near payment threshold
-	creditAction, err := acc.PrepareCredit(peer1Addr, requestPrice, true)
+	creditAction, err := acc.PrepareCredit(peer1Addr, requestPrice, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1220,7 +1235,7 @@ func TestAccountingCallPaymentFailureRetries(t *testing.T) {
 	}
 	creditAction.Cleanup()
 
-	creditAction, err = acc.PrepareCredit(peer1Addr, 2, true)
+	creditAction, err = acc.PrepareCredit(peer1Addr, 2, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1248,7 +1263,7 @@ func TestAccountingCallPaymentFailureRetries(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		ts++
 		acc.SetTime(ts)
-		creditAction, err = acc.PrepareCredit(peer1Addr, 2, true)
+		creditAction, err = acc.PrepareCredit(peer1Addr, 2, true, accounting.Cause{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -1270,7 +1285,7 @@ func TestAccountingCallPaymentFailureRetries(t *testing.T) {
 	acc.SetTime(ts)
 
 	// try another request
-	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true)
+	creditAction, err = acc.PrepareCredit(peer1Addr, 1, true, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1323,11 +1338,11 @@ func TestAccountingGhostOverdraft(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	acc.Connect(peer)
+	acc.Connect(p2p.Peer{Address: peer})
 
 	requestPrice := testPaymentThreshold.Uint64()
 
-	debitActionNormal, err := acc.PrepareDebit(peer, requestPrice)
+	debitActionNormal, err := acc.PrepareDebit(peer, requestPrice, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1338,14 +1353,14 @@ func TestAccountingGhostOverdraft(t *testing.T) {
 	debitActionNormal.Cleanup()
 
 	// debit ghost balance
-	debitActionGhost, err := acc.PrepareDebit(peer, requestPrice)
+	debitActionGhost, err := acc.PrepareDebit(peer, requestPrice, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
 	debitActionGhost.Cleanup()
 
 	// increase shadow reserve
-	debitActionShadow, err := acc.PrepareDebit(peer, requestPrice)
+	debitActionShadow, err := acc.PrepareDebit(peer, requestPrice, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1356,7 +1371,7 @@ func TestAccountingGhostOverdraft(t *testing.T) {
 	}
 
 	// ghost overdraft triggering blocklist
-	debitAction4, err := acc.PrepareDebit(peer, requestPrice)
+	debitAction4, err := acc.PrepareDebit(peer, requestPrice, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1397,11 +1412,11 @@ func TestAccountingReconnectBeforeAllowed(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	acc.Connect(peer)
+	acc.Connect(p2p.Peer{Address: peer})
 
 	requestPrice := testPaymentThreshold.Uint64()
 
-	debitActionNormal, err := acc.PrepareDebit(peer, requestPrice)
+	debitActionNormal, err := acc.PrepareDebit(peer, requestPrice, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1412,14 +1427,14 @@ func TestAccountingReconnectBeforeAllowed(t *testing.T) {
 	debitActionNormal.Cleanup()
 
 	// debit ghost balance
-	debitActionGhost, err := acc.PrepareDebit(peer, requestPrice)
+	debitActionGhost, err := acc.PrepareDebit(peer, requestPrice, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
 	debitActionGhost.Cleanup()
 
 	// increase shadow reserve
-	debitActionShadow, err := acc.PrepareDebit(peer, requestPrice)
+	debitActionShadow, err := acc.PrepareDebit(peer, requestPrice, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1467,11 +1482,11 @@ func TestAccountingResetBalanceAfterReconnect(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	acc.Connect(peer)
+	acc.Connect(p2p.Peer{Address: peer})
 
 	requestPrice := testPaymentThreshold.Uint64()
 
-	debitActionNormal, err := acc.PrepareDebit(peer, requestPrice)
+	debitActionNormal, err := acc.PrepareDebit(peer, requestPrice, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1482,14 +1497,14 @@ func TestAccountingResetBalanceAfterReconnect(t *testing.T) {
 	debitActionNormal.Cleanup()
 
 	// debit ghost balance
-	debitActionGhost, err := acc.PrepareDebit(peer, requestPrice)
+	debitActionGhost, err := acc.PrepareDebit(peer, requestPrice, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
 	debitActionGhost.Cleanup()
 
 	// increase shadow reserve
-	debitActionShadow, err := acc.PrepareDebit(peer, requestPrice)
+	debitActionShadow, err := acc.PrepareDebit(peer, requestPrice, accounting.Cause{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1505,7 +1520,7 @@ func TestAccountingResetBalanceAfterReconnect(t *testing.T) {
 		t.Fatalf("unexpected blocklisting time, got %v expected %v", blocklistTime, 4*paymentThresholdInRefreshmentSeconds)
 	}
 
-	acc.Connect(peer)
+	acc.Connect(p2p.Peer{Address: peer})
 
 	balance, err := acc.Balance(peer)
 	if err != nil {
@@ -1526,3 +1541,334 @@ func TestAccountingResetBalanceAfterReconnect(t *testing.T) {
 	}
 
 }
+
+// TestAccountingLedger tests that balance changes are recorded in the ledger
+// with their cause and that the ledger is bounded and persisted.
+func TestAccountingLedger(t *testing.T) {
+	defer func(size uint64) {
+		*accounting.LedgerSize = size
+	}(*accounting.LedgerSize)
+	*accounting.LedgerSize = 3
+
+	logger := logging.New(io.Discard, 0)
+
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, logger, store, nil, big.NewInt(testRefreshRate), p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc.SetTime(1000)
+
+	peer := swarm.MustParseHexAddress("00112233")
+	chunk := swarm.MustParseHexAddress("aabb")
+	acc.Connect(p2p.Peer{Address: peer})
+
+	creditAction, err := acc.PrepareCredit(peer, testPrice, true, accounting.Cause{Protocol: "retrieval", Chunk: chunk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = creditAction.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	creditAction.Cleanup()
+
+	debitAction, err := acc.PrepareDebit(peer, 3*testPrice, accounting.Cause{Protocol: "pushsync", Chunk: chunk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = debitAction.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	debitAction.Cleanup()
+
+	if err = acc.NotifyPaymentReceived(peer, new(big.Int).SetUint64(testPrice)); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := acc.History(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []accounting.LedgerEntry{
+		{
+			Timestamp: time.Unix(1000, 0).UTC(),
+			Type:      accounting.LedgerCredit,
+			Amount:    new(big.Int).SetUint64(testPrice),
+			Balance:   big.NewInt(-int64(testPrice)),
+			Protocol:  "retrieval",
+			Chunk:     chunk,
+		},
+		{
+			Timestamp: time.Unix(1000, 0).UTC(),
+			Type:      accounting.LedgerDebit,
+			Amount:    new(big.Int).SetUint64(3 * testPrice),
+			Balance:   big.NewInt(2 * int64(testPrice)),
+			Protocol:  "pushsync",
+			Chunk:     chunk,
+		},
+		{
+			Timestamp: time.Unix(1000, 0).UTC(),
+			Type:      accounting.LedgerPaymentReceived,
+			Amount:    new(big.Int).SetUint64(testPrice),
+			Balance:   big.NewInt(int64(testPrice)),
+			Chunk:     swarm.ZeroAddress,
+		},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("got %d history entries, want %d", len(history), len(want))
+	}
+	for i, e := range history {
+		w := want[i]
+		if !e.Timestamp.Equal(w.Timestamp) || e.Type != w.Type || e.Amount.Cmp(w.Amount) != 0 || e.Balance.Cmp(w.Balance) != 0 || e.Protocol != w.Protocol || !e.Chunk.Equal(w.Chunk) {
+			t.Fatalf("got history entry %d %+v, want %+v", i, e, w)
+		}
+	}
+
+	// a new instance continues the ledger persisted on close and drops the
+	// oldest entry
+	if err := acc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	acc, err = accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, logger, store, nil, big.NewInt(testRefreshRate), p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc.SetTime(1001)
+
+	if err = acc.NotifyRefreshmentReceived(peer, new(big.Int).SetUint64(testPrice)); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err = acc.History(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("got %d history entries, want 3", len(history))
+	}
+	if history[0].Type != accounting.LedgerDebit {
+		t.Fatalf("got oldest entry type %s, want %s", history[0].Type, accounting.LedgerDebit)
+	}
+	last := history[2]
+	if last.Type != accounting.LedgerRefreshmentReceived || last.Balance.Int64() != 0 || !last.Timestamp.Equal(time.Unix(1001, 0)) {
+		t.Fatalf("got last entry %+v", last)
+	}
+}
+
+// TestAccountingLedgerBatch tests that ledger entries are persisted in
+// batches, on close and that old batches are dropped.
+func TestAccountingLedgerBatch(t *testing.T) {
+	defer func(size uint64, batchSize int) {
+		*accounting.LedgerSize = size
+		*accounting.LedgerBatchSize = batchSize
+	}(*accounting.LedgerSize, *accounting.LedgerBatchSize)
+	*accounting.LedgerSize = 3
+	*accounting.LedgerBatchSize = 2
+
+	logger := logging.New(io.Discard, 0)
+
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, logger, store, nil, big.NewInt(testRefreshRate), p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peer := swarm.MustParseHexAddress("00112233")
+	acc.Connect(p2p.Peer{Address: peer})
+
+	persisted := func() int {
+		t.Helper()
+		var n int
+		err := store.Iterate("accounting_ledger_", func(_, _ []byte) (bool, error) {
+			n++
+			return false, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	for i, want := range []int{0, 1, 1, 2, 2} {
+		debitAction, err := acc.PrepareDebit(peer, testPrice, accounting.Cause{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = debitAction.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		debitAction.Cleanup()
+
+		if got := persisted(); got != want {
+			t.Fatalf("debit %d: got %d persisted batches, want %d", i, got, want)
+		}
+	}
+
+	if err := acc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := persisted(); got != 2 {
+		t.Fatalf("got %d persisted batches after close, want 2", got)
+	}
+
+	acc, err = accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, logger, store, nil, big.NewInt(testRefreshRate), p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := acc.History(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("got %d history entries, want 3", len(history))
+	}
+	for i, e := range history {
+		if want := int64(i+3) * int64(testPrice); e.Balance.Int64() != want {
+			t.Fatalf("got balance %d of history entry %d, want %d", e.Balance, i, want)
+		}
+	}
+}
+
+func BenchmarkAccountingApply(b *testing.B) {
+	logger := logging.New(io.Discard, 0)
+
+	store, err := leveldb.NewInMemoryStateStore(logger)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, logger, store, nil, big.NewInt(testRefreshRate), p2pmock.New())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	peers := make([]swarm.Address, 8)
+	for i := range peers {
+		peers[i] = swarm.MustParseHexAddress(fmt.Sprintf("%064x", i+1))
+		acc.Connect(p2p.Peer{Address: peers[i]})
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i int
+		for pb.Next() {
+			peer := peers[i%len(peers)]
+			i++
+			debitAction, err := acc.PrepareDebit(peer, testPrice, accounting.Cause{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := debitAction.Apply(); err != nil {
+				b.Fatal(err)
+			}
+			debitAction.Cleanup()
+
+			creditAction, err := acc.PrepareCredit(peer, testPrice, true, accounting.Cause{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := creditAction.Apply(); err != nil {
+				b.Fatal(err)
+			}
+			creditAction.Cleanup()
+		}
+	})
+}
+
+func TestAccountingBudget(t *testing.T) {
+	logger := logging.New(io.Discard, 0)
+
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, logger, store, nil, big.NewInt(testRefreshRate), p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc.SetTime(1000)
+	acc.SetBudgets(
+		accounting.BudgetLimits{Hourly: new(big.Int).SetUint64(3 * testPrice)},
+		accounting.BudgetLimits{Total: new(big.Int).SetUint64(2 * testPrice)},
+	)
+
+	peer := swarm.MustParseHexAddress("00112233")
+	acc.Connect(p2p.Peer{Address: peer})
+
+	credit := func(originated bool, budget string) error {
+		creditAction, err := acc.PrepareCredit(peer, testPrice, originated, accounting.Cause{Budget: budget})
+		if err != nil {
+			return err
+		}
+		defer creditAction.Cleanup()
+		return creditAction.Apply()
+	}
+
+	// a reservation which is not applied is not charged
+	creditAction, err := acc.PrepareCredit(peer, 3*testPrice, true, accounting.Cause{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	creditAction.Cleanup()
+
+	for i := 0; i < 2; i++ {
+		if err := credit(true, "key"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := credit(true, "key"); !errors.Is(err, accounting.ErrBudgetExceeded) {
+		t.Fatalf("got error %v for exceeded key budget, want %v", err, accounting.ErrBudgetExceeded)
+	}
+
+	if err := credit(true, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := credit(true, ""); !errors.Is(err, accounting.ErrBudgetExceeded) {
+		t.Fatalf("got error %v for exceeded global budget, want %v", err, accounting.ErrBudgetExceeded)
+	}
+
+	// forwarded traffic is not limited
+	if err := credit(false, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// the hourly budget is available again in the next hour
+	acc.SetTime(1000 + 3600)
+	if err := credit(true, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := credit(true, "key"); !errors.Is(err, accounting.ErrBudgetExceeded) {
+		t.Fatalf("got error %v for exceeded total key budget, want %v", err, accounting.ErrBudgetExceeded)
+	}
+
+	budgets, err := acc.Budgets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(budgets) != 2 {
+		t.Fatalf("got %d budgets, want 2", len(budgets))
+	}
+	for _, b := range []struct {
+		status               accounting.BudgetStatus
+		key                  string
+		hourly, daily, total uint64
+	}{
+		{status: budgets[0], key: "", hourly: testPrice, daily: 4 * testPrice, total: 4 * testPrice},
+		{status: budgets[1], key: "key", hourly: 0, daily: 2 * testPrice, total: 2 * testPrice},
+	} {
+		if b.status.Key != b.key {
+			t.Fatalf("got budget key %q, want %q", b.status.Key, b.key)
+		}
+		if b.status.Hourly.Uint64() != b.hourly || b.status.Daily.Uint64() != b.daily || b.status.Total.Uint64() != b.total {
+			t.Fatalf("got spending %d/%d/%d for budget %q, want %d/%d/%d", b.status.Hourly, b.status.Daily, b.status.Total, b.key, b.hourly, b.daily, b.total)
+		}
+		if b.status.Reserved.Sign() != 0 {
+			t.Fatalf("got reserved %d for budget %q, want 0", b.status.Reserved, b.key)
+		}
+	}
+}
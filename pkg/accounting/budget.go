@@ -0,0 +1,278 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holisticode/bee/pkg/storage"
+)
+
+var (
+	budgetPrefix = "accounting_budget_"
+	// storage key suffix of the global budget
+	globalBudgetKey = "global"
+)
+
+// ErrBudgetExceeded denotes that an originated request would exceed the
+// spending budget.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// BudgetLimits caps the amount spent on originated traffic. A nil limit does
+// not cap spending.
+type BudgetLimits struct {
+	Hourly *big.Int
+	Daily  *big.Int
+	Total  *big.Int
+}
+
+// BudgetStatus is the state of a spending budget.
+type BudgetStatus struct {
+	// Key identifies the budget, it is empty for the global budget.
+	Key    string
+	Limits BudgetLimits
+	// Hourly and Daily are the amounts spent in the current hour and day.
+	Hourly *big.Int
+	Daily  *big.Int
+	// Total is the amount spent since the node started recording spending.
+	Total *big.Int
+	// Reserved is the amount reserved for requests in flight.
+	Reserved *big.Int
+}
+
+type budgetKeyContextKey struct{}
+
+// WithBudgetKey returns a context which charges originated requests to the
+// budget identified by key, in addition to the global budget.
+func WithBudgetKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, budgetKeyContextKey{}, key)
+}
+
+// BudgetKey returns the budget key stored in the context, or an empty string
+// if there is none.
+func BudgetKey(ctx context.Context) string {
+	key, _ := ctx.Value(budgetKeyContextKey{}).(string)
+	return key
+}
+
+// budgetSpending is the persisted spending of a single budget.
+type budgetSpending struct {
+	Hour   time.Time `json:"hour"` // start of the hour Hourly is counted in
+	Hourly *big.Int  `json:"hourly"`
+	Day    time.Time `json:"day"` // start of the day Daily is counted in
+	Daily  *big.Int  `json:"daily"`
+	Total  *big.Int  `json:"total"`
+
+	reserved *big.Int
+}
+
+// roll resets the hourly and daily spending once their period is over.
+func (s *budgetSpending) roll(now time.Time) {
+	if hour := now.Truncate(time.Hour); !hour.Equal(s.Hour) {
+		s.Hour = hour
+		s.Hourly = big.NewInt(0)
+	}
+	if day := now.Truncate(24 * time.Hour); !day.Equal(s.Day) {
+		s.Day = day
+		s.Daily = big.NewInt(0)
+	}
+}
+
+// allows reports whether reserving amount stays within the limits.
+func (s *budgetSpending) allows(limits BudgetLimits, amount *big.Int) bool {
+	exceeds := func(spent, limit *big.Int) bool {
+		if limit == nil {
+			return false
+		}
+		next := new(big.Int).Add(spent, s.reserved)
+		return next.Add(next, amount).Cmp(limit) > 0
+	}
+	return !exceeds(s.Hourly, limits.Hourly) && !exceeds(s.Daily, limits.Daily) && !exceeds(s.Total, limits.Total)
+}
+
+// budgets tracks the spending on originated traffic and enforces the global
+// and per key spending limits.
+type budgets struct {
+	mtx      sync.Mutex
+	store    storage.StateStorer
+	global   BudgetLimits
+	perKey   BudgetLimits
+	spending map[string]*budgetSpending // loaded lazily, the global budget has an empty key
+	now      func() time.Time
+}
+
+func newBudgets(store storage.StateStorer, now func() time.Time) *budgets {
+	return &budgets{
+		store:    store,
+		spending: make(map[string]*budgetSpending),
+		now:      now,
+	}
+}
+
+func budgetStoreKey(key string) string {
+	if key == "" {
+		return budgetPrefix + globalBudgetKey
+	}
+	return budgetPrefix + "key_" + key
+}
+
+// load returns the spending of the budget. The lock must be held when called.
+func (b *budgets) load(key string) (*budgetSpending, error) {
+	if s, ok := b.spending[key]; ok {
+		return s, nil
+	}
+	s := &budgetSpending{
+		Hourly: big.NewInt(0),
+		Daily:  big.NewInt(0),
+		Total:  big.NewInt(0),
+	}
+	err := b.store.Get(budgetStoreKey(key), s)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	s.reserved = big.NewInt(0)
+	b.spending[key] = s
+	return s, nil
+}
+
+// charged returns the keys of the budgets an amount charged to the key is
+// counted against. The global budget is listed first.
+func charged(key string) []string {
+	if key == "" {
+		return []string{""}
+	}
+	return []string{"", key}
+}
+
+// limits returns the limits of the budget. The lock must be held when called.
+func (b *budgets) limits(key string) BudgetLimits {
+	if key == "" {
+		return b.global
+	}
+	return b.perKey
+}
+
+// reserve reserves the amount in the global budget and in the budget of the
+// key, if any. It returns ErrBudgetExceeded if any of them would be exceeded.
+func (b *budgets) reserve(key string, amount *big.Int) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	keys := charged(key)
+	now := b.now().UTC()
+	spendings := make([]*budgetSpending, 0, len(keys))
+	for _, k := range keys {
+		s, err := b.load(k)
+		if err != nil {
+			return fmt.Errorf("load budget: %w", err)
+		}
+		s.roll(now)
+		if !s.allows(b.limits(k), amount) {
+			return ErrBudgetExceeded
+		}
+		spendings = append(spendings, s)
+	}
+	for _, s := range spendings {
+		s.reserved.Add(s.reserved, amount)
+	}
+	return nil
+}
+
+// release releases a reservation made by reserve and, if spent is true,
+// charges the amount to the budgets.
+func (b *budgets) release(key string, amount *big.Int, spent bool) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	now := b.now().UTC()
+	for _, k := range charged(key) {
+		s, err := b.load(k)
+		if err != nil {
+			return fmt.Errorf("load budget: %w", err)
+		}
+		if s.reserved.Cmp(amount) < 0 {
+			s.reserved.SetInt64(0)
+		} else {
+			s.reserved.Sub(s.reserved, amount)
+		}
+		if !spent {
+			continue
+		}
+		s.roll(now)
+		s.Hourly.Add(s.Hourly, amount)
+		s.Daily.Add(s.Daily, amount)
+		s.Total.Add(s.Total, amount)
+		if err := b.store.Put(budgetStoreKey(k), s); err != nil {
+			return fmt.Errorf("persist budget: %w", err)
+		}
+	}
+	return nil
+}
+
+// status returns the state of the global budget followed by the budgets of
+// all keys which have been charged, ordered by key.
+func (b *budgets) status() ([]BudgetStatus, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	keyPrefix := budgetPrefix + "key_"
+	err := b.store.Iterate(keyPrefix, func(k, _ []byte) (bool, error) {
+		if _, err := b.load(strings.TrimPrefix(string(k), keyPrefix)); err != nil {
+			return true, err
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load budgets: %w", err)
+	}
+	if _, err := b.load(""); err != nil {
+		return nil, fmt.Errorf("load budget: %w", err)
+	}
+
+	keys := make([]string, 0, len(b.spending))
+	for k := range b.spending {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	now := b.now().UTC()
+	status := make([]BudgetStatus, 0, len(keys))
+	for _, k := range keys {
+		s := b.spending[k]
+		s.roll(now)
+		status = append(status, BudgetStatus{
+			Key:      k,
+			Limits:   b.limits(k),
+			Hourly:   new(big.Int).Set(s.Hourly),
+			Daily:    new(big.Int).Set(s.Daily),
+			Total:    new(big.Int).Set(s.Total),
+			Reserved: new(big.Int).Set(s.reserved),
+		})
+	}
+	return status, nil
+}
+
+// SetBudgets sets the limits of the global budget and of the budget of every
+// key originated requests are charged to. Originated credits are counted
+// against the budgets whether they are limited or not.
+func (a *Accounting) SetBudgets(global, perKey BudgetLimits) {
+	a.budgets.mtx.Lock()
+	defer a.budgets.mtx.Unlock()
+	a.budgets.global = global
+	a.budgets.perKey = perKey
+}
+
+// Budgets returns the state of the global budget followed by the budgets of
+// all keys.
+func (a *Accounting) Budgets() ([]BudgetStatus, error) {
+	return a.budgets.status()
+}
@@ -19,3 +19,6 @@ func (s *Accounting) SetTime(k int64) {
 func (a *Accounting) IsPaymentOngoing(peer swarm.Address) bool {
 	return a.getAccountingPeer(peer).paymentOngoing
 }
+
+var LedgerSize = &ledgerSize
+var LedgerBatchSize = &ledgerBatchSize
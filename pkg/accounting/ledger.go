@@ -0,0 +1,282 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/holisticode/bee/pkg/storage"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+var (
+	ledgerPrefix = "accounting_ledger_"
+	// maximum number of ledger entries kept per peer, older entries are
+	// dropped once the limit is reached
+	ledgerSize = uint64(1000)
+	// number of ledger entries of a peer buffered before they are persisted
+	ledgerBatchSize = 100
+)
+
+// LedgerEntryType is the kind of balance change recorded in the ledger.
+type LedgerEntryType string
+
+const (
+	// LedgerCredit is a service we received from the peer.
+	LedgerCredit LedgerEntryType = "credit"
+	// LedgerDebit is a service we provided to the peer.
+	LedgerDebit LedgerEntryType = "debit"
+	// LedgerPaymentSent is a monetary settlement we sent to the peer.
+	LedgerPaymentSent LedgerEntryType = "payment-sent"
+	// LedgerPaymentReceived is a monetary settlement we received from the peer.
+	LedgerPaymentReceived LedgerEntryType = "payment-received"
+	// LedgerRefreshmentSent is a time based settlement we sent to the peer.
+	LedgerRefreshmentSent LedgerEntryType = "refreshment-sent"
+	// LedgerRefreshmentReceived is a time based settlement we received from
+	// the peer.
+	LedgerRefreshmentReceived LedgerEntryType = "refreshment-received"
+)
+
+// Cause describes what caused a credit or debit action.
+type Cause struct {
+	// Protocol is the name of the protocol which caused the action.
+	Protocol string
+	// Chunk is the address of the chunk the action was charged for, if known.
+	Chunk swarm.Address
+	// Budget is the key of the budget originated credits are charged to in
+	// addition to the global budget, if any.
+	Budget string
+}
+
+// LedgerEntry is a single balance change with a peer.
+type LedgerEntry struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Type      LedgerEntryType `json:"type"`
+	Amount    *big.Int        `json:"amount"`
+	Balance   *big.Int        `json:"balance"`
+	Protocol  string          `json:"protocol,omitempty"`
+	Chunk     swarm.Address   `json:"chunk"`
+}
+
+// ledger is a size-bounded log of balance changes per peer persisted in the
+// state store. Entries are buffered per peer and persisted together as one
+// batch, so recording an entry does not write to the state store on every
+// accounting action. Buffered entries are persisted on close and lost if the
+// node crashes.
+type ledger struct {
+	store      storage.StateStorer
+	size       uint64
+	batchSize  int
+	maxBatches uint64 // number of batches kept per peer to hold size entries
+
+	mtx   sync.Mutex // guards the peers map
+	peers map[string]*peerLedger
+}
+
+// peerLedger is the ledger state of a single peer.
+type peerLedger struct {
+	mtx     sync.Mutex
+	loaded  bool   // whether next has been loaded from the store
+	next    uint64 // sequence number of the next persisted batch
+	pending []LedgerEntry
+}
+
+func newLedger(store storage.StateStorer, size uint64, batchSize int) *ledger {
+	return &ledger{
+		store:      store,
+		size:       size,
+		batchSize:  batchSize,
+		maxBatches: size/uint64(batchSize) + 1,
+		peers:      make(map[string]*peerLedger),
+	}
+}
+
+// peer returns the ledger state of the peer, creating it if needed.
+func (l *ledger) peer(peer swarm.Address) *peerLedger {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	p, ok := l.peers[peer.ByteString()]
+	if !ok {
+		p = new(peerLedger)
+		l.peers[peer.ByteString()] = p
+	}
+	return p
+}
+
+// ledgerPeerPrefix returns the storage key prefix of the ledger batches of
+// the given peer.
+func ledgerPeerPrefix(peer swarm.Address) string {
+	return fmt.Sprintf("%s%s_", ledgerPrefix, peer.String())
+}
+
+// ledgerKey returns the storage key of the ledger batch with the given
+// sequence number. Sequence numbers are zero padded to keep the keys ordered.
+func ledgerKey(peer swarm.Address, seq uint64) string {
+	return fmt.Sprintf("%s%020d", ledgerPeerPrefix(peer), seq)
+}
+
+// ledgerKeySeq returns the sequence number encoded in the ledger batch key.
+func ledgerKeySeq(key []byte) (uint64, error) {
+	i := strings.LastIndexByte(string(key), '_')
+	if i < 0 {
+		return 0, errors.New("no sequence number in key")
+	}
+	return strconv.ParseUint(string(key[i+1:]), 10, 64)
+}
+
+// load loads the next batch sequence number of the peer from the store. The
+// lock of the peer must be held when called.
+func (l *ledger) load(peer swarm.Address, p *peerLedger) error {
+	if p.loaded {
+		return nil
+	}
+	var next uint64
+	err := l.store.Iterate(ledgerPeerPrefix(peer), func(key, _ []byte) (bool, error) {
+		seq, err := ledgerKeySeq(key)
+		if err != nil {
+			return true, fmt.Errorf("parse ledger key %q: %w", string(key), err)
+		}
+		if seq >= next {
+			next = seq + 1
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	p.next, p.loaded = next, true
+	return nil
+}
+
+// record appends the entry to the ledger of the peer. The buffered entries
+// are persisted once a batch is full.
+func (l *ledger) record(peer swarm.Address, entry LedgerEntry) error {
+	p := l.peer(peer)
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.pending = append(p.pending, entry)
+	if len(p.pending) < l.batchSize {
+		return nil
+	}
+	return l.flushPeer(peer, p)
+}
+
+// flushPeer persists the buffered entries of the peer as one batch and drops
+// the oldest batch no longer needed to hold the size of the ledger. The lock
+// of the peer must be held when called.
+func (l *ledger) flushPeer(peer swarm.Address, p *peerLedger) error {
+	if len(p.pending) == 0 {
+		return nil
+	}
+	if err := l.load(peer, p); err != nil {
+		return err
+	}
+
+	if err := l.store.Put(ledgerKey(peer, p.next), p.pending); err != nil {
+		return err
+	}
+	if p.next >= l.maxBatches {
+		if err := l.store.Delete(ledgerKey(peer, p.next-l.maxBatches)); err != nil {
+			return err
+		}
+	}
+	p.next++
+	p.pending = nil
+	return nil
+}
+
+// flush persists the buffered entries of all peers.
+func (l *ledger) flush() error {
+	l.mtx.Lock()
+	peers := make(map[string]*peerLedger, len(l.peers))
+	for k, p := range l.peers {
+		peers[k] = p
+	}
+	l.mtx.Unlock()
+
+	var mErr error
+	for k, p := range peers {
+		p.mtx.Lock()
+		if err := l.flushPeer(swarm.NewAddress([]byte(k)), p); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+		p.mtx.Unlock()
+	}
+	return mErr
+}
+
+// history returns the last size ledger entries of the peer from oldest to
+// newest.
+func (l *ledger) history(peer swarm.Address) ([]LedgerEntry, error) {
+	p := l.peer(peer)
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	type seqBatch struct {
+		seq     uint64
+		entries []LedgerEntry
+	}
+	var batches []seqBatch
+	err := l.store.Iterate(ledgerPeerPrefix(peer), func(key, val []byte) (bool, error) {
+		seq, err := ledgerKeySeq(key)
+		if err != nil {
+			return true, fmt.Errorf("parse ledger key %q: %w", string(key), err)
+		}
+		var entries []LedgerEntry
+		if err := json.Unmarshal(val, &entries); err != nil {
+			return true, fmt.Errorf("unmarshal ledger batch %q: %w", string(key), err)
+		}
+		batches = append(batches, seqBatch{seq: seq, entries: entries})
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i].seq < batches[j].seq
+	})
+	var history []LedgerEntry
+	for _, b := range batches {
+		history = append(history, b.entries...)
+	}
+	history = append(history, p.pending...)
+	if uint64(len(history)) > l.size {
+		history = history[uint64(len(history))-l.size:]
+	}
+	return history, nil
+}
+
+// recordLedger appends an entry to the ledger of the peer. Failures are only
+// logged as the balance change itself has already been persisted.
+func (a *Accounting) recordLedger(peer swarm.Address, typ LedgerEntryType, amount, balance *big.Int, cause Cause) {
+	entry := LedgerEntry{
+		Timestamp: a.timeNow().UTC(),
+		Type:      typ,
+		Amount:    new(big.Int).Set(amount),
+		Balance:   new(big.Int).Set(balance),
+		Protocol:  cause.Protocol,
+		Chunk:     cause.Chunk,
+	}
+	if err := a.ledger.record(peer, entry); err != nil {
+		a.logger.Errorf("accounting: record %s ledger entry for peer %v: %v", typ, peer, err)
+	}
+}
+
+// History returns the recorded balance changes with the given peer from
+// oldest to newest.
+func (a *Accounting) History(peer swarm.Address) ([]LedgerEntry, error) {
+	return a.ledger.history(peer)
+}
@@ -26,6 +26,8 @@ type metrics struct {
 	AccountingReserveCount                   prometheus.Counter
 	TotalOriginatedCreditedAmount            prometheus.Counter
 	OriginatedCreditEventsCount              prometheus.Counter
+	BudgetExceededCount                      prometheus.Counter
+	TrustedEventsCount                       prometheus.Counter
 }
 
 func newMetrics() metrics {
@@ -111,6 +113,18 @@ func newMetrics() metrics {
 			Name:      "originated_credit_events_count",
 			Help:      "Number of occurrences of BZZ credit events as originator towards peers",
 		}),
+		BudgetExceededCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "budget_exceeded_count",
+			Help:      "Number of originated requests rejected because they would exceed the spending budget",
+		}),
+		TrustedEventsCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "trusted_events_count",
+			Help:      "Number of credit and debit events with trusted peers which were not accounted",
+		}),
 	}
 }
 
@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"github.com/holisticode/bee/pkg/accounting"
+	"github.com/holisticode/bee/pkg/p2p"
 	"github.com/holisticode/bee/pkg/swarm"
 )
 
@@ -25,6 +26,9 @@ type Service struct {
 	balancesFunc            func() (map[string]*big.Int, error)
 	compensatedBalanceFunc  func(swarm.Address) (*big.Int, error)
 	compensatedBalancesFunc func() (map[string]*big.Int, error)
+	historyFunc             func(swarm.Address) ([]accounting.LedgerEntry, error)
+	budgetsFunc             func() ([]accounting.BudgetStatus, error)
+	trustedFunc             func(swarm.Address) bool
 
 	balanceSurplusFunc func(swarm.Address) (*big.Int, error)
 }
@@ -92,6 +96,27 @@ func WithBalanceSurplusFunc(f func(swarm.Address) (*big.Int, error)) Option {
 	})
 }
 
+// WithHistoryFunc sets the mock History function
+func WithHistoryFunc(f func(swarm.Address) ([]accounting.LedgerEntry, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.historyFunc = f
+	})
+}
+
+// WithBudgetsFunc sets the mock Budgets function
+func WithBudgetsFunc(f func() ([]accounting.BudgetStatus, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.budgetsFunc = f
+	})
+}
+
+// WithTrustedFunc sets the mock Trusted function
+func WithTrustedFunc(f func(swarm.Address) bool) Option {
+	return optionFunc(func(s *Service) {
+		s.trustedFunc = f
+	})
+}
+
 // NewAccounting creates the mock accounting implementation
 func NewAccounting(opts ...Option) *Service {
 	mock := new(Service)
@@ -112,7 +137,7 @@ func (s *Service) MakeCreditAction(peer swarm.Address, price uint64) accounting.
 }
 
 // Debit is the mock function wrapper that calls the set implementation
-func (s *Service) PrepareDebit(peer swarm.Address, price uint64) (accounting.Action, error) {
+func (s *Service) PrepareDebit(peer swarm.Address, price uint64, _ accounting.Cause) (accounting.Action, error) {
 	if s.prepareDebitFunc != nil {
 		return s.prepareDebitFunc(peer, price)
 	}
@@ -126,7 +151,7 @@ func (s *Service) PrepareDebit(peer swarm.Address, price uint64) (accounting.Act
 	}, nil
 }
 
-func (s *Service) PrepareCredit(peer swarm.Address, price uint64, originated bool) (accounting.Action, error) {
+func (s *Service) PrepareCredit(peer swarm.Address, price uint64, originated bool, _ accounting.Cause) (accounting.Action, error) {
 	if s.prepareCreditFunc != nil {
 		return s.prepareCreditFunc(peer, price, originated)
 	}
@@ -217,7 +242,31 @@ func (s *Service) CompensatedBalances() (map[string]*big.Int, error) {
 	return s.balances, nil
 }
 
-func (s *Service) Connect(peer swarm.Address) {
+// History is the mock function wrapper that calls the set implementation
+func (s *Service) History(peer swarm.Address) ([]accounting.LedgerEntry, error) {
+	if s.historyFunc != nil {
+		return s.historyFunc(peer)
+	}
+	return nil, nil
+}
+
+// Budgets is the mock function wrapper that calls the set implementation
+func (s *Service) Budgets() ([]accounting.BudgetStatus, error) {
+	if s.budgetsFunc != nil {
+		return s.budgetsFunc()
+	}
+	return nil, nil
+}
+
+// Trusted is the mock function wrapper that calls the set implementation
+func (s *Service) Trusted(peer swarm.Address) bool {
+	if s.trustedFunc != nil {
+		return s.trustedFunc(peer)
+	}
+	return false
+}
+
+func (s *Service) Connect(peer p2p.Peer) {
 
 }
 
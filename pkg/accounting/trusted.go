@@ -0,0 +1,87 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"fmt"
+	"sync"
+
+	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+// trustedPeers is the allow-list of peers traffic with which is free.
+type trustedPeers struct {
+	overlays     []swarm.Address
+	ethAddresses [][]byte
+
+	mtx      sync.Mutex
+	resolved map[string]struct{} // overlays of connected peers trusted by key
+}
+
+// resolve records the overlay of the peer if it signed its handshake with a
+// trusted public key and reports whether the peer is trusted.
+func (t *trustedPeers) resolve(peer p2p.Peer) bool {
+	for _, ethAddress := range t.ethAddresses {
+		if bytes.Equal(ethAddress, peer.EthereumAddress) {
+			t.mtx.Lock()
+			t.resolved[peer.Address.ByteString()] = struct{}{}
+			t.mtx.Unlock()
+			return true
+		}
+	}
+	return t.trusts(peer.Address)
+}
+
+// trusts reports whether the peer is on the allow-list, either by its overlay
+// or by the Ethereum address of the public key it signed its handshake with.
+// Peers trusted by key are known once they connected.
+func (t *trustedPeers) trusts(peer swarm.Address) bool {
+	for _, overlay := range t.overlays {
+		if overlay.Equal(peer) {
+			return true
+		}
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	_, ok := t.resolved[peer.ByteString()]
+	return ok
+}
+
+// freeAction is the accounting action of traffic with a trusted peer, it
+// changes no balance.
+type freeAction struct{}
+
+func (freeAction) Apply() error { return nil }
+
+func (freeAction) Cleanup() {}
+
+// SetTrustedPeers exempts traffic with the given peers from accounting. Such
+// peers are neither debited nor credited and never blocklisted for debt. Peers
+// given by public key are recognized by the Ethereum address of their
+// handshake when they connect, which works for light nodes too.
+func (a *Accounting) SetTrustedPeers(overlays []swarm.Address, publicKeys []*ecdsa.PublicKey) error {
+	t := &trustedPeers{
+		overlays: overlays,
+		resolved: make(map[string]struct{}),
+	}
+	for _, publicKey := range publicKeys {
+		ethAddress, err := crypto.NewEthereumAddress(*publicKey)
+		if err != nil {
+			return fmt.Errorf("trusted public key: %w", err)
+		}
+		t.ethAddresses = append(t.ethAddresses, ethAddress)
+	}
+	a.trustedPeers = t
+	return nil
+}
+
+// Trusted reports whether traffic with the peer is exempt from accounting.
+func (a *Accounting) Trusted(peer swarm.Address) bool {
+	return a.trustedPeers != nil && a.trustedPeers.trusts(peer)
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting_test
+
+import (
+	"crypto/ecdsa"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/holisticode/bee/pkg/accounting"
+	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/p2p"
+	p2pmock "github.com/holisticode/bee/pkg/p2p/mock"
+	"github.com/holisticode/bee/pkg/statestore/mock"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+func TestAccountingTrustedPeers(t *testing.T) {
+	logger := logging.New(io.Discard, 0)
+
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	var blocklisted []swarm.Address
+	p2pService := p2pmock.New(p2pmock.WithBlocklistFunc(func(peer swarm.Address, _ time.Duration, _ string) error {
+		blocklisted = append(blocklisted, peer)
+		return nil
+	}))
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, logger, store, nil, big.NewInt(testRefreshRate), p2pService)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byOverlay := swarm.MustParseHexAddress("00112233")
+	byKey := swarm.MustParseHexAddress("00112244")
+	untrusted := swarm.MustParseHexAddress("00112255")
+
+	privateKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ethAddress, err := crypto.NewEthereumAddress(privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = acc.SetTrustedPeers([]swarm.Address{byOverlay}, []*ecdsa.PublicKey{&privateKey.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// peers trusted by key are recognized by the handshake when they connect,
+	// also if they are light nodes that are never in the addressbook
+	if acc.Trusted(byKey) {
+		t.Fatal("peer trusted by key before it connected")
+	}
+
+	for _, peer := range []p2p.Peer{
+		{Address: byOverlay, FullNode: true},
+		{Address: byKey, EthereumAddress: ethAddress},
+	} {
+		acc.Connect(peer)
+
+		if !acc.Trusted(peer.Address) {
+			t.Fatalf("peer %v not trusted", peer.Address)
+		}
+
+		// traffic far beyond the disconnect limit is free
+		debitAction, err := acc.PrepareDebit(peer.Address, 10*testPaymentThreshold.Uint64(), accounting.Cause{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := debitAction.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		debitAction.Cleanup()
+
+		creditAction, err := acc.PrepareCredit(peer.Address, 10*testPaymentThreshold.Uint64(), true, accounting.Cause{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := creditAction.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		creditAction.Cleanup()
+
+		balance, err := acc.Balance(peer.Address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance.Sign() != 0 {
+			t.Fatalf("got balance %d with trusted peer %v, want 0", balance, peer.Address)
+		}
+
+		acc.Disconnect(peer.Address)
+	}
+
+	acc.Connect(p2p.Peer{Address: untrusted, EthereumAddress: make([]byte, len(ethAddress))})
+	if acc.Trusted(untrusted) {
+		t.Fatal("untrusted peer trusted")
+	}
+	acc.Disconnect(untrusted)
+
+	if len(blocklisted) != 1 || !blocklisted[0].Equal(untrusted) {
+		t.Fatalf("got blocklisted peers %v, want only %v", blocklisted, untrusted)
+	}
+}
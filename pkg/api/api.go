@@ -22,6 +22,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/holisticode/bee/pkg/accounting"
 	"github.com/holisticode/bee/pkg/auth"
 	"github.com/holisticode/bee/pkg/crypto"
 	"github.com/holisticode/bee/pkg/feeds"
@@ -36,6 +37,7 @@ import (
 	"github.com/holisticode/bee/pkg/pss"
 	"github.com/holisticode/bee/pkg/pusher"
 	"github.com/holisticode/bee/pkg/resolver"
+	"github.com/holisticode/bee/pkg/sctx"
 	"github.com/holisticode/bee/pkg/steward"
 	"github.com/holisticode/bee/pkg/storage"
 	"github.com/holisticode/bee/pkg/swarm"
@@ -87,6 +89,7 @@ var (
 	errDirectoryStore       = errors.New("could not store directory")
 	errFileStore            = errors.New("could not store file")
 	errInvalidPostageBatch  = errors.New("invalid postage batch id")
+	errBudgetExceeded       = errors.New("spending budget exceeded")
 )
 
 // Service is the API service interface.
@@ -101,6 +104,7 @@ type authenticator interface {
 	GenerateKey(string, int) (string, error)
 	RefreshKey(string, int) (string, error)
 	Enforce(string, string, string) (bool, error)
+	Identity(string) (string, string, error)
 }
 
 type server struct {
@@ -132,6 +136,8 @@ type Options struct {
 	GatewayMode        bool
 	WsPingPeriod       time.Duration
 	Restricted         bool
+	BatchSelector      postage.BatchSelector // selects batches for uploads without a batch header, may be nil
+	PushFailures       pusher.FailureTracker // reports and retries failed chunk pushes of tags, may be nil
 }
 
 const (
@@ -406,6 +412,40 @@ func (s *server) newTracingHandler(spanName string) func(h http.Handler) http.Ha
 	}
 }
 
+const (
+	// budgetKeyPrefix prefixes the identifier of the security token in the key
+	// of its spending budget.
+	budgetKeyPrefix = "key:"
+	// budgetRolePrefix prefixes the role in the key of the spending budget of
+	// security tokens issued without an identifier.
+	budgetRolePrefix = "role:"
+)
+
+// budgetKeyHandler charges the originated traffic of the request to the
+// spending budget of the security token the request is authorized with. The
+// budget is identified by the identifier of the token, as the token itself
+// changes whenever it is refreshed. Tokens without an identifier are charged
+// to the budget of their role.
+func (s *server) budgetKeyHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		if apiKey != "" {
+			id, role, err := s.auth.Identity(apiKey)
+			if err != nil {
+				s.logger.Debugf("api: budget key: %v", err)
+				jsonhttp.Forbidden(w, "Invalid security token")
+				return
+			}
+			key := budgetKeyPrefix + id
+			if id == "" {
+				key = budgetRolePrefix + role
+			}
+			r = r.WithContext(accounting.WithBudgetKey(r.Context(), key))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 func (s *server) contentLengthMetricMiddleware() func(h http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -486,9 +526,9 @@ func equalASCIIFold(s, t string) bool {
 // direct push to the network (default) a pushStamperPutter is returned.
 // returns a function to wait on the errorgroup in case of a pushing stamper putter.
 func (s *server) newStamperPutter(r *http.Request) (storage.Storer, func() error, error) {
-	batch, err := requestPostageBatchId(r)
+	stamper, err := s.requestStamper(r)
 	if err != nil {
-		return nil, noopWaitFn, fmt.Errorf("postage batch id: %w", err)
+		return nil, noopWaitFn, err
 	}
 
 	deferred, err := requestDeferred(r)
@@ -497,11 +537,29 @@ func (s *server) newStamperPutter(r *http.Request) (storage.Storer, func() error
 	}
 
 	if deferred {
-		p, err := newStoringStamperPutter(s.storer, s.post, s.signer, batch)
-		return p, noopWaitFn, err
+		return newStoringStamperPutter(s.storer, s.tags, stamper), noopWaitFn, nil
+	}
+	p := newPushStamperPutter(s.storer, stamper, s.chunkPushC)
+	return p, p.eg.Wait, nil
+}
+
+// requestStamper returns a stamper for the postage batch given in the request
+// headers. When the header is absent and a batch selector is configured, the
+// batches are chosen by the node instead.
+func (s *server) requestStamper(r *http.Request) (postage.Stamper, error) {
+	if r.Header.Get(SwarmPostageBatchIdHeader) == "" && s.BatchSelector != nil {
+		return s.BatchSelector.Stamper(s.signer)
+	}
+
+	batch, err := requestPostageBatchId(r)
+	if err != nil {
+		return nil, fmt.Errorf("postage batch id: %w", err)
+	}
+	i, err := s.post.GetStampIssuer(batch)
+	if err != nil {
+		return nil, fmt.Errorf("stamp issuer: %w", err)
 	}
-	p, err := newPushStamperPutter(s.storer, s.post, s.signer, batch, s.chunkPushC)
-	return p, p.eg.Wait, err
+	return postage.NewStamper(i, s.signer), nil
 }
 
 type pushStamperPutter struct {
@@ -512,14 +570,8 @@ type pushStamperPutter struct {
 	sem     chan struct{}
 }
 
-func newPushStamperPutter(s storage.Storer, post postage.Service, signer crypto.Signer, batch []byte, cc chan *pusher.Op) (*pushStamperPutter, error) {
-	i, err := post.GetStampIssuer(batch)
-	if err != nil {
-		return nil, fmt.Errorf("stamp issuer: %w", err)
-	}
-
-	stamper := postage.NewStamper(i, signer)
-	return &pushStamperPutter{Storer: s, stamper: stamper, c: cc, sem: make(chan struct{}, uploadSem)}, nil
+func newPushStamperPutter(s storage.Storer, stamper postage.Stamper, cc chan *pusher.Op) *pushStamperPutter {
+	return &pushStamperPutter{Storer: s, stamper: stamper, c: cc, sem: make(chan struct{}, uploadSem)}
 }
 
 func (p *pushStamperPutter) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exists []bool, err error) {
@@ -551,9 +603,13 @@ func (p *pushStamperPutter) Put(ctx context.Context, mode storage.ModePut, chs .
 				// from the api here so that the putter knows not to keep on sending stuff
 				// and just returns an error... or?
 			PUSH:
-				p.c <- &pusher.Op{Chunk: ch, Err: errc, Direct: true}
+				p.c <- &pusher.Op{Chunk: ch, Err: errc, Direct: true, Budget: accounting.BudgetKey(ctx)}
 				select {
 				case err := <-errc:
+					// retrying does not help until the budget allows spending again
+					if errors.Is(err, accounting.ErrBudgetExceeded) {
+						return err
+					}
 					// if we're the closest one we will store the chunk and return no error
 					if errors.Is(err, topology.ErrWantSelf) {
 						if _, err := p.Storer.Put(ctx, storage.ModePutSync, ch); err != nil {
@@ -576,17 +632,47 @@ func (p *pushStamperPutter) Put(ctx context.Context, mode storage.ModePut, chs .
 
 type stamperPutter struct {
 	storage.Storer
+	tags    *tags.Tags
 	stamper postage.Stamper
+
+	tagMu sync.Mutex
+	tag   *tags.Tag // tag of chunks uploaded without one, charged to a budget
 }
 
-func newStoringStamperPutter(s storage.Storer, post postage.Service, signer crypto.Signer, batch []byte) (*stamperPutter, error) {
-	i, err := post.GetStampIssuer(batch)
-	if err != nil {
-		return nil, fmt.Errorf("stamp issuer: %w", err)
+func newStoringStamperPutter(s storage.Storer, tagger *tags.Tags, stamper postage.Stamper) *stamperPutter {
+	return &stamperPutter{Storer: s, tags: tagger, stamper: stamper}
+}
+
+// withBudget records the spending budget the upload is charged to on the tag
+// of the chunk, so that the pusher charges the deferred push to it. Chunks
+// without a tag are given the tag of the request or a new one.
+func (p *stamperPutter) withBudget(ctx context.Context, ch swarm.Chunk, budget string) (swarm.Chunk, error) {
+	if ch.TagID() == 0 {
+		p.tagMu.Lock()
+		if p.tag == nil {
+			p.tag = sctx.GetTag(ctx)
+		}
+		if p.tag == nil {
+			tag, err := p.tags.Create(0)
+			if err != nil {
+				p.tagMu.Unlock()
+				return nil, fmt.Errorf("cannot create tag: %w", err)
+			}
+			p.tag = tag
+		}
+		tag := p.tag
+		p.tagMu.Unlock()
+
+		tag.SetBudget(budget)
+		return ch.WithTagID(tag.Uid), nil
 	}
 
-	stamper := postage.NewStamper(i, signer)
-	return &stamperPutter{Storer: s, stamper: stamper}, nil
+	tag, err := p.tags.Get(ch.TagID())
+	if err != nil {
+		return nil, fmt.Errorf("get tag: %w", err)
+	}
+	tag.SetBudget(budget)
+	return ch, nil
 }
 
 func (p *stamperPutter) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exists []bool, err error) {
@@ -610,6 +696,11 @@ func (p *stamperPutter) Put(ctx context.Context, mode storage.ModePut, chs ...sw
 			return nil, err
 		}
 		chs[i] = c.WithStamp(stamp)
+		if budget := accounting.BudgetKey(ctx); budget != "" {
+			if chs[i], err = p.withBudget(ctx, chs[i], budget); err != nil {
+				return nil, err
+			}
+		}
 		ctp = append(ctp, chs[i])
 		idx = append(idx, i)
 	}
@@ -81,6 +81,8 @@ type testServerOptions struct {
 	Authenticator      *mockauth.Auth
 	Restricted         bool
 	DirectUpload       bool
+	BatchSelector      postage.BatchSelector
+	PushFailures       pusher.FailureTracker
 }
 
 func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.Conn, string, *chanStorer) {
@@ -109,6 +111,8 @@ func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.
 		GatewayMode:        o.GatewayMode,
 		WsPingPeriod:       o.WsPingPeriod,
 		Restricted:         o.Restricted,
+		BatchSelector:      o.BatchSelector,
+		PushFailures:       o.PushFailures,
 	})
 	if o.DirectUpload {
 		chanStore = newChanStore(chC)
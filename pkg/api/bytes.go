@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/holisticode/bee/pkg/accounting"
 	"github.com/holisticode/bee/pkg/jsonhttp"
 	"github.com/holisticode/bee/pkg/postage"
 	"github.com/holisticode/bee/pkg/sctx"
@@ -74,6 +75,10 @@ func (s *server) bytesUploadHandler(w http.ResponseWriter, r *http.Request) {
 	if err = wait(); err != nil {
 		logger.Debugf("bytes upload: sync chunks: %v", err)
 		logger.Error("bytes upload: sync chunks")
+		if errors.Is(err, accounting.ErrBudgetExceeded) {
+			jsonhttp.PaymentRequired(w, errBudgetExceeded)
+			return
+		}
 		jsonhttp.InternalServerError(w, nil)
 		return
 	}
@@ -16,6 +16,7 @@ import (
 	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
 	"github.com/holisticode/bee/pkg/logging"
 	pinning "github.com/holisticode/bee/pkg/pinning/mock"
+	"github.com/holisticode/bee/pkg/postage"
 	mockpost "github.com/holisticode/bee/pkg/postage/mock"
 	statestore "github.com/holisticode/bee/pkg/statestore/mock"
 	"github.com/holisticode/bee/pkg/storage/mock"
@@ -149,3 +150,66 @@ func TestBytes(t *testing.T) {
 		)
 	})
 }
+
+// TestBytesBatchSelection tests that uploads without a postage batch header
+// are stamped with a batch selected by the node, if configured.
+func TestBytesBatchSelection(t *testing.T) {
+	const resource = "/bytes"
+
+	content := []byte("foo")
+
+	t.Run("selected", func(t *testing.T) {
+		storerMock := mock.NewStorer()
+		client, _, _, _ := newTestServer(t, testServerOptions{
+			Storer:        storerMock,
+			Tags:          tags.NewTags(statestore.NewStateStore(), logging.New(io.Discard, 0)),
+			Logger:        logging.New(io.Discard, 0),
+			Post:          mockpost.New(),
+			BatchSelector: mockpost.NewBatchSelector(nil),
+		})
+
+		var res api.BytesPostResponse
+		jsonhttptest.Request(t, client, http.MethodPost, resource, http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmDeferredUploadHeader, "true"),
+			jsonhttptest.WithRequestBody(bytes.NewReader(content)),
+			jsonhttptest.WithUnmarshalJSONResponse(&res),
+		)
+
+		has, err := storerMock.Has(context.Background(), res.Reference)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !has {
+			t.Fatal("storer check root chunk reference: have none; want one")
+		}
+	})
+
+	t.Run("no usable batch", func(t *testing.T) {
+		client, _, _, _ := newTestServer(t, testServerOptions{
+			Storer:        mock.NewStorer(),
+			Tags:          tags.NewTags(statestore.NewStateStore(), logging.New(io.Discard, 0)),
+			Logger:        logging.New(io.Discard, 0),
+			Post:          mockpost.New(),
+			BatchSelector: mockpost.NewBatchSelector(postage.ErrNotUsable),
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPost, resource, http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmDeferredUploadHeader, "true"),
+			jsonhttptest.WithRequestBody(bytes.NewReader(content)),
+		)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		client, _, _, _ := newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tags.NewTags(statestore.NewStateStore(), logging.New(io.Discard, 0)),
+			Logger: logging.New(io.Discard, 0),
+			Post:   mockpost.New(),
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPost, resource, http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmDeferredUploadHeader, "true"),
+			jsonhttptest.WithRequestBody(bytes.NewReader(content)),
+		)
+	})
+}
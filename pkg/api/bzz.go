@@ -20,6 +20,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gorilla/mux"
 
+	"github.com/holisticode/bee/pkg/accounting"
 	"github.com/holisticode/bee/pkg/feeds"
 	"github.com/holisticode/bee/pkg/file/joiner"
 	"github.com/holisticode/bee/pkg/file/loadsave"
@@ -225,6 +226,10 @@ func (s *server) fileUploadHandler(w http.ResponseWriter, r *http.Request, store
 	if err = waitFn(); err != nil {
 		s.logger.Debugf("bzz upload: sync chunks: %v", err)
 		s.logger.Error("bzz upload: sync chunks")
+		if errors.Is(err, accounting.ErrBudgetExceeded) {
+			jsonhttp.PaymentRequired(w, errBudgetExceeded)
+			return
+		}
 		jsonhttp.InternalServerError(w, nil)
 		return
 	}
@@ -273,6 +278,10 @@ FETCH:
 	if err != nil {
 		logger.Debugf("bzz download: not manifest %s: %v", address, err)
 		logger.Error("bzz download: not manifest")
+		if errors.Is(err, accounting.ErrBudgetExceeded) {
+			jsonhttp.PaymentRequired(w, errBudgetExceeded)
+			return
+		}
 		jsonhttp.NotFound(w, nil)
 		return
 	}
@@ -436,6 +445,12 @@ func (s *server) downloadHandler(w http.ResponseWriter, r *http.Request, referen
 
 	reader, l, err := joiner.New(r.Context(), s.storer, reference)
 	if err != nil {
+		if errors.Is(err, accounting.ErrBudgetExceeded) {
+			logger.Debugf("api download: %s: %v", reference, err)
+			logger.Error("api download: budget exceeded")
+			jsonhttp.PaymentRequired(w, errBudgetExceeded)
+			return
+		}
 		if errors.Is(err, storage.ErrNotFound) {
 			logger.Debugf("api download: not found %s: %v", reference, err)
 			logger.Error("api download: not found")
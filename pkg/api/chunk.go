@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/holisticode/bee/pkg/accounting"
 	"github.com/holisticode/bee/pkg/cac"
 	"github.com/holisticode/bee/pkg/netstore"
 
@@ -156,6 +157,10 @@ func (s *server) chunkUploadHandler(w http.ResponseWriter, r *http.Request) {
 	if err = wait(); err != nil {
 		s.logger.Debugf("chunk upload: sync chunk: %v", err)
 		s.logger.Error("chunk upload: sync chunk")
+		if errors.Is(err, accounting.ErrBudgetExceeded) {
+			jsonhttp.PaymentRequired(w, errBudgetExceeded)
+			return
+		}
 		jsonhttp.InternalServerError(w, nil)
 		return
 	}
@@ -189,6 +194,11 @@ func (s *server) chunkGetHandler(w http.ResponseWriter, r *http.Request) {
 			return
 
 		}
+		if errors.Is(err, accounting.ErrBudgetExceeded) {
+			s.logger.Tracef("chunk: budget exceeded. addr %s", address)
+			jsonhttp.PaymentRequired(w, errBudgetExceeded)
+			return
+		}
 		if errors.Is(err, netstore.ErrRecoveryAttempt) {
 			s.logger.Tracef("chunk: chunk recovery initiated. addr %s", address)
 			jsonhttp.Accepted(w, "chunk recovery initiated. retry after sometime.")
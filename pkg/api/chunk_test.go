@@ -9,6 +9,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"strconv"
 	"testing"
 
 	"github.com/holisticode/bee/pkg/logging"
@@ -18,7 +19,9 @@ import (
 
 	"github.com/holisticode/bee/pkg/tags"
 
+	"github.com/holisticode/bee/pkg/accounting"
 	"github.com/holisticode/bee/pkg/api"
+	mockauth "github.com/holisticode/bee/pkg/auth/mock"
 	"github.com/holisticode/bee/pkg/jsonhttp"
 	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
 	"github.com/holisticode/bee/pkg/storage"
@@ -146,3 +149,93 @@ func TestChunkUploadDownload(t *testing.T) {
 		}
 	})
 }
+
+// budgetExceededStorer fails retrievals as the spending budget is exceeded.
+type budgetExceededStorer struct {
+	storage.Storer
+}
+
+func (budgetExceededStorer) Get(context.Context, storage.ModeGet, swarm.Address) (swarm.Chunk, error) {
+	return nil, accounting.ErrBudgetExceeded
+}
+
+func TestChunkDownloadBudgetExceeded(t *testing.T) {
+	var (
+		chunk           = testingc.GenerateTestRandomChunk()
+		client, _, _, _ = newTestServer(t, testServerOptions{
+			Storer: budgetExceededStorer{mock.NewStorer()},
+		})
+	)
+
+	jsonhttptest.Request(t, client, http.MethodGet, "/chunks/"+chunk.Address().String(), http.StatusPaymentRequired,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: api.ErrBudgetExceeded.Error(),
+			Code:    http.StatusPaymentRequired,
+		}),
+	)
+}
+
+func TestDeferredUploadBudget(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		id     string
+		budget string
+	}{
+		{name: "security token", id: "1234", budget: "key:1234"},
+		{name: "security token without identifier", budget: "role:creator"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				tag             = tags.NewTags(statestore.NewStateStore(), logging.New(io.Discard, 0))
+				client, _, _, _ = newTestServer(t, testServerOptions{
+					Storer:     mock.NewStorer(),
+					Tags:       tag,
+					Post:       mockpost.New(mockpost.WithAcceptAll()),
+					Restricted: true,
+					Authenticator: &mockauth.Auth{
+						EnforceFunc: func(string, string, string) (bool, error) {
+							return true, nil
+						},
+						IdentityFunc: func(string) (string, string, error) {
+							return tc.id, "creator", nil
+						},
+					},
+				})
+			)
+
+			// chunks uploaded without a tag are given one carrying the budget
+			jsonhttptest.Request(t, client, http.MethodPost, "/chunks", http.StatusCreated,
+				jsonhttptest.WithRequestHeader("Authorization", "Bearer token"),
+				jsonhttptest.WithRequestHeader(api.SwarmDeferredUploadHeader, "true"),
+				jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+				jsonhttptest.WithRequestBody(bytes.NewReader(testingc.GenerateTestRandomChunk().Data())),
+			)
+
+			all := tag.All()
+			if len(all) != 1 {
+				t.Fatalf("got %d tags, want 1", len(all))
+			}
+			if got := all[0].Budget(); got != tc.budget {
+				t.Fatalf("got chunk upload budget %q, want %q", got, tc.budget)
+			}
+
+			header := jsonhttptest.Request(t, client, http.MethodPost, "/bytes", http.StatusCreated,
+				jsonhttptest.WithRequestHeader("Authorization", "Bearer token"),
+				jsonhttptest.WithRequestHeader(api.SwarmDeferredUploadHeader, "true"),
+				jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+				jsonhttptest.WithRequestBody(bytes.NewReader([]byte("budget"))),
+			)
+			uid, err := strconv.Atoi(header.Get(api.SwarmTagHeader))
+			if err != nil {
+				t.Fatal(err)
+			}
+			bytesTag, err := tag.Get(uint32(uid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bytesTag.Budget(); got != tc.budget {
+				t.Fatalf("got bytes upload budget %q, want %q", got, tc.budget)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/holisticode/bee/pkg/accounting"
 	"github.com/holisticode/bee/pkg/file"
 	"github.com/holisticode/bee/pkg/file/loadsave"
 	"github.com/holisticode/bee/pkg/jsonhttp"
@@ -117,6 +118,10 @@ func (s *server) dirUploadHandler(w http.ResponseWriter, r *http.Request, storer
 	if err = waitFn(); err != nil {
 		s.logger.Debugf("bzz upload: sync chunks: %v", err)
 		s.logger.Error("bzz upload: sync chunks")
+		if errors.Is(err, accounting.ErrBudgetExceeded) {
+			jsonhttp.PaymentRequired(w, errBudgetExceeded)
+			return
+		}
 		jsonhttp.InternalServerError(w, nil)
 		return
 	}
@@ -16,6 +16,10 @@ type (
 	BzzUploadResponse     = bzzUploadResponse
 	TagResponse           = tagResponse
 	TagRequest            = tagRequest
+	TagStreamResponse     = tagStreamResponse
+	TagFailuresResponse   = tagFailuresResponse
+	TagFailureResponse    = tagFailureResponse
+	TagRetryResponse      = tagRetryResponse
 	ListTagsResponse      = listTagsResponse
 	IsRetrievableResponse = isRetrievableResponse
 	SecurityTokenResponse = securityTokenRsp
@@ -36,6 +40,7 @@ var (
 var (
 	ErrNoResolver           = errNoResolver
 	ErrInvalidNameOrAddress = errInvalidNameOrAddress
+	ErrBudgetExceeded       = errBudgetExceeded
 )
 
 var (
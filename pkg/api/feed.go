@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/accounting"
 	"github.com/holisticode/bee/pkg/feeds"
 	"github.com/holisticode/bee/pkg/file/loadsave"
 	"github.com/holisticode/bee/pkg/jsonhttp"
@@ -208,6 +209,10 @@ func (s *server) feedPostHandler(w http.ResponseWriter, r *http.Request) {
 	if err = wait(); err != nil {
 		s.logger.Debugf("feed upload: sync chunks: %v", err)
 		s.logger.Error("feed upload: sync chunks")
+		if errors.Is(err, accounting.ErrBudgetExceeded) {
+			jsonhttp.PaymentRequired(w, errBudgetExceeded)
+			return
+		}
 		jsonhttp.InternalServerError(w, nil)
 		return
 	}
@@ -30,7 +30,7 @@ func (s *server) setupRouting() {
 	// handle is a helper closure which simplifies the router setup.
 	handle := func(path string, handler http.Handler) {
 		if s.Restricted {
-			handler = web.ChainHandlers(auth.PermissionCheckHandler(s.auth), web.FinalHandler(handler))
+			handler = web.ChainHandlers(auth.PermissionCheckHandler(s.auth), s.budgetKeyHandler, web.FinalHandler(handler))
 		}
 		router.Handle(path, handler)
 		router.Handle(rootPath+path, handler)
@@ -169,6 +169,24 @@ func (s *server) setupRouting() {
 			),
 		})),
 	)
+	handle("/tags/{id}/stream", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		web.FinalHandlerFunc(s.tagStreamHandler),
+	))
+	if s.PushFailures != nil {
+		handle("/tags/{id}/failures", web.ChainHandlers(
+			s.gatewayModeForbidEndpointHandler,
+			web.FinalHandler(jsonhttp.MethodHandler{
+				"GET": http.HandlerFunc(s.tagFailuresHandler),
+			})),
+		)
+		handle("/tags/{id}/retry", web.ChainHandlers(
+			s.gatewayModeForbidEndpointHandler,
+			web.FinalHandler(jsonhttp.MethodHandler{
+				"POST": http.HandlerFunc(s.tagRetryHandler),
+			})),
+		)
+	}
 
 	handle("/pins", web.ChainHandlers(
 		s.gatewayModeForbidEndpointHandler,
@@ -0,0 +1,114 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/swarm"
+	"github.com/holisticode/bee/pkg/tags"
+)
+
+type tagFailureResponse struct {
+	Address     swarm.Address `json:"address"`
+	Error       string        `json:"error"`
+	Attempts    int           `json:"attempts"`
+	LastAttempt time.Time     `json:"lastAttempt"`
+}
+
+type tagFailuresResponse struct {
+	Failures []tagFailureResponse `json:"failures"`
+}
+
+type tagRetryResponse struct {
+	Retried int `json:"retried"`
+}
+
+// tagFailuresHandler lists the chunks of a tag which could not be pushed to
+// the network yet.
+func (s *server) tagFailuresHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		s.logger.Debugf("tag failures: parse id  %s: %v", idStr, err)
+		s.logger.Error("tag failures: parse id")
+		jsonhttp.BadRequest(w, "invalid id")
+		return
+	}
+
+	tag, err := s.tags.Get(uint32(id))
+	if err != nil {
+		if errors.Is(err, tags.ErrNotFound) {
+			s.logger.Debugf("tag failures: tag not present: %v, id %s", err, idStr)
+			s.logger.Error("tag failures: tag not present")
+			jsonhttp.NotFound(w, "tag not present")
+			return
+		}
+		s.logger.Debugf("tag failures: tag %v: %v", idStr, err)
+		s.logger.Errorf("tag failures: %v", idStr)
+		jsonhttp.InternalServerError(w, "cannot get tag")
+		return
+	}
+
+	failures := s.PushFailures.Failures(tag.Uid)
+	resp := tagFailuresResponse{
+		Failures: make([]tagFailureResponse, 0, len(failures)),
+	}
+	for _, f := range failures {
+		resp.Failures = append(resp.Failures, tagFailureResponse{
+			Address:     f.Address,
+			Error:       f.Error,
+			Attempts:    f.Attempts,
+			LastAttempt: f.LastAttempt,
+		})
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, private, max-age=0")
+	jsonhttp.OK(w, resp)
+}
+
+// tagRetryHandler enqueues the failed chunks of a tag for a new round of
+// push attempts.
+func (s *server) tagRetryHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		s.logger.Debugf("tag retry: parse id  %s: %v", idStr, err)
+		s.logger.Error("tag retry: parse id")
+		jsonhttp.BadRequest(w, "invalid id")
+		return
+	}
+
+	tag, err := s.tags.Get(uint32(id))
+	if err != nil {
+		if errors.Is(err, tags.ErrNotFound) {
+			s.logger.Debugf("tag retry: tag not present: %v, id %s", err, idStr)
+			s.logger.Error("tag retry: tag not present")
+			jsonhttp.NotFound(w, "tag not present")
+			return
+		}
+		s.logger.Debugf("tag retry: tag %v: %v", idStr, err)
+		s.logger.Errorf("tag retry: %v", idStr)
+		jsonhttp.InternalServerError(w, "cannot get tag")
+		return
+	}
+
+	n, err := s.PushFailures.Retry(r.Context(), tag.Uid)
+	if err != nil {
+		s.logger.Debugf("tag retry: tag %v: %v", idStr, err)
+		s.logger.Errorf("tag retry: %v", idStr)
+		jsonhttp.InternalServerError(w, "cannot retry tag")
+		return
+	}
+
+	jsonhttp.OK(w, tagRetryResponse{Retried: n})
+}
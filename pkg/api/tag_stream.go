@@ -0,0 +1,188 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/tags"
+)
+
+// tagStreamInterval is the minimal interval between two progress messages
+// of the same tag stream.
+const tagStreamInterval = 250 * time.Millisecond
+
+type tagStreamResponse struct {
+	Uid       uint32     `json:"uid"`
+	StartedAt time.Time  `json:"startedAt"`
+	Total     int64      `json:"total"`
+	Split     int64      `json:"split"`
+	Seen      int64      `json:"seen"`
+	Stored    int64      `json:"stored"`
+	Sent      int64      `json:"sent"`
+	Synced    int64      `json:"synced"`
+	ETA       *time.Time `json:"eta,omitempty"`
+	Done      bool       `json:"done"`
+}
+
+func newTagStreamResponse(tag *tags.Tag) tagStreamResponse {
+	resp := tagStreamResponse{
+		Uid:       tag.Uid,
+		StartedAt: tag.StartedAt,
+		Total:     tag.Get(tags.TotalChunks),
+		Split:     tag.Get(tags.StateSplit),
+		Seen:      tag.Get(tags.StateSeen),
+		Stored:    tag.Get(tags.StateStored),
+		Sent:      tag.Get(tags.StateSent),
+		Synced:    tag.Get(tags.StateSynced),
+		Done:      tag.Done(tags.StateSynced),
+	}
+	if eta, err := tag.ETA(tags.StateSynced); err == nil {
+		resp.ETA = &eta
+	}
+	return resp
+}
+
+// tagStreamHandler streams the progress of a tag over a websocket until all
+// of its chunks are synced.
+func (s *server) tagStreamHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		s.logger.Debugf("tag stream: parse id  %s: %v", idStr, err)
+		s.logger.Error("tag stream: parse id")
+		jsonhttp.BadRequest(w, "invalid id")
+		return
+	}
+
+	tag, err := s.tags.Get(uint32(id))
+	if err != nil {
+		if errors.Is(err, tags.ErrNotFound) {
+			s.logger.Debugf("tag stream: tag not present: %v, id %s", err, idStr)
+			s.logger.Error("tag stream: tag not present")
+			jsonhttp.NotFound(w, "tag not present")
+			return
+		}
+		s.logger.Debugf("tag stream: tag %v: %v", idStr, err)
+		s.logger.Errorf("tag stream: %v", idStr)
+		jsonhttp.InternalServerError(w, "cannot get tag")
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     s.checkOrigin,
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Debugf("tag stream: upgrade: %v", err)
+		s.logger.Error("tag stream: cannot upgrade")
+		jsonhttp.BadRequest(w, "not a websocket connection")
+		return
+	}
+
+	s.wsWg.Add(1)
+	go s.pumpTag(conn, tag)
+}
+
+// pumpTag writes the tag counters to the connection whenever they change,
+// at most once per tagStreamInterval.
+func (s *server) pumpTag(conn *websocket.Conn, tag *tags.Tag) {
+	defer s.wsWg.Done()
+
+	var (
+		changed, unsubscribe = tag.Subscribe()
+		gone                 = make(chan struct{})
+		ticker               = time.NewTicker(tagStreamInterval)
+		pingTicker           = time.NewTicker(s.WsPingPeriod)
+	)
+	defer func() {
+		unsubscribe()
+		ticker.Stop()
+		pingTicker.Stop()
+		_ = conn.Close()
+	}()
+
+	// the read loop processes control messages and detects when the
+	// client goes away
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	writeClose := func(code int, text string) {
+		if err := conn.SetWriteDeadline(time.Now().Add(writeDeadline)); err != nil {
+			s.logger.Debugf("tag stream: set write deadline: %v", err)
+			return
+		}
+		if err := conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, text)); err != nil {
+			s.logger.Debugf("tag stream: write close message: %v", err)
+		}
+	}
+
+	// sendProgress writes the current counters and reports whether the
+	// stream should continue
+	sendProgress := func() bool {
+		resp := newTagStreamResponse(tag)
+		if err := conn.SetWriteDeadline(time.Now().Add(writeDeadline)); err != nil {
+			s.logger.Debugf("tag stream: set write deadline: %v", err)
+			return false
+		}
+		if err := conn.WriteJSON(resp); err != nil {
+			s.logger.Debugf("tag stream: write to websocket: %v", err)
+			return false
+		}
+		if resp.Done {
+			writeClose(websocket.CloseNormalClosure, "synced")
+			return false
+		}
+		return true
+	}
+
+	if !sendProgress() {
+		return
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			select {
+			case <-changed:
+			default:
+				continue
+			}
+			if !sendProgress() {
+				return
+			}
+		case <-pingTicker.C:
+			if err := conn.SetWriteDeadline(time.Now().Add(writeDeadline)); err != nil {
+				s.logger.Debugf("tag stream: set write deadline: %v", err)
+				return
+			}
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				// error encountered while pinging client. client probably gone
+				return
+			}
+		case <-s.quit:
+			writeClose(websocket.CloseGoingAway, "node shutting down")
+			return
+		case <-gone:
+			return
+		}
+	}
+}
@@ -6,6 +6,7 @@ package api_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,6 +23,7 @@ import (
 	"github.com/holisticode/bee/pkg/api"
 	"github.com/holisticode/bee/pkg/jsonhttp"
 	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/holisticode/bee/pkg/pusher"
 	"github.com/holisticode/bee/pkg/storage/mock"
 	testingc "github.com/holisticode/bee/pkg/storage/testing"
 	"github.com/holisticode/bee/pkg/swarm"
@@ -417,6 +419,172 @@ func TestTags(t *testing.T) {
 	})
 }
 
+func TestTagStream(t *testing.T) {
+	var (
+		logger          = logging.New(io.Discard, 0)
+		tagService      = tags.NewTags(statestore.NewStateStore(), logger)
+		_, _, listen, _ = newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tagService,
+			Logger: logger,
+		})
+	)
+
+	tag, err := tagService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := url.URL{Scheme: "ws", Host: listen, Path: tagsWithIdResource(tag.Uid) + "/stream"}
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatalf("dial: %v. url %v", err, u.String())
+	}
+	defer conn.Close()
+
+	readProgress := func() api.TagStreamResponse {
+		t.Helper()
+		if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		var resp api.TagStreamResponse
+		if err := conn.ReadJSON(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// the current state is sent right away
+	resp := readProgress()
+	if resp.Uid != tag.Uid || resp.Total != 2 || resp.Synced != 0 || resp.Done {
+		t.Fatalf("unexpected initial progress %+v", resp)
+	}
+
+	for _, state := range []tags.State{tags.StateSplit, tags.StateStored, tags.StateSent} {
+		if err := tag.IncN(state, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tag.Inc(tags.StateSynced); err != nil {
+		t.Fatal(err)
+	}
+
+	// intermediate states may be coalesced
+	readUntilSynced := func(synced int64) api.TagStreamResponse {
+		t.Helper()
+		for {
+			resp := readProgress()
+			if resp.Synced == synced {
+				return resp
+			}
+		}
+	}
+
+	resp = readUntilSynced(1)
+	if resp.Stored != 2 || resp.Sent != 2 || resp.Done {
+		t.Fatalf("unexpected progress %+v", resp)
+	}
+	if resp.ETA == nil {
+		t.Fatal("expected eta")
+	}
+
+	if err := tag.Inc(tags.StateSynced); err != nil {
+		t.Fatal(err)
+	}
+
+	resp = readUntilSynced(2)
+	if !resp.Done {
+		t.Fatalf("unexpected final progress %+v", resp)
+	}
+
+	// the stream is closed once all chunks are synced
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Fatalf("got error %v, want normal closure", err)
+	}
+}
+
+func TestTagFailures(t *testing.T) {
+	var (
+		logger     = logging.New(io.Discard, 0)
+		tagService = tags.NewTags(statestore.NewStateStore(), logger)
+		failure    = pusher.Failure{
+			Address:     test.RandomAddress(),
+			Error:       "push failed",
+			Attempts:    3,
+			LastAttempt: time.Unix(1000, 0).UTC(),
+		}
+		tracker         = &mockFailureTracker{failures: []pusher.Failure{failure}}
+		client, _, _, _ = newTestServer(t, testServerOptions{
+			Storer:       mock.NewStorer(),
+			Tags:         tagService,
+			Logger:       logger,
+			PushFailures: tracker,
+		})
+	)
+
+	tag, err := tagService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("failures", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, tagsWithIdResource(tag.Uid)+"/failures", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.TagFailuresResponse{
+				Failures: []api.TagFailureResponse{{
+					Address:     failure.Address,
+					Error:       failure.Error,
+					Attempts:    failure.Attempts,
+					LastAttempt: failure.LastAttempt,
+				}},
+			}),
+		)
+	})
+
+	t.Run("failures tag not present", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, tagsWithIdResource(333)+"/failures", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "tag not present",
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, tagsWithIdResource(tag.Uid)+"/retry", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.TagRetryResponse{
+				Retried: 1,
+			}),
+		)
+		if tracker.retried != tag.Uid {
+			t.Fatalf("got retried tag %d, want %d", tracker.retried, tag.Uid)
+		}
+	})
+
+	t.Run("retry tag not present", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, tagsWithIdResource(333)+"/retry", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "tag not present",
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
+}
+
+type mockFailureTracker struct {
+	failures []pusher.Failure
+	retried  uint32
+}
+
+func (m *mockFailureTracker) Failures(uint32) []pusher.Failure {
+	return m.failures
+}
+
+func (m *mockFailureTracker) Retry(_ context.Context, tagID uint32) (int, error) {
+	m.retried = tagID
+	return len(m.failures), nil
+}
+
 // isTagFoundInResponse verifies that the tag id is found in the supplied HTTP headers
 // if an API tag response is supplied, it also verifies that it contains an id which matches the headers
 func isTagFoundInResponse(t *testing.T, headers http.Header, tr *api.TagResponse) uint32 {
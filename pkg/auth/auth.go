@@ -23,6 +23,7 @@ import (
 )
 
 type authRecord struct {
+	ID     string    `json:"i"`
 	Role   string    `json:"r"`
 	Expiry time.Time `json:"e"`
 }
@@ -87,7 +88,13 @@ func (a *Authenticator) GenerateKey(role string, expiryDuration int) (string, er
 		return "", ErrExpiry
 	}
 
+	id := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return "", err
+	}
+
 	ar := authRecord{
+		ID:     hex.EncodeToString(id),
 		Role:   role,
 		Expiry: time.Now().Add(time.Second * time.Duration(expiryDuration)),
 	}
@@ -151,36 +158,56 @@ func (a *Authenticator) RefreshKey(apiKey string, expiryDuration int) (string, e
 }
 
 func (a *Authenticator) Enforce(apiKey, obj, act string) (bool, error) {
+	ar, err := a.authRecord(apiKey)
+	if err != nil {
+		return false, err
+	}
+
+	allow, err := a.enforcer.Enforce(ar.Role, obj, act)
+	if err != nil {
+		a.log.Error("enforce", err)
+		return false, err
+	}
+
+	return allow, nil
+}
+
+// Identity returns the identifier of the security token and the role it was
+// issued for. Unlike the token neither changes when the token is refreshed.
+// Tokens issued without an identifier return an empty one.
+func (a *Authenticator) Identity(apiKey string) (id, role string, err error) {
+	ar, err := a.authRecord(apiKey)
+	if err != nil {
+		return "", "", err
+	}
+	return ar.ID, ar.Role, nil
+}
+
+// authRecord returns the record of the unexpired security token.
+func (a *Authenticator) authRecord(apiKey string) (authRecord, error) {
 	decoded, err := base64.StdEncoding.DecodeString(apiKey)
 	if err != nil {
 		a.log.Error("decode token", err)
-		return false, err
+		return authRecord{}, err
 	}
 
 	decryptedBytes, err := a.ciph.decrypt(decoded)
 	if err != nil {
 		a.log.Error("decrypt token", err)
-		return false, err
+		return authRecord{}, err
 	}
 
 	var ar authRecord
 	if err := json.Unmarshal(decryptedBytes, &ar); err != nil {
 		a.log.Error("unmarshal token", err)
-		return false, err
+		return authRecord{}, err
 	}
 
 	if time.Now().After(ar.Expiry) {
 		a.log.Error("token expired")
-		return false, ErrTokenExpired
+		return authRecord{}, ErrTokenExpired
 	}
-
-	allow, err := a.enforcer.Enforce(ar.Role, obj, act)
-	if err != nil {
-		a.log.Error("enforce", err)
-		return false, err
-	}
-
-	return allow, nil
+	return ar, nil
 }
 
 type encrypter struct {
@@ -242,6 +269,9 @@ func applyPolicies(e *casbin.Enforcer) error {
 		{"creator", "/tags?*", "GET"},
 		{"creator", "/tags", "POST"},
 		{"creator", "/tags/*", "(GET)|(DELETE)|(PATCH)"},
+		{"creator", "/tags/*/stream", "GET"},
+		{"creator", "/tags/*/failures", "GET"},
+		{"creator", "/tags/*/retry", "POST"},
 		{"creator", "/pins/*", "(GET)|(DELETE)|(POST)"},
 		{"maintainer", "/pins", "GET"},
 		{"creator", "/pss/send/*", "POST"},
@@ -256,14 +286,18 @@ func applyPolicies(e *casbin.Enforcer) error {
 		{"maintainer", "/stamps/dilute/*/*", "PATCH"},
 		{"maintainer", "/addresses", "GET"},
 		{"maintainer", "/blocklist", "GET"},
+		{"maintainer", "/blocklist/*", "(POST)|(DELETE)"},
 		{"maintainer", "/connect/*", "POST"},
 		{"maintainer", "/peers", "GET"},
-		{"maintainer", "/peers/*", "DELETE"},
+		{"maintainer", "/peers/*", "(GET)|(DELETE)"},
 		{"maintainer", "/pingpong/*", "POST"},
 		{"maintainer", "/topology", "GET"},
+		{"maintainer", "/topology/graph", "GET"},
+		{"maintainer", "/topology/graph?*", "GET"},
 		{"maintainer", "/welcome-message", "(GET)|(POST)"},
 		{"maintainer", "/balances", "GET"},
 		{"maintainer", "/balances/*", "GET"},
+		{"maintainer", "/budget", "GET"},
 		{"maintainer", "/chequebook/cashout/*", "GET"},
 		{"accountant", "/chequebook/cashout/*", "POST"},
 		{"accountant", "/chequebook/withdraw", "POST"},
@@ -274,12 +308,21 @@ func applyPolicies(e *casbin.Enforcer) error {
 		{"maintainer", "/chequebook/cheque", "GET"},
 		{"maintainer", "/chequebook/address", "GET"},
 		{"maintainer", "/chequebook/balance", "GET"},
+		{"maintainer", "/chequebook/refills", "GET"},
 		{"maintainer", "/chunks/*", "(GET)|(DELETE)"},
 		{"maintainer", "/reservestate", "GET"},
 		{"maintainer", "/chainstate", "GET"},
 		{"maintainer", "/settlements/*", "GET"},
 		{"maintainer", "/settlements", "GET"},
+		{"maintainer", "/settlementdrivers", "GET"},
+		{"maintainer", "/priceoracle/history", "GET"},
+		{"maintainer", "/priceoracle/history?*", "GET"},
+		{"accountant", "/settlementdrivers/*", "(PUT)|(DELETE)"},
 		{"maintainer", "/transactions", "GET"},
+		{"maintainer", "/events", "GET"},
+		{"maintainer", "/events?*", "GET"},
+		{"maintainer", "/events/peers", "GET"},
+		{"maintainer", "/events/peers?*", "GET"},
 		{"consumer", "/transactions/*", "GET"},
 		{"accountant", "/transactions/*", "(POST)|(DELETE)"},
 		{"consumer", "/consumed", "GET"},
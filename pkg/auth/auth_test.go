@@ -137,3 +137,57 @@ func TestEnforce(t *testing.T) {
 		})
 	}
 }
+
+func TestIdentity(t *testing.T) {
+	a, err := auth.New(encryptionKey, passwordHash, logging.New(io.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := a.GenerateKey("creator", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err := a.RefreshKey(key, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := a.GenerateKey("creator", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, _, err := a.Identity(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == "" {
+		t.Fatal("expected security token to have an identifier")
+	}
+
+	for _, k := range []string{key, refreshed} {
+		gotID, role, err := a.Identity(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotID != id {
+			t.Errorf("got identifier %q, want %q", gotID, id)
+		}
+		if role != "creator" {
+			t.Errorf("got role %q, want %q", role, "creator")
+		}
+	}
+
+	otherID, _, err := a.Identity(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherID == id {
+		t.Error("expected security tokens of the same role to have different identifiers")
+	}
+
+	if _, _, err := a.Identity("invalid"); err == nil {
+		t.Error("expected error for invalid key")
+	}
+}
@@ -7,6 +7,8 @@ package mock
 type Auth struct {
 	AuthorizeFunc   func(string) bool
 	GenerateKeyFunc func(string) (string, error)
+	EnforceFunc     func(string, string, string) (bool, error)
+	IdentityFunc    func(string) (string, string, error)
 }
 
 func (ma *Auth) Authorize(u string) bool {
@@ -27,6 +29,15 @@ func (ma *Auth) RefreshKey(k string, _ int) (string, error) {
 	}
 	return ma.GenerateKeyFunc(k)
 }
-func (*Auth) Enforce(string, string, string) (bool, error) {
-	return false, nil
+func (ma *Auth) Enforce(k, obj, act string) (bool, error) {
+	if ma.EnforceFunc == nil {
+		return false, nil
+	}
+	return ma.EnforceFunc(k, obj, act)
+}
+func (ma *Auth) Identity(k string) (string, string, error) {
+	if ma.IdentityFunc == nil {
+		return "", "", nil
+	}
+	return ma.IdentityFunc(k)
 }
@@ -77,7 +77,7 @@ func (b *Blocker) block() {
 		}
 
 		if !peer.blockAfter.IsZero() && time.Now().After(peer.blockAfter) {
-			if err := b.disconnector.Blocklist(peer.addr, b.blockDuration, "blocker: flag timeout"); err != nil {
+			if err := p2p.BlocklistBy(b.disconnector, "blocker", peer.addr, b.blockDuration, "blocker: flag timeout"); err != nil {
 				b.logger.Warningf("blocker: blocking peer %s failed: %v", peer.addr, err)
 			}
 			if b.blocklistCallback != nil {
@@ -27,12 +27,15 @@ var ErrInvalidAddress = errors.New("invalid address")
 // Address represents the bzz address in swarm.
 // It consists of a peers underlay (physical) address, overlay (topology) address and signature.
 // Signature is used to verify the `Overlay/Underlay` pair, as it is based on `underlay|networkID`, signed with the public key of Overlay address
+// Peers that accept QUIC connections also advertise a QUIC underlay, signed in the same way as the underlay.
 type Address struct {
 	Underlay        ma.Multiaddr
 	Overlay         swarm.Address
 	Signature       []byte
 	Transaction     []byte
 	EthereumAddress []byte
+	QUICUnderlay    ma.Multiaddr
+	QUICSignature   []byte
 }
 
 type addressJSON struct {
@@ -40,6 +43,11 @@ type addressJSON struct {
 	Underlay    string `json:"underlay"`
 	Signature   string `json:"signature"`
 	Transaction string `json:"transaction"`
+	// EthereumAddress is empty for addresses persisted by older versions.
+	EthereumAddress string `json:"ethereumAddress,omitempty"`
+	// QUICUnderlay and QUICSignature are empty for peers without QUIC.
+	QUICUnderlay  string `json:"quicUnderlay,omitempty"`
+	QUICSignature string `json:"quicSignature,omitempty"`
 }
 
 func NewAddress(signer crypto.Signer, underlay ma.Multiaddr, overlay swarm.Address, networkID uint64, trx []byte) (*Address, error) {
@@ -94,6 +102,49 @@ func ParseAddress(underlay, overlay, signature, trxHash, blockHash []byte, netwo
 	}, nil
 }
 
+// SetQUICUnderlay signs and sets the QUIC underlay of the address.
+func (a *Address) SetQUICUnderlay(signer crypto.Signer, underlay ma.Multiaddr, networkID uint64) error {
+	underlayBinary, err := underlay.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	signature, err := signer.Sign(generateSignData(underlayBinary, a.Overlay.Bytes(), networkID))
+	if err != nil {
+		return err
+	}
+
+	a.QUICUnderlay = underlay
+	a.QUICSignature = signature
+	return nil
+}
+
+// ParseQUICUnderlay verifies that the QUIC underlay is signed by the same key
+// as the underlay of the address parsed by ParseAddress and sets it.
+func (a *Address) ParseQUICUnderlay(underlay, signature []byte, networkID uint64) error {
+	recoveredPK, err := crypto.Recover(signature, generateSignData(underlay, a.Overlay.Bytes(), networkID))
+	if err != nil {
+		return ErrInvalidAddress
+	}
+
+	ethAddress, err := crypto.NewEthereumAddress(*recoveredPK)
+	if err != nil {
+		return fmt.Errorf("extract ethereum address: %v: %w", err, ErrInvalidAddress)
+	}
+	if !bytes.Equal(ethAddress, a.EthereumAddress) {
+		return ErrInvalidAddress
+	}
+
+	multiUnderlay, err := ma.NewMultiaddrBytes(underlay)
+	if err != nil {
+		return ErrInvalidAddress
+	}
+
+	a.QUICUnderlay = multiUnderlay
+	a.QUICSignature = signature
+	return nil
+}
+
 func generateSignData(underlay, overlay []byte, networkID uint64) []byte {
 	networkIDBytes := make([]byte, 8)
 	binary.BigEndian.PutUint64(networkIDBytes, networkID)
@@ -103,16 +154,30 @@ func generateSignData(underlay, overlay []byte, networkID uint64) []byte {
 }
 
 func (a *Address) Equal(b *Address) bool {
-	return a.Overlay.Equal(b.Overlay) && a.Underlay.Equal(b.Underlay) && bytes.Equal(a.Signature, b.Signature) && bytes.Equal(a.Transaction, b.Transaction)
+	return a.Overlay.Equal(b.Overlay) && a.Underlay.Equal(b.Underlay) && bytes.Equal(a.Signature, b.Signature) && bytes.Equal(a.Transaction, b.Transaction) &&
+		equalMultiaddr(a.QUICUnderlay, b.QUICUnderlay) && bytes.Equal(a.QUICSignature, b.QUICSignature)
+}
+
+func equalMultiaddr(a, b ma.Multiaddr) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(b)
 }
 
 func (a *Address) MarshalJSON() ([]byte, error) {
-	return json.Marshal(&addressJSON{
-		Overlay:     a.Overlay.String(),
-		Underlay:    a.Underlay.String(),
-		Signature:   base64.StdEncoding.EncodeToString(a.Signature),
-		Transaction: common.Bytes2Hex(a.Transaction),
-	})
+	v := &addressJSON{
+		Overlay:         a.Overlay.String(),
+		Underlay:        a.Underlay.String(),
+		Signature:       base64.StdEncoding.EncodeToString(a.Signature),
+		Transaction:     common.Bytes2Hex(a.Transaction),
+		EthereumAddress: common.Bytes2Hex(a.EthereumAddress),
+	}
+	if a.QUICUnderlay != nil {
+		v.QUICUnderlay = a.QUICUnderlay.String()
+		v.QUICSignature = base64.StdEncoding.EncodeToString(a.QUICSignature)
+	}
+	return json.Marshal(v)
 }
 
 func (a *Address) UnmarshalJSON(b []byte) error {
@@ -136,7 +201,18 @@ func (a *Address) UnmarshalJSON(b []byte) error {
 
 	a.Underlay = m
 	a.Signature, err = base64.StdEncoding.DecodeString(v.Signature)
+	if err != nil {
+		return err
+	}
 	a.Transaction = common.Hex2Bytes(v.Transaction)
+	a.EthereumAddress = common.Hex2Bytes(v.EthereumAddress)
+
+	if v.QUICUnderlay != "" {
+		if a.QUICUnderlay, err = ma.NewMultiaddr(v.QUICUnderlay); err != nil {
+			return err
+		}
+		a.QUICSignature, err = base64.StdEncoding.DecodeString(v.QUICSignature)
+	}
 	return err
 }
 
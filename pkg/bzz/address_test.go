@@ -5,6 +5,7 @@
 package bzz_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -62,3 +63,69 @@ func TestBzzAddress(t *testing.T) {
 		t.Fatalf("got %s expected %s", newbzz, bzzAddress)
 	}
 }
+
+func TestBzzAddressQUIC(t *testing.T) {
+	underlay, err := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/1634/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	quicUnderlay, err := ma.NewMultiaddr("/ip4/127.0.0.1/udp/1634/quic/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockHash := common.HexToHash("0x2").Bytes()
+
+	privateKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := crypto.NewOverlayAddress(privateKey.PublicKey, 3, blockHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privateKey)
+
+	bzzAddress, err := bzz.NewAddress(signer, underlay, overlay, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bzzAddress.SetQUICUnderlay(signer, quicUnderlay, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := bzz.ParseAddress(underlay.Bytes(), overlay.Bytes(), bzzAddress.Signature, nil, blockHash, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.ParseQUICUnderlay(quicUnderlay.Bytes(), bzzAddress.QUICSignature, 3); err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Equal(bzzAddress) {
+		t.Fatalf("got %s expected %s", parsed, bzzAddress)
+	}
+
+	// the QUIC underlay must be signed by the owner of the overlay
+	otherKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bzzAddress.SetQUICUnderlay(crypto.NewDefaultSigner(otherKey), quicUnderlay, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.ParseQUICUnderlay(quicUnderlay.Bytes(), bzzAddress.QUICSignature, 3); !errors.Is(err, bzz.ErrInvalidAddress) {
+		t.Fatalf("got error %v, want %v", err, bzz.ErrInvalidAddress)
+	}
+
+	bytes, err := parsed.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var newbzz bzz.Address
+	if err := newbzz.UnmarshalJSON(bytes); err != nil {
+		t.Fatal(err)
+	}
+	if !newbzz.Equal(parsed) {
+		t.Fatalf("got %s expected %s", newbzz, parsed)
+	}
+}
@@ -5,12 +5,17 @@
 package debugapi
 
 import (
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"math/big"
 	"net/http"
+	"time"
 
 	"github.com/holisticode/bee/pkg/accounting"
 	"github.com/holisticode/bee/pkg/bigint"
 	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/settlement/swap/priceoracle"
 	"github.com/holisticode/bee/pkg/swarm"
 	"github.com/gorilla/mux"
 )
@@ -20,15 +25,61 @@ var (
 	errCantBalance    = "Cannot get balance"
 	errNoBalance      = "No balance for peer"
 	errInvalidAddress = "Invalid address"
+
+	errCantHistory          = "Cannot get balance history"
+	errInvalidHistoryFormat = "Invalid history format"
 )
 
 type balanceResponse struct {
 	Peer    string         `json:"peer"`
 	Balance *bigint.BigInt `json:"balance"`
+	// the balance valued at the current exchange rate
+	BalanceBZZ *bigint.BigInt `json:"balanceBZZ,omitempty"`
+}
+
+// currentRates returns the exchange rate in effect now to value balances
+// with. It is empty if the node does not track the rates.
+func (s *Service) currentRates() (time.Time, []priceoracle.Rate) {
+	now := time.Now()
+	return now, s.rateHistory(now, now.Add(time.Nanosecond))
+}
+
+// newBalanceResponse values the balance at the exchange rate that applies
+// at time t, if any.
+func newBalanceResponse(peer string, balance *big.Int, rates []priceoracle.Rate, t time.Time) balanceResponse {
+	b := balanceResponse{
+		Peer:    peer,
+		Balance: bigint.Wrap(balance),
+	}
+	if v, ok := valueUnits(rates, t, balance); ok {
+		b.BalanceBZZ = bigint.Wrap(v.bzz)
+	}
+	return b
 }
 
 type balancesResponse struct {
 	Balances []balanceResponse `json:"balances"`
+	// Trusted are the balances of trusted peers, traffic with which is not
+	// accounted.
+	Trusted []balanceResponse `json:"trusted,omitempty"`
+}
+
+// newBalancesResponse lists the balances of trusted peers separately from
+// the others.
+func (s *Service) newBalancesResponse(balances map[string]*big.Int) balancesResponse {
+	resp := balancesResponse{
+		Balances: make([]balanceResponse, 0, len(balances)),
+	}
+	now, rates := s.currentRates()
+	for k, balance := range balances {
+		b := newBalanceResponse(k, balance, rates, now)
+		if peer, err := swarm.ParseHexAddress(k); err == nil && s.accounting.Trusted(peer) {
+			resp.Trusted = append(resp.Trusted, b)
+			continue
+		}
+		resp.Balances = append(resp.Balances, b)
+	}
+	return resp
 }
 
 func (s *Service) balancesHandler(w http.ResponseWriter, r *http.Request) {
@@ -40,17 +91,7 @@ func (s *Service) balancesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	balResponses := make([]balanceResponse, len(balances))
-	i := 0
-	for k := range balances {
-		balResponses[i] = balanceResponse{
-			Peer:    k,
-			Balance: bigint.Wrap(balances[k]),
-		}
-		i++
-	}
-
-	jsonhttp.OK(w, balancesResponse{Balances: balResponses})
+	jsonhttp.OK(w, s.newBalancesResponse(balances))
 }
 
 func (s *Service) peerBalanceHandler(w http.ResponseWriter, r *http.Request) {
@@ -75,10 +116,8 @@ func (s *Service) peerBalanceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jsonhttp.OK(w, balanceResponse{
-		Peer:    peer.String(),
-		Balance: bigint.Wrap(balance),
-	})
+	now, rates := s.currentRates()
+	jsonhttp.OK(w, newBalanceResponse(peer.String(), balance, rates, now))
 }
 
 func (s *Service) compensatedBalancesHandler(w http.ResponseWriter, r *http.Request) {
@@ -90,17 +129,7 @@ func (s *Service) compensatedBalancesHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	balResponses := make([]balanceResponse, len(balances))
-	i := 0
-	for k := range balances {
-		balResponses[i] = balanceResponse{
-			Peer:    k,
-			Balance: bigint.Wrap(balances[k]),
-		}
-		i++
-	}
-
-	jsonhttp.OK(w, balancesResponse{Balances: balResponses})
+	jsonhttp.OK(w, s.newBalancesResponse(balances))
 }
 
 func (s *Service) compensatedPeerBalanceHandler(w http.ResponseWriter, r *http.Request) {
@@ -125,8 +154,126 @@ func (s *Service) compensatedPeerBalanceHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	jsonhttp.OK(w, balanceResponse{
+	now, rates := s.currentRates()
+	jsonhttp.OK(w, newBalanceResponse(peer.String(), balance, rates, now))
+}
+
+type ledgerEntryResponse struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Type      string         `json:"type"`
+	Amount    *bigint.BigInt `json:"amount"`
+	Balance   *bigint.BigInt `json:"balance"`
+	Protocol  string         `json:"protocol,omitempty"`
+	Chunk     string         `json:"chunk,omitempty"`
+	// the amount and balance valued at the exchange rate that applied at
+	// the time
+	ExchangeRate *bigint.BigInt `json:"exchangeRate,omitempty"`
+	AmountBZZ    *bigint.BigInt `json:"amountBZZ,omitempty"`
+	BalanceBZZ   *bigint.BigInt `json:"balanceBZZ,omitempty"`
+}
+
+type balanceHistoryResponse struct {
+	Peer    string                `json:"peer"`
+	History []ledgerEntryResponse `json:"history"`
+}
+
+// balanceHistoryHandler returns the recorded balance changes with a peer,
+// either as JSON or, with the format=csv query parameter, as CSV.
+func (s *Service) balanceHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["peer"]
+	peer, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.logger.Debugf("debug api: balance history: invalid peer address %s: %v", addr, err)
+		s.logger.Errorf("debug api: balance history: invalid peer address %s", addr)
+		jsonhttp.NotFound(w, errInvalidAddress)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		jsonhttp.BadRequest(w, errInvalidHistoryFormat)
+		return
+	}
+
+	history, err := s.accounting.History(peer)
+	if err != nil {
+		s.logger.Debugf("debug api: balance history: get peer %s history: %v", peer.String(), err)
+		s.logger.Errorf("debug api: balance history: can't get peer %s history", peer.String())
+		jsonhttp.InternalServerError(w, errCantHistory)
+		return
+	}
+
+	var rates []priceoracle.Rate
+	if len(history) > 0 {
+		rates = s.rateHistory(history[0].Timestamp, time.Now())
+	}
+
+	entries := make([]ledgerEntryResponse, 0, len(history))
+	for _, e := range history {
+		entry := ledgerEntryResponse{
+			Timestamp: e.Timestamp,
+			Type:      string(e.Type),
+			Amount:    bigint.Wrap(e.Amount),
+			Balance:   bigint.Wrap(e.Balance),
+			Protocol:  e.Protocol,
+		}
+		if !e.Chunk.IsZero() {
+			entry.Chunk = e.Chunk.String()
+		}
+		if v, ok := valueUnits(rates, e.Timestamp, e.Amount); ok {
+			entry.ExchangeRate = bigint.Wrap(v.exchangeRate)
+			entry.AmountBZZ = bigint.Wrap(v.bzz)
+			entry.BalanceBZZ = bigint.Wrap(new(big.Int).Mul(e.Balance, v.exchangeRate))
+		}
+		entries = append(entries, entry)
+	}
+
+	if format == "csv" {
+		s.writeHistoryCSV(w, peer, entries)
+		return
+	}
+
+	jsonhttp.OK(w, balanceHistoryResponse{
 		Peer:    peer.String(),
-		Balance: bigint.Wrap(balance),
+		History: entries,
 	})
 }
+
+func (s *Service) writeHistoryCSV(w http.ResponseWriter, peer swarm.Address, entries []ledgerEntryResponse) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", peer.String()+".csv"))
+	w.WriteHeader(http.StatusOK)
+
+	// the valuation columns are only written if the node tracks the
+	// exchange rates
+	valued := s.priceOracle != nil
+
+	cw := csv.NewWriter(w)
+	header := []string{"timestamp", "type", "amount", "balance", "protocol", "chunk"}
+	if valued {
+		header = append(header, "exchange_rate", "amount_bzz", "balance_bzz")
+	}
+	records := [][]string{header}
+	for _, e := range entries {
+		record := []string{
+			e.Timestamp.Format(time.RFC3339Nano),
+			e.Type,
+			e.Amount.String(),
+			e.Balance.String(),
+			e.Protocol,
+			e.Chunk,
+		}
+		if valued {
+			if e.ExchangeRate != nil {
+				record = append(record, e.ExchangeRate.String(), e.AmountBZZ.String(), e.BalanceBZZ.String())
+			} else {
+				record = append(record, "", "", "")
+			}
+		}
+		records = append(records, record)
+	}
+	if err := cw.WriteAll(records); err != nil {
+		s.logger.Debugf("debug api: balance history: write csv: %v", err)
+		s.logger.Error("debug api: balance history: can't write csv")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/holisticode/bee/pkg/accounting"
 	"github.com/holisticode/bee/pkg/accounting/mock"
@@ -33,7 +34,7 @@ func TestBalances(t *testing.T) {
 	})
 
 	expected := &debugapi.BalancesResponse{
-		[]debugapi.BalanceResponse{
+		Balances: []debugapi.BalanceResponse{
 			{
 				Peer:    "DEAD",
 				Balance: bigint.Wrap(big.NewInt(1000000000000000000)),
@@ -61,6 +62,41 @@ func TestBalances(t *testing.T) {
 
 }
 
+func TestBalancesTrusted(t *testing.T) {
+	trusted := swarm.MustParseHexAddress("dead")
+	compensatedBalancesFunc := func() (ret map[string]*big.Int, err error) {
+		ret = make(map[string]*big.Int)
+		ret["dead"] = big.NewInt(0)
+		ret["beef"] = big.NewInt(-100)
+		return ret, err
+	}
+	testServer := newTestServer(t, testServerOptions{
+		AccountingOpts: []mock.Option{
+			mock.WithCompensatedBalancesFunc(compensatedBalancesFunc),
+			mock.WithTrustedFunc(func(peer swarm.Address) bool {
+				return peer.Equal(trusted)
+			}),
+		},
+	})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/balances", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.BalancesResponse{
+			Balances: []debugapi.BalanceResponse{
+				{
+					Peer:    "beef",
+					Balance: bigint.Wrap(big.NewInt(-100)),
+				},
+			},
+			Trusted: []debugapi.BalanceResponse{
+				{
+					Peer:    "dead",
+					Balance: bigint.Wrap(big.NewInt(0)),
+				},
+			},
+		}),
+	)
+}
+
 func TestBalancesError(t *testing.T) {
 	wantErr := errors.New("ASDF")
 	compensatedBalancesFunc := func() (ret map[string]*big.Int, err error) {
@@ -186,7 +222,7 @@ func TestConsumedBalances(t *testing.T) {
 	})
 
 	expected := &debugapi.BalancesResponse{
-		[]debugapi.BalanceResponse{
+		Balances: []debugapi.BalanceResponse{
 			{
 				Peer:    "DEAD",
 				Balance: bigint.Wrap(big.NewInt(1000000000000000000)),
@@ -295,3 +331,87 @@ func TestConsumedInvalidAddress(t *testing.T) {
 		}),
 	)
 }
+
+func TestBalanceHistory(t *testing.T) {
+	peer := swarm.MustParseHexAddress("bff2")
+	chunk := swarm.MustParseHexAddress("aa")
+	ts := time.Unix(1000, 0).UTC()
+	historyFunc := func(addr swarm.Address) ([]accounting.LedgerEntry, error) {
+		if !addr.Equal(peer) {
+			return nil, errors.New("wrong address")
+		}
+		return []accounting.LedgerEntry{
+			{
+				Timestamp: ts,
+				Type:      accounting.LedgerCredit,
+				Amount:    big.NewInt(10),
+				Balance:   big.NewInt(-10),
+				Protocol:  "retrieval",
+				Chunk:     chunk,
+			},
+			{
+				Timestamp: ts.Add(time.Second),
+				Type:      accounting.LedgerRefreshmentSent,
+				Amount:    big.NewInt(10),
+				Balance:   big.NewInt(0),
+			},
+		}, nil
+	}
+	testServer := newTestServer(t, testServerOptions{
+		AccountingOpts: []mock.Option{mock.WithHistoryFunc(historyFunc)},
+	})
+
+	t.Run("json", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/balances/"+peer.String()+"/history", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(debugapi.BalanceHistoryResponse{
+				Peer: peer.String(),
+				History: []debugapi.LedgerEntryResponse{
+					{
+						Timestamp: ts,
+						Type:      "credit",
+						Amount:    bigint.Wrap(big.NewInt(10)),
+						Balance:   bigint.Wrap(big.NewInt(-10)),
+						Protocol:  "retrieval",
+						Chunk:     chunk.String(),
+					},
+					{
+						Timestamp: ts.Add(time.Second),
+						Type:      "refreshment-sent",
+						Amount:    bigint.Wrap(big.NewInt(10)),
+						Balance:   bigint.Wrap(big.NewInt(0)),
+					},
+				},
+			}),
+		)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		want := "timestamp,type,amount,balance,protocol,chunk\n" +
+			"1970-01-01T00:16:40Z,credit,10,-10,retrieval,aa\n" +
+			"1970-01-01T00:16:41Z,refreshment-sent,10,0,,\n"
+		header := jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/balances/"+peer.String()+"/history?format=csv", http.StatusOK,
+			jsonhttptest.WithExpectedResponse([]byte(want)),
+		)
+		if ct := header.Get("Content-Type"); ct != "text/csv" {
+			t.Fatalf("got content type %s, want text/csv", ct)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/balances/"+peer.String()+"/history?format=xml", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "Invalid history format",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/balances/abcd/history", http.StatusInternalServerError,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: debugapi.ErrCantHistory,
+				Code:    http.StatusInternalServerError,
+			}),
+		)
+	})
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+const defaultBlocklistReason = "manual ban"
+
+var (
+	errInvalidBlocklistTarget   = errors.New("invalid peer address, ip address or network")
+	errInvalidBlocklistDuration = errors.New("invalid duration")
+)
+
+type blocklistedPeerResponse struct {
+	Address   swarm.Address `json:"address"`
+	FullNode  bool          `json:"fullNode"`
+	Reason    string        `json:"reason"`
+	Subsystem string        `json:"subsystem"`
+	Timestamp time.Time     `json:"timestamp"`
+	Duration  int64         `json:"duration"` // in seconds, zero for a permanent ban
+}
+
+type blocklistedNetworkResponse struct {
+	Network   string    `json:"network"`
+	Reason    string    `json:"reason"`
+	Subsystem string    `json:"subsystem"`
+	Timestamp time.Time `json:"timestamp"`
+	Duration  int64     `json:"duration"` // in seconds, zero for a permanent ban
+}
+
+type blocklistResponse struct {
+	Peers    []blocklistedPeerResponse    `json:"peers"`
+	Networks []blocklistedNetworkResponse `json:"networks"`
+}
+
+func (s *Service) blocklistedPeersHandler(w http.ResponseWriter, r *http.Request) {
+	peers, err := s.p2p.BlocklistEntries()
+	if err != nil {
+		s.logger.Debugf("debug api: blocklisted peers: %v", err)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+	networks, err := s.p2p.BlocklistedNetworks()
+	if err != nil {
+		s.logger.Debugf("debug api: blocklisted networks: %v", err)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	var resp blocklistResponse
+	for _, p := range peers {
+		resp.Peers = append(resp.Peers, blocklistedPeerResponse{
+			Address:   p.Address,
+			FullNode:  p.FullNode,
+			Reason:    p.Reason,
+			Subsystem: p.Subsystem,
+			Timestamp: p.Timestamp,
+			Duration:  int64(p.Duration.Seconds()),
+		})
+	}
+	for _, n := range networks {
+		resp.Networks = append(resp.Networks, blocklistedNetworkResponse{
+			Network:   n.Network.String(),
+			Reason:    n.Reason,
+			Subsystem: n.Subsystem,
+			Timestamp: n.Timestamp,
+			Duration:  int64(n.Duration.Seconds()),
+		})
+	}
+	jsonhttp.OK(w, resp)
+}
+
+// blocklistHandler bans a peer, an IP address or a network for the duration
+// in seconds given by the duration query parameter. A missing or zero
+// duration bans permanently.
+func (s *Service) blocklistHandler(w http.ResponseWriter, r *http.Request) {
+	target := mux.Vars(r)["address"]
+	overlay, network, err := parseBlocklistTarget(target)
+	if err != nil {
+		s.logger.Debugf("debug api: blocklist: parse address %s: %v", target, err)
+		jsonhttp.BadRequest(w, errInvalidBlocklistTarget)
+		return
+	}
+
+	var duration time.Duration
+	if v := r.URL.Query().Get("duration"); v != "" {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || sec < 0 {
+			s.logger.Debugf("debug api: blocklist: parse duration %s: %v", v, err)
+			jsonhttp.BadRequest(w, errInvalidBlocklistDuration)
+			return
+		}
+		duration = time.Duration(sec) * time.Second
+	}
+	reason := r.URL.Query().Get("reason")
+	if reason == "" {
+		reason = defaultBlocklistReason
+	}
+
+	if network != nil {
+		err = s.p2p.BlocklistNetwork(network, duration, reason)
+	} else {
+		err = s.p2p.BlocklistBy("api", overlay, duration, reason)
+	}
+	if err != nil {
+		s.logger.Debugf("debug api: blocklist %s: %v", target, err)
+		s.logger.Errorf("unable to blocklist %s", target)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+// unblocklistHandler lifts the ban of a peer, an IP address or a network.
+func (s *Service) unblocklistHandler(w http.ResponseWriter, r *http.Request) {
+	target := mux.Vars(r)["address"]
+	overlay, network, err := parseBlocklistTarget(target)
+	if err != nil {
+		s.logger.Debugf("debug api: unblocklist: parse address %s: %v", target, err)
+		jsonhttp.BadRequest(w, errInvalidBlocklistTarget)
+		return
+	}
+
+	if network != nil {
+		err = s.p2p.UnblocklistNetwork(network)
+	} else {
+		err = s.p2p.Unblocklist(overlay)
+	}
+	if err != nil {
+		s.logger.Debugf("debug api: unblocklist %s: %v", target, err)
+		if errors.Is(err, p2p.ErrNotBlocklisted) {
+			jsonhttp.NotFound(w, "not blocklisted")
+			return
+		}
+		s.logger.Errorf("unable to unblocklist %s", target)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+// parseBlocklistTarget parses the target of a ban which is either an overlay
+// address, an IP address or a network in CIDR notation. A single IP address
+// is returned as a network containing only that address.
+func parseBlocklistTarget(s string) (swarm.Address, *net.IPNet, error) {
+	if overlay, err := swarm.ParseHexAddress(s); err == nil && len(overlay.Bytes()) == swarm.HashSize {
+		return overlay, nil, nil
+	}
+	if ip := net.ParseIP(s); ip != nil {
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		return swarm.ZeroAddress, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return swarm.ZeroAddress, nil, err
+	}
+	return swarm.ZeroAddress, network, nil
+}
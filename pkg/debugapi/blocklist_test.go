@@ -0,0 +1,240 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/holisticode/bee/pkg/debugapi"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/p2p/mock"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+func TestBlocklistedPeers(t *testing.T) {
+	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+	ts := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testServer := newTestServer(t, testServerOptions{
+		P2P: mock.New(
+			mock.WithBlocklistEntriesFunc(func() ([]p2p.BlocklistedPeer, error) {
+				return []p2p.BlocklistedPeer{{
+					Peer: p2p.Peer{Address: overlay, FullNode: true},
+					BlocklistEntry: p2p.BlocklistEntry{
+						Reason:    "disconnected while in debt",
+						Subsystem: "accounting",
+						Timestamp: ts,
+						Duration:  time.Hour,
+					},
+				}}, nil
+			}),
+			mock.WithBlocklistedNetworksFunc(func() ([]p2p.BlocklistedNetwork, error) {
+				return []p2p.BlocklistedNetwork{{
+					Network: network,
+					BlocklistEntry: p2p.BlocklistEntry{
+						Reason:    "manual ban",
+						Subsystem: "api",
+						Timestamp: ts,
+					},
+				}}, nil
+			}),
+		),
+	})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/blocklist", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.BlocklistResponse{
+			Peers: []debugapi.BlocklistedPeerResponse{{
+				Address:   overlay,
+				FullNode:  true,
+				Reason:    "disconnected while in debt",
+				Subsystem: "accounting",
+				Timestamp: ts,
+				Duration:  3600,
+			}},
+			Networks: []debugapi.BlocklistedNetworkResponse{{
+				Network:   "10.0.0.0/8",
+				Reason:    "manual ban",
+				Subsystem: "api",
+				Timestamp: ts,
+			}},
+		}),
+	)
+}
+
+func TestBlocklistedPeersErr(t *testing.T) {
+	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	testServer := newTestServer(t, testServerOptions{
+		P2P: mock.New(mock.WithBlocklistedPeersFunc(func() ([]p2p.Peer, error) {
+			return []p2p.Peer{{Address: overlay}}, errors.New("some error")
+		})),
+	})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/blocklist", http.StatusInternalServerError,
+		jsonhttptest.WithExpectedJSONResponse(
+			jsonhttp.StatusResponse{
+				Code:    http.StatusInternalServerError,
+				Message: http.StatusText(http.StatusInternalServerError),
+			}),
+	)
+}
+
+func TestBlocklist(t *testing.T) {
+	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+
+	type ban struct {
+		subsystem string
+		overlay   swarm.Address
+		network   string
+		duration  time.Duration
+		reason    string
+	}
+	var got ban
+	testServer := newTestServer(t, testServerOptions{
+		P2P: mock.New(
+			mock.WithBlocklistByFunc(func(subsystem string, overlay swarm.Address, duration time.Duration, reason string) error {
+				got = ban{subsystem: subsystem, overlay: overlay, duration: duration, reason: reason}
+				return nil
+			}),
+			mock.WithBlocklistNetworkFunc(func(network *net.IPNet, duration time.Duration, reason string) error {
+				got = ban{network: network.String(), duration: duration, reason: reason}
+				return nil
+			}),
+		),
+	})
+
+	for _, tc := range []struct {
+		name string
+		url  string
+		want ban
+	}{
+		{
+			name: "peer",
+			url:  "/blocklist/" + overlay.String() + "?duration=60&reason=spam",
+			want: ban{subsystem: "api", overlay: overlay, duration: time.Minute, reason: "spam"},
+		},
+		{
+			name: "permanent",
+			url:  "/blocklist/" + overlay.String(),
+			want: ban{subsystem: "api", overlay: overlay, reason: "manual ban"},
+		},
+		{
+			name: "ip",
+			url:  "/blocklist/192.168.1.10",
+			want: ban{network: "192.168.1.10/32", reason: "manual ban"},
+		},
+		{
+			name: "ipv6",
+			url:  "/blocklist/2001:db8::1?duration=10",
+			want: ban{network: "2001:db8::1/128", duration: 10 * time.Second, reason: "manual ban"},
+		},
+		{
+			name: "cidr",
+			url:  "/blocklist/10.0.0.0/8",
+			want: ban{network: "10.0.0.0/8", reason: "manual ban"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got = ban{}
+			jsonhttptest.Request(t, testServer.Client, http.MethodPost, tc.url, http.StatusOK,
+				jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+					Code:    http.StatusOK,
+					Message: http.StatusText(http.StatusOK),
+				}),
+			)
+			if got.subsystem != tc.want.subsystem || !got.overlay.Equal(tc.want.overlay) || got.network != tc.want.network ||
+				got.duration != tc.want.duration || got.reason != tc.want.reason {
+				t.Fatalf("got ban %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("invalid address", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/blocklist/invalid", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid peer address, ip address or network",
+			}),
+		)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/blocklist/"+overlay.String()+"?duration=-1", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid duration",
+			}),
+		)
+	})
+}
+
+func TestUnblocklist(t *testing.T) {
+	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	unknown := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59d")
+
+	var lifted string
+	testServer := newTestServer(t, testServerOptions{
+		P2P: mock.New(
+			mock.WithUnblocklistFunc(func(addr swarm.Address) error {
+				if !addr.Equal(overlay) {
+					return p2p.ErrNotBlocklisted
+				}
+				lifted = addr.String()
+				return nil
+			}),
+			mock.WithUnblocklistNetworkFunc(func(network *net.IPNet) error {
+				lifted = network.String()
+				return nil
+			}),
+		),
+	})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodDelete, "/blocklist/"+overlay.String(), http.StatusOK)
+	if lifted != overlay.String() {
+		t.Fatalf("got lifted %q, want %q", lifted, overlay)
+	}
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodDelete, "/blocklist/10.1.0.0/16", http.StatusOK)
+	if lifted != "10.1.0.0/16" {
+		t.Fatalf("got lifted %q, want %q", lifted, "10.1.0.0/16")
+	}
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodDelete, "/blocklist/"+unknown.String(), http.StatusNotFound,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Code:    http.StatusNotFound,
+			Message: "not blocklisted",
+		}),
+	)
+}
+
+func TestBlocklistInternalError(t *testing.T) {
+	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	storeErr := errors.New("state store failure")
+
+	testServer := newTestServer(t, testServerOptions{
+		P2P: mock.New(
+			mock.WithBlocklistFunc(func(swarm.Address, time.Duration, string) error {
+				return storeErr
+			}),
+			mock.WithUnblocklistFunc(func(swarm.Address) error {
+				return storeErr
+			}),
+		),
+	})
+
+	// the error is logged, not returned
+	want := jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+		Code:    http.StatusInternalServerError,
+		Message: http.StatusText(http.StatusInternalServerError),
+	})
+	jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/blocklist/"+overlay.String(), http.StatusInternalServerError, want)
+	jsonhttptest.Request(t, testServer.Client, http.MethodDelete, "/blocklist/"+overlay.String(), http.StatusInternalServerError, want)
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"math/big"
+	"net/http"
+
+	"github.com/holisticode/bee/pkg/accounting"
+	"github.com/holisticode/bee/pkg/bigint"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+)
+
+var errCantBudgets = "Cannot get budgets"
+
+type budgetResponse struct {
+	Key         string         `json:"key,omitempty"`
+	HourlyLimit *bigint.BigInt `json:"hourlyLimit"`
+	DailyLimit  *bigint.BigInt `json:"dailyLimit"`
+	TotalLimit  *bigint.BigInt `json:"totalLimit"`
+	HourlySpent *bigint.BigInt `json:"hourlySpent"`
+	DailySpent  *bigint.BigInt `json:"dailySpent"`
+	TotalSpent  *bigint.BigInt `json:"totalSpent"`
+	Reserved    *bigint.BigInt `json:"reserved"`
+}
+
+type budgetsResponse struct {
+	Global budgetResponse   `json:"global"`
+	Keys   []budgetResponse `json:"keys"`
+}
+
+// wrapLimit wraps the limit, leaving unset limits nil.
+func wrapLimit(limit *big.Int) *bigint.BigInt {
+	if limit == nil {
+		return nil
+	}
+	return bigint.Wrap(limit)
+}
+
+func newBudgetResponse(b accounting.BudgetStatus) budgetResponse {
+	return budgetResponse{
+		Key:         b.Key,
+		HourlyLimit: wrapLimit(b.Limits.Hourly),
+		DailyLimit:  wrapLimit(b.Limits.Daily),
+		TotalLimit:  wrapLimit(b.Limits.Total),
+		HourlySpent: bigint.Wrap(b.Hourly),
+		DailySpent:  bigint.Wrap(b.Daily),
+		TotalSpent:  bigint.Wrap(b.Total),
+		Reserved:    bigint.Wrap(b.Reserved),
+	}
+}
+
+// budgetHandler reports the spending on originated traffic against the
+// global budget and the budgets of the security tokens.
+func (s *Service) budgetHandler(w http.ResponseWriter, r *http.Request) {
+	budgets, err := s.accounting.Budgets()
+	if err != nil {
+		jsonhttp.InternalServerError(w, errCantBudgets)
+		s.logger.Debugf("debug api: budgets: %v", err)
+		s.logger.Error("debug api: can not get budgets")
+		return
+	}
+
+	resp := budgetsResponse{
+		Keys: make([]budgetResponse, 0, len(budgets)),
+	}
+	for _, b := range budgets {
+		if b.Key == "" {
+			resp.Global = newBudgetResponse(b)
+			continue
+		}
+		resp.Keys = append(resp.Keys, newBudgetResponse(b))
+	}
+
+	jsonhttp.OK(w, resp)
+}
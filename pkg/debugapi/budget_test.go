@@ -0,0 +1,82 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/holisticode/bee/pkg/accounting"
+	"github.com/holisticode/bee/pkg/accounting/mock"
+	"github.com/holisticode/bee/pkg/bigint"
+	"github.com/holisticode/bee/pkg/debugapi"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
+)
+
+func TestBudget(t *testing.T) {
+	budgetsFunc := func() ([]accounting.BudgetStatus, error) {
+		return []accounting.BudgetStatus{
+			{
+				Limits:   accounting.BudgetLimits{Daily: big.NewInt(1000)},
+				Hourly:   big.NewInt(10),
+				Daily:    big.NewInt(100),
+				Total:    big.NewInt(500),
+				Reserved: big.NewInt(5),
+			},
+			{
+				Key:      "abcd",
+				Limits:   accounting.BudgetLimits{Total: big.NewInt(200)},
+				Hourly:   big.NewInt(0),
+				Daily:    big.NewInt(20),
+				Total:    big.NewInt(150),
+				Reserved: big.NewInt(0),
+			},
+		}, nil
+	}
+	testServer := newTestServer(t, testServerOptions{
+		AccountingOpts: []mock.Option{mock.WithBudgetsFunc(budgetsFunc)},
+	})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/budget", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.BudgetsResponse{
+			Global: debugapi.BudgetResponse{
+				DailyLimit:  bigint.Wrap(big.NewInt(1000)),
+				HourlySpent: bigint.Wrap(big.NewInt(10)),
+				DailySpent:  bigint.Wrap(big.NewInt(100)),
+				TotalSpent:  bigint.Wrap(big.NewInt(500)),
+				Reserved:    bigint.Wrap(big.NewInt(5)),
+			},
+			Keys: []debugapi.BudgetResponse{
+				{
+					Key:         "abcd",
+					TotalLimit:  bigint.Wrap(big.NewInt(200)),
+					HourlySpent: bigint.Wrap(big.NewInt(0)),
+					DailySpent:  bigint.Wrap(big.NewInt(20)),
+					TotalSpent:  bigint.Wrap(big.NewInt(150)),
+					Reserved:    bigint.Wrap(big.NewInt(0)),
+				},
+			},
+		}),
+	)
+}
+
+func TestBudgetError(t *testing.T) {
+	budgetsFunc := func() ([]accounting.BudgetStatus, error) {
+		return nil, errors.New("ASDF")
+	}
+	testServer := newTestServer(t, testServerOptions{
+		AccountingOpts: []mock.Option{mock.WithBudgetsFunc(budgetsFunc)},
+	})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/budget", http.StatusInternalServerError,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: debugapi.ErrCantBudgets,
+			Code:    http.StatusInternalServerError,
+		}),
+	)
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+
+	"github.com/holisticode/bee/pkg/bigint"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+)
+
+var errCantRefills = "Cannot get chequebook refills"
+
+type chequebookRefillPolicyResponse struct {
+	Floor  *bigint.BigInt `json:"floor"`
+	Target *bigint.BigInt `json:"target"`
+	Cap    *bigint.BigInt `json:"cap"`
+	Period int64          `json:"period"` // in seconds
+}
+
+type chequebookRefillResponse struct {
+	Timestamp        int64          `json:"timestamp"`
+	Amount           *bigint.BigInt `json:"amount"`
+	AvailableBalance *bigint.BigInt `json:"availableBalance"`
+	TransactionHash  string         `json:"transactionHash"`
+	Pending          bool           `json:"pending,omitempty"`
+	Error            string         `json:"error,omitempty"`
+}
+
+type chequebookRefillsResponse struct {
+	Enabled bool                            `json:"enabled"`
+	Policy  *chequebookRefillPolicyResponse `json:"policy,omitempty"`
+	Refills []chequebookRefillResponse      `json:"refills"`
+}
+
+// chequebookRefillsHandler reports the refill policy of the chequebook and
+// the deposits made by it.
+func (s *Service) chequebookRefillsHandler(w http.ResponseWriter, r *http.Request) {
+	if s.refiller == nil {
+		jsonhttp.OK(w, chequebookRefillsResponse{Refills: []chequebookRefillResponse{}})
+		return
+	}
+
+	refills, err := s.refiller.Refills()
+	if err != nil {
+		jsonhttp.InternalServerError(w, errCantRefills)
+		s.logger.Debugf("debug api: chequebook refills: %v", err)
+		s.logger.Error("debug api: cannot get chequebook refills")
+		return
+	}
+
+	policy := s.refiller.Policy()
+	resp := chequebookRefillsResponse{
+		Enabled: true,
+		Policy: &chequebookRefillPolicyResponse{
+			Floor:  bigint.Wrap(policy.Floor),
+			Target: bigint.Wrap(policy.Target),
+			Cap:    bigint.Wrap(policy.Cap),
+			Period: int64(policy.Period.Seconds()),
+		},
+		Refills: make([]chequebookRefillResponse, 0, len(refills)),
+	}
+	for _, refill := range refills {
+		resp.Refills = append(resp.Refills, chequebookRefillResponse{
+			Timestamp:        refill.Timestamp,
+			Amount:           bigint.Wrap(refill.Amount),
+			AvailableBalance: bigint.Wrap(refill.AvailableBalance),
+			TransactionHash:  refill.TxHash.String(),
+			Pending:          refill.Pending,
+			Error:            refill.Error,
+		})
+	}
+
+	jsonhttp.OK(w, resp)
+}
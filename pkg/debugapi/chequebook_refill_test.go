@@ -0,0 +1,86 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/bigint"
+	"github.com/holisticode/bee/pkg/debugapi"
+	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
+	"github.com/holisticode/bee/pkg/settlement/swap/chequebook/mock"
+	erc20mock "github.com/holisticode/bee/pkg/settlement/swap/erc20/mock"
+	statestore "github.com/holisticode/bee/pkg/statestore/mock"
+)
+
+func TestChequebookRefills(t *testing.T) {
+	store := statestore.NewStateStore()
+	t.Cleanup(func() { _ = store.Close() })
+
+	txHash := common.HexToHash("0xaa")
+	err := store.Put("swap_chequebook_refills", []chequebook.Refill{
+		{
+			Timestamp:        100,
+			Amount:           big.NewInt(250),
+			AvailableBalance: big.NewInt(50),
+			TxHash:           txHash,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refiller, err := chequebook.NewRefiller(mock.NewChequebook(), erc20mock.New(), common.Address{}, store, chequebook.RefillPolicy{
+		Floor:  big.NewInt(100),
+		Target: big.NewInt(300),
+		Cap:    big.NewInt(1000),
+		Period: 24 * time.Hour,
+	}, logging.New(io.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = refiller.Close() })
+
+	testServer := newTestServer(t, testServerOptions{
+		Refiller: refiller,
+	})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/chequebook/refills", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.ChequebookRefillsResponse{
+			Enabled: true,
+			Policy: &debugapi.ChequebookRefillPolicyResponse{
+				Floor:  bigint.Wrap(big.NewInt(100)),
+				Target: bigint.Wrap(big.NewInt(300)),
+				Cap:    bigint.Wrap(big.NewInt(1000)),
+				Period: 86400,
+			},
+			Refills: []debugapi.ChequebookRefillResponse{
+				{
+					Timestamp:        100,
+					Amount:           bigint.Wrap(big.NewInt(250)),
+					AvailableBalance: bigint.Wrap(big.NewInt(50)),
+					TransactionHash:  txHash.String(),
+				},
+			},
+		}),
+	)
+}
+
+func TestChequebookRefillsDisabled(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/chequebook/refills", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.ChequebookRefillsResponse{
+			Refills: []debugapi.ChequebookRefillResponse{},
+		}),
+	)
+}
@@ -16,14 +16,18 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/holisticode/bee/pkg/accounting"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/p2p"
 	"github.com/holisticode/bee/pkg/pingpong"
 	"github.com/holisticode/bee/pkg/postage"
 	"github.com/holisticode/bee/pkg/postage/postagecontract"
 	"github.com/holisticode/bee/pkg/settlement"
+	"github.com/holisticode/bee/pkg/settlement/driver"
+	"github.com/holisticode/bee/pkg/settlement/history"
 	"github.com/holisticode/bee/pkg/settlement/swap"
 	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
+	"github.com/holisticode/bee/pkg/settlement/swap/priceoracle"
 	"github.com/holisticode/bee/pkg/storage"
 	"github.com/holisticode/bee/pkg/swarm"
 	"github.com/holisticode/bee/pkg/tags"
@@ -60,6 +64,10 @@ type Service struct {
 	pseudosettle       settlement.Interface
 	chequebookEnabled  bool
 	chequebook         chequebook.Service
+	refiller           *chequebook.Refiller
+	settlementHistory  *history.History
+	settlementDrivers  *driver.Service
+	priceOracle        priceoracle.Service
 	swap               swap.Interface
 	batchStore         postage.Storer
 	transaction        transaction.Service
@@ -71,6 +79,7 @@ type Service struct {
 	lightNodes         *lightnode.Container
 	blockTime          *big.Int
 	traverser          traversal.Traverser
+	events             *events.Service
 	// handler is changed in the Configure method
 	handler   http.Handler
 	handlerMu sync.RWMutex
@@ -109,7 +118,7 @@ func New(publicKey, pssPublicKey ecdsa.PublicKey, ethereumAddress common.Address
 // Configure injects required dependencies and configuration parameters and
 // constructs HTTP routes that depend on them. It is intended and safe to call
 // this method only once.
-func (s *Service) Configure(overlay swarm.Address, p2p p2p.DebugService, pingpong pingpong.Interface, topologyDriver topology.Driver, lightNodes *lightnode.Container, storer storage.Storer, tags *tags.Tags, accounting accounting.Interface, pseudosettle settlement.Interface, chequebookEnabled bool, swap swap.Interface, chequebook chequebook.Service, batchStore postage.Storer, post postage.Service, postageContract postagecontract.Interface, traverser traversal.Traverser) {
+func (s *Service) Configure(overlay swarm.Address, p2p p2p.DebugService, pingpong pingpong.Interface, topologyDriver topology.Driver, lightNodes *lightnode.Container, storer storage.Storer, tags *tags.Tags, accounting accounting.Interface, pseudosettle settlement.Interface, chequebookEnabled bool, swap swap.Interface, chequebook chequebook.Service, refiller *chequebook.Refiller, settlementHistory *history.History, settlementDrivers *driver.Service, priceOracle priceoracle.Service, batchStore postage.Storer, post postage.Service, postageContract postagecontract.Interface, traverser traversal.Traverser, events *events.Service) {
 	s.p2p = p2p
 	s.pingpong = pingpong
 	s.topologyDriver = topologyDriver
@@ -118,6 +127,10 @@ func (s *Service) Configure(overlay swarm.Address, p2p p2p.DebugService, pingpon
 	s.accounting = accounting
 	s.chequebookEnabled = chequebookEnabled
 	s.chequebook = chequebook
+	s.refiller = refiller
+	s.settlementHistory = settlementHistory
+	s.settlementDrivers = settlementDrivers
+	s.priceOracle = priceOracle
 	s.swap = swap
 	s.lightNodes = lightNodes
 	s.batchStore = batchStore
@@ -126,6 +139,7 @@ func (s *Service) Configure(overlay swarm.Address, p2p p2p.DebugService, pingpon
 	s.post = post
 	s.postageContract = postageContract
 	s.traverser = traverser
+	s.events = events
 
 	s.setRouter(s.newRouter())
 }
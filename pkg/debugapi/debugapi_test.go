@@ -21,6 +21,7 @@ import (
 	"github.com/holisticode/bee/pkg/api"
 	"github.com/holisticode/bee/pkg/crypto"
 	"github.com/holisticode/bee/pkg/debugapi"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/jsonhttp"
 	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
 	"github.com/holisticode/bee/pkg/logging"
@@ -30,8 +31,12 @@ import (
 	mockpost "github.com/holisticode/bee/pkg/postage/mock"
 	"github.com/holisticode/bee/pkg/postage/postagecontract"
 	"github.com/holisticode/bee/pkg/resolver"
+	"github.com/holisticode/bee/pkg/settlement/driver"
+	"github.com/holisticode/bee/pkg/settlement/history"
+	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
 	chequebookmock "github.com/holisticode/bee/pkg/settlement/swap/chequebook/mock"
 	swapmock "github.com/holisticode/bee/pkg/settlement/swap/mock"
+	"github.com/holisticode/bee/pkg/settlement/swap/priceoracle"
 	"github.com/holisticode/bee/pkg/storage"
 	"github.com/holisticode/bee/pkg/swarm"
 	"github.com/holisticode/bee/pkg/tags"
@@ -65,16 +70,22 @@ type testServerOptions struct {
 	Storer             storage.Storer
 	Resolver           resolver.Interface
 	TopologyOpts       []topologymock.Option
+	LightNodes         *lightnode.Container
 	Tags               *tags.Tags
 	AccountingOpts     []accountingmock.Option
 	SettlementOpts     []swapmock.Option
 	ChequebookOpts     []chequebookmock.Option
+	Refiller           *chequebook.Refiller
+	SettlementHistory  *history.History
+	SettlementDrivers  *driver.Service
+	PriceOracle        priceoracle.Service
 	SwapOpts           []swapmock.Option
 	BatchStore         postage.Storer
 	TransactionOpts    []transactionmock.Option
 	PostageContract    postagecontract.Interface
 	Post               postage.Service
 	Traverser          traversal.Traverser
+	Events             *events.Service
 }
 
 type testServer struct {
@@ -89,9 +100,12 @@ func newTestServer(t *testing.T, o testServerOptions) *testServer {
 	chequebook := chequebookmock.NewChequebook(o.ChequebookOpts...)
 	swapserv := swapmock.New(o.SwapOpts...)
 	transaction := transactionmock.New(o.TransactionOpts...)
-	ln := lightnode.NewContainer(o.Overlay)
+	ln := o.LightNodes
+	if ln == nil {
+		ln = lightnode.NewContainer(o.Overlay)
+	}
 	s := debugapi.New(o.PublicKey, o.PSSPublicKey, o.EthereumAddress, logging.New(io.Discard, 0), nil, o.CORSAllowedOrigins, big.NewInt(2), transaction, false, nil)
-	s.Configure(o.Overlay, o.P2P, o.Pingpong, topologyDriver, ln, o.Storer, o.Tags, acc, settlement, true, swapserv, chequebook, o.BatchStore, o.Post, o.PostageContract, o.Traverser)
+	s.Configure(o.Overlay, o.P2P, o.Pingpong, topologyDriver, ln, o.Storer, o.Tags, acc, settlement, true, swapserv, chequebook, o.Refiller, o.SettlementHistory, o.SettlementDrivers, o.PriceOracle, o.BatchStore, o.Post, o.PostageContract, o.Traverser, o.Events)
 	ts := httptest.NewServer(s)
 	t.Cleanup(ts.Close)
 
@@ -188,7 +202,7 @@ func TestServer_Configure(t *testing.T) {
 		}),
 	)
 
-	s.Configure(o.Overlay, o.P2P, o.Pingpong, topologyDriver, ln, o.Storer, o.Tags, acc, settlement, true, swapserv, chequebook, nil, mockpost.New(), nil, nil)
+	s.Configure(o.Overlay, o.P2P, o.Pingpong, topologyDriver, ln, o.Storer, o.Tags, acc, settlement, true, swapserv, chequebook, nil, nil, nil, nil, nil, mockpost.New(), nil, nil, nil)
 
 	testBasicRouter(t, client)
 	jsonhttptest.Request(t, client, http.MethodGet, "/readiness", http.StatusOK,
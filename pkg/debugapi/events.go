@@ -0,0 +1,98 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/holisticode/bee/pkg/events"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+)
+
+const (
+	errEventsUnsupported = "streaming unsupported"
+	errEventsBadType     = "invalid event type"
+)
+
+// eventsHandler streams node events to the client as server-sent events.
+// The optional comma separated type query parameter limits the stream to
+// the given event types.
+func (s *Service) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	s.streamEvents(w, r, nil)
+}
+
+// peerEventsHandler streams the lifecycle events of the peers in the
+// topology to the client as server-sent events. The optional comma separated
+// type query parameter limits the stream to the given peer event types.
+func (s *Service) peerEventsHandler(w http.ResponseWriter, r *http.Request) {
+	s.streamEvents(w, r, events.IsPeerType)
+}
+
+// streamEvents streams the events of the types given in the type query
+// parameter, or of all types accepted by the filter if it is not given. A
+// nil filter accepts all types.
+func (s *Service) streamEvents(w http.ResponseWriter, r *http.Request, filter func(events.Type) bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		jsonhttp.InternalServerError(w, errEventsUnsupported)
+		s.logger.Error("debug api: events: response writer does not support flushing")
+		return
+	}
+
+	var types []events.Type
+	if q := r.URL.Query().Get("type"); q != "" {
+		for _, name := range strings.Split(q, ",") {
+			t, err := events.ParseType(strings.TrimSpace(name))
+			if err == nil && filter != nil && !filter(t) {
+				err = fmt.Errorf("%w: %s", events.ErrUnknownType, name)
+			}
+			if err != nil {
+				jsonhttp.BadRequest(w, errEventsBadType)
+				s.logger.Debugf("debug api: events: %v", err)
+				return
+			}
+			types = append(types, t)
+		}
+	} else if filter != nil {
+		for _, t := range events.Types() {
+			if filter(t) {
+				types = append(types, t)
+			}
+		}
+	}
+
+	c, unsubscribe := s.events.Subscribe(types...)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case e, ok := <-c:
+			if !ok {
+				return
+			}
+			b, err := json.Marshal(e)
+			if err != nil {
+				s.logger.Debugf("debug api: events: marshal %s event: %v", e.Type, err)
+				s.logger.Error("debug api: events: cannot marshal event")
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, b); err != nil {
+				s.logger.Debugf("debug api: events: write: %v", err)
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/holisticode/bee/pkg/events"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+func TestEvents(t *testing.T) {
+	eventsService := events.New(logging.New(io.Discard, 0))
+	t.Cleanup(func() { eventsService.Close() })
+
+	srv := newTestServer(t, testServerOptions{
+		Events: eventsService,
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/events?type=batch-expired", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := srv.Client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("got content type %s, want text/event-stream", ct)
+		}
+
+		eventsService.Publish(events.Event{Type: events.BatchExpiring, Data: events.BatchData{BatchID: "aa"}})
+		eventsService.Publish(events.Event{Type: events.BatchExpired, Data: events.BatchData{BatchID: "bb"}})
+
+		r := bufio.NewReader(resp.Body)
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "event: batch-expired\n"; line != want {
+			t.Fatalf("got line %q, want %q", line, want)
+		}
+		line, err = r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		var e struct {
+			Type string           `json:"type"`
+			Data events.BatchData `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Type != events.BatchExpired.String() {
+			t.Fatalf("got type %s, want %s", e.Type, events.BatchExpired)
+		}
+		if e.Data.BatchID != "bb" {
+			t.Fatalf("got batch id %s, want bb", e.Data.BatchID)
+		}
+	})
+
+	t.Run("bad type", func(t *testing.T) {
+		jsonhttptest.Request(t, srv.Client, http.MethodGet, "/events?type=unknown", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid event type",
+			}),
+		)
+	})
+}
+
+func TestPeerEvents(t *testing.T) {
+	eventsService := events.New(logging.New(io.Discard, 0))
+	t.Cleanup(func() { eventsService.Close() })
+
+	srv := newTestServer(t, testServerOptions{
+		Events: eventsService,
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/events/peers", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := srv.Client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+
+		overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+		eventsService.Publish(events.Event{Type: events.BatchExpired, Data: events.BatchData{BatchID: "bb"}})
+		eventsService.Publish(events.Event{Type: events.PeerDisconnected, Data: events.PeerData{
+			Overlay: overlay,
+			Bin:     4,
+			Reason:  "blocklisting peer",
+		}})
+
+		r := bufio.NewReader(resp.Body)
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "event: peer-disconnected\n"; line != want {
+			t.Fatalf("got line %q, want %q", line, want)
+		}
+		line, err = r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		var e struct {
+			Type string          `json:"type"`
+			Data events.PeerData `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e); err != nil {
+			t.Fatal(err)
+		}
+		if !e.Data.Overlay.Equal(overlay) || e.Data.Bin != 4 || e.Data.Reason != "blocklisting peer" {
+			t.Fatalf("unexpected event data %+v", e.Data)
+		}
+	})
+
+	t.Run("not a peer type", func(t *testing.T) {
+		jsonhttptest.Request(t, srv.Client, http.MethodGet, "/events/peers?type=batch-expired", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid event type",
+			}),
+		)
+	})
+}
@@ -9,11 +9,20 @@ type (
 	PingpongResponse                  = pingpongResponse
 	PeerConnectResponse               = peerConnectResponse
 	PeersResponse                     = peersResponse
+	PeerResponse                      = peerResponse
+	ProtocolBandwidthResponse         = protocolBandwidthResponse
+	BlocklistResponse                 = blocklistResponse
+	BlocklistedPeerResponse           = blocklistedPeerResponse
+	BlocklistedNetworkResponse        = blocklistedNetworkResponse
 	AddressesResponse                 = addressesResponse
 	WelcomeMessageRequest             = welcomeMessageRequest
 	WelcomeMessageResponse            = welcomeMessageResponse
 	BalancesResponse                  = balancesResponse
 	BalanceResponse                   = balanceResponse
+	BalanceHistoryResponse            = balanceHistoryResponse
+	BudgetResponse                    = budgetResponse
+	BudgetsResponse                   = budgetsResponse
+	LedgerEntryResponse               = ledgerEntryResponse
 	SettlementResponse                = settlementResponse
 	SettlementsResponse               = settlementsResponse
 	ChequebookBalanceResponse         = chequebookBalanceResponse
@@ -22,6 +31,17 @@ type (
 	ChequebookLastChequesResponse     = chequebookLastChequesResponse
 	ChequebookLastChequesPeerResponse = chequebookLastChequesPeerResponse
 	ChequebookTxResponse              = chequebookTxResponse
+	ChequebookRefillsResponse         = chequebookRefillsResponse
+	ChequebookRefillPolicyResponse    = chequebookRefillPolicyResponse
+	ChequebookRefillResponse          = chequebookRefillResponse
+	SettlementEventResponse           = settlementEventResponse
+	SettlementHistoryResponse         = settlementHistoryResponse
+	SettlementReportEntryResponse     = settlementReportEntryResponse
+	SettlementReportResponse          = settlementReportResponse
+	PriceOracleHistoryResponse        = priceOracleHistoryResponse
+	PriceOracleRateResponse           = priceOracleRateResponse
+	SettlementDriversResponse         = settlementDriversResponse
+	SettlementDriversPeerResponse     = settlementDriversPeerResponse
 	SwapCashoutResponse               = swapCashoutResponse
 	SwapCashoutStatusResponse         = swapCashoutStatusResponse
 	SwapCashoutStatusResult           = swapCashoutStatusResult
@@ -41,9 +61,14 @@ type (
 var (
 	ErrCantBalance           = errCantBalance
 	ErrCantBalances          = errCantBalances
+	ErrCantBudgets           = errCantBudgets
 	ErrNoBalance             = errNoBalance
+	ErrCantHistory           = errCantHistory
 	ErrCantSettlementsPeer   = errCantSettlementsPeer
 	ErrCantSettlements       = errCantSettlements
+	ErrInvalidGroupBy        = errInvalidGroupBy
+	ErrUnknownDriver         = errUnknownDriver
+	ErrInvalidInterval       = errInvalidInterval
 	ErrChequebookBalance     = errChequebookBalance
 	ErrInvalidAddress        = errInvalidAddress
 	ErrUnknownTransaction    = errUnknownTransaction
@@ -71,6 +71,52 @@ func (s *Service) peerDisconnectHandler(w http.ResponseWriter, r *http.Request)
 	jsonhttp.OK(w, nil)
 }
 
+type protocolBandwidthResponse struct {
+	Protocol string `json:"protocol"`
+	BytesIn  uint64 `json:"bytesIn"`
+	BytesOut uint64 `json:"bytesOut"`
+}
+
+type peerResponse struct {
+	Address   swarm.Address               `json:"address"`
+	Bandwidth []protocolBandwidthResponse `json:"bandwidth"`
+}
+
+func (s *Service) peerHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["address"]
+	swarmAddr, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.logger.Debugf("debug api: parse peer address %s: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+
+	bandwidth, err := s.p2p.PeerBandwidth(swarmAddr)
+	if err != nil {
+		s.logger.Debugf("debug api: peer bandwidth %s: %v", addr, err)
+		if errors.Is(err, p2p.ErrPeerNotFound) {
+			jsonhttp.NotFound(w, "peer not found")
+			return
+		}
+		s.logger.Errorf("unable to get peer bandwidth %s", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	resp := peerResponse{
+		Address:   swarmAddr,
+		Bandwidth: make([]protocolBandwidthResponse, 0, len(bandwidth)),
+	}
+	for _, b := range bandwidth {
+		resp.Bandwidth = append(resp.Bandwidth, protocolBandwidthResponse{
+			Protocol: b.Protocol,
+			BytesIn:  b.BytesIn,
+			BytesOut: b.BytesOut,
+		})
+	}
+	jsonhttp.OK(w, resp)
+}
+
 // Peer holds information about a Peer.
 type Peer struct {
 	Address  swarm.Address `json:"address"`
@@ -87,19 +133,6 @@ func (s *Service) peersHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (s *Service) blocklistedPeersHandler(w http.ResponseWriter, r *http.Request) {
-	peers, err := s.p2p.BlocklistedPeers()
-	if err != nil {
-		s.logger.Debugf("debug api: blocklisted peers: %v", err)
-		jsonhttp.InternalServerError(w, nil)
-		return
-	}
-
-	jsonhttp.OK(w, peersResponse{
-		Peers: mapPeers(peers),
-	})
-}
-
 func mapPeers(peers []p2p.Peer) (out []Peer) {
 	for _, peer := range peers {
 		out = append(out, Peer{
@@ -189,34 +189,63 @@ func TestPeer(t *testing.T) {
 	})
 }
 
-func TestBlocklistedPeers(t *testing.T) {
-	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+func TestPeerBandwidth(t *testing.T) {
+	address := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	unknownAddress := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59e")
+	errorAddress := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59a")
+	testErr := errors.New("test error")
+
 	testServer := newTestServer(t, testServerOptions{
-		P2P: mock.New(mock.WithBlocklistedPeersFunc(func() ([]p2p.Peer, error) {
-			return []p2p.Peer{{Address: overlay}}, nil
+		P2P: mock.New(mock.WithPeerBandwidthFunc(func(addr swarm.Address) ([]p2p.ProtocolBandwidth, error) {
+			if addr.Equal(address) {
+				return []p2p.ProtocolBandwidth{
+					{Protocol: "pullsync", BytesIn: 4096, BytesOut: 128},
+					{Protocol: "retrieval", BytesIn: 10, BytesOut: 20},
+				}, nil
+			}
+			if addr.Equal(errorAddress) {
+				return nil, testErr
+			}
+			return nil, p2p.ErrPeerNotFound
 		})),
 	})
 
-	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/blocklist", http.StatusOK,
-		jsonhttptest.WithExpectedJSONResponse(debugapi.PeersResponse{
-			Peers: []debugapi.Peer{{Address: overlay}},
-		}),
-	)
-}
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/peers/"+address.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(debugapi.PeerResponse{
+				Address: address,
+				Bandwidth: []debugapi.ProtocolBandwidthResponse{
+					{Protocol: "pullsync", BytesIn: 4096, BytesOut: 128},
+					{Protocol: "retrieval", BytesIn: 10, BytesOut: 20},
+				},
+			}),
+		)
+	})
 
-func TestBlocklistedPeersErr(t *testing.T) {
-	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
-	testServer := newTestServer(t, testServerOptions{
-		P2P: mock.New(mock.WithBlocklistedPeersFunc(func() ([]p2p.Peer, error) {
-			return []p2p.Peer{{Address: overlay}}, errors.New("some error")
-		})),
+	t.Run("unknown", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/peers/"+unknownAddress.String(), http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: "peer not found",
+			}),
+		)
+	})
+
+	t.Run("invalid peer address", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/peers/invalid-address", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid peer address",
+			}),
+		)
 	})
 
-	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/blocklist", http.StatusInternalServerError,
-		jsonhttptest.WithExpectedJSONResponse(
-			jsonhttp.StatusResponse{
+	t.Run("error", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/peers/"+errorAddress.String(), http.StatusInternalServerError,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
 				Code:    http.StatusInternalServerError,
-				Message: http.StatusText(http.StatusInternalServerError),
+				Message: testErr.Error(),
 			}),
-	)
+		)
+	})
 }
@@ -0,0 +1,152 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/holisticode/bee/pkg/bigint"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/settlement/history"
+	"github.com/holisticode/bee/pkg/settlement/swap/priceoracle"
+)
+
+var errCantPriceHistory = "can not get price oracle history"
+
+type priceOracleRateResponse struct {
+	Timestamp    time.Time      `json:"timestamp"`
+	ExchangeRate *bigint.BigInt `json:"exchangeRate"`
+	Deduction    *bigint.BigInt `json:"deduction"`
+}
+
+type priceOracleHistoryResponse struct {
+	From  time.Time                 `json:"from"`
+	To    time.Time                 `json:"to"`
+	Rates []priceOracleRateResponse `json:"rates"`
+}
+
+// priceOracleHistoryHandler lists the exchange rates and deductions that
+// applied in an interval.
+func (s *Service) priceOracleHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseInterval(r)
+	if err != nil {
+		s.logger.Debugf("debug api: price oracle history: parse interval: %v", err)
+		jsonhttp.BadRequest(w, errInvalidInterval)
+		return
+	}
+
+	rates, err := s.priceOracle.History(from, to)
+	if err != nil {
+		s.logger.Debugf("debug api: price oracle history: %v", err)
+		s.logger.Error("debug api: can not get price oracle history")
+		jsonhttp.InternalServerError(w, errCantPriceHistory)
+		return
+	}
+
+	resp := priceOracleHistoryResponse{
+		From:  from,
+		To:    to,
+		Rates: make([]priceOracleRateResponse, 0, len(rates)),
+	}
+	for _, rate := range rates {
+		resp.Rates = append(resp.Rates, priceOracleRateResponse{
+			Timestamp:    rate.Timestamp,
+			ExchangeRate: bigint.Wrap(rate.ExchangeRate),
+			Deduction:    bigint.Wrap(rate.Deduction),
+		})
+	}
+
+	jsonhttp.OK(w, resp)
+}
+
+// rateHistory returns the exchange rates that applied in the interval to
+// value amounts with. It is empty if the node does not track the rates.
+func (s *Service) rateHistory(from, to time.Time) []priceoracle.Rate {
+	if s.priceOracle == nil {
+		return nil
+	}
+	rates, err := s.priceOracle.History(from, to)
+	if err != nil {
+		s.logger.Debugf("debug api: price oracle history: %v", err)
+		s.logger.Warning("debug api: can not get price oracle history, amounts are not valued")
+		return nil
+	}
+	return rates
+}
+
+// valuation is an amount both in accounting units and in BZZ at the exchange
+// rate that applied at the time. The BZZ amount of a cheque includes the
+// deduction, if any, which does not settle accounting units.
+type valuation struct {
+	exchangeRate *big.Int
+	units        *big.Int
+	bzz          *big.Int
+	deduction    *big.Int
+}
+
+// valueUnits values an amount in accounting units at time t. It returns false
+// if no exchange rate is known for that time.
+func valueUnits(rates []priceoracle.Rate, t time.Time, units *big.Int) (valuation, bool) {
+	rate, ok := priceoracle.RateAt(rates, t)
+	if !ok || rate.ExchangeRate.Sign() <= 0 {
+		return valuation{}, false
+	}
+	return valuation{
+		exchangeRate: rate.ExchangeRate,
+		units:        units,
+		bzz:          new(big.Int).Mul(units, rate.ExchangeRate),
+		deduction:    big.NewInt(0),
+	}, true
+}
+
+// valueBZZ values an amount in BZZ at time t. If the accounting units settled
+// by the amount are known, the remainder of the amount is the deduction. It
+// returns false if no exchange rate is known for that time.
+func valueBZZ(rates []priceoracle.Rate, t time.Time, bzz, units *big.Int) (valuation, bool) {
+	rate, ok := priceoracle.RateAt(rates, t)
+	if !ok || rate.ExchangeRate.Sign() <= 0 {
+		return valuation{}, false
+	}
+	deduction := big.NewInt(0)
+	if units != nil {
+		deduction.Sub(bzz, new(big.Int).Mul(units, rate.ExchangeRate))
+		if deduction.Sign() < 0 {
+			deduction.SetInt64(0)
+		}
+	} else {
+		units = new(big.Int).Quo(bzz, rate.ExchangeRate)
+	}
+	return valuation{
+		exchangeRate: rate.ExchangeRate,
+		units:        units,
+		bzz:          bzz,
+		deduction:    deduction,
+	}, true
+}
+
+// valueEvent values a settlement event at the exchange rate that applied at
+// its time. It returns false if no exchange rate is known for that time.
+func valueEvent(rates []priceoracle.Rate, e history.Event) (valuation, bool) {
+	switch e.Type {
+	case history.RefreshmentSent, history.RefreshmentReceived:
+		return valueUnits(rates, e.Timestamp, e.Amount)
+	default:
+		return valueBZZ(rates, e.Timestamp, e.Amount, e.Units)
+	}
+}
+
+// eventValuer returns the history.Valuer of the settlement events with the
+// rates.
+func eventValuer(rates []priceoracle.Rate) history.Valuer {
+	return func(e history.Event) (*big.Int, *big.Int, bool) {
+		v, ok := valueEvent(rates, e)
+		if !ok {
+			return nil, nil, false
+		}
+		return v.units, v.bzz, true
+	}
+}
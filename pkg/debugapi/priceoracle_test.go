@@ -0,0 +1,129 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/holisticode/bee/pkg/accounting/mock"
+	"github.com/holisticode/bee/pkg/bigint"
+	"github.com/holisticode/bee/pkg/debugapi"
+	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/holisticode/bee/pkg/settlement/history"
+	priceoraclemock "github.com/holisticode/bee/pkg/settlement/swap/priceoracle/mock"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+func TestPriceOracleHistory(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{
+		PriceOracle: priceoraclemock.New(big.NewInt(5), big.NewInt(20)),
+	})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/priceoracle/history?from=0&to=1000", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.PriceOracleHistoryResponse{
+			From: time.Unix(0, 0).UTC(),
+			To:   time.Unix(1000, 0).UTC(),
+			Rates: []debugapi.PriceOracleRateResponse{
+				{
+					ExchangeRate: bigint.Wrap(big.NewInt(5)),
+					Deduction:    bigint.Wrap(big.NewInt(20)),
+				},
+			},
+		}),
+	)
+}
+
+func TestSettlementHistoryValuation(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{
+		SettlementHistory: newTestSettlementHistory(t),
+		PriceOracle:       priceoraclemock.New(big.NewInt(5), big.NewInt(20)),
+	})
+
+	var resp debugapi.SettlementHistoryResponse
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/settlements/history", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&resp),
+	)
+
+	// the amounts valued in the other denomination and the deductions by
+	// event type
+	want := map[history.EventType][3]int64{
+		history.RefreshmentReceived: {10, 50, 0},
+		history.ChequeReceived:      {16, 100, 20},
+		history.ChequeSent:          {8, 40, 0},
+		history.Cashout:             {20, 100, 0},
+	}
+	if len(resp.Events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(resp.Events), len(want))
+	}
+	for _, e := range resp.Events {
+		if e.ExchangeRate == nil || e.ExchangeRate.Cmp(big.NewInt(5)) != 0 {
+			t.Fatalf("got exchange rate %v for event %+v, want 5", e.ExchangeRate, e)
+		}
+		w := want[history.EventType(e.Type)]
+		if e.AmountAccountingUnits.Cmp(big.NewInt(w[0])) != 0 || e.AmountBZZ.Cmp(big.NewInt(w[1])) != 0 {
+			t.Fatalf("got valuation %v units %v BZZ for event %+v, want %d units %d BZZ", e.AmountAccountingUnits, e.AmountBZZ, e, w[0], w[1])
+		}
+		var deduction int64
+		if e.Deduction != nil {
+			deduction = e.Deduction.Int64()
+		}
+		if deduction != w[2] {
+			t.Fatalf("got deduction %d for event %+v, want %d", deduction, e, w[2])
+		}
+	}
+}
+
+func TestSettlementReportValuation(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{
+		SettlementHistory: newTestSettlementHistory(t),
+		PriceOracle:       priceoraclemock.New(big.NewInt(5), big.NewInt(20)),
+	})
+
+	var resp debugapi.SettlementReportResponse
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/settlements/report", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&resp),
+	)
+
+	total := resp.Total
+	for _, v := range []struct {
+		name string
+		got  *bigint.BigInt
+		want int64
+	}{
+		{"refreshments sent BZZ", total.RefreshmentsSentBZZ, 0},
+		{"refreshments received BZZ", total.RefreshmentsReceivedBZZ, 50},
+		{"cheques sent units", total.ChequesSentUnits, 8},
+		{"cheques received units", total.ChequesReceivedUnits, 16},
+		{"cashed units", total.CashedUnits, 20},
+	} {
+		if v.got == nil || v.got.Cmp(big.NewInt(v.want)) != 0 {
+			t.Fatalf("got %s %v, want %d", v.name, v.got, v.want)
+		}
+	}
+	if total.Unvalued != 0 {
+		t.Fatalf("got %d unvalued events, want 0", total.Unvalued)
+	}
+}
+
+func TestBalancesValuation(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{
+		AccountingOpts: []mock.Option{mock.WithCompensatedBalanceFunc(func(swarm.Address) (*big.Int, error) {
+			return big.NewInt(-30), nil
+		})},
+		PriceOracle: priceoraclemock.New(big.NewInt(5), big.NewInt(20)),
+	})
+
+	peer := "ff"
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/balances/"+peer, http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.BalanceResponse{
+			Peer:       peer,
+			Balance:    bigint.Wrap(big.NewInt(-30)),
+			BalanceBZZ: bigint.Wrap(big.NewInt(-150)),
+		}),
+	)
+}
@@ -122,8 +122,13 @@ func (s *Service) newRouter() *mux.Router {
 	handle("/blocklist", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.blocklistedPeersHandler),
 	})
+	handle("/blocklist/{address:.+}", jsonhttp.MethodHandler{
+		"POST":   http.HandlerFunc(s.blocklistHandler),
+		"DELETE": http.HandlerFunc(s.unblocklistHandler),
+	})
 
 	handle("/peers/{address}", jsonhttp.MethodHandler{
+		"GET":    http.HandlerFunc(s.peerHandler),
 		"DELETE": http.HandlerFunc(s.peerDisconnectHandler),
 	})
 	handle("/chunks/{address}", jsonhttp.MethodHandler{
@@ -133,6 +138,9 @@ func (s *Service) newRouter() *mux.Router {
 	handle("/topology", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.topologyHandler),
 	})
+	handle("/topology/graph", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.topologyGraphHandler),
+	})
 	handle("/welcome-message", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.getWelcomeMessageHandler),
 		"POST": web.ChainHandlers(
@@ -149,6 +157,14 @@ func (s *Service) newRouter() *mux.Router {
 		"GET": http.HandlerFunc(s.compensatedPeerBalanceHandler),
 	})
 
+	handle("/balances/{peer}/history", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.balanceHistoryHandler),
+	})
+
+	handle("/budget", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.budgetHandler),
+	})
+
 	handle("/consumed", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.balancesHandler),
 	})
@@ -161,6 +177,33 @@ func (s *Service) newRouter() *mux.Router {
 		"GET": http.HandlerFunc(s.settlementsHandlerPseudosettle),
 	})
 
+	if s.settlementHistory != nil {
+		handle("/settlements/history", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.settlementHistoryHandler),
+		})
+		handle("/settlements/report", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.settlementReportHandler),
+		})
+	}
+
+	if s.priceOracle != nil {
+		handle("/priceoracle/history", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.priceOracleHistoryHandler),
+		})
+	}
+
+	if s.settlementDrivers != nil {
+		handle("/settlementdrivers", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.settlementDriversHandler),
+		})
+		handle("/settlementdrivers/{peer}", jsonhttp.MethodHandler{
+			"DELETE": http.HandlerFunc(s.settlementDriverClearHandler),
+		})
+		handle("/settlementdrivers/{peer}/{driver}", jsonhttp.MethodHandler{
+			"PUT": http.HandlerFunc(s.settlementDriverSelectHandler),
+		})
+	}
+
 	if s.chequebookEnabled {
 		handle("/settlements", jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.settlementsHandler),
@@ -181,6 +224,10 @@ func (s *Service) newRouter() *mux.Router {
 			"POST": http.HandlerFunc(s.chequebookDepositHandler),
 		})
 
+		handle("/chequebook/refills", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookRefillsHandler),
+		})
+
 		handle("/chequebook/withdraw", jsonhttp.MethodHandler{
 			"POST": http.HandlerFunc(s.chequebookWithdrawHandler),
 		})
@@ -199,6 +246,15 @@ func (s *Service) newRouter() *mux.Router {
 		})
 	}
 
+	if s.events != nil {
+		handle("/events", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.eventsHandler),
+		})
+		handle("/events/peers", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.peerEventsHandler),
+		})
+	}
+
 	handle("/tags/{id}", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.getTagHandler),
 	})
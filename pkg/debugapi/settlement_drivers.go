@@ -0,0 +1,112 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/settlement/driver"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+var (
+	errCantSettlementDrivers = "can not get settlement drivers"
+	errCantSelectDriver      = "can not select settlement driver"
+	errUnknownDriver         = "unknown settlement driver"
+)
+
+type settlementDriversPeerResponse struct {
+	Peer      string   `json:"peer"`
+	Supported []string `json:"supported"`
+	Selected  string   `json:"selected,omitempty"`
+	Driver    string   `json:"driver,omitempty"`
+}
+
+type settlementDriversResponse struct {
+	Drivers []string                        `json:"drivers"`
+	Peers   []settlementDriversPeerResponse `json:"peers"`
+}
+
+// settlementDriversHandler lists the enabled settlement drivers and the
+// drivers negotiated with the connected peers.
+func (s *Service) settlementDriversHandler(w http.ResponseWriter, r *http.Request) {
+	peers, err := s.settlementDrivers.Peers()
+	if err != nil {
+		s.logger.Debugf("debug api: settlement drivers: %v", err)
+		s.logger.Error("debug api: can not get settlement drivers")
+		jsonhttp.InternalServerError(w, errCantSettlementDrivers)
+		return
+	}
+
+	resp := settlementDriversResponse{
+		Drivers: s.settlementDrivers.Names(),
+		Peers:   make([]settlementDriversPeerResponse, 0, len(peers)),
+	}
+	for _, p := range peers {
+		supported := p.Supported
+		if supported == nil {
+			supported = []string{}
+		}
+		resp.Peers = append(resp.Peers, settlementDriversPeerResponse{
+			Peer:      p.Peer.String(),
+			Supported: supported,
+			Selected:  p.Selected,
+			Driver:    p.Driver,
+		})
+	}
+
+	jsonhttp.OK(w, resp)
+}
+
+// settlementDriverSelectHandler selects the settlement driver payments to a
+// peer are made with.
+func (s *Service) settlementDriverSelectHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["peer"]
+	peer, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.logger.Debugf("debug api: settlement driver select: invalid peer address %s: %v", addr, err)
+		s.logger.Errorf("debug api: settlement driver select: invalid peer address %s", addr)
+		jsonhttp.NotFound(w, errInvalidAddress)
+		return
+	}
+
+	if err := s.settlementDrivers.Select(peer, mux.Vars(r)["driver"]); err != nil {
+		if errors.Is(err, driver.ErrUnknownDriver) {
+			jsonhttp.BadRequest(w, errUnknownDriver)
+			return
+		}
+		s.logger.Debugf("debug api: settlement driver select: peer %s: %v", peer, err)
+		s.logger.Errorf("debug api: settlement driver select: can not select driver for peer %s", peer)
+		jsonhttp.InternalServerError(w, errCantSelectDriver)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+// settlementDriverClearHandler clears the settlement driver selected for a
+// peer so that the negotiated driver is used again.
+func (s *Service) settlementDriverClearHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["peer"]
+	peer, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.logger.Debugf("debug api: settlement driver clear: invalid peer address %s: %v", addr, err)
+		s.logger.Errorf("debug api: settlement driver clear: invalid peer address %s", addr)
+		jsonhttp.NotFound(w, errInvalidAddress)
+		return
+	}
+
+	if err := s.settlementDrivers.Select(peer, ""); err != nil {
+		s.logger.Debugf("debug api: settlement driver clear: peer %s: %v", peer, err)
+		s.logger.Errorf("debug api: settlement driver clear: can not clear driver for peer %s", peer)
+		jsonhttp.InternalServerError(w, errCantSelectDriver)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
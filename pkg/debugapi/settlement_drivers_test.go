@@ -0,0 +1,98 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/holisticode/bee/pkg/debugapi"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/p2p/streamtest"
+	"github.com/holisticode/bee/pkg/settlement/driver"
+	statestore "github.com/holisticode/bee/pkg/statestore/mock"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+type settlementDriverMock struct {
+	name string
+}
+
+func (d settlementDriverMock) Name() string                                                 { return d.name }
+func (d settlementDriverMock) Pay(ctx context.Context, peer swarm.Address, amount *big.Int) {}
+func (d settlementDriverMock) TotalSent(peer swarm.Address) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (d settlementDriverMock) TotalReceived(peer swarm.Address) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (d settlementDriverMock) SettlementsSent() (map[string]*big.Int, error)     { return nil, nil }
+func (d settlementDriverMock) SettlementsReceived() (map[string]*big.Int, error) { return nil, nil }
+
+func TestSettlementDrivers(t *testing.T) {
+	store := statestore.NewStateStore()
+	t.Cleanup(func() { _ = store.Close() })
+
+	drivers := driver.New(streamtest.New(), store, logging.New(io.Discard, 0), []string{"swap"})
+	for _, name := range []string{"swap", "channel"} {
+		if err := drivers.Add(settlementDriverMock{name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testServer := newTestServer(t, testServerOptions{
+		SettlementDrivers: drivers,
+	})
+
+	peer := "bb00000000000000000000000000000000000000000000000000000000000000"
+
+	t.Run("list", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/settlementdrivers", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(debugapi.SettlementDriversResponse{
+				Drivers: []string{"swap", "channel"},
+				Peers:   []debugapi.SettlementDriversPeerResponse{},
+			}),
+		)
+	})
+
+	t.Run("select", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPut, "/settlementdrivers/"+peer+"/channel", http.StatusOK)
+
+		var selected string
+		if err := store.Get("settlement_driver_selection_"+peer, &selected); err != nil {
+			t.Fatal(err)
+		}
+		if selected != "channel" {
+			t.Fatalf("got selected driver %q, want %q", selected, "channel")
+		}
+	})
+
+	t.Run("select unknown", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPut, "/settlementdrivers/"+peer+"/unknown", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: debugapi.ErrUnknownDriver,
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodDelete, "/settlementdrivers/"+peer, http.StatusOK)
+
+		var selected string
+		if err := store.Get("settlement_driver_selection_"+peer, &selected); err == nil {
+			t.Fatalf("got selected driver %q after clearing", selected)
+		}
+	})
+
+	t.Run("invalid peer", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodDelete, "/settlementdrivers/invalid", http.StatusNotFound)
+	})
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/bigint"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/settlement/history"
+)
+
+var (
+	errCantSettlementHistory = "can not get settlement history"
+	errInvalidInterval       = "invalid interval"
+	errInvalidGroupBy        = "invalid group by"
+)
+
+type settlementEventResponse struct {
+	Timestamp       time.Time      `json:"timestamp"`
+	Type            string         `json:"type"`
+	Peer            string         `json:"peer,omitempty"`
+	Chequebook      string         `json:"chequebook,omitempty"`
+	Amount          *bigint.BigInt `json:"amount"`
+	TransactionHash string         `json:"transactionHash,omitempty"`
+	// the amount valued at the exchange rate that applied at the time
+	ExchangeRate          *bigint.BigInt `json:"exchangeRate,omitempty"`
+	AmountAccountingUnits *bigint.BigInt `json:"amountAccountingUnits,omitempty"`
+	AmountBZZ             *bigint.BigInt `json:"amountBZZ,omitempty"`
+	Deduction             *bigint.BigInt `json:"deduction,omitempty"`
+}
+
+type settlementHistoryResponse struct {
+	From   time.Time                 `json:"from"`
+	To     time.Time                 `json:"to"`
+	Events []settlementEventResponse `json:"events"`
+}
+
+type settlementReportEntryResponse struct {
+	Group                string         `json:"group,omitempty"`
+	RefreshmentsSent     *bigint.BigInt `json:"refreshmentsSent"`
+	RefreshmentsReceived *bigint.BigInt `json:"refreshmentsReceived"`
+	ChequesSent          *bigint.BigInt `json:"chequesSent"`
+	ChequesReceived      *bigint.BigInt `json:"chequesReceived"`
+	Cashed               *bigint.BigInt `json:"cashed"`
+	Events               int            `json:"events"`
+	// the amounts in the other denomination, only set if the node tracks
+	// the exchange rates
+	RefreshmentsSentBZZ     *bigint.BigInt `json:"refreshmentsSentBZZ,omitempty"`
+	RefreshmentsReceivedBZZ *bigint.BigInt `json:"refreshmentsReceivedBZZ,omitempty"`
+	ChequesSentUnits        *bigint.BigInt `json:"chequesSentAccountingUnits,omitempty"`
+	ChequesReceivedUnits    *bigint.BigInt `json:"chequesReceivedAccountingUnits,omitempty"`
+	CashedUnits             *bigint.BigInt `json:"cashedAccountingUnits,omitempty"`
+	Unvalued                int            `json:"unvalued,omitempty"`
+}
+
+type settlementReportResponse struct {
+	From    time.Time                       `json:"from"`
+	To      time.Time                       `json:"to"`
+	GroupBy string                          `json:"groupBy"`
+	Total   settlementReportEntryResponse   `json:"total"`
+	Entries []settlementReportEntryResponse `json:"entries"`
+}
+
+// parseInterval parses the from and to query parameters given in unix
+// seconds. The interval defaults to everything recorded up to now.
+func parseInterval(r *http.Request) (from, to time.Time, err error) {
+	from, to = time.Unix(0, 0).UTC(), time.Now().UTC()
+	if v := r.URL.Query().Get("from"); v != "" {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return from, to, err
+		}
+		from = time.Unix(sec, 0).UTC()
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return from, to, err
+		}
+		to = time.Unix(sec, 0).UTC()
+	}
+	if to.Before(from) {
+		return from, to, errors.New("to before from")
+	}
+	return from, to, nil
+}
+
+// settlementHistoryHandler lists the settlement events recorded in an
+// interval from oldest to newest.
+func (s *Service) settlementHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseInterval(r)
+	if err != nil {
+		s.logger.Debugf("debug api: settlement history: parse interval: %v", err)
+		jsonhttp.BadRequest(w, errInvalidInterval)
+		return
+	}
+
+	events, err := s.settlementHistory.Events(from, to)
+	if err != nil {
+		s.logger.Debugf("debug api: settlement history: %v", err)
+		s.logger.Error("debug api: can not get settlement history")
+		jsonhttp.InternalServerError(w, errCantSettlementHistory)
+		return
+	}
+
+	rates := s.rateHistory(from, to)
+
+	resp := settlementHistoryResponse{
+		From:   from,
+		To:     to,
+		Events: make([]settlementEventResponse, 0, len(events)),
+	}
+	for _, e := range events {
+		event := settlementEventResponse{
+			Timestamp: e.Timestamp,
+			Type:      string(e.Type),
+			Amount:    bigint.Wrap(e.Amount),
+		}
+		if !e.Peer.IsZero() {
+			event.Peer = e.Peer.String()
+		}
+		if e.Chequebook != (common.Address{}) {
+			event.Chequebook = e.Chequebook.String()
+		}
+		if e.TxHash != (common.Hash{}) {
+			event.TransactionHash = e.TxHash.String()
+		}
+		if v, ok := valueEvent(rates, e); ok {
+			event.ExchangeRate = bigint.Wrap(v.exchangeRate)
+			event.AmountAccountingUnits = bigint.Wrap(v.units)
+			event.AmountBZZ = bigint.Wrap(v.bzz)
+			if v.deduction.Sign() > 0 {
+				event.Deduction = bigint.Wrap(v.deduction)
+			}
+		}
+		resp.Events = append(resp.Events, event)
+	}
+
+	jsonhttp.OK(w, resp)
+}
+
+// settlementReportHandler sums the settlement events recorded in an interval
+// per day or per peer.
+func (s *Service) settlementReportHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseInterval(r)
+	if err != nil {
+		s.logger.Debugf("debug api: settlement report: parse interval: %v", err)
+		jsonhttp.BadRequest(w, errInvalidInterval)
+		return
+	}
+
+	groupBy := history.GroupByDay
+	if v := r.URL.Query().Get("groupBy"); v != "" {
+		groupBy = history.GroupBy(v)
+	}
+
+	var value history.Valuer
+	if rates := s.rateHistory(from, to); len(rates) > 0 {
+		value = eventValuer(rates)
+	}
+
+	report, err := s.settlementHistory.Report(from, to, groupBy, value)
+	if err != nil {
+		if errors.Is(err, history.ErrInvalidGroupBy) {
+			jsonhttp.BadRequest(w, errInvalidGroupBy)
+			return
+		}
+		s.logger.Debugf("debug api: settlement report: %v", err)
+		s.logger.Error("debug api: can not get settlement report")
+		jsonhttp.InternalServerError(w, errCantSettlementHistory)
+		return
+	}
+
+	total := history.ReportEntry{
+		RefreshmentsSent:        big.NewInt(0),
+		RefreshmentsReceived:    big.NewInt(0),
+		ChequesSent:             big.NewInt(0),
+		ChequesReceived:         big.NewInt(0),
+		Cashed:                  big.NewInt(0),
+		RefreshmentsSentBZZ:     big.NewInt(0),
+		RefreshmentsReceivedBZZ: big.NewInt(0),
+		ChequesSentUnits:        big.NewInt(0),
+		ChequesReceivedUnits:    big.NewInt(0),
+		CashedUnits:             big.NewInt(0),
+	}
+	entries := make([]settlementReportEntryResponse, 0, len(report))
+	for _, e := range report {
+		total.RefreshmentsSent.Add(total.RefreshmentsSent, e.RefreshmentsSent)
+		total.RefreshmentsReceived.Add(total.RefreshmentsReceived, e.RefreshmentsReceived)
+		total.ChequesSent.Add(total.ChequesSent, e.ChequesSent)
+		total.ChequesReceived.Add(total.ChequesReceived, e.ChequesReceived)
+		total.Cashed.Add(total.Cashed, e.Cashed)
+		total.RefreshmentsSentBZZ.Add(total.RefreshmentsSentBZZ, e.RefreshmentsSentBZZ)
+		total.RefreshmentsReceivedBZZ.Add(total.RefreshmentsReceivedBZZ, e.RefreshmentsReceivedBZZ)
+		total.ChequesSentUnits.Add(total.ChequesSentUnits, e.ChequesSentUnits)
+		total.ChequesReceivedUnits.Add(total.ChequesReceivedUnits, e.ChequesReceivedUnits)
+		total.CashedUnits.Add(total.CashedUnits, e.CashedUnits)
+		total.Events += e.Events
+		total.Unvalued += e.Unvalued
+		entries = append(entries, newSettlementReportEntryResponse(e, value != nil))
+	}
+
+	jsonhttp.OK(w, settlementReportResponse{
+		From:    from,
+		To:      to,
+		GroupBy: string(groupBy),
+		Total:   newSettlementReportEntryResponse(total, value != nil),
+		Entries: entries,
+	})
+}
+
+func newSettlementReportEntryResponse(e history.ReportEntry, valued bool) settlementReportEntryResponse {
+	resp := settlementReportEntryResponse{
+		Group:                e.Group,
+		RefreshmentsSent:     bigint.Wrap(e.RefreshmentsSent),
+		RefreshmentsReceived: bigint.Wrap(e.RefreshmentsReceived),
+		ChequesSent:          bigint.Wrap(e.ChequesSent),
+		ChequesReceived:      bigint.Wrap(e.ChequesReceived),
+		Cashed:               bigint.Wrap(e.Cashed),
+		Events:               e.Events,
+	}
+	if valued {
+		resp.RefreshmentsSentBZZ = bigint.Wrap(e.RefreshmentsSentBZZ)
+		resp.RefreshmentsReceivedBZZ = bigint.Wrap(e.RefreshmentsReceivedBZZ)
+		resp.ChequesSentUnits = bigint.Wrap(e.ChequesSentUnits)
+		resp.ChequesReceivedUnits = bigint.Wrap(e.ChequesReceivedUnits)
+		resp.CashedUnits = bigint.Wrap(e.CashedUnits)
+		resp.Unvalued = e.Unvalued
+	}
+	return resp
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/bigint"
+	"github.com/holisticode/bee/pkg/debugapi"
+	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/holisticode/bee/pkg/settlement/history"
+	statestore "github.com/holisticode/bee/pkg/statestore/mock"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+func newTestSettlementHistory(t *testing.T) *history.History {
+	t.Helper()
+
+	store := statestore.NewStateStore()
+	t.Cleanup(func() { _ = store.Close() })
+
+	h := history.New(store, 0)
+	record := func(typ history.EventType, peer swarm.Address, chequebook common.Address, amount int64, txHash common.Hash) {
+		t.Helper()
+		if err := h.Record(typ, peer, chequebook, big.NewInt(amount), txHash); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recordCheque := func(typ history.EventType, peer swarm.Address, chequebook common.Address, amount, units int64) {
+		t.Helper()
+		if err := h.RecordCheque(typ, peer, chequebook, big.NewInt(amount), big.NewInt(units)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record(history.RefreshmentReceived, swarm.MustParseHexAddress("01"), common.Address{}, 10, common.Hash{})
+	// the first cheque of the chequebook includes a deduction of 20 at an
+	// exchange rate of 5
+	recordCheque(history.ChequeReceived, swarm.MustParseHexAddress("01"), common.HexToAddress("aa"), 100, 16)
+	recordCheque(history.ChequeSent, swarm.MustParseHexAddress("02"), common.HexToAddress("bb"), 40, 8)
+	record(history.Cashout, swarm.MustParseHexAddress("01"), common.HexToAddress("aa"), 100, common.HexToHash("ff"))
+	return h
+}
+
+func TestSettlementHistory(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{
+		SettlementHistory: newTestSettlementHistory(t),
+	})
+
+	var resp debugapi.SettlementHistoryResponse
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/settlements/history", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&resp),
+	)
+
+	want := []debugapi.SettlementEventResponse{
+		{
+			Type:   string(history.RefreshmentReceived),
+			Peer:   "01",
+			Amount: bigint.Wrap(big.NewInt(10)),
+		},
+		{
+			Type:       string(history.ChequeReceived),
+			Peer:       "01",
+			Chequebook: common.HexToAddress("aa").String(),
+			Amount:     bigint.Wrap(big.NewInt(100)),
+		},
+		{
+			Type:       string(history.ChequeSent),
+			Peer:       "02",
+			Chequebook: common.HexToAddress("bb").String(),
+			Amount:     bigint.Wrap(big.NewInt(40)),
+		},
+		{
+			Type:            string(history.Cashout),
+			Peer:            "01",
+			Chequebook:      common.HexToAddress("aa").String(),
+			Amount:          bigint.Wrap(big.NewInt(100)),
+			TransactionHash: common.HexToHash("ff").String(),
+		},
+	}
+	if len(resp.Events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(resp.Events), len(want))
+	}
+	for i, w := range want {
+		got := resp.Events[i]
+		if got.Type != w.Type || got.Peer != w.Peer || got.Chequebook != w.Chequebook || got.TransactionHash != w.TransactionHash || got.Amount.Cmp(w.Amount.Int) != 0 {
+			t.Fatalf("got event %d %+v, want %+v", i, got, w)
+		}
+	}
+
+	// nothing was recorded before the interval
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/settlements/history?from=0&to=1000", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.SettlementHistoryResponse{
+			From:   time.Unix(0, 0).UTC(),
+			To:     time.Unix(1000, 0).UTC(),
+			Events: []debugapi.SettlementEventResponse{},
+		}),
+	)
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/settlements/history?from=1000&to=0", http.StatusBadRequest,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: debugapi.ErrInvalidInterval,
+			Code:    http.StatusBadRequest,
+		}),
+	)
+}
+
+func TestSettlementReport(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{
+		SettlementHistory: newTestSettlementHistory(t),
+	})
+
+	from := time.Now().Add(-time.Hour).Unix()
+	to := time.Now().Add(time.Hour).Unix()
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/settlements/report?groupBy=peer&from="+strconv.FormatInt(from, 10)+"&to="+strconv.FormatInt(to, 10), http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.SettlementReportResponse{
+			From:    time.Unix(from, 0).UTC(),
+			To:      time.Unix(to, 0).UTC(),
+			GroupBy: "peer",
+			Total: debugapi.SettlementReportEntryResponse{
+				RefreshmentsSent:     bigint.Wrap(big.NewInt(0)),
+				RefreshmentsReceived: bigint.Wrap(big.NewInt(10)),
+				ChequesSent:          bigint.Wrap(big.NewInt(40)),
+				ChequesReceived:      bigint.Wrap(big.NewInt(100)),
+				Cashed:               bigint.Wrap(big.NewInt(100)),
+				Events:               4,
+			},
+			Entries: []debugapi.SettlementReportEntryResponse{
+				{
+					Group:                "01",
+					RefreshmentsSent:     bigint.Wrap(big.NewInt(0)),
+					RefreshmentsReceived: bigint.Wrap(big.NewInt(10)),
+					ChequesSent:          bigint.Wrap(big.NewInt(0)),
+					ChequesReceived:      bigint.Wrap(big.NewInt(100)),
+					Cashed:               bigint.Wrap(big.NewInt(100)),
+					Events:               3,
+				},
+				{
+					Group:                "02",
+					RefreshmentsSent:     bigint.Wrap(big.NewInt(0)),
+					RefreshmentsReceived: bigint.Wrap(big.NewInt(0)),
+					ChequesSent:          bigint.Wrap(big.NewInt(40)),
+					ChequesReceived:      bigint.Wrap(big.NewInt(0)),
+					Cashed:               bigint.Wrap(big.NewInt(0)),
+					Events:               1,
+				},
+			},
+		}),
+	)
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/settlements/report?groupBy=week", http.StatusBadRequest,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: debugapi.ErrInvalidGroupBy,
+			Code:    http.StatusBadRequest,
+		}),
+	)
+}
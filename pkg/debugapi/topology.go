@@ -11,6 +11,7 @@ import (
 	"net/http"
 
 	"github.com/holisticode/bee/pkg/jsonhttp"
+	"github.com/holisticode/bee/pkg/topology/graph"
 )
 
 func (s *Service) topologyHandler(w http.ResponseWriter, r *http.Request) {
@@ -27,3 +28,39 @@ func (s *Service) topologyHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", jsonhttp.DefaultContentTypeHeader)
 	_, _ = io.Copy(w, bytes.NewBuffer(b))
 }
+
+const errTopologyGraphFormat = "invalid graph format"
+
+var topologyGraphContentTypes = map[graph.Format]string{
+	graph.FormatJSON:    jsonhttp.DefaultContentTypeHeader,
+	graph.FormatDOT:     "text/vnd.graphviz; charset=utf-8",
+	graph.FormatGraphML: "application/xml; charset=utf-8",
+}
+
+// topologyGraphHandler exports the topology as a graph of the node and its
+// peers in the format given by the format query parameter, json by default.
+func (s *Service) topologyGraphHandler(w http.ResponseWriter, r *http.Request) {
+	format := graph.FormatJSON
+	if q := r.URL.Query().Get("format"); q != "" {
+		f, err := graph.ParseFormat(q)
+		if err != nil {
+			s.logger.Debugf("debug api: topology graph: %v", err)
+			jsonhttp.BadRequest(w, errTopologyGraphFormat)
+			return
+		}
+		format = f
+	}
+
+	params := s.topologyDriver.Snapshot()
+	params.LightNodes = s.lightNodes.PeerInfo()
+
+	var buf bytes.Buffer
+	if err := graph.New(params).Write(&buf, format); err != nil {
+		s.logger.Debugf("debug api: topology graph: write %s: %v", format, err)
+		s.logger.Error("debug api: topology graph: cannot write graph")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", topologyGraphContentTypes[format])
+	_, _ = io.Copy(w, &buf)
+}
@@ -5,10 +5,19 @@
 package debugapi_test
 
 import (
+	"context"
 	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/holisticode/bee/pkg/jsonhttp"
 	"github.com/holisticode/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/swarm"
+	"github.com/holisticode/bee/pkg/topology"
+	"github.com/holisticode/bee/pkg/topology/graph"
+	"github.com/holisticode/bee/pkg/topology/lightnode"
+	topologymock "github.com/holisticode/bee/pkg/topology/mock"
 )
 
 func TestTopologyOK(t *testing.T) {
@@ -22,3 +31,66 @@ func TestTopologyOK(t *testing.T) {
 		t.Error("empty response")
 	}
 }
+
+func TestTopologyGraph(t *testing.T) {
+	base := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	peer := swarm.MustParseHexAddress("4a1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	light := swarm.MustParseHexAddress("ca1f9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+
+	params := &topology.KadParams{Base: base.String(), Depth: 2, Reachability: "Public"}
+	params.Bins.Bin0.ConnectedPeers = []*topology.PeerInfo{{Address: peer}}
+
+	lightNodes := lightnode.NewContainer(base)
+	lightNodes.Connected(context.Background(), p2p.Peer{Address: light})
+
+	testServer := newTestServer(t, testServerOptions{
+		Overlay:      base,
+		TopologyOpts: []topologymock.Option{topologymock.WithSnapshot(params)},
+		LightNodes:   lightNodes,
+	})
+
+	withLightNodes := *params
+	withLightNodes.LightNodes = lightNodes.PeerInfo()
+	want := graph.New(&withLightNodes)
+	if len(want.Nodes) != 3 || !want.Nodes[2].Light {
+		t.Fatalf("light node is missing from the graph %+v", want)
+	}
+
+	t.Run("json", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/topology/graph", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(want),
+		)
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		var body []byte
+		header := jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/topology/graph?format=dot", http.StatusOK,
+			jsonhttptest.WithPutResponseBody(&body),
+		)
+		if ct := header.Get("Content-Type"); !strings.HasPrefix(ct, "text/vnd.graphviz") {
+			t.Fatalf("got content type %s, want text/vnd.graphviz", ct)
+		}
+		if want := `"` + base.String() + `" -> "` + peer.String() + `"`; !strings.Contains(string(body), want) {
+			t.Fatalf("dot graph %q does not contain the edge %q", body, want)
+		}
+	})
+
+	t.Run("graphml", func(t *testing.T) {
+		var body []byte
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/topology/graph?format=graphml", http.StatusOK,
+			jsonhttptest.WithPutResponseBody(&body),
+		)
+		if !strings.Contains(string(body), "<graphml") {
+			t.Fatalf("got %q, want a graphml document", body)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/topology/graph?format=svg", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid graph format",
+			}),
+		)
+	})
+}
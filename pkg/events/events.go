@@ -0,0 +1,360 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package events provides typed notifications about node conditions which
+// need the attention of an operator, like expiring postage batches or low
+// chequebook and wallet balances, and about the lifecycle of the peers in
+// the topology.
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/bigint"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/swarm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// subscriptionBuffer is the number of events buffered for a subscriber
+// before new events are dropped.
+const subscriptionBuffer = 64
+
+// ErrUnknownType is returned when an event type name is not recognised.
+var ErrUnknownType = errors.New("unknown event type")
+
+// Type is the type of an event.
+type Type int
+
+const (
+	// BatchExpiring is emitted when an owned batch is about to expire.
+	BatchExpiring Type = iota + 1
+	// BatchExpired is emitted when an expired batch is evicted from the batch store.
+	BatchExpired
+	// ChequebookBalanceLow is emitted when the available chequebook balance drops below the threshold.
+	ChequebookBalanceLow
+	// WalletBalanceLow is emitted when the native balance of the node wallet drops below the threshold.
+	WalletBalanceLow
+	// PeerConnected is emitted when a connection with a peer is established.
+	PeerConnected
+	// PeerDisconnected is emitted when the connection with a peer is closed.
+	PeerDisconnected
+	// PeerBlocklisted is emitted when a peer is added to the blocklist.
+	PeerBlocklisted
+	// PeerReachabilityChanged is emitted when the reachability of a peer is determined.
+	PeerReachabilityChanged
+	// DepthChanged is emitted when the neighborhood depth changes.
+	DepthChanged
+	// RadiusChanged is emitted when the storage radius changes.
+	RadiusChanged
+)
+
+var typeNames = map[Type]string{
+	BatchExpiring:           "batch-expiring",
+	BatchExpired:            "batch-expired",
+	ChequebookBalanceLow:    "chequebook-balance-low",
+	WalletBalanceLow:        "wallet-balance-low",
+	PeerConnected:           "peer-connected",
+	PeerDisconnected:        "peer-disconnected",
+	PeerBlocklisted:         "peer-blocklisted",
+	PeerReachabilityChanged: "peer-reachability-changed",
+	DepthChanged:            "depth-changed",
+	RadiusChanged:           "radius-changed",
+}
+
+// Types returns all known event types.
+func Types() []Type {
+	return append(AlertTypes(), PeerTypes()...)
+}
+
+// AlertTypes returns the types of the events about node conditions which
+// need the attention of an operator.
+func AlertTypes() []Type {
+	return []Type{BatchExpiring, BatchExpired, ChequebookBalanceLow, WalletBalanceLow}
+}
+
+// PeerTypes returns the types of the events about the lifecycle of the
+// peers in the topology.
+func PeerTypes() []Type {
+	return []Type{PeerConnected, PeerDisconnected, PeerBlocklisted, PeerReachabilityChanged, DepthChanged, RadiusChanged}
+}
+
+// IsPeerType returns whether the type is one of the PeerTypes.
+func IsPeerType(t Type) bool {
+	return t >= PeerConnected && t <= RadiusChanged
+}
+
+// String returns the name of the event type.
+func (t Type) String() string {
+	if n, ok := typeNames[t]; ok {
+		return n
+	}
+	return fmt.Sprintf("unknown(%d)", int(t))
+}
+
+// MarshalJSON encodes the type as its name.
+func (t Type) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON decodes the type from its name.
+func (t *Type) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := ParseType(s)
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+// ParseType returns the event type with the given name.
+func ParseType(s string) (Type, error) {
+	for t, n := range typeNames {
+		if n == s {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownType, s)
+}
+
+// Event is a single notification.
+type Event struct {
+	Type Type        `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// BatchData is the payload of batch events.
+type BatchData struct {
+	BatchID    string         `json:"batchID"`
+	Owner      common.Address `json:"owner"`
+	BlocksLeft uint64         `json:"blocksLeft"`
+}
+
+// BalanceData is the payload of low balance events.
+type BalanceData struct {
+	Address   common.Address `json:"address"`
+	Balance   *bigint.BigInt `json:"balance"`
+	Threshold *bigint.BigInt `json:"threshold"`
+}
+
+// Directions of the connection with a peer.
+const (
+	DirectionInbound  = "inbound"
+	DirectionOutbound = "outbound"
+)
+
+// PeerData is the payload of peer lifecycle events. The fields which are not
+// known to the emitter of the event are omitted.
+type PeerData struct {
+	Overlay      swarm.Address `json:"overlay"`
+	Underlay     string        `json:"underlay,omitempty"`
+	Bin          uint8         `json:"bin"`
+	Direction    string        `json:"direction,omitempty"`
+	Reason       string        `json:"reason,omitempty"`
+	Reachability string        `json:"reachability,omitempty"`
+}
+
+// DepthData is the payload of depth changed events.
+type DepthData struct {
+	Depth uint8 `json:"depth"`
+}
+
+// RadiusData is the payload of radius changed events.
+type RadiusData struct {
+	Radius uint8 `json:"radius"`
+}
+
+// Publisher publishes events to interested subscribers.
+type Publisher interface {
+	Publish(Event)
+}
+
+// PublisherFunc is an adapter to allow the use of ordinary functions as
+// publishers.
+type PublisherFunc func(Event)
+
+// Publish calls f(e).
+func (f PublisherFunc) Publish(e Event) {
+	f(e)
+}
+
+type subscription struct {
+	c     chan Event
+	types map[Type]bool
+}
+
+func (s *subscription) wants(t Type) bool {
+	return len(s.types) == 0 || s.types[t]
+}
+
+// Service fans out published events to subscribers.
+type Service struct {
+	logger  logging.Logger
+	metrics metrics
+
+	mu     sync.Mutex
+	subs   map[*subscription]struct{}
+	closed bool
+}
+
+var _ Publisher = (*Service)(nil)
+
+// New creates a new events service.
+func New(logger logging.Logger) *Service {
+	return &Service{
+		logger:  logger,
+		metrics: newMetrics(),
+		subs:    make(map[*subscription]struct{}),
+	}
+}
+
+// Publish delivers the event to all subscribers of its type. It never
+// blocks, events are dropped for subscribers which do not keep up.
+func (s *Service) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	s.logger.Debugf("events: %s: %+v", e.Type, e.Data)
+	s.metrics.PublishedEvents.WithLabelValues(e.Type.String()).Inc()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	for sub := range s.subs {
+		if !sub.wants(e.Type) {
+			continue
+		}
+		select {
+		case sub.c <- e:
+		default:
+			s.metrics.DroppedEvents.Inc()
+			s.logger.Debugf("events: subscriber too slow, dropping %s event", e.Type)
+		}
+	}
+}
+
+// Subscribe returns a channel of events of the given types, or of all types
+// if none are given. The returned function cancels the subscription and
+// closes the channel.
+func (s *Service) Subscribe(types ...Type) (c <-chan Event, unsubscribe func()) {
+	sub := &subscription{
+		c:     make(chan Event, subscriptionBuffer),
+		types: make(map[Type]bool, len(types)),
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		close(sub.c)
+		return sub.c, func() {}
+	}
+	s.subs[sub] = struct{}{}
+
+	var once sync.Once
+	return sub.c, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if _, ok := s.subs[sub]; ok {
+				delete(s.subs, sub)
+				close(sub.c)
+			}
+		})
+	}
+}
+
+// Close closes all subscriptions.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for sub := range s.subs {
+		delete(s.subs, sub)
+		close(sub.c)
+	}
+	return nil
+}
+
+// Metrics returns the prometheus collectors of the service.
+func (s *Service) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{s.metrics.PublishedEvents, s.metrics.DroppedEvents}
+}
+
+// BalanceMonitor publishes a low balance event once a balance drops below
+// the threshold. It publishes again only after the balance has recovered.
+type BalanceMonitor struct {
+	publisher Publisher
+	typ       Type
+	address   common.Address
+	threshold *big.Int
+
+	mu  sync.Mutex
+	low bool
+}
+
+// NewBalanceMonitor creates a balance monitor for the given address. It
+// returns nil if either publisher or threshold is nil, on which Check is a
+// no-op.
+func NewBalanceMonitor(publisher Publisher, typ Type, address common.Address, threshold *big.Int) *BalanceMonitor {
+	if publisher == nil || threshold == nil {
+		return nil
+	}
+	return &BalanceMonitor{
+		publisher: publisher,
+		typ:       typ,
+		address:   address,
+		threshold: threshold,
+	}
+}
+
+// Check compares the balance against the threshold and publishes an event
+// if it dropped below it.
+func (m *BalanceMonitor) Check(balance *big.Int) {
+	if m == nil || balance == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if balance.Cmp(m.threshold) >= 0 {
+		m.low = false
+		return
+	}
+	if m.low {
+		return
+	}
+	m.low = true
+	m.publisher.Publish(Event{
+		Type: m.typ,
+		Time: time.Now(),
+		Data: BalanceData{
+			Address:   m.address,
+			Balance:   &bigint.BigInt{Int: new(big.Int).Set(balance)},
+			Threshold: &bigint.BigInt{Int: new(big.Int).Set(m.threshold)},
+		},
+	})
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package events_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/events"
+	"github.com/holisticode/bee/pkg/logging"
+)
+
+func TestSubscribe(t *testing.T) {
+	s := events.New(logging.New(io.Discard, 0))
+	defer s.Close()
+
+	all, unsubscribeAll := s.Subscribe()
+	defer unsubscribeAll()
+	expired, unsubscribeExpired := s.Subscribe(events.BatchExpired)
+
+	s.Publish(events.Event{Type: events.BatchExpiring, Data: events.BatchData{BatchID: "aa"}})
+	s.Publish(events.Event{Type: events.BatchExpired, Data: events.BatchData{BatchID: "bb"}})
+
+	for _, want := range []events.Type{events.BatchExpiring, events.BatchExpired} {
+		e := receive(t, all)
+		if e.Type != want {
+			t.Fatalf("got event type %s, want %s", e.Type, want)
+		}
+		if e.Time.IsZero() {
+			t.Fatal("event time not set")
+		}
+	}
+
+	e := receive(t, expired)
+	if e.Type != events.BatchExpired {
+		t.Fatalf("got event type %s, want %s", e.Type, events.BatchExpired)
+	}
+	if got := e.Data.(events.BatchData).BatchID; got != "bb" {
+		t.Fatalf("got batch id %s, want bb", got)
+	}
+
+	unsubscribeExpired()
+	if _, ok := <-expired; ok {
+		t.Fatal("channel not closed after unsubscribe")
+	}
+	// unsubscribing twice must not panic
+	unsubscribeExpired()
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-all; ok {
+		t.Fatal("channel not closed after close")
+	}
+	// publishing after close is a no-op
+	s.Publish(events.Event{Type: events.BatchExpired})
+}
+
+func TestPublishSlowSubscriber(t *testing.T) {
+	s := events.New(logging.New(io.Discard, 0))
+	defer s.Close()
+
+	_, unsubscribe := s.Subscribe()
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			s.Publish(events.Event{Type: events.WalletBalanceLow})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish blocked on a slow subscriber")
+	}
+}
+
+func TestTypeJSON(t *testing.T) {
+	for _, typ := range events.Types() {
+		b, err := json.Marshal(typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got events.Type
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != typ {
+			t.Fatalf("got %s, want %s", got, typ)
+		}
+	}
+
+	if _, err := events.ParseType("unknown"); !errors.Is(err, events.ErrUnknownType) {
+		t.Fatalf("got error %v, want %v", err, events.ErrUnknownType)
+	}
+}
+
+func TestBalanceMonitor(t *testing.T) {
+	var published []events.Event
+	publisher := events.PublisherFunc(func(e events.Event) {
+		published = append(published, e)
+	})
+	address := common.HexToAddress("0xabcd")
+
+	m := events.NewBalanceMonitor(publisher, events.ChequebookBalanceLow, address, big.NewInt(100))
+
+	m.Check(big.NewInt(150))
+	m.Check(big.NewInt(50))
+	m.Check(big.NewInt(40))
+	if len(published) != 1 {
+		t.Fatalf("got %d events, want 1", len(published))
+	}
+	data := published[0].Data.(events.BalanceData)
+	if data.Address != address || data.Balance.Cmp(big.NewInt(50)) != 0 || data.Threshold.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("unexpected event data %+v", data)
+	}
+
+	// recovering the balance rearms the monitor
+	m.Check(big.NewInt(100))
+	m.Check(big.NewInt(10))
+	if len(published) != 2 {
+		t.Fatalf("got %d events, want 2", len(published))
+	}
+
+	// without a threshold the monitor is disabled
+	disabled := events.NewBalanceMonitor(publisher, events.WalletBalanceLow, address, nil)
+	disabled.Check(big.NewInt(0))
+	if len(published) != 2 {
+		t.Fatalf("got %d events, want 2", len(published))
+	}
+}
+
+func receive(t *testing.T, c <-chan events.Event) events.Event {
+	t.Helper()
+	select {
+	case e, ok := <-c:
+		if !ok {
+			t.Fatal("subscription closed")
+		}
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+	return events.Event{}
+}
+
+func TestPeerTypes(t *testing.T) {
+	for _, typ := range events.PeerTypes() {
+		if !events.IsPeerType(typ) {
+			t.Fatalf("%s is not a peer type", typ)
+		}
+	}
+	for _, typ := range events.AlertTypes() {
+		if events.IsPeerType(typ) {
+			t.Fatalf("%s is a peer type", typ)
+		}
+	}
+	if got, want := len(events.Types()), len(events.AlertTypes())+len(events.PeerTypes()); got != want {
+		t.Fatalf("got %d types, want %d", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package events
+
+import (
+	m "github.com/holisticode/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	PublishedEvents   *prometheus.CounterVec
+	DroppedEvents     prometheus.Counter
+	WebhookDeliveries prometheus.Counter
+	WebhookRetries    prometheus.Counter
+	WebhookFailures   prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "events"
+
+	return metrics{
+		PublishedEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "published_count",
+			Help:      "Number of published events by type.",
+		}, []string{"type"}),
+		DroppedEvents: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "dropped_count",
+			Help:      "Number of events dropped for slow subscribers.",
+		}),
+		WebhookDeliveries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "webhook_delivered_count",
+			Help:      "Number of events delivered to webhooks.",
+		}),
+		WebhookRetries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "webhook_retry_count",
+			Help:      "Number of retried webhook deliveries.",
+		}),
+		WebhookFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "webhook_failed_count",
+			Help:      "Number of events which could not be delivered to webhooks.",
+		}),
+	}
+}
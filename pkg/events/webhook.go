@@ -0,0 +1,174 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultWebhookBackoff = time.Second
+	defaultWebhookTimeout = 10 * time.Second
+)
+
+// WebhookOptions configure the delivery of events to webhooks.
+type WebhookOptions struct {
+	// Types limits the delivered events to the given types, all events are
+	// delivered if empty.
+	Types []Type
+	// Retries is the number of retries of a failed delivery.
+	Retries int
+	// Backoff is the wait time before the first retry, it doubles with every
+	// subsequent retry.
+	Backoff time.Duration
+	// Timeout is the timeout of a single delivery attempt.
+	Timeout time.Duration
+}
+
+// Webhooks delivers events as JSON encoded POST requests to a set of URLs.
+// Each URL is served by its own subscription, so a slow or failing endpoint
+// does not delay deliveries to the others.
+type Webhooks struct {
+	client  *http.Client
+	logger  logging.Logger
+	metrics metrics
+	retries int
+	backoff time.Duration
+	timeout time.Duration
+
+	quit   chan struct{}
+	wg     sync.WaitGroup
+	cancel []func()
+}
+
+// NewWebhooks subscribes to the service and starts delivering events to the
+// given URLs.
+func NewWebhooks(s *Service, urls []string, client *http.Client, logger logging.Logger, o WebhookOptions) *Webhooks {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	w := &Webhooks{
+		client:  client,
+		logger:  logger,
+		metrics: newMetrics(),
+		retries: o.Retries,
+		backoff: o.Backoff,
+		timeout: o.Timeout,
+		quit:    make(chan struct{}),
+	}
+	if w.retries < 0 {
+		w.retries = 0
+	}
+	if w.backoff <= 0 {
+		w.backoff = defaultWebhookBackoff
+	}
+	if w.timeout <= 0 {
+		w.timeout = defaultWebhookTimeout
+	}
+
+	for _, url := range urls {
+		c, unsubscribe := s.Subscribe(o.Types...)
+		w.cancel = append(w.cancel, unsubscribe)
+		w.wg.Add(1)
+		go w.run(url, c)
+	}
+	return w
+}
+
+func (w *Webhooks) run(url string, c <-chan Event) {
+	defer w.wg.Done()
+	for {
+		select {
+		case e, ok := <-c:
+			if !ok {
+				return
+			}
+			if err := w.deliver(url, e); err != nil {
+				w.metrics.WebhookFailures.Inc()
+				w.logger.Debugf("events: webhook %s: %v", url, err)
+				w.logger.Errorf("events: failed to deliver %s event to webhook %s", e.Type, url)
+				continue
+			}
+			w.metrics.WebhookDeliveries.Inc()
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// deliver posts the event to the url, retrying with exponential backoff
+// until it succeeds, the retries are exhausted or the webhooks are closed.
+func (w *Webhooks) deliver(url string, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	backoff := w.backoff
+	for attempt := 0; ; attempt++ {
+		err = w.post(url, body)
+		if err == nil {
+			return nil
+		}
+		if attempt >= w.retries {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+		w.metrics.WebhookRetries.Inc()
+		select {
+		case <-time.After(backoff):
+		case <-w.quit:
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func (w *Webhooks) post(url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+
+// Close stops the delivery of events.
+func (w *Webhooks) Close() error {
+	close(w.quit)
+	for _, unsubscribe := range w.cancel {
+		unsubscribe()
+	}
+	w.wg.Wait()
+	return nil
+}
+
+// Metrics returns the prometheus collectors of the webhooks.
+func (w *Webhooks) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{w.metrics.WebhookDeliveries, w.metrics.WebhookRetries, w.metrics.WebhookFailures}
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package events_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/holisticode/bee/pkg/events"
+	"github.com/holisticode/bee/pkg/logging"
+)
+
+func TestWebhooks(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+		received = make(chan map[string]interface{}, 1)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("got method %s, want %s", r.Method, http.MethodPost)
+		}
+		mu.Lock()
+		attempts++
+		fail := attempts < 3
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		received <- body
+	}))
+	defer server.Close()
+
+	logger := logging.New(io.Discard, 0)
+	s := events.New(logger)
+	defer s.Close()
+
+	w := events.NewWebhooks(s, []string{server.URL}, nil, logger, events.WebhookOptions{
+		Types:   []events.Type{events.BatchExpired},
+		Retries: 3,
+		Backoff: 10 * time.Millisecond,
+	})
+	defer w.Close()
+
+	s.Publish(events.Event{Type: events.BatchExpiring, Data: events.BatchData{BatchID: "aa"}})
+	s.Publish(events.Event{Type: events.BatchExpired, Data: events.BatchData{BatchID: "bb"}})
+
+	select {
+	case body := <-received:
+		if body["type"] != events.BatchExpired.String() {
+			t.Fatalf("got type %v, want %s", body["type"], events.BatchExpired)
+		}
+		if id := body["data"].(map[string]interface{})["batchID"]; id != "bb" {
+			t.Fatalf("got batch id %v, want bb", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for webhook delivery")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 3 {
+		t.Fatalf("got %d attempts, want 3", attempts)
+	}
+}
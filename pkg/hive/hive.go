@@ -199,12 +199,17 @@ func (s *Service) sendPeers(ctx context.Context, peer swarm.Address, peers []swa
 			continue // Don't advertise private CIDRs to the public network.
 		}
 
-		peersRequest.Peers = append(peersRequest.Peers, &pb.BzzAddress{
+		bzzAddress := &pb.BzzAddress{
 			Overlay:     addr.Overlay.Bytes(),
 			Underlay:    addr.Underlay.Bytes(),
 			Signature:   addr.Signature,
 			Transaction: addr.Transaction,
-		})
+		}
+		if addr.QUICUnderlay != nil {
+			bzzAddress.QUICUnderlay = addr.QUICUnderlay.Bytes()
+			bzzAddress.QUICSignature = addr.QUICSignature
+		}
+		peersRequest.Peers = append(peersRequest.Peers, bzzAddress)
 	}
 
 	if err := w.WriteMsgWithContext(ctx, &peersRequest); err != nil {
@@ -359,6 +364,17 @@ func (s *Service) checkAndAddPeers(ctx context.Context, peers pb.Peers) {
 				Transaction: newPeer.Transaction,
 			}
 
+			if len(newPeer.QUICUnderlay) > 0 {
+				// the QUIC underlay is optional, the peer is still reachable over the underlay
+				if quicUnderlay, err := ma.NewMultiaddrBytes(newPeer.QUICUnderlay); err != nil {
+					s.metrics.PeerUnderlayErr.Inc()
+					s.logger.Debugf("hive: peer %s: quic underlay err: %v", hex.EncodeToString(newPeer.Overlay), err)
+				} else {
+					bzzAddress.QUICUnderlay = quicUnderlay
+					bzzAddress.QUICSignature = newPeer.QUICSignature
+				}
+			}
+
 			err = s.addressBook.Put(bzzAddress.Overlay, bzzAddress)
 			if err != nil {
 				s.metrics.StorePeerErr.Inc()
@@ -146,6 +146,24 @@ func TestBroadcastPeers(t *testing.T) {
 			t.Fatal(err)
 		}
 
+		wantMsg := &pb.BzzAddress{
+			Overlay:     bzzAddr.Overlay.Bytes(),
+			Underlay:    bzzAddr.Underlay.Bytes(),
+			Signature:   bzzAddr.Signature,
+			Transaction: tx,
+		}
+		if i%2 == 0 { // every other peer also advertises a QUIC underlay
+			quicUnderlay, err := ma.NewMultiaddr(base + strconv.Itoa(i) + "/quic")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := bzzAddr.SetQUICUnderlay(signer, quicUnderlay, networkID); err != nil {
+				t.Fatal(err)
+			}
+			wantMsg.QUICUnderlay = bzzAddr.QUICUnderlay.Bytes()
+			wantMsg.QUICSignature = bzzAddr.QUICSignature
+		}
+
 		bzzAddresses = append(bzzAddresses, *bzzAddr)
 		overlays = append(overlays, bzzAddr.Overlay)
 		err = addressbook.Put(bzzAddr.Overlay, *bzzAddr)
@@ -153,12 +171,7 @@ func TestBroadcastPeers(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		wantMsgs[i/hive.MaxBatchSize].Peers = append(wantMsgs[i/hive.MaxBatchSize].Peers, &pb.BzzAddress{
-			Overlay:     bzzAddresses[i].Overlay.Bytes(),
-			Underlay:    bzzAddresses[i].Underlay.Bytes(),
-			Signature:   bzzAddresses[i].Signature,
-			Transaction: tx,
-		})
+		wantMsgs[i/hive.MaxBatchSize].Peers = append(wantMsgs[i/hive.MaxBatchSize].Peers, wantMsg)
 	}
 
 	testCases := map[string]struct {
@@ -18,10 +18,12 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/holisticode/bee/pkg/config"
 	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/p2p/libp2p"
 	"github.com/holisticode/bee/pkg/sctx"
 	"github.com/holisticode/bee/pkg/settlement"
+	"github.com/holisticode/bee/pkg/settlement/driver"
 	"github.com/holisticode/bee/pkg/settlement/swap"
 	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
 	"github.com/holisticode/bee/pkg/settlement/swap/priceoracle"
@@ -46,6 +48,8 @@ func InitChain(
 	endpoint string,
 	signer crypto.Signer,
 	pollingInterval time.Duration,
+	publisher events.Publisher,
+	walletLowBalance *big.Int,
 ) (transaction.Backend, common.Address, int64, transaction.Monitor, transaction.Service, error) {
 	var backend transaction.Backend
 	rpcClient, err := rpc.DialContext(ctx, endpoint)
@@ -76,7 +80,7 @@ func InitChain(
 
 	transactionMonitor := transaction.NewMonitor(logger, backend, overlayEthAddress, pollingInterval, cancellationDepth)
 
-	transactionService, err := transaction.NewService(logger, backend, signer, stateStore, chainID, transactionMonitor)
+	transactionService, err := transaction.NewService(logger, backend, signer, stateStore, chainID, transactionMonitor, publisher, walletLowBalance)
 	if err != nil {
 		return nil, common.Address{}, 0, nil, nil, fmt.Errorf("new transaction service: %w", err)
 	}
@@ -148,6 +152,8 @@ func InitChequebookService(
 	chequebookFactory chequebook.Factory,
 	initialDeposit string,
 	deployGasPrice string,
+	publisher events.Publisher,
+	lowBalance *big.Int,
 ) (chequebook.Service, error) {
 	chequeSigner := chequebook.NewChequeSigner(signer, chainID)
 
@@ -175,6 +181,8 @@ func InitChequebookService(
 		chainID,
 		overlayEthAddress,
 		chequeSigner,
+		publisher,
+		lowBalance,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("chequebook init: %w", err)
@@ -210,7 +218,8 @@ func initChequeStoreCashout(
 	return chequeStore, cashout
 }
 
-// InitSwap will initialize and register the swap service.
+// InitSwap will initialize and register the swap service. Swap is opened
+// through the settlement driver registry.
 func InitSwap(
 	p2ps *libp2p.Service,
 	logger logging.Logger,
@@ -237,26 +246,34 @@ func InitSwap(
 		currentPriceOracleAddress = common.HexToAddress(priceOracleAddress)
 	}
 
-	priceOracle := priceoracle.New(logger, currentPriceOracleAddress, transactionService, 300)
+	priceOracle := priceoracle.New(logger, currentPriceOracleAddress, transactionService, stateStore, 300)
 	priceOracle.Start()
 	swapProtocol := swapprotocol.New(p2ps, logger, overlayEthAddress, priceOracle)
 	swapAddressBook := swap.NewAddressbook(stateStore)
 
-	swapService := swap.New(
-		swapProtocol,
-		logger,
-		stateStore,
-		chequebookService,
-		chequeStore,
-		swapAddressBook,
-		networkID,
-		cashoutService,
-		accounting,
-	)
-
-	swapProtocol.SetSwap(swapService)
+	d, err := driver.Open(swap.DriverName, driver.Options{
+		Streamer:   p2ps,
+		Store:      stateStore,
+		Accounting: accounting,
+		Logger:     logger,
+		Backend: &swap.Backend{
+			Protocol:    swapProtocol,
+			Chequebook:  chequebookService,
+			ChequeStore: chequeStore,
+			Cashout:     cashoutService,
+			Addressbook: swapAddressBook,
+			NetworkID:   networkID,
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	swapService, ok := d.(*swap.Service)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected swap settlement driver %T", d)
+	}
 
-	err := p2ps.AddProtocol(swapProtocol.Protocol())
+	err = p2ps.AddProtocol(swapProtocol.Protocol())
 	if err != nil {
 		return nil, nil, err
 	}
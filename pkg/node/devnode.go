@@ -21,25 +21,28 @@ import (
 	"github.com/holisticode/bee/pkg/bzz"
 	"github.com/holisticode/bee/pkg/crypto"
 	"github.com/holisticode/bee/pkg/debugapi"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/feeds/factory"
 	"github.com/holisticode/bee/pkg/localstore"
 	"github.com/holisticode/bee/pkg/logging"
 	mockP2P "github.com/holisticode/bee/pkg/p2p/mock"
+	"github.com/holisticode/bee/pkg/p2p/streamtest"
 	mockPingPong "github.com/holisticode/bee/pkg/pingpong/mock"
 	"github.com/holisticode/bee/pkg/pinning"
 	"github.com/holisticode/bee/pkg/postage"
+	"github.com/holisticode/bee/pkg/postage/batchservice"
 	"github.com/holisticode/bee/pkg/postage/batchstore"
-	mockPost "github.com/holisticode/bee/pkg/postage/mock"
+	"github.com/holisticode/bee/pkg/postage/listener"
 	"github.com/holisticode/bee/pkg/postage/postagecontract"
-	mockPostContract "github.com/holisticode/bee/pkg/postage/postagecontract/mock"
-	postagetesting "github.com/holisticode/bee/pkg/postage/testing"
 	"github.com/holisticode/bee/pkg/pss"
 	"github.com/holisticode/bee/pkg/pushsync"
 	mockPushsync "github.com/holisticode/bee/pkg/pushsync/mock"
+	"github.com/holisticode/bee/pkg/settlement/driver"
 	"github.com/holisticode/bee/pkg/settlement/pseudosettle"
+	"github.com/holisticode/bee/pkg/settlement/swap"
 	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
-	mockchequebook "github.com/holisticode/bee/pkg/settlement/swap/chequebook/mock"
-	swapmock "github.com/holisticode/bee/pkg/settlement/swap/mock"
+	mockPriceOracle "github.com/holisticode/bee/pkg/settlement/swap/priceoracle/mock"
+	"github.com/holisticode/bee/pkg/settlement/swap/swapprotocol"
 	"github.com/holisticode/bee/pkg/statestore/leveldb"
 	mockStateStore "github.com/holisticode/bee/pkg/statestore/mock"
 	"github.com/holisticode/bee/pkg/swarm"
@@ -48,24 +51,50 @@ import (
 	mockTopology "github.com/holisticode/bee/pkg/topology/mock"
 	"github.com/holisticode/bee/pkg/tracing"
 	"github.com/holisticode/bee/pkg/transaction"
-	transactionmock "github.com/holisticode/bee/pkg/transaction/mock"
+	"github.com/holisticode/bee/pkg/transaction/backendsimulation"
 	"github.com/holisticode/bee/pkg/traversal"
 	"github.com/hashicorp/go-multierror"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/sha3"
 	"golang.org/x/sync/errgroup"
 )
 
+const (
+	devChainID      = 1337
+	devBlockTime    = time.Second
+	devPostagePrice = 1
+	// devBatchExpiryWarning is the number of blocks before expiry at which
+	// owned batches are announced, one minute on the simulated chain.
+	devBatchExpiryWarning = 60
+)
+
+var (
+	// devChainAdmin mints the tokens and sets the postage price on the
+	// simulated chain.
+	devChainAdmin     = common.HexToAddress("0xad")
+	devFundingETH     = new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)
+	devFundingBZZ     = new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)
+	devInitialDeposit = new(big.Int).Exp(big.NewInt(10), big.NewInt(17), nil)
+	devExchangeRate   = big.NewInt(1)
+)
+
 type DevBee struct {
-	tracerCloser     io.Closer
-	stateStoreCloser io.Closer
-	localstoreCloser io.Closer
-	apiCloser        io.Closer
-	pssCloser        io.Closer
-	tagsCloser       io.Closer
-	errorLogWriter   *io.PipeWriter
-	apiServer        *http.Server
-	debugAPIServer   *http.Server
+	tracerCloser             io.Closer
+	stateStoreCloser         io.Closer
+	localstoreCloser         io.Closer
+	apiCloser                io.Closer
+	pssCloser                io.Closer
+	tagsCloser               io.Closer
+	blockMinerCloser         io.Closer
+	transactionMonitorCloser io.Closer
+	transactionCloser        io.Closer
+	listenerCloser           io.Closer
+	postageServiceCloser     io.Closer
+	eventsCloser             io.Closer
+	errorLogWriter           *io.PipeWriter
+	apiServer                *http.Server
+	debugAPIServer           *http.Server
 }
 
 type DevOptions struct {
@@ -81,10 +110,14 @@ type DevOptions struct {
 	Restricted               bool
 	TokenEncryptionKey       string
 	AdminPasswordHash        string
+	// P2PNetworkKey is the pre-shared key of a private network. The p2p
+	// service of the development node is mocked, so the key is only checked.
+	P2PNetworkKey []byte
 }
 
 // NewDevBee starts the bee instance in 'development' mode
 // this implies starting an API and a Debug endpoints while mocking all their services.
+// Postage, chequebook and swap transactions are executed on an in-process simulated chain.
 func NewDevBee(logger logging.Logger, o *DevOptions) (b *DevBee, err error) {
 	tracer, tracerCloser, err := tracing.NewTracer(&tracing.Options{
 		Enabled: false,
@@ -98,12 +131,19 @@ func NewDevBee(logger logging.Logger, o *DevOptions) (b *DevBee, err error) {
 		tracerCloser:   tracerCloser,
 	}
 
+	if o.P2PNetworkKey != nil {
+		logger.Info("private network key is not used by the mocked p2p service in development mode")
+	}
+
 	stateStore, err := leveldb.NewInMemoryStateStore(logger)
 	if err != nil {
 		return nil, err
 	}
 	b.stateStoreCloser = stateStore
 
+	eventsService := events.New(logger)
+	b.eventsCloser = eventsService
+
 	mockKey, err := crypto.GenerateSecp256k1Key()
 	if err != nil {
 		return nil, err
@@ -115,6 +155,26 @@ func NewDevBee(logger logging.Logger, o *DevOptions) (b *DevBee, err error) {
 		return nil, fmt.Errorf("eth address: %w", err)
 	}
 
+	chain := backendsimulation.NewChain(devChainID)
+	deployment, err := backendsimulation.Deploy(chain, devChainAdmin, big.NewInt(devPostagePrice))
+	if err != nil {
+		return nil, fmt.Errorf("simulated chain: %w", err)
+	}
+	if err := deployment.Fund(overlayEthAddress, devFundingETH, devFundingBZZ); err != nil {
+		return nil, fmt.Errorf("simulated chain funding: %w", err)
+	}
+	b.blockMinerCloser = newBlockMiner(chain, devBlockTime)
+	logger.Infof("using simulated chain with postage stamp contract %x and chequebook factory %x", deployment.PostageStamp, deployment.Factory)
+
+	transactionMonitor := transaction.NewMonitor(logger, chain, overlayEthAddress, devBlockTime, cancellationDepth)
+	b.transactionMonitorCloser = transactionMonitor
+
+	transactionService, err := transaction.NewService(logger, chain, signer, stateStore, big.NewInt(devChainID), transactionMonitor, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new transaction service: %w", err)
+	}
+	b.transactionCloser = transactionService
+
 	var authenticator *auth.Authenticator
 
 	if o.Restricted {
@@ -132,28 +192,7 @@ func NewDevBee(logger logging.Logger, o *DevOptions) (b *DevBee, err error) {
 			return nil, fmt.Errorf("debug api listener: %w", err)
 		}
 
-		var mockTransaction = transactionmock.New(transactionmock.WithPendingTransactionsFunc(func() ([]common.Hash, error) {
-			return []common.Hash{common.HexToHash("abcd")}, nil
-		}), transactionmock.WithResendTransactionFunc(func(ctx context.Context, txHash common.Hash) error {
-			return nil
-		}), transactionmock.WithStoredTransactionFunc(func(txHash common.Hash) (*transaction.StoredTransaction, error) {
-			recipient := common.HexToAddress("dfff")
-			return &transaction.StoredTransaction{
-				To:          &recipient,
-				Created:     1,
-				Data:        []byte{1, 2, 3, 4},
-				GasPrice:    big.NewInt(12),
-				GasLimit:    5345,
-				Value:       big.NewInt(4),
-				Nonce:       3,
-				Description: "test",
-			}, nil
-		}), transactionmock.WithCancelTransactionFunc(func(ctx context.Context, originalTxHash common.Hash) (common.Hash, error) {
-			return common.Hash{}, nil
-		}),
-		)
-
-		debugAPIService = debugapi.New(mockKey.PublicKey, mockKey.PublicKey, overlayEthAddress, logger, tracer, nil, big.NewInt(0), mockTransaction, o.Restricted, authenticator)
+		debugAPIService = debugapi.New(mockKey.PublicKey, mockKey.PublicKey, overlayEthAddress, logger, tracer, nil, big.NewInt(0), transactionService, o.Restricted, authenticator)
 		debugAPIServer := &http.Server{
 			IdleTimeout:       30 * time.Second,
 			ReadHeaderTimeout: 3 * time.Second,
@@ -207,79 +246,41 @@ func NewDevBee(logger logging.Logger, o *DevOptions) (b *DevBee, err error) {
 
 	pinningService := pinning.NewService(storer, stateStore, traversalService)
 
-	batchStore, err := batchstore.New(stateStore, func(b []byte) error { return nil }, logger)
+	batchStore, err := batchstore.New(stateStore, func(b []byte) error {
+		_, err := storer.UnreserveBatch(b, swarm.MaxPO+1)
+		return err
+	}, eventsService, logger)
 	if err != nil {
 		return nil, fmt.Errorf("batchstore: %w", err)
 	}
 
-	post := mockPost.New()
-	postageContract := mockPostContract.New(
-		mockPostContract.WithCreateBatchFunc(
-			func(ctx context.Context, initialBalance *big.Int, depth uint8, immutable bool, label string) ([]byte, error) {
-				id := postagetesting.MustNewID()
-				b := &postage.Batch{
-					ID:        id,
-					Owner:     overlayEthAddress.Bytes(),
-					Value:     big.NewInt(0),
-					Depth:     depth,
-					Immutable: immutable,
-				}
-
-				totalAmount := big.NewInt(0).Mul(initialBalance, big.NewInt(int64(1<<depth)))
-
-				err := batchStore.Put(b, totalAmount, depth)
-				if err != nil {
-					return nil, err
-				}
-
-				stampIssuer := postage.NewStampIssuer(label, string(overlayEthAddress.Bytes()), id, totalAmount, depth, 0, 0, immutable)
-				_ = post.Add(stampIssuer)
-
-				return id, nil
-			},
-		),
-		mockPostContract.WithTopUpBatchFunc(
-			func(ctx context.Context, batchID []byte, topupAmount *big.Int) error {
-				batch, err := batchStore.Get(batchID)
-				if err != nil {
-					return err
-				}
-
-				totalAmount := big.NewInt(0).Mul(topupAmount, big.NewInt(int64(1<<batch.Depth)))
+	post, err := postage.NewService(stateStore, batchStore, devChainID)
+	if err != nil {
+		return nil, fmt.Errorf("postage service load: %w", err)
+	}
+	b.postageServiceCloser = post
 
-				newBalance := big.NewInt(0).Add(totalAmount, batch.Value)
+	eventListener := listener.New(logger, chain, deployment.PostageStamp, uint64(devBlockTime/time.Second), b, postageSyncingStallingTimeout, postageSyncingBackoffTimeout)
+	b.listenerCloser = eventListener
 
-				err = batchStore.Put(batch, newBalance, batch.Depth)
-				if err != nil {
-					return err
-				}
+	batchSvc, err := batchservice.New(stateStore, batchStore, logger, eventListener, overlayEthAddress.Bytes(), post, eventsService, devBatchExpiryWarning, sha3.New256, false)
+	if err != nil {
+		return nil, err
+	}
 
-				post.HandleTopUp(batch.ID, newBalance)
-				return nil
-			},
-		),
-		mockPostContract.WithDiluteBatchFunc(
-			func(ctx context.Context, batchID []byte, newDepth uint8) error {
-				batch, err := batchStore.Get(batchID)
-				if err != nil {
-					return err
-				}
-
-				if newDepth < batch.Depth {
-					return postagecontract.ErrInvalidDepth
-				}
-
-				newBalance := big.NewInt(0).Div(batch.Value, big.NewInt(int64(1<<(newDepth-batch.Depth))))
-
-				err = batchStore.Put(batch, newBalance, newDepth)
-				if err != nil {
-					return err
-				}
-
-				post.HandleDepthIncrease(batch.ID, newDepth, newBalance)
-				return nil
-			},
-		),
+	syncedChan, err := batchSvc.Start(0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to start batch service: %w", err)
+	}
+	<-syncedChan
+
+	postageContract := postagecontract.New(
+		overlayEthAddress,
+		deployment.PostageStamp,
+		deployment.Token,
+		transactionService,
+		post,
+		batchStore,
 	)
 
 	feedFactory := factory.New(storer)
@@ -336,53 +337,55 @@ func NewDevBee(logger logging.Logger, o *DevOptions) (b *DevBee, err error) {
 			kad            = mockTopology.NewTopologyDriver()
 			storeRecipient = mockStateStore.NewStateStore()
 			pseudoset      = pseudosettle.New(nil, logger, storeRecipient, nil, big.NewInt(10000), big.NewInt(10000), p2ps)
-			mockSwap       = swapmock.New(swapmock.WithCashoutStatusFunc(
-				func(ctx context.Context, peer swarm.Address) (*chequebook.CashoutStatus, error) {
-					return &chequebook.CashoutStatus{
-						Last:           &chequebook.LastCashout{},
-						UncashedAmount: big.NewInt(0),
-					}, nil
-				},
-			), swapmock.WithLastSentChequeFunc(
-				func(a swarm.Address) (*chequebook.SignedCheque, error) {
-					return &chequebook.SignedCheque{
-						Cheque: chequebook.Cheque{
-							Beneficiary: common.Address{},
-							Chequebook:  common.Address{},
-						},
-					}, nil
-				},
-			), swapmock.WithLastReceivedChequeFunc(
-				func(a swarm.Address) (*chequebook.SignedCheque, error) {
-					return &chequebook.SignedCheque{
-						Cheque: chequebook.Cheque{
-							Beneficiary: common.Address{},
-							Chequebook:  common.Address{},
-						},
-					}, nil
-				},
-			))
-			mockChequebook = mockchequebook.NewChequebook(mockchequebook.WithChequebookBalanceFunc(
-				func(context.Context) (ret *big.Int, err error) {
-					return big.NewInt(0), nil
-				},
-			), mockchequebook.WithChequebookAvailableBalanceFunc(
-				func(context.Context) (ret *big.Int, err error) {
-					return big.NewInt(0), nil
-				},
-			), mockchequebook.WithChequebookWithdrawFunc(
-				func(ctx context.Context, amount *big.Int) (hash common.Hash, err error) {
-					return common.Hash{}, nil
-				},
-			), mockchequebook.WithChequebookDepositFunc(
-				func(ctx context.Context, amount *big.Int) (hash common.Hash, err error) {
-					return common.Hash{}, nil
-				},
-			))
 		)
 
+		chequebookFactory := chequebook.NewFactory(chain, transactionService, deployment.Factory, nil)
+		chequebookService, err := chequebook.Init(
+			context.Background(),
+			chequebookFactory,
+			stateStore,
+			logger,
+			devInitialDeposit,
+			transactionService,
+			chain,
+			devChainID,
+			overlayEthAddress,
+			chequebook.NewChequeSigner(signer, devChainID),
+			eventsService,
+			nil,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("chequebook init: %w", err)
+		}
+
+		chequeStore, cashoutService := initChequeStoreCashout(stateStore, chain, chequebookFactory, devChainID, overlayEthAddress, transactionService)
+
+		// the development node has no peers, streams to them fail and there
+		// are no payments to account, the simulated chain has no price oracle
+		// contract
+		swapProtocol := swapprotocol.New(streamtest.New(), logger, overlayEthAddress, mockPriceOracle.New(devExchangeRate, big.NewInt(0)))
+		d, err := driver.Open(swap.DriverName, driver.Options{
+			Store:  stateStore,
+			Logger: logger,
+			Backend: &swap.Backend{
+				Protocol:    swapProtocol,
+				Chequebook:  chequebookService,
+				ChequeStore: chequeStore,
+				Cashout:     cashoutService,
+				Addressbook: swap.NewAddressbook(stateStore),
+				NetworkID:   devChainID,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("swap: %w", err)
+		}
+		swapService, ok := d.(*swap.Service)
+		if !ok {
+			return nil, fmt.Errorf("unexpected swap settlement driver %T", d)
+		}
+
 		// inject dependencies and configure full debug api http path routes
-		debugAPIService.Configure(swarmAddress, p2ps, pingPong, kad, lightNodes, storer, tagService, acc, pseudoset, true, mockSwap, mockChequebook, batchStore, post, postageContract, traversalService)
+		debugAPIService.Configure(swarmAddress, p2ps, pingPong, kad, lightNodes, storer, tagService, acc, pseudoset, true, swapService, chequebookService, nil, nil, nil, nil, batchStore, post, postageContract, traversalService, eventsService)
 	}
 
 	return b, nil
@@ -401,6 +404,7 @@ func (b *DevBee) Shutdown(ctx context.Context) error {
 	}
 
 	tryClose(b.apiCloser, "api")
+	tryClose(b.eventsCloser, "events")
 
 	var eg errgroup.Group
 	if b.apiServer != nil {
@@ -425,6 +429,11 @@ func (b *DevBee) Shutdown(ctx context.Context) error {
 	}
 
 	tryClose(b.pssCloser, "pss")
+	tryClose(b.listenerCloser, "listener")
+	tryClose(b.postageServiceCloser, "postage service")
+	tryClose(b.transactionMonitorCloser, "transaction monitor")
+	tryClose(b.transactionCloser, "transaction")
+	tryClose(b.blockMinerCloser, "block miner")
 	tryClose(b.tracerCloser, "tracer")
 	tryClose(b.tagsCloser, "tag persistence")
 	tryClose(b.stateStoreCloser, "statestore")
@@ -437,3 +446,36 @@ func (b *DevBee) Shutdown(ctx context.Context) error {
 func pong(ctx context.Context, address swarm.Address, msgs ...string) (rtt time.Duration, err error) {
 	return time.Millisecond, nil
 }
+
+// blockMiner mines blocks on the simulated chain at a fixed interval.
+type blockMiner struct {
+	quit chan struct{}
+	done chan struct{}
+}
+
+func newBlockMiner(chain *backendsimulation.Chain, interval time.Duration) *blockMiner {
+	m := &blockMiner{
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				chain.Mine()
+			case <-m.quit:
+				return
+			}
+		}
+	}()
+	return m
+}
+
+func (m *blockMiner) Close() error {
+	close(m.quit)
+	<-m.done
+	return nil
+}
@@ -10,6 +10,7 @@ package node
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -20,10 +21,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/holisticode/bee/pkg/accounting"
@@ -35,6 +38,7 @@ import (
 	"github.com/holisticode/bee/pkg/config"
 	"github.com/holisticode/bee/pkg/crypto"
 	"github.com/holisticode/bee/pkg/debugapi"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/feeds/factory"
 	"github.com/holisticode/bee/pkg/hive"
 	"github.com/holisticode/bee/pkg/localstore"
@@ -59,11 +63,16 @@ import (
 	"github.com/holisticode/bee/pkg/pusher"
 	"github.com/holisticode/bee/pkg/pushsync"
 	"github.com/holisticode/bee/pkg/recovery"
+	"github.com/holisticode/bee/pkg/reputation"
 	"github.com/holisticode/bee/pkg/resolver/multiresolver"
 	"github.com/holisticode/bee/pkg/retrieval"
+	_ "github.com/holisticode/bee/pkg/settlement/consortium" // register the consortium settlement driver
+	"github.com/holisticode/bee/pkg/settlement/driver"
+	"github.com/holisticode/bee/pkg/settlement/history"
 	"github.com/holisticode/bee/pkg/settlement/pseudosettle"
 	"github.com/holisticode/bee/pkg/settlement/swap"
 	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
+	"github.com/holisticode/bee/pkg/settlement/swap/erc20"
 	"github.com/holisticode/bee/pkg/settlement/swap/priceoracle"
 	"github.com/holisticode/bee/pkg/shed"
 	"github.com/holisticode/bee/pkg/steward"
@@ -113,6 +122,12 @@ type Bee struct {
 	priceOracleCloser        io.Closer
 	hiveCloser               io.Closer
 	chainSyncerCloser        io.Closer
+	eventsCloser             io.Closer
+	webhooksCloser           io.Closer
+	pricerCloser             io.Closer
+	autoCashoutCloser        io.Closer
+	refillerCloser           io.Closer
+	settlementDriversCloser  io.Closer
 	shutdownInProgress       bool
 	shutdownMutex            sync.Mutex
 }
@@ -129,6 +144,17 @@ type Options struct {
 	Addr                       string
 	NATAddr                    string
 	EnableWS                   bool
+	EnableQUIC                 bool
+	QUICAddr                   string
+	EnableMDNS                 bool
+	P2PInboundLimit            int
+	P2POutboundLimit           int
+	P2PIPLimit                 int
+	P2PSubnetLimit             int
+	P2PNetworkLimits           []string
+	P2PBandwidthLimit          int
+	P2PProtocolBandwidthLimits []string
+	P2PNetworkKey              []byte
 	WelcomeMessage             string
 	Bootnodes                  []string
 	CORSAllowedOrigins         []string
@@ -166,12 +192,39 @@ type Options struct {
 	Restricted                 bool
 	TokenEncryptionKey         string
 	AdminPasswordHash          string
+	BatchSelectionPolicy       postage.SelectionPolicy
+	DefaultBatchID             []byte
+	EventWebhooks              []string
+	EventWebhookRetries        int
+	BatchExpiryWarning         uint64
+	ChequebookLowBalance       string
+	WalletLowBalance           string
+	DynamicPricing             bool
+	BudgetHourly               string
+	BudgetDaily                string
+	BudgetTotal                string
+	KeyBudgetHourly            string
+	KeyBudgetDaily             string
+	KeyBudgetTotal             string
+	AutoCashout                bool
+	AutoCashoutGasMultiple     float64
+	AutoCashoutInterval        time.Duration
+	NativeExchangeRate         string
+	ChequebookRefillFloor      string
+	ChequebookRefillTarget     string
+	ChequebookRefillCap        string
+	ChequebookRefillPeriod     time.Duration
+	SettlementDrivers          []string
+	ConsortiumMembers          []string
+	TrustedPeers               []string
+	SettlementHistoryRetention time.Duration
 }
 
 const (
 	refreshRate                   = int64(4500000)
 	lightRefreshRate              = int64(450000)
 	basePrice                     = 10000
+	maxInflightRetrievals         = 1000
 	postageSyncingStallingTimeout = 10 * time.Minute
 	postageSyncingBackoffTimeout  = 5 * time.Second
 )
@@ -216,6 +269,32 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 
 	addressbook := addressbook.New(stateStore)
 
+	eventsService := events.New(logger)
+	b.eventsCloser = eventsService
+
+	settlementHistory := history.New(stateStore, o.SettlementHistoryRetention)
+
+	walletLowBalance, err := parseOptionalAmount(o.WalletLowBalance)
+	if err != nil {
+		return nil, fmt.Errorf("wallet low balance: %w", err)
+	}
+	chequebookLowBalance, err := parseOptionalAmount(o.ChequebookLowBalance)
+	if err != nil {
+		return nil, fmt.Errorf("chequebook low balance: %w", err)
+	}
+	globalBudget, err := parseBudgetLimits(o.BudgetHourly, o.BudgetDaily, o.BudgetTotal)
+	if err != nil {
+		return nil, fmt.Errorf("budget: %w", err)
+	}
+	keyBudget, err := parseBudgetLimits(o.KeyBudgetHourly, o.KeyBudgetDaily, o.KeyBudgetTotal)
+	if err != nil {
+		return nil, fmt.Errorf("api key budget: %w", err)
+	}
+	refillPolicy, err := parseRefillPolicy(o.ChequebookRefillFloor, o.ChequebookRefillTarget, o.ChequebookRefillCap, o.ChequebookRefillPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("chequebook refill: %w", err)
+	}
+
 	var (
 		swapBackend        transaction.Backend
 		overlayEthAddress  common.Address
@@ -226,6 +305,7 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		chequebookService  chequebook.Service
 		chequeStore        chequebook.ChequeStore
 		cashoutService     chequebook.CashoutService
+		refiller           *chequebook.Refiller
 		pollingInterval    = time.Duration(o.BlockTime) * time.Second
 	)
 	swapBackend, overlayEthAddress, chainID, transactionMonitor, transactionService, err = InitChain(
@@ -235,6 +315,8 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		o.SwapEndpoint,
 		signer,
 		pollingInterval,
+		eventsService,
+		walletLowBalance,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("init chain: %w", err)
@@ -342,6 +424,8 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 			chequebookFactory,
 			o.SwapInitialDeposit,
 			o.DeployGasPrice,
+			eventsService,
+			chequebookLowBalance,
 		)
 		if err != nil {
 			return nil, err
@@ -355,6 +439,25 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 			overlayEthAddress,
 			transactionService,
 		)
+
+		if refillPolicy.Floor != nil {
+			erc20Address, err := chequebookFactory.ERC20Address(p2pCtx)
+			if err != nil {
+				return nil, fmt.Errorf("chequebook refill: %w", err)
+			}
+			refiller, err = chequebook.NewRefiller(
+				chequebookService,
+				erc20.New(swapBackend, transactionService, erc20Address),
+				overlayEthAddress,
+				stateStore,
+				refillPolicy,
+				logger,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("chequebook refill: %w", err)
+			}
+			b.refillerCloser = refiller
+		}
 	}
 
 	pubKey, _ := signer.PublicKey()
@@ -394,12 +497,25 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 	}
 
 	p2ps, err := libp2p.New(p2pCtx, signer, networkID, swarmAddress, addr, addressbook, stateStore, lightNodes, senderMatcher, logger, tracer, libp2p.Options{
-		PrivateKey:     libp2pPrivateKey,
-		NATAddr:        o.NATAddr,
-		EnableWS:       o.EnableWS,
-		WelcomeMessage: o.WelcomeMessage,
-		FullNode:       o.FullNodeMode,
-		Transaction:    txHash,
+		PrivateKey:        libp2pPrivateKey,
+		NATAddr:           o.NATAddr,
+		EnableWS:          o.EnableWS,
+		EnableQUIC:        o.EnableQUIC,
+		QUICAddr:          o.QUICAddr,
+		EnableMDNS:        o.EnableMDNS,
+		AllowPrivateCIDRs: o.AllowPrivateCIDRs,
+		WelcomeMessage:    o.WelcomeMessage,
+		FullNode:          o.FullNodeMode,
+		Transaction:       txHash,
+		InboundLimit:      o.P2PInboundLimit,
+		OutboundLimit:     o.P2POutboundLimit,
+		IPLimit:           o.P2PIPLimit,
+		SubnetLimit:       o.P2PSubnetLimit,
+		NetworkLimits:     o.P2PNetworkLimits,
+		PrivateNetworkKey: o.P2PNetworkKey,
+		BandwidthLimit:    o.P2PBandwidthLimit,
+		ProtocolLimits:    o.P2PProtocolBandwidthLimits,
+		Publisher:         eventsService,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("p2p service: %w", err)
@@ -413,7 +529,7 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		return err
 	}
 
-	batchStore, err := batchstore.New(stateStore, evictFn, logger)
+	batchStore, err := batchstore.New(stateStore, evictFn, eventsService, logger)
 	if err != nil {
 		return nil, fmt.Errorf("batchstore: %w", err)
 	}
@@ -473,7 +589,7 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 	eventListener = listener.New(logger, swapBackend, postageContractAddress, o.BlockTime, &pidKiller{node: b}, postageSyncingStallingTimeout, postageSyncingBackoffTimeout)
 	b.listenerCloser = eventListener
 
-	batchSvc, err = batchservice.New(stateStore, batchStore, logger, eventListener, overlayEthAddress.Bytes(), post, sha3.New256, o.Resync)
+	batchSvc, err = batchservice.New(stateStore, batchStore, logger, eventListener, overlayEthAddress.Bytes(), post, eventsService, o.BatchExpiryWarning, sha3.New256, o.Resync)
 	if err != nil {
 		return nil, err
 	}
@@ -543,14 +659,17 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		return nil, fmt.Errorf("unable to create metrics storage for kademlia: %w", err)
 	}
 
+	peerReputation := reputation.New()
+
 	kad, err := kademlia.New(swarmAddress, addressbook, hive, p2ps, pingPong, metricsDB, logger,
-		kademlia.Options{Bootnodes: bootnodes, BootnodeMode: o.BootnodeMode, StaticNodes: o.StaticNodes})
+		kademlia.Options{Bootnodes: bootnodes, BootnodeMode: o.BootnodeMode, StaticNodes: o.StaticNodes, Reputation: peerReputation, EnableQUIC: o.EnableQUIC, Publisher: eventsService})
 	if err != nil {
 		return nil, fmt.Errorf("unable to create kademlia: %w", err)
 	}
 	b.topologyCloser = kad
 	b.topologyHalter = kad
 	hive.SetAddPeersHandler(kad.AddPeers)
+	p2ps.SetAddPeersHandler(kad.AddPeers)
 	p2ps.SetPickyNotifier(kad)
 	batchStore.SetRadiusSetter(kad)
 
@@ -578,7 +697,13 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		return nil, fmt.Errorf("invalid payment threshold: %s", paymentThreshold)
 	}
 
-	pricer := pricer.NewFixedPricer(swarmAddress, basePrice)
+	var chunkPricer pricer.Interface = pricer.NewFixedPricer(swarmAddress, basePrice)
+	var dynamicPricer *pricer.DynamicPricer
+	if o.DynamicPricing {
+		dynamicPricer = pricer.NewDynamicPricer(swarmAddress, basePrice, logger)
+		b.pricerCloser = dynamicPricer
+		chunkPricer = dynamicPricer
+	}
 
 	if paymentThreshold.Cmp(minThreshold) < 0 {
 		return nil, fmt.Errorf("payment threshold below minimum generally accepted value, need at least %s", minThreshold)
@@ -589,10 +714,19 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 	}
 
 	pricing := pricing.New(p2ps, logger, paymentThreshold, minThreshold)
+	if dynamicPricer != nil {
+		pricing.SetPriceTableObserver(dynamicPricer)
+		dynamicPricer.SetBroadcaster(pricing)
+	}
 
 	if err = p2ps.AddProtocol(pricing.Protocol()); err != nil {
 		return nil, fmt.Errorf("pricing service: %w", err)
 	}
+	if dynamicPricer != nil {
+		if err = p2ps.AddProtocol(pricing.PriceTableProtocol()); err != nil {
+			return nil, fmt.Errorf("pricing service price tables: %w", err)
+		}
+	}
 
 	addrs, err := p2ps.Addresses()
 	if err != nil {
@@ -625,6 +759,15 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		return nil, fmt.Errorf("accounting: %w", err)
 	}
 	b.accountingCloser = acc
+	acc.SetBudgets(globalBudget, keyBudget)
+
+	trustedOverlays, trustedKeys, err := parseTrustedPeers(o.TrustedPeers)
+	if err != nil {
+		return nil, fmt.Errorf("trusted peers: %w", err)
+	}
+	if err = acc.SetTrustedPeers(trustedOverlays, trustedKeys); err != nil {
+		return nil, fmt.Errorf("trusted peers: %w", err)
+	}
 
 	var enforcedRefreshRate *big.Int
 
@@ -635,14 +778,18 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 	}
 
 	pseudosettleService := pseudosettle.New(p2ps, logger, stateStore, acc, enforcedRefreshRate, big.NewInt(lightRefreshRate), p2ps)
+	pseudosettleService.SetHistory(settlementHistory)
 	if err = p2ps.AddProtocol(pseudosettleService.Protocol()); err != nil {
 		return nil, fmt.Errorf("pseudosettle service: %w", err)
 	}
 
 	acc.SetRefreshFunc(pseudosettleService.Pay)
+	acc.SetDisputeFunc(func(peer swarm.Address, _ string) {
+		peerReputation.Dispute(peer)
+	})
 
+	var priceOracle priceoracle.Service
 	if o.SwapEnable {
-		var priceOracle priceoracle.Service
 		swapService, priceOracle, err = InitSwap(
 			p2ps,
 			logger,
@@ -661,12 +808,92 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 			return nil, err
 		}
 		b.priceOracleCloser = priceOracle
-		acc.SetPayFunc(swapService.Pay)
+		swapService.SetHistory(settlementHistory)
+
+		if o.NativeExchangeRate != "" {
+			nativeRate, ok := new(big.Float).SetString(o.NativeExchangeRate)
+			if !ok || nativeRate.Sign() <= 0 {
+				return nil, fmt.Errorf("invalid native exchange rate %q", o.NativeExchangeRate)
+			}
+			priceOracle.SetNativeRate(nativeRate)
+		}
+
+		if o.AutoCashout {
+			if o.NativeExchangeRate == "" {
+				return nil, errors.New("auto cashout needs the native exchange rate to value gas costs")
+			}
+			b.autoCashoutCloser = chequebook.NewAutoCashout(
+				stateStore,
+				swapBackend,
+				cashoutService,
+				chequeStore,
+				chequebookService.Address(),
+				settlementHistory,
+				priceOracle,
+				o.AutoCashoutGasMultiple,
+				o.AutoCashoutInterval,
+				logger,
+			)
+		}
+	}
+
+	consortiumMembers, err := parseConsortiumMembers(o.ConsortiumMembers)
+	if err != nil {
+		return nil, fmt.Errorf("consortium members: %w", err)
+	}
+
+	settlementDrivers := driver.New(p2ps, stateStore, logger, []string{swap.DriverName})
+	settlementDrivers.SetAccounting(acc)
+	b.settlementDriversCloser = settlementDrivers
+	for _, name := range o.SettlementDrivers {
+		// swap is opened by InitSwap as the node uses it beyond payments
+		if name == swap.DriverName {
+			if swapService == nil {
+				logger.Debugf("settlement driver %s: swap disabled", name)
+				continue
+			}
+			if err = settlementDrivers.Add(swapService); err != nil {
+				return nil, fmt.Errorf("settlement driver: %w", err)
+			}
+			continue
+		}
+		d, err := driver.Open(name, driver.Options{
+			Overlay:    swarmAddress,
+			Signer:     signer,
+			Streamer:   p2ps,
+			Store:      stateStore,
+			Accounting: acc,
+			Logger:     logger,
+			Members:    consortiumMembers,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("settlement driver: %w", err)
+		}
+		if err = settlementDrivers.Add(d); err != nil {
+			return nil, fmt.Errorf("settlement driver: %w", err)
+		}
+		if pd, ok := d.(driver.ProtocolDriver); ok {
+			if err = p2ps.AddProtocol(pd.Protocol()); err != nil {
+				return nil, fmt.Errorf("settlement driver %s: %w", name, err)
+			}
+		}
+	}
+	if len(settlementDrivers.Names()) > 0 {
+		if err = p2ps.AddProtocol(settlementDrivers.Protocol()); err != nil {
+			return nil, fmt.Errorf("settlement driver service: %w", err)
+		}
+		acc.SetPayFunc(settlementDrivers.Pay)
 	}
 
 	pricing.SetPaymentThresholdObserver(acc)
 
-	retrieve := retrieval.New(swarmAddress, storer, p2ps, kad, logger, acc, pricer, tracer, o.RetrievalCaching, validStamp)
+	retrieve := retrieval.New(swarmAddress, storer, p2ps, kad, logger, acc, chunkPricer, tracer, o.RetrievalCaching, validStamp)
+	retrieve.SetReputation(peerReputation)
+	if dynamicPricer != nil {
+		dynamicPricer.SetLoad(pricer.LoadFunc(func() float64 {
+			return float64(retrieve.InflightRequests()) / maxInflightRetrievals
+		}))
+	}
 	tagService := tags.NewTags(stateStore, logger)
 	b.tagsCloser = tagService
 
@@ -687,7 +914,8 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 
 	pinningService := pinning.NewService(storer, stateStore, traversalService)
 
-	pushSyncProtocol := pushsync.New(swarmAddress, blockHash, p2ps, storer, kad, tagService, o.FullNodeMode, pssService.TryUnwrap, validStamp, logger, acc, pricer, signer, tracer, warmupTime)
+	pushSyncProtocol := pushsync.New(swarmAddress, blockHash, p2ps, storer, kad, tagService, o.FullNodeMode, pssService.TryUnwrap, validStamp, logger, acc, chunkPricer, signer, tracer, warmupTime)
+	pushSyncProtocol.SetReputation(peerReputation)
 
 	// set the pushSyncer in the PSS
 	pssService.SetPushSyncer(pushSyncProtocol)
@@ -763,11 +991,17 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		var chunkC <-chan *pusher.Op
 		feedFactory := factory.New(ns)
 		steward := steward.New(storer, traversalService, retrieve, pushSyncProtocol)
+		var batchSelector postage.BatchSelector
+		if o.BatchSelectionPolicy != 0 {
+			batchSelector = postage.NewBatchSelector(post, batchStore, o.BatchSelectionPolicy, o.DefaultBatchID)
+		}
 		apiService, chunkC = api.New(tagService, ns, multiResolver, pssService, traversalService, pinningService, feedFactory, post, postageContractService, steward, signer, authenticator, logger, tracer, api.Options{
 			CORSAllowedOrigins: o.CORSAllowedOrigins,
 			GatewayMode:        o.GatewayMode,
 			WsPingPeriod:       60 * time.Second,
 			Restricted:         o.Restricted,
+			BatchSelector:      batchSelector,
+			PushFailures:       pusherService,
 		})
 		pusherService.AddFeed(chunkC)
 		apiListener, err := net.Listen("tcp", o.APIAddr)
@@ -795,6 +1029,16 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		b.apiCloser = apiService
 	}
 
+	var webhooks *events.Webhooks
+	if len(o.EventWebhooks) > 0 {
+		webhooks = events.NewWebhooks(eventsService, o.EventWebhooks, nil, logger, events.WebhookOptions{
+			Retries: o.EventWebhookRetries,
+			// peer lifecycle events are too frequent to be posted
+			Types: events.AlertTypes(),
+		})
+		b.webhooksCloser = webhooks
+	}
+
 	if debugAPIService != nil {
 		// register metrics from components
 		debugAPIService.MustRegisterMetrics(p2ps.Metrics()...)
@@ -848,8 +1092,12 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		if chainSyncer != nil {
 			debugAPIService.MustRegisterMetrics(chainSyncer.Metrics()...)
 		}
+		debugAPIService.MustRegisterMetrics(eventsService.Metrics()...)
+		if webhooks != nil {
+			debugAPIService.MustRegisterMetrics(webhooks.Metrics()...)
+		}
 		// inject dependencies and configure full debug api http path routes
-		debugAPIService.Configure(swarmAddress, p2ps, pingPong, kad, lightNodes, storer, tagService, acc, pseudosettleService, o.SwapEnable, swapService, chequebookService, batchStore, post, postageContractService, traversalService)
+		debugAPIService.Configure(swarmAddress, p2ps, pingPong, kad, lightNodes, storer, tagService, acc, pseudosettleService, o.SwapEnable, swapService, chequebookService, refiller, settlementHistory, settlementDrivers, priceOracle, batchStore, post, postageContractService, traversalService, eventsService)
 	}
 
 	if err := kad.Start(p2pCtx); err != nil {
@@ -891,6 +1139,8 @@ func (b *Bee) Shutdown(ctx context.Context) error {
 	}
 
 	tryClose(b.apiCloser, "api")
+	tryClose(b.webhooksCloser, "event webhooks")
+	tryClose(b.eventsCloser, "events")
 
 	var eg errgroup.Group
 	if b.apiServer != nil {
@@ -954,6 +1204,10 @@ func (b *Bee) Shutdown(ctx context.Context) error {
 
 	tryClose(b.p2pService, "p2p server")
 	tryClose(b.priceOracleCloser, "price oracle service")
+	tryClose(b.pricerCloser, "pricer")
+	tryClose(b.autoCashoutCloser, "auto cashout")
+	tryClose(b.refillerCloser, "chequebook refiller")
+	tryClose(b.settlementDriversCloser, "settlement drivers")
 
 	wg.Add(3)
 	go func() {
@@ -1012,3 +1266,83 @@ func (p *pidKiller) Shutdown(ctx context.Context) error {
 	}
 	return ps.Kill()
 }
+
+// parseOptionalAmount parses an amount in the smallest unit of its currency.
+// An empty string leaves the amount unset.
+func parseOptionalAmount(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("\"%s\" cannot be parsed", s)
+	}
+	return v, nil
+}
+
+// parseBudgetLimits parses the hourly, daily and total limits of a spending
+// budget. Empty strings leave the respective limit unset.
+func parseBudgetLimits(hourly, daily, total string) (limits accounting.BudgetLimits, err error) {
+	if limits.Hourly, err = parseOptionalAmount(hourly); err != nil {
+		return limits, fmt.Errorf("hourly: %w", err)
+	}
+	if limits.Daily, err = parseOptionalAmount(daily); err != nil {
+		return limits, fmt.Errorf("daily: %w", err)
+	}
+	if limits.Total, err = parseOptionalAmount(total); err != nil {
+		return limits, fmt.Errorf("total: %w", err)
+	}
+	return limits, nil
+}
+
+// parseTrustedPeers parses hex encoded overlays and public keys of trusted
+// peers.
+func parseTrustedPeers(peers []string) (overlays []swarm.Address, publicKeys []*ecdsa.PublicKey, err error) {
+	for _, p := range peers {
+		b, err := hex.DecodeString(strings.TrimPrefix(p, "0x"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", p, err)
+		}
+		if len(b) == swarm.HashSize {
+			overlays = append(overlays, swarm.NewAddress(b))
+			continue
+		}
+		publicKey, err := btcec.ParsePubKey(b, btcec.S256())
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: neither overlay nor public key: %w", p, err)
+		}
+		publicKeys = append(publicKeys, (*ecdsa.PublicKey)(publicKey))
+	}
+	return overlays, publicKeys, nil
+}
+
+// parseConsortiumMembers parses the hex encoded Ethereum addresses of the
+// consortium members.
+func parseConsortiumMembers(members []string) (addresses []common.Address, err error) {
+	for _, m := range members {
+		if !common.IsHexAddress(m) {
+			return nil, fmt.Errorf("%s: invalid ethereum address", m)
+		}
+		addresses = append(addresses, common.HexToAddress(m))
+	}
+	return addresses, nil
+}
+
+// parseRefillPolicy parses the chequebook refill policy. Refilling is disabled
+// if no floor is set, in which case the returned policy has a nil floor.
+func parseRefillPolicy(floor, target, limit string, period time.Duration) (policy chequebook.RefillPolicy, err error) {
+	if policy.Floor, err = parseOptionalAmount(floor); err != nil {
+		return policy, fmt.Errorf("floor: %w", err)
+	}
+	if policy.Floor == nil {
+		return policy, nil
+	}
+	if policy.Target, err = parseOptionalAmount(target); err != nil {
+		return policy, fmt.Errorf("target: %w", err)
+	}
+	if policy.Cap, err = parseOptionalAmount(limit); err != nil {
+		return policy, fmt.Errorf("cap: %w", err)
+	}
+	policy.Period = period
+	return policy, nil
+}
@@ -18,6 +18,9 @@ var (
 	ErrAlreadyConnected = errors.New("already connected")
 	// ErrDialLightNode is returned if connect was attempted to a light node.
 	ErrDialLightNode = errors.New("target peer is a light node")
+	// ErrNotBlocklisted is returned when lifting the ban of a peer or a
+	// network which is not blocklisted.
+	ErrNotBlocklisted = errors.New("not blocklisted")
 )
 
 const (
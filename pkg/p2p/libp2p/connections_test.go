@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/holisticode/bee/pkg/addressbook"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/p2p"
 	"github.com/holisticode/bee/pkg/p2p/libp2p"
@@ -79,6 +80,66 @@ func TestConnectDisconnect(t *testing.T) {
 	expectPeersEventually(t, s1)
 }
 
+func TestConnectDisconnectPublishEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newPublisher := func() (events.Publisher, <-chan events.Event) {
+		c := make(chan events.Event, 10)
+		return events.PublisherFunc(func(e events.Event) { c <- e }), c
+	}
+	receive := func(c <-chan events.Event, typ events.Type) events.PeerData {
+		t.Helper()
+		select {
+		case e := <-c:
+			if e.Type != typ {
+				t.Fatalf("got %s event, want %s", e.Type, typ)
+			}
+			return e.Data.(events.PeerData)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %s event", typ)
+		}
+		return events.PeerData{}
+	}
+
+	p1, c1 := newPublisher()
+	p2, c2 := newPublisher()
+	s1, overlay1 := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		FullNode:  true,
+		Publisher: p1,
+	}})
+	s2, overlay2 := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		Publisher: p2,
+	}})
+
+	if _, err := s2.Connect(ctx, serviceUnderlayAddress(t, s1)); err != nil {
+		t.Fatal(err)
+	}
+
+	out := receive(c2, events.PeerConnected)
+	if !out.Overlay.Equal(overlay1) || out.Direction != events.DirectionOutbound || out.Underlay == "" {
+		t.Fatalf("unexpected outbound connection event data %+v", out)
+	}
+	if bin := swarm.Proximity(overlay2.Bytes(), overlay1.Bytes()); out.Bin != bin {
+		t.Fatalf("got bin %d, want %d", out.Bin, bin)
+	}
+	in := receive(c1, events.PeerConnected)
+	if !in.Overlay.Equal(overlay2) || in.Direction != events.DirectionInbound {
+		t.Fatalf("unexpected inbound connection event data %+v", in)
+	}
+
+	if err := s2.Disconnect(overlay1, testDisconnectMsg); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := receive(c2, events.PeerDisconnected); !d.Overlay.Equal(overlay1) || d.Reason != testDisconnectMsg {
+		t.Fatalf("unexpected disconnection event data %+v", d)
+	}
+	if d := receive(c1, events.PeerDisconnected); !d.Overlay.Equal(overlay2) {
+		t.Fatalf("unexpected remote disconnection event data %+v", d)
+	}
+}
+
 func TestConnectToLightPeer(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -362,6 +423,58 @@ func TestConnectWithEnabledWSTransports(t *testing.T) {
 	expectPeersEventually(t, s1, overlay2)
 }
 
+func TestConnectWithEnabledQUICTransport(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2pServiceOpts{
+		libp2pOpts: libp2p.Options{
+			EnableQUIC: true,
+			FullNode:   true,
+		},
+		addr: "127.0.0.1:0",
+	})
+
+	s2, overlay2 := newService(t, 1, libp2pServiceOpts{
+		libp2pOpts: libp2p.Options{
+			EnableQUIC: true,
+			FullNode:   true,
+		},
+		addr: "127.0.0.1:0",
+	})
+
+	s3, _ := newService(t, 1, libp2pServiceOpts{
+		libp2pOpts: libp2p.Options{
+			EnableQUIC: true,
+		},
+		addr: "127.0.0.1:0",
+	})
+
+	addr := serviceLoopbackAddress(t, s1, ma.P_TCP)
+
+	bzzAddr, err := s2.Connect(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectPeers(t, s2, overlay1)
+	expectPeersEventually(t, s1, overlay2)
+
+	// the advertised QUIC underlay is the one s1 listens on
+	quicAddr := serviceLoopbackAddress(t, s1, ma.P_QUIC)
+	if bzzAddr.QUICUnderlay == nil {
+		t.Fatal("quic underlay not advertised")
+	}
+	if !bzzAddr.QUICUnderlay.Equal(quicAddr) {
+		t.Fatalf("got advertised quic underlay %s, want %s", bzzAddr.QUICUnderlay, quicAddr)
+	}
+
+	if _, err := s3.Connect(ctx, quicAddr); err != nil {
+		t.Fatal(err)
+	}
+	expectPeers(t, s3, overlay1)
+}
+
 // TestConnectRepeatHandshake tests if handshake was attempted more then once by the same peer
 func TestConnectRepeatHandshake(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -12,6 +12,7 @@ import (
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/network"
 	libp2ppeer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
 )
 
 func (s *Service) HandshakeService() *handshake.Service {
@@ -37,3 +38,7 @@ func WithHostFactory(factory func(...libp2pm.Option) (host.Host, error)) Options
 }
 
 var UserAgent = userAgent
+
+func MDNSUnderlay(info libp2ppeer.AddrInfo, allowPrivateCIDRs bool) (ma.Multiaddr, error) {
+	return (&mdnsNotifee{allowPrivateCIDRs: allowPrivateCIDRs}).underlay(info)
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p
+
+import (
+	"github.com/holisticode/bee/pkg/p2p/libp2p/internal/blocklist"
+	"github.com/holisticode/bee/pkg/p2p/libp2p/internal/connlimit"
+	"github.com/libp2p/go-libp2p-core/connmgr"
+	"github.com/libp2p/go-libp2p-core/control"
+	"github.com/libp2p/go-libp2p-core/network"
+	libp2ppeer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+var _ connmgr.ConnectionGater = (*connectionGater)(nil)
+
+// connectionGater rejects connections which exceed the connection limits or
+// have addresses in blocklisted networks before they are set up and counts the
+// open connections.
+type connectionGater struct {
+	limiter   *connlimit.Limiter
+	blocklist *blocklist.Blocklist
+	metrics   metrics
+	network.Notifiee
+}
+
+func newConnectionGater(limiter *connlimit.Limiter, bl *blocklist.Blocklist, m metrics) *connectionGater {
+	return &connectionGater{
+		limiter:   limiter,
+		blocklist: bl,
+		metrics:   m,
+		Notifiee:  new(network.NoopNotifiee),
+	}
+}
+
+func (g *connectionGater) InterceptPeerDial(libp2ppeer.ID) bool {
+	return true
+}
+
+func (g *connectionGater) InterceptAddrDial(_ libp2ppeer.ID, addr ma.Multiaddr) bool {
+	if g.banned(addr) {
+		return false
+	}
+	if !g.limiter.Allow(network.DirOutbound, addr) {
+		g.metrics.ConnectionLimitRejectCount.Inc()
+		return false
+	}
+	return true
+}
+
+func (g *connectionGater) InterceptAccept(addrs network.ConnMultiaddrs) bool {
+	if g.banned(addrs.RemoteMultiaddr()) {
+		return false
+	}
+	if !g.limiter.Allow(network.DirInbound, addrs.RemoteMultiaddr()) {
+		g.metrics.ConnectionLimitRejectCount.Inc()
+		return false
+	}
+	return true
+}
+
+// banned returns true if the IP address of addr is in a blocklisted network.
+// Blocklist errors do not reject the connection, the peer is checked again
+// after the handshake.
+func (g *connectionGater) banned(addr ma.Multiaddr) bool {
+	ip, err := manet.ToIP(addr)
+	if err != nil {
+		return false
+	}
+	if banned, err := g.blocklist.IPExists(ip); err != nil || !banned {
+		return false
+	}
+	g.metrics.NetworkBanRejectCount.Inc()
+	return true
+}
+
+func (g *connectionGater) InterceptSecured(network.Direction, libp2ppeer.ID, network.ConnMultiaddrs) bool {
+	return true
+}
+
+func (g *connectionGater) InterceptUpgraded(network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
+
+func (g *connectionGater) Connected(_ network.Network, c network.Conn) {
+	g.limiter.Add(c.Stat().Direction, c.RemoteMultiaddr())
+}
+
+func (g *connectionGater) Disconnected(_ network.Network, c network.Conn) {
+	g.limiter.Remove(c.Stat().Direction, c.RemoteMultiaddr())
+}
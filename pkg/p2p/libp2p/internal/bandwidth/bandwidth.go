@@ -0,0 +1,229 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bandwidth counts the bytes exchanged with peers per protocol and
+// limits the traffic with token buckets, globally and per protocol. The
+// protocol limits keep bulk protocols like pullsync from starving the
+// others, e.g. retrieval, on metered links.
+package bandwidth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/holisticode/bee/pkg/swarm"
+	"golang.org/x/time/rate"
+)
+
+// ErrInvalidProtocolLimit is returned by ParseProtocolLimit for a malformed
+// protocol limit.
+var ErrInvalidProtocolLimit = errors.New("invalid protocol limit")
+
+// ProtocolLimit limits the traffic of the protocol to Limit bytes per
+// second.
+type ProtocolLimit struct {
+	Protocol string
+	Limit    int
+}
+
+// ParseProtocolLimit parses a protocol limit given in the protocol=limit
+// format, e.g. pullsync=1048576.
+func ParseProtocolLimit(s string) (ProtocolLimit, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return ProtocolLimit{}, fmt.Errorf("%w: %q", ErrInvalidProtocolLimit, s)
+	}
+	protocol := strings.TrimSpace(s[:i])
+	if protocol == "" {
+		return ProtocolLimit{}, fmt.Errorf("%w: %q: empty protocol", ErrInvalidProtocolLimit, s)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(s[i+1:]))
+	if err != nil || limit < 0 {
+		return ProtocolLimit{}, fmt.Errorf("%w: %q: limit must be a non negative integer", ErrInvalidProtocolLimit, s)
+	}
+	return ProtocolLimit{Protocol: protocol, Limit: limit}, nil
+}
+
+// Options are the bandwidth limits in bytes per second of the traffic in
+// both directions, zero limits are unlimited.
+type Options struct {
+	// Limit limits the traffic of all protocols together.
+	Limit int
+	// Protocols limit the traffic of each of the protocols.
+	Protocols []ProtocolLimit
+}
+
+// Stat is the number of bytes exchanged with a peer over a protocol.
+type Stat struct {
+	Protocol string
+	In       uint64
+	Out      uint64
+}
+
+// Meter counts and limits the traffic with all peers.
+type Meter struct {
+	limiter   *rate.Limiter
+	protocols map[string]*rate.Limiter
+
+	mu    sync.Mutex
+	peers map[string]map[string]*Account // overlay -> protocol -> account
+}
+
+// New creates a new Meter.
+func New(o Options) *Meter {
+	m := &Meter{
+		limiter:   newLimiter(o.Limit),
+		protocols: make(map[string]*rate.Limiter),
+		peers:     make(map[string]map[string]*Account),
+	}
+	for _, p := range o.Protocols {
+		if l := newLimiter(p.Limit); l != nil {
+			m.protocols[p.Protocol] = l
+		}
+	}
+	return m
+}
+
+// newLimiter returns a token bucket allowing limit bytes per second with a
+// burst of one second worth of traffic, or nil for the zero limit.
+func newLimiter(limit int) *rate.Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(limit), limit)
+}
+
+// Account returns the account of the traffic with the peer over the
+// protocol, creating it if needed.
+func (m *Meter) Account(overlay swarm.Address, protocol string) *Account {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := overlay.ByteString()
+	accounts, ok := m.peers[key]
+	if !ok {
+		accounts = make(map[string]*Account)
+		m.peers[key] = accounts
+	}
+	a, ok := accounts[protocol]
+	if !ok {
+		a = &Account{}
+		if m.limiter != nil {
+			a.limiters = append(a.limiters, m.limiter)
+		}
+		if l, ok := m.protocols[protocol]; ok {
+			a.limiters = append(a.limiters, l)
+		}
+		accounts[protocol] = a
+	}
+	return a
+}
+
+// Peer returns the traffic with the peer per protocol, sorted by protocol.
+func (m *Meter) Peer(overlay swarm.Address) []Stat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	accounts := m.peers[overlay.ByteString()]
+	stats := make([]Stat, 0, len(accounts))
+	for protocol, a := range accounts {
+		stats = append(stats, Stat{
+			Protocol: protocol,
+			In:       atomic.LoadUint64(&a.in),
+			Out:      atomic.LoadUint64(&a.out),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Protocol < stats[j].Protocol
+	})
+	return stats
+}
+
+// Remove forgets the traffic with the peer.
+func (m *Meter) Remove(overlay swarm.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.peers, overlay.ByteString())
+}
+
+// Account counts the traffic with a single peer over a single protocol and
+// applies the limits of the protocol.
+type Account struct {
+	in       uint64
+	out      uint64
+	limiters []*rate.Limiter
+}
+
+// Received counts n received bytes.
+func (a *Account) Received(n int) {
+	atomic.AddUint64(&a.in, uint64(n))
+}
+
+// Sent counts n sent bytes.
+func (a *Account) Sent(n int) {
+	atomic.AddUint64(&a.out, uint64(n))
+}
+
+// Limited returns whether the traffic of the account is rate limited.
+func (a *Account) Limited() bool {
+	return len(a.limiters) > 0
+}
+
+// Wait blocks until the limits allow n bytes of traffic or the context is
+// done.
+func (a *Account) Wait(ctx context.Context, n int) error {
+	for _, l := range a.limiters {
+		// a single wait can not exceed the burst of the limiter
+		for n := n; n > 0; {
+			c := chunk(l, n)
+			if err := l.WaitN(ctx, c); err != nil {
+				return err
+			}
+			n -= c
+		}
+	}
+	return nil
+}
+
+// Ready blocks until the traffic charged to the limits is paid off or the
+// context is done. It is called before traffic of unknown size, like a read,
+// which is charged afterwards.
+func (a *Account) Ready(ctx context.Context) error {
+	for _, l := range a.limiters {
+		if err := l.WaitN(ctx, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Charge charges n bytes of traffic which already happened to the limits,
+// delaying the following traffic until they are paid off.
+func (a *Account) Charge(n int) {
+	now := time.Now()
+	for _, l := range a.limiters {
+		for n := n; n > 0; {
+			c := chunk(l, n)
+			l.ReserveN(now, c)
+			n -= c
+		}
+	}
+}
+
+// chunk returns the part of the n bytes which fits into the burst of the
+// limiter.
+func chunk(l *rate.Limiter, n int) int {
+	if b := l.Burst(); n > b {
+		return b
+	}
+	return n
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bandwidth_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/holisticode/bee/pkg/p2p/libp2p/internal/bandwidth"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+func TestParseProtocolLimit(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want bandwidth.ProtocolLimit
+		err  error
+	}{
+		{in: "pullsync=1048576", want: bandwidth.ProtocolLimit{Protocol: "pullsync", Limit: 1048576}},
+		{in: " retrieval = 0 ", want: bandwidth.ProtocolLimit{Protocol: "retrieval", Limit: 0}},
+		{in: "pullsync", err: bandwidth.ErrInvalidProtocolLimit},
+		{in: "=10", err: bandwidth.ErrInvalidProtocolLimit},
+		{in: "pullsync=-1", err: bandwidth.ErrInvalidProtocolLimit},
+		{in: "pullsync=fast", err: bandwidth.ErrInvalidProtocolLimit},
+	} {
+		got, err := bandwidth.ParseProtocolLimit(tc.in)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("%q: got error %v, want %v", tc.in, err, tc.err)
+		}
+		if got != tc.want {
+			t.Fatalf("%q: got %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestAccounting(t *testing.T) {
+	m := bandwidth.New(bandwidth.Options{})
+
+	a := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	b := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59d")
+
+	m.Account(a, "retrieval").Received(10)
+	m.Account(a, "retrieval").Sent(4)
+	m.Account(a, "hive").Sent(7)
+	m.Account(b, "pullsync").Received(100)
+
+	want := []bandwidth.Stat{
+		{Protocol: "hive", Out: 7},
+		{Protocol: "retrieval", In: 10, Out: 4},
+	}
+	if got := m.Peer(a); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	m.Remove(a)
+	if got := m.Peer(a); len(got) != 0 {
+		t.Fatalf("got %+v after remove, want none", got)
+	}
+	if got := m.Peer(b); len(got) != 1 || got[0].In != 100 {
+		t.Fatalf("got %+v, want the traffic of the other peer to be kept", got)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	m := bandwidth.New(bandwidth.Options{
+		Protocols: []bandwidth.ProtocolLimit{{Protocol: "pullsync", Limit: 1000}},
+	})
+	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+
+	retrieval := m.Account(overlay, "retrieval")
+	if retrieval.Limited() {
+		t.Fatal("protocol without a limit is limited")
+	}
+
+	pullsync := m.Account(overlay, "pullsync")
+	if !pullsync.Limited() {
+		t.Fatal("protocol with a limit is not limited")
+	}
+
+	ctx := context.Background()
+
+	// the burst is allowed right away
+	if err := pullsync.Wait(ctx, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	// the bucket is empty, so more traffic has to wait
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := pullsync.Wait(ctx, 500); err == nil {
+		t.Fatal("traffic over the limit was allowed")
+	}
+
+	// other protocols are not affected
+	if err := retrieval.Wait(ctx, 1<<20); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGlobalLimit(t *testing.T) {
+	m := bandwidth.New(bandwidth.Options{Limit: 100})
+	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+
+	if err := m.Account(overlay, "retrieval").Wait(context.Background(), 100); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := m.Account(overlay, "hive").Wait(ctx, 100); err == nil {
+		t.Fatal("global limit is not shared by the protocols")
+	}
+}
+
+func TestCharge(t *testing.T) {
+	m := bandwidth.New(bandwidth.Options{Limit: 100})
+	a := m.Account(swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c"), "retrieval")
+
+	if err := a.Ready(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// traffic over the burst puts the limits into debt
+	a.Charge(250)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := a.Ready(ctx); err == nil {
+		t.Fatal("traffic was allowed before the charged traffic was paid off")
+	}
+}
@@ -5,6 +5,7 @@
 package blocklist
 
 import (
+	"net"
 	"strings"
 	"time"
 
@@ -13,7 +14,10 @@ import (
 	"github.com/holisticode/bee/pkg/swarm"
 )
 
-var keyPrefix = "blocklist-"
+var (
+	keyPrefix        = "blocklist-"
+	networkKeyPrefix = "blocklistnet-"
+)
 
 // timeNow is used to deterministically mock time.Now() in tests.
 var timeNow = time.Now
@@ -29,12 +33,39 @@ func NewBlocklist(store storage.StateStorer) *Blocklist {
 type entry struct {
 	Timestamp time.Time `json:"timestamp"`
 	Duration  string    `json:"duration"` // Duration is string because the time.Duration does not implement MarshalJSON/UnmarshalJSON methods.
+	Reason    string    `json:"reason,omitempty"`
+	Subsystem string    `json:"subsystem,omitempty"`
 }
 
-func (b *Blocklist) Exists(overlay swarm.Address) (bool, error) {
-	key := generateKey(overlay)
-	timestamp, duration, err := b.get(key)
+func (e *entry) expired() (bool, error) {
+	duration, err := time.ParseDuration(e.Duration)
 	if err != nil {
+		return false, err
+	}
+	// using timeNow.Sub() so it can be mocked in unit tests
+	return timeNow().Sub(e.Timestamp) > duration && duration != 0, nil
+}
+
+func (e *entry) blocklistEntry() (p2p.BlocklistEntry, error) {
+	duration, err := time.ParseDuration(e.Duration)
+	if err != nil {
+		return p2p.BlocklistEntry{}, err
+	}
+	return p2p.BlocklistEntry{
+		Reason:    e.Reason,
+		Subsystem: e.Subsystem,
+		Timestamp: e.Timestamp,
+		Duration:  duration,
+	}, nil
+}
+
+func (b *Blocklist) Exists(overlay swarm.Address) (bool, error) {
+	return b.exists(generateKey(overlay))
+}
+
+func (b *Blocklist) exists(key string) (bool, error) {
+	var e entry
+	if err := b.store.Get(key, &e); err != nil {
 		if err == storage.ErrNotFound {
 			return false, nil
 		}
@@ -42,8 +73,11 @@ func (b *Blocklist) Exists(overlay swarm.Address) (bool, error) {
 		return false, err
 	}
 
-	// using timeNow.Sub() so it can be mocked in unit tests
-	if timeNow().Sub(timestamp) > duration && duration != 0 {
+	expired, err := e.expired()
+	if err != nil {
+		return false, err
+	}
+	if expired {
 		_ = b.store.Delete(key)
 		return false, nil
 	}
@@ -51,8 +85,14 @@ func (b *Blocklist) Exists(overlay swarm.Address) (bool, error) {
 	return true, nil
 }
 
-func (b *Blocklist) Add(overlay swarm.Address, duration time.Duration) (err error) {
-	key := generateKey(overlay)
+// Add blocklists the peer for the duration, zero duration blocklists the peer
+// forever. The reason and the subsystem which initiated the blocklisting are
+// recorded with the entry.
+func (b *Blocklist) Add(overlay swarm.Address, duration time.Duration, reason, subsystem string) (err error) {
+	return b.add(generateKey(overlay), duration, reason, subsystem)
+}
+
+func (b *Blocklist) add(key string, duration time.Duration, reason, subsystem string) (err error) {
 	_, d, err := b.get(key)
 	if err != nil {
 		if err != storage.ErrNotFound {
@@ -68,12 +108,46 @@ func (b *Blocklist) Add(overlay swarm.Address, duration time.Duration) (err erro
 	return b.store.Put(key, &entry{
 		Timestamp: timeNow(),
 		Duration:  duration.String(),
+		Reason:    reason,
+		Subsystem: subsystem,
 	})
 }
 
+// Remove lifts the ban of the peer. It returns p2p.ErrNotBlocklisted if the
+// peer is not blocklisted.
+func (b *Blocklist) Remove(overlay swarm.Address) error {
+	return b.remove(generateKey(overlay))
+}
+
+func (b *Blocklist) remove(key string) error {
+	exists, err := b.exists(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return p2p.ErrNotBlocklisted
+	}
+	return b.store.Delete(key)
+}
+
 // Peers returns all currently blocklisted peers.
 func (b *Blocklist) Peers() ([]p2p.Peer, error) {
-	var peers []p2p.Peer
+	entries, err := b.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make([]p2p.Peer, 0, len(entries))
+	for _, e := range entries {
+		peers = append(peers, e.Peer)
+	}
+	return peers, nil
+}
+
+// Entries returns all currently blocklisted peers together with the details
+// of their blocklisting.
+func (b *Blocklist) Entries() ([]p2p.BlocklistedPeer, error) {
+	var peers []p2p.BlocklistedPeer
 	if err := b.store.Iterate(keyPrefix, func(k, v []byte) (bool, error) {
 		if !strings.HasPrefix(string(k), keyPrefix) {
 			return true, nil
@@ -83,18 +157,19 @@ func (b *Blocklist) Peers() ([]p2p.Peer, error) {
 			return true, err
 		}
 
-		t, d, err := b.get(string(k))
+		e, ok, err := b.entry(string(k))
 		if err != nil {
 			return true, err
 		}
-
-		if timeNow().Sub(t) > d && d != 0 {
+		if !ok {
 			// skip to the next item
 			return false, nil
 		}
 
-		p := p2p.Peer{Address: addr}
-		peers = append(peers, p)
+		peers = append(peers, p2p.BlocklistedPeer{
+			Peer:           p2p.Peer{Address: addr},
+			BlocklistEntry: e,
+		})
 		return false, nil
 	}); err != nil {
 		return nil, err
@@ -103,6 +178,87 @@ func (b *Blocklist) Peers() ([]p2p.Peer, error) {
 	return peers, nil
 }
 
+// AddNetwork blocklists all the underlay IP addresses in the network for the
+// duration, zero duration blocklists the network forever.
+func (b *Blocklist) AddNetwork(network *net.IPNet, duration time.Duration, reason, subsystem string) error {
+	return b.add(generateNetworkKey(network), duration, reason, subsystem)
+}
+
+// RemoveNetwork lifts the ban of the network. It returns
+// p2p.ErrNotBlocklisted if the network is not blocklisted.
+func (b *Blocklist) RemoveNetwork(network *net.IPNet) error {
+	return b.remove(generateNetworkKey(network))
+}
+
+// Networks returns all currently blocklisted networks.
+func (b *Blocklist) Networks() ([]p2p.BlocklistedNetwork, error) {
+	var networks []p2p.BlocklistedNetwork
+	if err := b.store.Iterate(networkKeyPrefix, func(k, v []byte) (bool, error) {
+		if !strings.HasPrefix(string(k), networkKeyPrefix) {
+			return true, nil
+		}
+		_, network, err := net.ParseCIDR(strings.TrimPrefix(string(k), networkKeyPrefix))
+		if err != nil {
+			return true, err
+		}
+
+		e, ok, err := b.entry(string(k))
+		if err != nil {
+			return true, err
+		}
+		if !ok {
+			return false, nil
+		}
+
+		networks = append(networks, p2p.BlocklistedNetwork{
+			Network:        network,
+			BlocklistEntry: e,
+		})
+		return false, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return networks, nil
+}
+
+// IPExists returns true if the IP address is part of a blocklisted network.
+func (b *Blocklist) IPExists(ip net.IP) (bool, error) {
+	networks, err := b.Networks()
+	if err != nil {
+		return false, err
+	}
+	for _, n := range networks {
+		if n.Network.Contains(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// entry returns the blocklist entry under the key, the returned bool is false
+// if the entry is expired.
+func (b *Blocklist) entry(key string) (p2p.BlocklistEntry, bool, error) {
+	var e entry
+	if err := b.store.Get(key, &e); err != nil {
+		return p2p.BlocklistEntry{}, false, err
+	}
+
+	expired, err := e.expired()
+	if err != nil {
+		return p2p.BlocklistEntry{}, false, err
+	}
+	if expired {
+		return p2p.BlocklistEntry{}, false, nil
+	}
+
+	be, err := e.blocklistEntry()
+	if err != nil {
+		return p2p.BlocklistEntry{}, false, err
+	}
+	return be, true, nil
+}
+
 func (b *Blocklist) get(key string) (timestamp time.Time, duration time.Duration, err error) {
 	var e entry
 	if err := b.store.Get(key, &e); err != nil {
@@ -121,6 +277,10 @@ func generateKey(overlay swarm.Address) string {
 	return keyPrefix + overlay.String()
 }
 
+func generateNetworkKey(network *net.IPNet) string {
+	return networkKeyPrefix + network.String()
+}
+
 func unmarshalKey(s string) (swarm.Address, error) {
 	addr := strings.TrimPrefix(s, keyPrefix)
 	return swarm.ParseHexAddress(addr)
@@ -5,6 +5,8 @@
 package blocklist_test
 
 import (
+	"errors"
+	"net"
 	"testing"
 	"time"
 
@@ -30,12 +32,12 @@ func TestExist(t *testing.T) {
 	}
 
 	// add forever
-	if err := bl.Add(addr1, 0); err != nil {
+	if err := bl.Add(addr1, 0, "", ""); err != nil {
 		t.Fatal(err)
 	}
 
 	// add for 50 miliseconds
-	if err := bl.Add(addr2, time.Millisecond*50); err != nil {
+	if err := bl.Add(addr2, time.Millisecond*50, "", ""); err != nil {
 		t.Fatal(err)
 	}
 
@@ -68,12 +70,12 @@ func TestPeers(t *testing.T) {
 	bl := blocklist.NewBlocklist(mock.NewStateStore())
 
 	// add forever
-	if err := bl.Add(addr1, 0); err != nil {
+	if err := bl.Add(addr1, 0, "", ""); err != nil {
 		t.Fatal(err)
 	}
 
 	// add for 50 miliseconds
-	if err := bl.Add(addr2, time.Millisecond*50); err != nil {
+	if err := bl.Add(addr2, time.Millisecond*50, "", ""); err != nil {
 		t.Fatal(err)
 	}
 
@@ -106,6 +108,107 @@ func TestPeers(t *testing.T) {
 	}
 }
 
+func TestEntries(t *testing.T) {
+	addr := swarm.NewAddress([]byte{0, 1, 2, 3})
+
+	bl := blocklist.NewBlocklist(mock.NewStateStore())
+
+	if err := bl.Add(addr, time.Minute, "invalid receipt", "pushsync"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := bl.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if !e.Address.Equal(addr) {
+		t.Fatalf("got address %s, want %s", e.Address, addr)
+	}
+	if e.Reason != "invalid receipt" || e.Subsystem != "pushsync" || e.Duration != time.Minute {
+		t.Fatalf("got entry %+v", e.BlocklistEntry)
+	}
+	if e.Timestamp.IsZero() {
+		t.Fatal("missing timestamp")
+	}
+
+	if err := bl.Remove(addr); err != nil {
+		t.Fatal(err)
+	}
+	exists, err := bl.Exists(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Fatal("got exists after removal")
+	}
+	if err := bl.Remove(addr); !errors.Is(err, p2p.ErrNotBlocklisted) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrNotBlocklisted)
+	}
+}
+
+func TestNetworks(t *testing.T) {
+	_, network, err := net.ParseCIDR("10.1.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bl := blocklist.NewBlocklist(mock.NewStateStore())
+
+	if err := bl.AddNetwork(network, time.Millisecond*50, "spam", "api"); err != nil {
+		t.Fatal(err)
+	}
+
+	exists, err := bl.IPExists(net.ParseIP("10.1.2.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Fatal("got not exists, expected exists")
+	}
+	exists, err = bl.IPExists(net.ParseIP("10.2.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Fatal("got exists, expected not exists")
+	}
+
+	networks, err := bl.Networks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(networks) != 1 || networks[0].Network.String() != network.String() || networks[0].Reason != "spam" {
+		t.Fatalf("got networks %+v", networks)
+	}
+
+	// the network entries are not mistaken for peers
+	peers, err := bl.Peers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 0 {
+		t.Fatalf("got peers %v, want none", peers)
+	}
+
+	blocklist.SetTimeNow(func() time.Time { return time.Now().Add(100 * time.Millisecond) })
+	defer func() { blocklist.SetTimeNow(time.Now) }()
+
+	exists, err = bl.IPExists(net.ParseIP("10.1.2.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Fatal("got exists after expiry")
+	}
+	if err := bl.RemoveNetwork(network); !errors.Is(err, p2p.ErrNotBlocklisted) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrNotBlocklisted)
+	}
+}
+
 func isIn(p swarm.Address, peers []p2p.Peer) bool {
 	for _, v := range peers {
 		if v.Address.Equal(p) {
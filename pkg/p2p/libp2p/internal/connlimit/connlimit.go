@@ -0,0 +1,198 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package connlimit limits the number of connections per direction and per
+// IP address, subnet and configured network of the remote side. It guards
+// against a single host filling the connection slots of the node with many
+// overlays.
+package connlimit
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+const (
+	// ipv4SubnetBits is the prefix length of the IPv4 subnets limited by
+	// the subnet limit.
+	ipv4SubnetBits = 24
+	// ipv6SubnetBits is the prefix length of the IPv6 subnets limited by
+	// the subnet limit.
+	ipv6SubnetBits = 48
+)
+
+// ErrInvalidNetworkLimit is returned by ParseNetworkLimit for a malformed
+// network limit.
+var ErrInvalidNetworkLimit = errors.New("invalid network limit")
+
+// NetworkLimit limits the number of connections to the IP addresses within
+// the network.
+type NetworkLimit struct {
+	Network *net.IPNet
+	Limit   int
+}
+
+// ParseNetworkLimit parses a network limit given in the cidr=limit format,
+// e.g. 10.0.0.0/8=20.
+func ParseNetworkLimit(s string) (NetworkLimit, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return NetworkLimit{}, fmt.Errorf("%w: %q", ErrInvalidNetworkLimit, s)
+	}
+	_, network, err := net.ParseCIDR(strings.TrimSpace(s[:i]))
+	if err != nil {
+		return NetworkLimit{}, fmt.Errorf("%w: %q: %v", ErrInvalidNetworkLimit, s, err)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(s[i+1:]))
+	if err != nil || limit < 0 {
+		return NetworkLimit{}, fmt.Errorf("%w: %q: limit must be a non negative integer", ErrInvalidNetworkLimit, s)
+	}
+	return NetworkLimit{Network: network, Limit: limit}, nil
+}
+
+// Options are the connection limits, zero limits are unlimited.
+type Options struct {
+	// Inbound limits the number of inbound connections.
+	Inbound int
+	// Outbound limits the number of outbound connections.
+	Outbound int
+	// IP limits the number of connections to a single IP address.
+	IP int
+	// Subnet limits the number of connections to a single /24 IPv4 or /48
+	// IPv6 subnet.
+	Subnet int
+	// Networks limit the number of connections to each of the networks.
+	Networks []NetworkLimit
+}
+
+// Limiter counts the connections and decides whether new ones are within
+// the limits.
+type Limiter struct {
+	o Options
+
+	mu       sync.Mutex
+	inbound  int
+	outbound int
+	ips      map[string]int
+	subnets  map[string]int
+	networks []int // number of connections in each of the limited networks
+}
+
+// New creates a new Limiter.
+func New(o Options) *Limiter {
+	return &Limiter{
+		o:        o,
+		ips:      make(map[string]int),
+		subnets:  make(map[string]int),
+		networks: make([]int, len(o.Networks)),
+	}
+}
+
+// Allow returns whether a new connection in the direction to the remote
+// address is within the limits.
+func (l *Limiter) Allow(dir network.Direction, remote ma.Multiaddr) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch dir {
+	case network.DirInbound:
+		if l.o.Inbound > 0 && l.inbound >= l.o.Inbound {
+			return false
+		}
+	case network.DirOutbound:
+		if l.o.Outbound > 0 && l.outbound >= l.o.Outbound {
+			return false
+		}
+	}
+
+	ip, err := manet.ToIP(remote)
+	if err != nil {
+		// connections without an IP address are only limited by direction
+		return true
+	}
+	if l.o.IP > 0 && l.ips[ip.String()] >= l.o.IP {
+		return false
+	}
+	if l.o.Subnet > 0 && l.subnets[subnet(ip)] >= l.o.Subnet {
+		return false
+	}
+	for i, n := range l.o.Networks {
+		if n.Network.Contains(ip) && l.networks[i] >= n.Limit {
+			return false
+		}
+	}
+	return true
+}
+
+// Add counts a new connection in the direction to the remote address.
+func (l *Limiter) Add(dir network.Direction, remote ma.Multiaddr) {
+	l.add(dir, remote, 1)
+}
+
+// Remove stops counting a connection previously counted by Add.
+func (l *Limiter) Remove(dir network.Direction, remote ma.Multiaddr) {
+	l.add(dir, remote, -1)
+}
+
+func (l *Limiter) add(dir network.Direction, remote ma.Multiaddr, delta int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch dir {
+	case network.DirInbound:
+		l.inbound += delta
+	case network.DirOutbound:
+		l.outbound += delta
+	}
+
+	ip, err := manet.ToIP(remote)
+	if err != nil {
+		return
+	}
+	increment(l.ips, ip.String(), delta)
+	increment(l.subnets, subnet(ip), delta)
+	for i, n := range l.o.Networks {
+		if n.Network.Contains(ip) {
+			l.networks[i] += delta
+		}
+	}
+}
+
+// Count returns the number of counted connections in the direction.
+func (l *Limiter) Count(dir network.Direction) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch dir {
+	case network.DirInbound:
+		return l.inbound
+	case network.DirOutbound:
+		return l.outbound
+	}
+	return 0
+}
+
+func increment(m map[string]int, key string, delta int) {
+	if m[key]+delta <= 0 {
+		delete(m, key)
+		return
+	}
+	m[key] += delta
+}
+
+// subnet returns the /24 IPv4 or /48 IPv6 subnet of the IP address.
+func subnet(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(ipv4SubnetBits, 8*net.IPv4len)).String()
+	}
+	return ip.Mask(net.CIDRMask(ipv6SubnetBits, 8*net.IPv6len)).String()
+}
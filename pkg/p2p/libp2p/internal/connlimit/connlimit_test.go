@@ -0,0 +1,145 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package connlimit_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/holisticode/bee/pkg/p2p/libp2p/internal/connlimit"
+	"github.com/libp2p/go-libp2p-core/network"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func mustMultiaddr(t *testing.T, s string) ma.Multiaddr {
+	t.Helper()
+
+	addr, err := ma.NewMultiaddr(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func TestDirectionLimits(t *testing.T) {
+	l := connlimit.New(connlimit.Options{Inbound: 2, Outbound: 1})
+
+	a := mustMultiaddr(t, "/ip4/1.1.1.1/tcp/1634")
+	b := mustMultiaddr(t, "/ip4/2.2.2.2/tcp/1634")
+	c := mustMultiaddr(t, "/ip4/3.3.3.3/tcp/1634")
+
+	l.Add(network.DirInbound, a)
+	l.Add(network.DirInbound, b)
+	if l.Allow(network.DirInbound, c) {
+		t.Fatal("allowed inbound connection over the limit")
+	}
+	if !l.Allow(network.DirOutbound, c) {
+		t.Fatal("inbound connections limit outbound ones")
+	}
+
+	l.Add(network.DirOutbound, c)
+	if l.Allow(network.DirOutbound, mustMultiaddr(t, "/ip4/4.4.4.4/tcp/1634")) {
+		t.Fatal("allowed outbound connection over the limit")
+	}
+
+	l.Remove(network.DirInbound, a)
+	if !l.Allow(network.DirInbound, c) {
+		t.Fatal("removed connection is still counted")
+	}
+	if got := l.Count(network.DirInbound); got != 1 {
+		t.Fatalf("got %d inbound connections, want 1", got)
+	}
+}
+
+func TestAddressLimits(t *testing.T) {
+	network10, err := connlimit.ParseNetworkLimit("10.0.0.0/8=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := connlimit.New(connlimit.Options{
+		IP:       2,
+		Subnet:   3,
+		Networks: []connlimit.NetworkLimit{network10},
+	})
+
+	for _, tc := range []struct {
+		name   string
+		add    []string
+		try    string
+		denied bool
+	}{
+		{
+			name:   "ip",
+			add:    []string{"/ip4/1.1.1.1/tcp/1634", "/ip4/1.1.1.1/tcp/1635"},
+			try:    "/ip4/1.1.1.1/tcp/1636",
+			denied: true,
+		},
+		{
+			name:   "subnet",
+			add:    []string{"/ip4/2.2.2.1/tcp/1634", "/ip4/2.2.2.2/tcp/1634", "/ip4/2.2.2.3/tcp/1634"},
+			try:    "/ip4/2.2.2.4/tcp/1634",
+			denied: true,
+		},
+		{
+			name: "other subnet",
+			add:  []string{"/ip4/3.3.3.1/tcp/1634", "/ip4/3.3.3.2/tcp/1634", "/ip4/3.3.3.3/tcp/1634"},
+			try:  "/ip4/3.3.4.1/tcp/1634",
+		},
+		{
+			name:   "ipv6 subnet",
+			add:    []string{"/ip6/2001:db8:1::1/tcp/1634", "/ip6/2001:db8:1:1::1/tcp/1634", "/ip6/2001:db8:1:2::1/tcp/1634"},
+			try:    "/ip6/2001:db8:1:3::1/tcp/1634",
+			denied: true,
+		},
+		{
+			name:   "network",
+			add:    []string{"/ip4/10.1.0.1/tcp/1634", "/ip4/10.2.0.1/tcp/1634", "/ip4/10.3.0.1/tcp/1634"},
+			try:    "/ip4/10.4.0.1/tcp/1634",
+			denied: true,
+		},
+		{
+			name: "no ip",
+			add:  []string{"/dns4/example.com/tcp/1634", "/dns4/example.com/tcp/1634"},
+			try:  "/dns4/example.com/tcp/1634",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, a := range tc.add {
+				addr := mustMultiaddr(t, a)
+				if !l.Allow(network.DirInbound, addr) {
+					t.Fatalf("denied connection to %s within the limits", a)
+				}
+				l.Add(network.DirInbound, addr)
+			}
+
+			try := mustMultiaddr(t, tc.try)
+			if got := !l.Allow(network.DirOutbound, try); got != tc.denied {
+				t.Fatalf("got denied %v, want %v", got, tc.denied)
+			}
+
+			// the limits apply again after removing a connection
+			l.Remove(network.DirInbound, mustMultiaddr(t, tc.add[0]))
+			if !l.Allow(network.DirOutbound, try) {
+				t.Fatal("denied connection after a removal")
+			}
+		})
+	}
+}
+
+func TestParseNetworkLimit(t *testing.T) {
+	nl, err := connlimit.ParseNetworkLimit("192.168.0.0/16=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nl.Network.String() != "192.168.0.0/16" || nl.Limit != 5 {
+		t.Fatalf("got %s=%d, want 192.168.0.0/16=5", nl.Network, nl.Limit)
+	}
+
+	for _, s := range []string{"192.168.0.0/16", "192.168.0.0=5", "192.168.0.0/16=x", "192.168.0.0/16=-1"} {
+		if _, err := connlimit.ParseNetworkLimit(s); !errors.Is(err, connlimit.ErrInvalidNetworkLimit) {
+			t.Fatalf("%q: got error %v, want %v", s, err, connlimit.ErrInvalidNetworkLimit)
+		}
+	}
+}
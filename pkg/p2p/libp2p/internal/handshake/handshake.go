@@ -83,6 +83,8 @@ type Service struct {
 	metrics               metrics
 	network.Notifiee      // handshake service can be the receiver for network.Notify
 	picker                p2p.Picker
+	tcpPort               string
+	quicPort              string
 }
 
 // Info contains the information received from the handshake.
@@ -128,6 +130,13 @@ func (s *Service) SetPicker(n p2p.Picker) {
 	s.picker = n
 }
 
+// EnableQUIC makes the handshake advertise a QUIC underlay on the quicPort
+// next to the TCP underlay on the tcpPort.
+func (s *Service) EnableQUIC(tcpPort, quicPort string) {
+	s.tcpPort = tcpPort
+	s.quicPort = quicPort
+}
+
 // Handshake initiates a handshake with a peer.
 func (s *Service) Handshake(ctx context.Context, stream p2p.Stream, peerMultiaddr ma.Multiaddr, peerID libp2ppeer.ID) (i *Info, err error) {
 	ctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
@@ -170,17 +179,12 @@ func (s *Service) Handshake(ctx context.Context, stream p2p.Stream, peerMultiadd
 		s.logger.Warningf("received peer ID %s does not match ours: %s", observedUnderlayAddrInfo.ID, s.libp2pID)
 	}
 
-	advertisableUnderlay, err := s.advertisableAddresser.Resolve(observedUnderlay)
-	if err != nil {
-		return nil, err
-	}
-
-	bzzAddress, err := bzz.NewAddress(s.signer, advertisableUnderlay, s.overlay, s.networkID, s.transaction)
+	bzzAddress, err := s.bzzAddress(observedUnderlay)
 	if err != nil {
 		return nil, err
 	}
 
-	advertisableUnderlayBytes, err := bzzAddress.Underlay.MarshalBinary()
+	addressMsg, err := newAddressMessage(bzzAddress)
 	if err != nil {
 		return nil, err
 	}
@@ -204,11 +208,7 @@ func (s *Service) Handshake(ctx context.Context, stream p2p.Stream, peerMultiadd
 	// Synced read:
 	welcomeMessage := s.GetWelcomeMessage()
 	if err := w.WriteMsgWithContext(ctx, &pb.Ack{
-		Address: &pb.BzzAddress{
-			Underlay:  advertisableUnderlayBytes,
-			Overlay:   bzzAddress.Overlay.Bytes(),
-			Signature: bzzAddress.Signature,
-		},
+		Address:        addressMsg,
 		NetworkID:      s.networkID,
 		FullNode:       s.fullNode,
 		Transaction:    s.transaction,
@@ -264,17 +264,12 @@ func (s *Service) Handle(ctx context.Context, stream p2p.Stream, remoteMultiaddr
 		return nil, ErrInvalidSyn
 	}
 
-	advertisableUnderlay, err := s.advertisableAddresser.Resolve(observedUnderlay)
-	if err != nil {
-		return nil, err
-	}
-
-	bzzAddress, err := bzz.NewAddress(s.signer, advertisableUnderlay, s.overlay, s.networkID, s.transaction)
+	bzzAddress, err := s.bzzAddress(observedUnderlay)
 	if err != nil {
 		return nil, err
 	}
 
-	advertisableUnderlayBytes, err := bzzAddress.Underlay.MarshalBinary()
+	addressMsg, err := newAddressMessage(bzzAddress)
 	if err != nil {
 		return nil, err
 	}
@@ -286,11 +281,7 @@ func (s *Service) Handle(ctx context.Context, stream p2p.Stream, remoteMultiaddr
 			ObservedUnderlay: fullRemoteMABytes,
 		},
 		Ack: &pb.Ack{
-			Address: &pb.BzzAddress{
-				Underlay:  advertisableUnderlayBytes,
-				Overlay:   bzzAddress.Overlay.Bytes(),
-				Signature: bzzAddress.Signature,
-			},
+			Address:        addressMsg,
 			NetworkID:      s.networkID,
 			FullNode:       s.fullNode,
 			Transaction:    s.transaction,
@@ -367,11 +358,103 @@ func buildFullMA(addr ma.Multiaddr, peerID libp2ppeer.ID) (ma.Multiaddr, error)
 	return ma.NewMultiaddr(fmt.Sprintf("%s/p2p/%s", addr.String(), peerID.Pretty()))
 }
 
+// bzzAddress creates the signed address of this node that is advertised to
+// the peer which observed it on the observedUnderlay.
+func (s *Service) bzzAddress(observedUnderlay ma.Multiaddr) (*bzz.Address, error) {
+	if s.quicPort != "" && isQUIC(observedUnderlay) {
+		// the address resolvers expect the observed TCP underlay
+		tcpUnderlay, err := withTransport(observedUnderlay, "/tcp/"+s.tcpPort)
+		if err != nil {
+			return nil, err
+		}
+		observedUnderlay = tcpUnderlay
+	}
+
+	advertisableUnderlay, err := s.advertisableAddresser.Resolve(observedUnderlay)
+	if err != nil {
+		return nil, err
+	}
+
+	bzzAddress, err := bzz.NewAddress(s.signer, advertisableUnderlay, s.overlay, s.networkID, s.transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.quicPort != "" {
+		quicUnderlay, err := withTransport(advertisableUnderlay, "/udp/"+s.quicPort+"/quic")
+		if err != nil {
+			return nil, err
+		}
+		if err := bzzAddress.SetQUICUnderlay(s.signer, quicUnderlay, s.networkID); err != nil {
+			return nil, err
+		}
+	}
+
+	return bzzAddress, nil
+}
+
+func newAddressMessage(bzzAddress *bzz.Address) (*pb.BzzAddress, error) {
+	underlay, err := bzzAddress.Underlay.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	var quicUnderlay []byte
+	if bzzAddress.QUICUnderlay != nil {
+		quicUnderlay, err = bzzAddress.QUICUnderlay.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &pb.BzzAddress{
+		Underlay:      underlay,
+		Overlay:       bzzAddress.Overlay.Bytes(),
+		Signature:     bzzAddress.Signature,
+		QUICUnderlay:  quicUnderlay,
+		QUICSignature: bzzAddress.QUICSignature,
+	}, nil
+}
+
+// isQUIC returns whether the underlay is a QUIC address.
+func isQUIC(underlay ma.Multiaddr) bool {
+	_, err := underlay.ValueForProtocol(ma.P_QUIC)
+	return err == nil
+}
+
+// withTransport replaces the transport part of the underlay, keeping its IP
+// or DNS address and the peer ID.
+func withTransport(underlay ma.Multiaddr, transport string) (ma.Multiaddr, error) {
+	parts := ma.Split(underlay)
+	if len(parts) == 0 {
+		return nil, errors.New("empty underlay")
+	}
+
+	t, err := ma.NewMultiaddr(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	a := parts[0].Encapsulate(t)
+	for _, p := range parts[1:] {
+		if p.Protocols()[0].Code == ma.P_P2P {
+			a = a.Encapsulate(p)
+		}
+	}
+	return a, nil
+}
+
 func (s *Service) parseCheckAck(ack *pb.Ack, blockHash []byte) (*bzz.Address, error) {
 	bzzAddress, err := bzz.ParseAddress(ack.Address.Underlay, ack.Address.Overlay, ack.Address.Signature, ack.Transaction, blockHash, s.networkID)
 	if err != nil {
 		return nil, ErrInvalidAck
 	}
 
+	if len(ack.Address.QUICUnderlay) > 0 {
+		if err := bzzAddress.ParseQUICUnderlay(ack.Address.QUICUnderlay, ack.Address.QUICSignature, s.networkID); err != nil {
+			return nil, ErrInvalidAck
+		}
+	}
+
 	return bzzAddress, nil
 }
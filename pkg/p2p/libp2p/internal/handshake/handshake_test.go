@@ -768,6 +768,90 @@ func TestHandshake(t *testing.T) {
 			t.Fatal("expected nil res")
 		}
 	})
+
+	t.Run("Handshake - QUIC", func(t *testing.T) {
+		handshakeService, err := handshake.New(signer1, aaddresser, senderMatcher, node1Info.BzzAddress.Overlay, networkID, true, trxHash, "", node1AddrInfo.ID, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handshakeService.EnableQUIC("1634", "1635")
+
+		node1QUICma, err := ma.NewMultiaddr("/ip4/127.0.0.1/udp/1634/quic/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA")
+		if err != nil {
+			t.Fatal(err)
+		}
+		node2QUICma, err := ma.NewMultiaddr("/ip4/127.0.0.1/udp/1634/quic/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkS")
+		if err != nil {
+			t.Fatal(err)
+		}
+		node2QUICBzzAddress := *node2BzzAddress
+		if err := node2QUICBzzAddress.SetQUICUnderlay(signer2, node2QUICma, networkID); err != nil {
+			t.Fatal(err)
+		}
+
+		var buffer1 bytes.Buffer
+		var buffer2 bytes.Buffer
+		stream1 := mock.NewStream(&buffer1, &buffer2)
+		stream2 := mock.NewStream(&buffer2, &buffer1)
+
+		w, r := protobuf.NewWriterAndReader(stream2)
+		if err := w.WriteMsg(&pb.SynAck{
+			Syn: &pb.Syn{
+				// the peer observed the QUIC connection
+				ObservedUnderlay: node1QUICma.Bytes(),
+			},
+			Ack: &pb.Ack{
+				Address: &pb.BzzAddress{
+					Underlay:      node2maBinary,
+					Overlay:       node2BzzAddress.Overlay.Bytes(),
+					Signature:     node2BzzAddress.Signature,
+					QUICUnderlay:  node2QUICma.Bytes(),
+					QUICSignature: node2QUICBzzAddress.QUICSignature,
+				},
+				NetworkID:   networkID,
+				FullNode:    true,
+				Transaction: trxHash,
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := handshakeService.Handshake(context.Background(), stream1, node2AddrInfo.Addrs[0], node2AddrInfo.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testInfo(t, *res, handshake.Info{BzzAddress: &node2QUICBzzAddress, FullNode: true})
+
+		var syn pb.Syn
+		if err := r.ReadMsg(&syn); err != nil {
+			t.Fatal(err)
+		}
+
+		var ack pb.Ack
+		if err := r.ReadMsg(&ack); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(ack.Address.Underlay, node1maBinary) {
+			t.Fatal("bad ack - underlay")
+		}
+		quicUnderlay, err := ma.NewMultiaddrBytes(ack.Address.QUICUnderlay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "/ip4/127.0.0.1/udp/1635/quic/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA"; quicUnderlay.String() != want {
+			t.Fatalf("bad ack - got quic underlay %s, want %s", quicUnderlay, want)
+		}
+
+		ackAddress, err := bzz.ParseAddress(ack.Address.Underlay, ack.Address.Overlay, ack.Address.Signature, ack.Transaction, blockhash, networkID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ackAddress.ParseQUICUnderlay(ack.Address.QUICUnderlay, ack.Address.QUICSignature, networkID); err != nil {
+			t.Fatalf("bad ack - quic signature: %v", err)
+		}
+	})
 }
 
 func mockPicker(f func(p2p.Peer) bool) p2p.Picker {
@@ -195,9 +195,11 @@ func (m *SynAck) GetAck() *Ack {
 }
 
 type BzzAddress struct {
-	Underlay  []byte `protobuf:"bytes,1,opt,name=Underlay,proto3" json:"Underlay,omitempty"`
-	Signature []byte `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
-	Overlay   []byte `protobuf:"bytes,3,opt,name=Overlay,proto3" json:"Overlay,omitempty"`
+	Underlay      []byte `protobuf:"bytes,1,opt,name=Underlay,proto3" json:"Underlay,omitempty"`
+	Signature     []byte `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	Overlay       []byte `protobuf:"bytes,3,opt,name=Overlay,proto3" json:"Overlay,omitempty"`
+	QUICUnderlay  []byte `protobuf:"bytes,4,opt,name=QUICUnderlay,proto3" json:"QUICUnderlay,omitempty"`
+	QUICSignature []byte `protobuf:"bytes,5,opt,name=QUICSignature,proto3" json:"QUICSignature,omitempty"`
 }
 
 func (m *BzzAddress) Reset()         { *m = BzzAddress{} }
@@ -254,6 +256,20 @@ func (m *BzzAddress) GetOverlay() []byte {
 	return nil
 }
 
+func (m *BzzAddress) GetQUICUnderlay() []byte {
+	if m != nil {
+		return m.QUICUnderlay
+	}
+	return nil
+}
+
+func (m *BzzAddress) GetQUICSignature() []byte {
+	if m != nil {
+		return m.QUICSignature
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Syn)(nil), "handshake.Syn")
 	proto.RegisterType((*Ack)(nil), "handshake.Ack")
@@ -264,28 +280,29 @@ func init() {
 func init() { proto.RegisterFile("handshake.proto", fileDescriptor_a77305914d5d202f) }
 
 var fileDescriptor_a77305914d5d202f = []byte{
-	// 324 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x91, 0xdf, 0x4a, 0xf3, 0x30,
-	0x18, 0xc6, 0x97, 0x75, 0xec, 0xcf, 0xbb, 0xb1, 0xef, 0x23, 0x20, 0x14, 0x19, 0x25, 0xf4, 0x40,
-	0x8a, 0x07, 0x13, 0xf5, 0x0a, 0x36, 0x44, 0x10, 0x74, 0x83, 0x54, 0x11, 0x3c, 0x32, 0x6b, 0xc3,
-	0x36, 0x5a, 0x93, 0x91, 0x74, 0x93, 0xee, 0x2a, 0xbc, 0x1e, 0xaf, 0xc0, 0xc3, 0x1d, 0x7a, 0x28,
-	0xdb, 0x8d, 0x48, 0xe3, 0xd6, 0xce, 0x79, 0xf8, 0xfe, 0x9e, 0x27, 0xc9, 0xf3, 0xbc, 0x81, 0x7f,
-	0x13, 0x26, 0x42, 0x3d, 0x61, 0x11, 0xef, 0xce, 0x94, 0x4c, 0x24, 0x6e, 0xe4, 0xc0, 0x3d, 0x07,
-	0xcb, 0x4f, 0x05, 0x3e, 0x85, 0xff, 0xc3, 0x91, 0xe6, 0x6a, 0xc1, 0xc3, 0x07, 0x11, 0x72, 0x15,
-	0xb3, 0xd4, 0x46, 0x04, 0x79, 0x2d, 0xfa, 0x87, 0xbb, 0xef, 0x08, 0xac, 0x5e, 0x10, 0xe1, 0x33,
-	0xa8, 0xf5, 0xc2, 0x50, 0x71, 0xad, 0x8d, 0xb5, 0x79, 0x71, 0xd4, 0x2d, 0x1e, 0xea, 0x2f, 0x97,
-	0x5b, 0x91, 0xee, 0x5c, 0xb8, 0x03, 0x8d, 0x01, 0x4f, 0x5e, 0xa5, 0x8a, 0x6e, 0xae, 0xec, 0x32,
-	0x41, 0x5e, 0x85, 0x16, 0x00, 0x1f, 0x43, 0xfd, 0x7a, 0x1e, 0xc7, 0x03, 0x19, 0x72, 0xdb, 0x22,
-	0xc8, 0xab, 0xd3, 0x7c, 0xc6, 0x04, 0x9a, 0xf7, 0x8a, 0x09, 0xcd, 0x82, 0x64, 0x2a, 0x85, 0x5d,
-	0x31, 0xc9, 0xf6, 0x11, 0x3e, 0x81, 0xf6, 0x23, 0x8f, 0x03, 0xf9, 0xc2, 0xef, 0xb8, 0xd6, 0x6c,
-	0xcc, 0xed, 0x80, 0x20, 0xaf, 0x41, 0x0f, 0xa8, 0x7b, 0x0b, 0x55, 0x3f, 0x15, 0x59, 0x7c, 0x62,
-	0x9a, 0x6f, 0xa3, 0xb7, 0xf7, 0xa2, 0xfb, 0xa9, 0xa0, 0x66, 0x29, 0xc4, 0xf4, 0x34, 0x49, 0x7f,
-	0x3b, 0x7a, 0x41, 0x44, 0x33, 0xc9, 0x7d, 0x06, 0x28, 0x8a, 0x66, 0x0d, 0x0e, 0x96, 0x97, 0xcf,
-	0x59, 0x77, 0x7f, 0x3a, 0x16, 0x2c, 0x99, 0x2b, 0x6e, 0x6e, 0x6c, 0xd1, 0x02, 0x60, 0x1b, 0x6a,
-	0xc3, 0xc5, 0xcf, 0x41, 0xcb, 0x68, 0xbb, 0xb1, 0xdf, 0xf9, 0x58, 0x3b, 0x68, 0xb5, 0x76, 0xd0,
-	0xd7, 0xda, 0x41, 0x6f, 0x1b, 0xa7, 0xb4, 0xda, 0x38, 0xa5, 0xcf, 0x8d, 0x53, 0x7a, 0x2a, 0xcf,
-	0x46, 0xa3, 0xaa, 0xf9, 0xcf, 0xcb, 0xef, 0x00, 0x00, 0x00, 0xff, 0xff, 0xd1, 0x48, 0x2c, 0x4a,
-	0xe2, 0x01, 0x00, 0x00,
+	// 348 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x92, 0xdd, 0x6a, 0xea, 0x40,
+	0x14, 0x85, 0x1d, 0xe3, 0xf1, 0x67, 0xeb, 0xf1, 0x1c, 0x06, 0x0e, 0x84, 0x83, 0x84, 0x10, 0x4a,
+	0x09, 0xbd, 0xb0, 0xb4, 0x7d, 0x02, 0x6d, 0x29, 0x08, 0xad, 0xd2, 0x49, 0xa5, 0xd0, 0xbb, 0x31,
+	0x19, 0x54, 0x92, 0x4e, 0x64, 0x26, 0x5a, 0xe2, 0x53, 0xf4, 0x39, 0xfa, 0x08, 0x7d, 0x82, 0x5e,
+	0x7a, 0xd9, 0xcb, 0xa2, 0x2f, 0x52, 0x32, 0x6a, 0xe2, 0xcf, 0xe5, 0xfa, 0xf6, 0xca, 0xce, 0x5a,
+	0x9b, 0x81, 0x3f, 0x23, 0xca, 0x3d, 0x39, 0xa2, 0x3e, 0x6b, 0x4e, 0x44, 0x18, 0x85, 0xb8, 0x92,
+	0x02, 0xeb, 0x02, 0x34, 0x27, 0xe6, 0xf8, 0x0c, 0xfe, 0xf6, 0x06, 0x92, 0x89, 0x19, 0xf3, 0xfa,
+	0xdc, 0x63, 0x22, 0xa0, 0xb1, 0x8e, 0x4c, 0x64, 0xd7, 0xc8, 0x11, 0xb7, 0x3e, 0x10, 0x68, 0x2d,
+	0xd7, 0xc7, 0xe7, 0x50, 0x6a, 0x79, 0x9e, 0x60, 0x52, 0x2a, 0x6b, 0xf5, 0xf2, 0x5f, 0x33, 0xfb,
+	0x51, 0x7b, 0x3e, 0xdf, 0x0c, 0xc9, 0xd6, 0x85, 0x1b, 0x50, 0xe9, 0xb2, 0xe8, 0x35, 0x14, 0x7e,
+	0xe7, 0x46, 0xcf, 0x9b, 0xc8, 0x2e, 0x90, 0x0c, 0xe0, 0xff, 0x50, 0xbe, 0x9d, 0x06, 0x41, 0x37,
+	0xf4, 0x98, 0xae, 0x99, 0xc8, 0x2e, 0x93, 0x54, 0x63, 0x13, 0xaa, 0x8f, 0x82, 0x72, 0x49, 0xdd,
+	0x68, 0x1c, 0x72, 0xbd, 0xa0, 0x92, 0xed, 0x22, 0x7c, 0x0a, 0xf5, 0x27, 0x16, 0xb8, 0xe1, 0x0b,
+	0xbb, 0x67, 0x52, 0xd2, 0x21, 0xd3, 0x5d, 0x13, 0xd9, 0x15, 0x72, 0x40, 0xad, 0x3b, 0x28, 0x3a,
+	0x31, 0x4f, 0xe2, 0x9b, 0xaa, 0xf9, 0x26, 0x7a, 0x7d, 0x27, 0xba, 0x13, 0x73, 0xa2, 0x8e, 0x62,
+	0xaa, 0x9e, 0x7a, 0xfe, 0xc8, 0xd1, 0x72, 0x7d, 0x92, 0x8c, 0xac, 0x77, 0x04, 0x90, 0x35, 0x4d,
+	0x2a, 0x1c, 0x5c, 0x2f, 0xd5, 0x49, 0x79, 0x67, 0x3c, 0xe4, 0x34, 0x9a, 0x0a, 0xa6, 0x56, 0xd6,
+	0x48, 0x06, 0xb0, 0x0e, 0xa5, 0xde, 0x6c, 0xfd, 0xa1, 0xa6, 0x66, 0x5b, 0x89, 0x2d, 0xa8, 0x3d,
+	0xf4, 0x3b, 0xd7, 0xe9, 0xde, 0x75, 0xf7, 0x3d, 0x86, 0x4f, 0xe0, 0x77, 0xa2, 0xb3, 0xfd, 0xbf,
+	0x94, 0x69, 0x1f, 0xb6, 0x1b, 0x9f, 0x4b, 0x03, 0x2d, 0x96, 0x06, 0xfa, 0x5e, 0x1a, 0xe8, 0x6d,
+	0x65, 0xe4, 0x16, 0x2b, 0x23, 0xf7, 0xb5, 0x32, 0x72, 0xcf, 0xf9, 0xc9, 0x60, 0x50, 0x54, 0x4f,
+	0xe3, 0xea, 0x67, 0x00, 0x2e, 0x40, 0xa1, 0x0c, 0x2d, 0x02, 0x00, 0x00,
 }
 
 func (m *Syn) Marshal() (dAtA []byte, err error) {
@@ -451,6 +468,20 @@ func (m *BzzAddress) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.QUICSignature) > 0 {
+		i -= len(m.QUICSignature)
+		copy(dAtA[i:], m.QUICSignature)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.QUICSignature)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.QUICUnderlay) > 0 {
+		i -= len(m.QUICUnderlay)
+		copy(dAtA[i:], m.QUICUnderlay)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.QUICUnderlay)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if len(m.Overlay) > 0 {
 		i -= len(m.Overlay)
 		copy(dAtA[i:], m.Overlay)
@@ -561,6 +592,14 @@ func (m *BzzAddress) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovHandshake(uint64(l))
 	}
+	l = len(m.QUICUnderlay)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
+	l = len(m.QUICSignature)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
 	return n
 }
 
@@ -1107,6 +1146,74 @@ func (m *BzzAddress) Unmarshal(dAtA []byte) error {
 				m.Overlay = []byte{}
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QUICUnderlay", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.QUICUnderlay = append(m.QUICUnderlay[:0], dAtA[iNdEx:postIndex]...)
+			if m.QUICUnderlay == nil {
+				m.QUICUnderlay = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QUICSignature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.QUICSignature = append(m.QUICSignature[:0], dAtA[iNdEx:postIndex]...)
+			if m.QUICSignature == nil {
+				m.QUICSignature = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHandshake(dAtA[iNdEx:])
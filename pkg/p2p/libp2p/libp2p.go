@@ -19,10 +19,13 @@ import (
 	"github.com/holisticode/bee/pkg/addressbook"
 	"github.com/holisticode/bee/pkg/bzz"
 	beecrypto "github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/p2p/libp2p/internal/bandwidth"
 	"github.com/holisticode/bee/pkg/p2p/libp2p/internal/blocklist"
 	"github.com/holisticode/bee/pkg/p2p/libp2p/internal/breaker"
+	"github.com/holisticode/bee/pkg/p2p/libp2p/internal/connlimit"
 	handshake "github.com/holisticode/bee/pkg/p2p/libp2p/internal/handshake"
 	"github.com/holisticode/bee/pkg/p2p/libp2p/internal/reacher"
 	"github.com/holisticode/bee/pkg/storage"
@@ -40,11 +43,14 @@ import (
 	"github.com/libp2p/go-libp2p-core/peerstore"
 	protocol "github.com/libp2p/go-libp2p-core/protocol"
 	"github.com/libp2p/go-libp2p-peerstore/pstoremem"
+	libp2pquic "github.com/libp2p/go-libp2p-quic-transport"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
 	basichost "github.com/libp2p/go-libp2p/p2p/host/basic"
 	libp2pping "github.com/libp2p/go-libp2p/p2p/protocol/ping"
 	"github.com/libp2p/go-tcp-transport"
 	ws "github.com/libp2p/go-ws-transport"
 	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
 	"github.com/multiformats/go-multistream"
 )
 
@@ -87,6 +93,11 @@ type Service struct {
 	lightNodeLimit    int
 	protocolsmu       sync.RWMutex
 	reacher           p2p.Reacher
+	mdns              mdns.Service
+	addPeersHandler   func(...swarm.Address)
+	bandwidth         *bandwidth.Meter
+	overlay           swarm.Address
+	publisher         events.Publisher
 }
 
 type lightnodes interface {
@@ -103,9 +114,39 @@ type Options struct {
 	EnableWS       bool
 	FullNode       bool
 	LightNodeLimit int
-	WelcomeMessage string
-	Transaction    []byte
-	hostFactory    func(...libp2p.Option) (host.Host, error)
+	// EnableQUIC adds the QUIC transport listening on the QUICAddr, or on the
+	// UDP port of the p2p address if QUICAddr is empty.
+	EnableQUIC bool
+	QUICAddr   string
+	// EnableMDNS enables the discovery of peers on the local network with
+	// mDNS. Their private addresses are used only if AllowPrivateCIDRs is set.
+	EnableMDNS        bool
+	AllowPrivateCIDRs bool
+	// InboundLimit and OutboundLimit limit the number of connections per
+	// direction, zero is unlimited.
+	InboundLimit  int
+	OutboundLimit int
+	// IPLimit limits the number of connections to a single IP address and
+	// SubnetLimit to a single /24 IPv4 or /48 IPv6 subnet, zero is unlimited.
+	IPLimit     int
+	SubnetLimit int
+	// NetworkLimits limit the number of connections to networks given in
+	// the cidr=limit format, e.g. 10.0.0.0/8=20.
+	NetworkLimits []string
+	// BandwidthLimit limits the traffic of all protocols in bytes per second
+	// and ProtocolLimits the traffic of single protocols given in the
+	// protocol=limit format, e.g. pullsync=1048576. Zero is unlimited.
+	BandwidthLimit int
+	ProtocolLimits []string
+	// Publisher, if set, is notified about connected, disconnected and
+	// blocklisted peers.
+	Publisher events.Publisher
+	// PrivateNetworkKey is the pre-shared key of a private network. Only
+	// peers with the same key are able to establish transport connections.
+	PrivateNetworkKey []byte
+	WelcomeMessage    string
+	Transaction       []byte
+	hostFactory       func(...libp2p.Option) (host.Host, error)
 }
 
 func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay swarm.Address, addr string, ab addressbook.Putter, storer storage.StateStorer, lightNodes *lightnode.Container, swapBackend handshake.SenderMatcher, logger logging.Logger, tracer *tracing.Tracer, o Options) (*Service, error) {
@@ -114,19 +155,7 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		return nil, fmt.Errorf("address: %w", err)
 	}
 
-	ip4Addr := "0.0.0.0"
-	ip6Addr := "::"
-
-	if host != "" {
-		ip := net.ParseIP(host)
-		if ip4 := ip.To4(); ip4 != nil {
-			ip4Addr = ip4.String()
-			ip6Addr = ""
-		} else if ip6 := ip.To16(); ip6 != nil {
-			ip6Addr = ip6.String()
-			ip4Addr = ""
-		}
-	}
+	ip4Addr, ip6Addr := listenIPs(host)
 
 	var listenAddrs []string
 	if ip4Addr != "" {
@@ -143,12 +172,61 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		}
 	}
 
+	if o.EnableQUIC {
+		if o.PrivateNetworkKey != nil {
+			return nil, errors.New("quic: private networks are not supported")
+		}
+
+		quicHost, quicPort := host, port
+		if o.QUICAddr != "" {
+			quicHost, quicPort, err = net.SplitHostPort(o.QUICAddr)
+			if err != nil {
+				return nil, fmt.Errorf("quic address: %w", err)
+			}
+		}
+
+		quicIP4Addr, quicIP6Addr := listenIPs(quicHost)
+		if quicIP4Addr != "" {
+			listenAddrs = append(listenAddrs, fmt.Sprintf("/ip4/%s/udp/%s/quic", quicIP4Addr, quicPort))
+		}
+		if quicIP6Addr != "" {
+			listenAddrs = append(listenAddrs, fmt.Sprintf("/ip6/%s/udp/%s/quic", quicIP6Addr, quicPort))
+		}
+	}
+
 	security := libp2p.DefaultSecurity
 	libp2pPeerstore, err := pstoremem.NewPeerstore()
 	if err != nil {
 		return nil, err
 	}
 
+	limits := connlimit.Options{
+		Inbound:  o.InboundLimit,
+		Outbound: o.OutboundLimit,
+		IP:       o.IPLimit,
+		Subnet:   o.SubnetLimit,
+	}
+	for _, v := range o.NetworkLimits {
+		nl, err := connlimit.ParseNetworkLimit(v)
+		if err != nil {
+			return nil, fmt.Errorf("connection limits: %w", err)
+		}
+		limits.Networks = append(limits.Networks, nl)
+	}
+
+	bandwidthLimits := bandwidth.Options{Limit: o.BandwidthLimit}
+	for _, v := range o.ProtocolLimits {
+		pl, err := bandwidth.ParseProtocolLimit(v)
+		if err != nil {
+			return nil, fmt.Errorf("bandwidth limits: %w", err)
+		}
+		bandwidthLimits.Protocols = append(bandwidthLimits.Protocols, pl)
+	}
+
+	serviceMetrics := newMetrics()
+	bl := blocklist.NewBlocklist(storer)
+	gater := newConnectionGater(connlimit.New(limits), bl, serviceMetrics)
+
 	var natManager basichost.NATManager
 
 	opts := []libp2p.Option{
@@ -157,6 +235,7 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		// Use dedicated peerstore instead the global DefaultPeerstore
 		libp2p.Peerstore(libp2pPeerstore),
 		libp2p.UserAgent(userAgent()),
+		libp2p.ConnectionGater(gater),
 	}
 
 	if o.NATAddr == "" {
@@ -182,6 +261,15 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		transports = append(transports, libp2p.Transport(ws.New))
 	}
 
+	if o.EnableQUIC {
+		transports = append(transports, libp2p.Transport(libp2pquic.NewTransport))
+	}
+
+	if o.PrivateNetworkKey != nil {
+		// all hosts need the key to be able to connect to the private network
+		transports = append(transports, libp2p.PrivateNetwork(o.PrivateNetworkKey))
+	}
+
 	opts = append(opts, transports...)
 
 	if o.hostFactory == nil {
@@ -236,6 +324,13 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 	if err != nil {
 		return nil, fmt.Errorf("handshake service: %w", err)
 	}
+	if o.EnableQUIC {
+		tcpPort, quicPort, err := listenPorts(h.Network().ListenAddresses())
+		if err != nil {
+			return nil, fmt.Errorf("quic: %w", err)
+		}
+		handshakeService.EnableQUIC(tcpPort, quicPort)
+	}
 
 	// Create a new dialer for libp2p ping protocol. This ensures that the protocol
 	// uses a different set of keys to do ping. It prevents inconsistencies in peerstore as
@@ -257,17 +352,20 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		pingDialer:        pingDialer,
 		handshakeService:  handshakeService,
 		libp2pPeerstore:   libp2pPeerstore,
-		metrics:           newMetrics(),
+		metrics:           serviceMetrics,
 		networkID:         networkID,
 		peers:             peerRegistry,
 		addressbook:       ab,
-		blocklist:         blocklist.NewBlocklist(storer),
+		blocklist:         bl,
 		logger:            logger,
 		tracer:            tracer,
 		connectionBreaker: breaker.NewBreaker(breaker.Options{}), // use default options
 		ready:             make(chan struct{}),
 		halt:              make(chan struct{}),
 		lightNodes:        lightNodes,
+		bandwidth:         bandwidth.New(bandwidthLimits),
+		overlay:           overlay,
+		publisher:         o.Publisher,
 	}
 
 	peerRegistry.setDisconnecter(s)
@@ -294,9 +392,59 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 	h.Network().Notify(peerRegistry)       // update peer registry on network events
 	h.Network().Notify(s.handshakeService) // update handshake service on network events
 	h.Network().Notify(connMetricNotify)
+	h.Network().Notify(gater) // count connections against the connection limits
+
+	if o.EnableMDNS {
+		if !o.AllowPrivateCIDRs {
+			logger.Info("mdns: private addresses of local peers are ignored without allowing private CIDRs")
+		}
+		s.mdns = mdns.NewMdnsService(h, mdnsServiceName(networkID), &mdnsNotifee{s: s, allowPrivateCIDRs: o.AllowPrivateCIDRs})
+		if err := s.mdns.Start(); err != nil {
+			return nil, fmt.Errorf("mdns: %w", err)
+		}
+	}
 	return s, nil
 }
 
+// listenIPs returns the IPv4 and IPv6 addresses to listen on for the host,
+// all interfaces of both versions if the host is not an IP address.
+func listenIPs(host string) (ip4Addr, ip6Addr string) {
+	ip4Addr = "0.0.0.0"
+	ip6Addr = "::"
+
+	if host != "" {
+		ip := net.ParseIP(host)
+		if ip4 := ip.To4(); ip4 != nil {
+			ip4Addr = ip4.String()
+			ip6Addr = ""
+		} else if ip6 := ip.To16(); ip6 != nil {
+			ip6Addr = ip6.String()
+			ip4Addr = ""
+		}
+	}
+	return ip4Addr, ip6Addr
+}
+
+// listenPorts returns the TCP and QUIC ports the host listens on.
+func listenPorts(addrs []ma.Multiaddr) (tcpPort, quicPort string, err error) {
+	for _, a := range addrs {
+		if _, err := a.ValueForProtocol(ma.P_QUIC); err == nil {
+			quicPort, _ = a.ValueForProtocol(ma.P_UDP)
+			continue
+		}
+		if _, err := a.ValueForProtocol(ma.P_WS); err == nil {
+			continue
+		}
+		if p, err := a.ValueForProtocol(ma.P_TCP); err == nil {
+			tcpPort = p
+		}
+	}
+	if tcpPort == "" || quicPort == "" {
+		return "", "", errors.New("no tcp or quic listen address")
+	}
+	return tcpPort, quicPort, nil
+}
+
 func (s *Service) reachabilityWorker() error {
 	sub, err := s.host.EventBus().Subscribe([]interface{}{new(event.EvtLocalReachabilityChanged)})
 	if err != nil {
@@ -349,7 +497,7 @@ func (s *Service) handleIncoming(stream network.Stream) {
 
 	overlay := i.BzzAddress.Overlay
 
-	blocked, err := s.blocklist.Exists(overlay)
+	blocked, err := s.blocked(overlay, stream.Conn().RemoteMultiaddr())
 	if err != nil {
 		s.logger.Debugf("stream handler: blocklisting: exists %s: %v", overlay, err)
 		s.logger.Errorf("stream handler: internal error while connecting with peer %s", overlay)
@@ -473,6 +621,7 @@ func (s *Service) handleIncoming(stream network.Stream) {
 
 	s.logger.Debugf("stream handler: successfully connected to peer %s%s%s (inbound)", i.BzzAddress.ShortString(), i.LightString(), peerUserAgent)
 	s.logger.Infof("stream handler: successfully connected to peer %s%s%s (inbound)", i.BzzAddress.Overlay, i.LightString(), peerUserAgent)
+	s.publishPeerEvent(events.PeerConnected, overlay, i.BzzAddress.Underlay, events.DirectionInbound, "")
 }
 
 func (s *Service) SetPickyNotifier(n p2p.PickyNotifier) {
@@ -481,6 +630,12 @@ func (s *Service) SetPickyNotifier(n p2p.PickyNotifier) {
 	s.notifier = n
 }
 
+// SetAddPeersHandler sets the handler of the peers discovered on the local
+// network.
+func (s *Service) SetAddPeersHandler(h func(addr ...swarm.Address)) {
+	s.addPeersHandler = h
+}
+
 func (s *Service) AddProtocol(p p2p.ProtocolSpec) (err error) {
 	for _, ss := range p.StreamSpecs {
 		ss := ss
@@ -507,6 +662,8 @@ func (s *Service) AddProtocol(p p2p.ProtocolSpec) (err error) {
 			}
 
 			stream := newStream(streamlibp2p)
+			stream.meter = s.newMeter(s.ctx, overlay, p.Name)
+			defer stream.meter.cancel()
 
 			// exchange headers
 			if err := handleHeaders(ss.Headler, stream, overlay); err != nil {
@@ -518,7 +675,10 @@ func (s *Service) AddProtocol(p p2p.ProtocolSpec) (err error) {
 
 			ctx, cancel := context.WithCancel(s.ctx)
 
-			s.peers.addStream(peerID, streamlibp2p, cancel)
+			s.peers.addStream(peerID, streamlibp2p, func() {
+				cancel()
+				stream.meter.cancel()
+			})
 			defer s.peers.removeStream(peerID, streamlibp2p)
 
 			// tracing: get span tracing context and add it to the context
@@ -545,7 +705,7 @@ func (s *Service) AddProtocol(p p2p.ProtocolSpec) (err error) {
 				var bpe *p2p.BlockPeerError
 				if errors.As(err, &bpe) {
 					_ = stream.Reset()
-					if err := s.Blocklist(overlay, bpe.Duration(), bpe.Error()); err != nil {
+					if err := s.BlocklistBy(p.Name, overlay, bpe.Duration(), bpe.Error()); err != nil {
 						logger.Debugf("blocklist: could not blocklist peer %s: %v", peerID, err)
 						logger.Errorf("unable to blocklist peer %v", peerID)
 					}
@@ -592,18 +752,79 @@ func (s *Service) NATManager() basichost.NATManager {
 }
 
 func (s *Service) Blocklist(overlay swarm.Address, duration time.Duration, reason string) error {
-	s.logger.Tracef("libp2p blocklist: peer %s for %v reason: %s", overlay.String(), duration, reason)
-	if err := s.blocklist.Add(overlay, duration); err != nil {
+	return s.BlocklistBy("", overlay, duration, reason)
+}
+
+// BlocklistBy blocklists the peer and records the subsystem which initiated
+// the blocklisting.
+func (s *Service) BlocklistBy(subsystem string, overlay swarm.Address, duration time.Duration, reason string) error {
+	s.logger.Tracef("libp2p blocklist: peer %s for %v by %q reason: %s", overlay.String(), duration, subsystem, reason)
+	if err := s.blocklist.Add(overlay, duration, reason, subsystem); err != nil {
 		s.metrics.BlocklistedPeerErrCount.Inc()
 		_ = s.Disconnect(overlay, "failed blocklisting peer")
 		return fmt.Errorf("blocklist peer %s: %w", overlay, err)
 	}
 	s.metrics.BlocklistedPeerCount.Inc()
+	s.publishPeerEvent(events.PeerBlocklisted, overlay, nil, "", reason)
 
 	_ = s.Disconnect(overlay, "blocklisting peer")
 	return nil
 }
 
+// Unblocklist lifts the ban of the peer.
+func (s *Service) Unblocklist(overlay swarm.Address) error {
+	s.logger.Tracef("libp2p blocklist: lifting ban of peer %s", overlay.String())
+	return s.blocklist.Remove(overlay)
+}
+
+// BlocklistEntries returns the blocklisted peers together with the details
+// of their blocklisting.
+func (s *Service) BlocklistEntries() ([]p2p.BlocklistedPeer, error) {
+	return s.blocklist.Entries()
+}
+
+// BlocklistNetwork blocks the connections from and to the underlay IP
+// addresses in the network and disconnects the peers connected over them.
+func (s *Service) BlocklistNetwork(network *net.IPNet, duration time.Duration, reason string) error {
+	s.logger.Tracef("libp2p blocklist: network %s for %v reason: %s", network, duration, reason)
+	if err := s.blocklist.AddNetwork(network, duration, reason, "api"); err != nil {
+		return fmt.Errorf("blocklist network %s: %w", network, err)
+	}
+
+	for _, overlay := range s.peers.inNetwork(network) {
+		_ = s.Disconnect(overlay, "blocklisting network")
+	}
+	return nil
+}
+
+// UnblocklistNetwork lifts the ban of the network.
+func (s *Service) UnblocklistNetwork(network *net.IPNet) error {
+	s.logger.Tracef("libp2p blocklist: lifting ban of network %s", network)
+	return s.blocklist.RemoveNetwork(network)
+}
+
+// BlocklistedNetworks returns the blocklisted networks.
+func (s *Service) BlocklistedNetworks() ([]p2p.BlocklistedNetwork, error) {
+	return s.blocklist.Networks()
+}
+
+// blocked returns true if the peer or the network of the IP address of its
+// underlay are blocklisted. Blocklisted networks are already rejected by the
+// connection gater, they are checked again for the bans added during the
+// handshake.
+func (s *Service) blocked(overlay swarm.Address, underlay ma.Multiaddr) (bool, error) {
+	blocked, err := s.blocklist.Exists(overlay)
+	if err != nil || blocked {
+		return blocked, err
+	}
+	ip, err := manet.ToIP(underlay)
+	if err != nil {
+		// underlays without an IP address are not subject to network bans
+		return false, nil
+	}
+	return s.blocklist.IPExists(ip)
+}
+
 func buildHostAddress(peerID libp2ppeer.ID) (ma.Multiaddr, error) {
 	return ma.NewMultiaddr(fmt.Sprintf("/p2p/%s", peerID.Pretty()))
 }
@@ -670,7 +891,7 @@ func (s *Service) Connect(ctx context.Context, addr ma.Multiaddr) (address *bzz.
 
 	overlay := i.BzzAddress.Overlay
 
-	blocked, err := s.blocklist.Exists(overlay)
+	blocked, err := s.blocked(overlay, stream.Conn().RemoteMultiaddr())
 	if err != nil {
 		s.logger.Debugf("blocklisting: exists %s: %v", info.ID, err)
 		s.logger.Errorf("internal error while connecting with peer %s", info.ID)
@@ -736,6 +957,7 @@ func (s *Service) Connect(ctx context.Context, addr ma.Multiaddr) (address *bzz.
 
 	s.logger.Debugf("successfully connected to peer %s%s%s (outbound)", i.BzzAddress.ShortString(), i.LightString(), peerUserAgent)
 	s.logger.Infof("successfully connected to peer %s%s%s (outbound)", overlay, i.LightString(), peerUserAgent)
+	s.publishPeerEvent(events.PeerConnected, overlay, i.BzzAddress.Underlay, events.DirectionOutbound, "")
 	return i.BzzAddress, nil
 }
 
@@ -747,6 +969,7 @@ func (s *Service) Disconnect(overlay swarm.Address, reason string) error {
 	// found is checked at the bottom of the function
 	found, full, peerID := s.peers.remove(overlay)
 
+	underlay := s.peerUnderlay(peerID)
 	_ = s.host.Network().ClosePeer(peerID)
 
 	peer := p2p.Peer{Address: overlay, FullNode: full}
@@ -771,11 +994,15 @@ func (s *Service) Disconnect(overlay swarm.Address, reason string) error {
 		s.reacher.Disconnected(overlay)
 	}
 
+	s.bandwidth.Remove(overlay)
+
 	if !found {
 		s.logger.Debugf("libp2p disconnect: peer %s not found", overlay)
 		return p2p.ErrPeerNotFound
 	}
 
+	s.publishPeerEvent(events.PeerDisconnected, overlay, underlay, "", reason)
+
 	return nil
 }
 
@@ -810,6 +1037,73 @@ func (s *Service) disconnected(address swarm.Address) {
 	if s.reacher != nil {
 		s.reacher.Disconnected(address)
 	}
+
+	s.bandwidth.Remove(address)
+	s.publishPeerEvent(events.PeerDisconnected, address, nil, "", "connection closed")
+}
+
+// peerUnderlay returns the underlay of a connection with the peer, or nil if
+// there are none.
+func (s *Service) peerUnderlay(peerID libp2ppeer.ID) ma.Multiaddr {
+	for _, c := range s.host.Network().ConnsToPeer(peerID) {
+		if underlay, err := buildUnderlayAddress(c.RemoteMultiaddr(), peerID); err == nil {
+			return underlay
+		}
+	}
+	return nil
+}
+
+// publishPeerEvent publishes a peer lifecycle event if the publisher is set.
+func (s *Service) publishPeerEvent(typ events.Type, overlay swarm.Address, underlay ma.Multiaddr, direction, reason string) {
+	if s.publisher == nil {
+		return
+	}
+	data := events.PeerData{
+		Overlay:   overlay,
+		Bin:       swarm.Proximity(s.overlay.Bytes(), overlay.Bytes()),
+		Direction: direction,
+		Reason:    reason,
+	}
+	if underlay != nil {
+		data.Underlay = underlay.String()
+	}
+	s.publisher.Publish(events.Event{
+		Type: typ,
+		Time: time.Now(),
+		Data: data,
+	})
+}
+
+// newMeter returns the meter of the traffic of a stream with the peer over the
+// protocol. The waits for the limits end when the context is done.
+func (s *Service) newMeter(ctx context.Context, overlay swarm.Address, protocol string) *meter {
+	ctx, cancel := context.WithCancel(ctx)
+	return &meter{
+		ctx:      ctx,
+		cancel:   cancel,
+		account:  s.bandwidth.Account(overlay, protocol),
+		received: s.metrics.ReceivedBytes.WithLabelValues(protocol),
+		sent:     s.metrics.SentBytes.WithLabelValues(protocol),
+	}
+}
+
+// PeerBandwidth returns the number of bytes exchanged with the connected peer
+// per protocol.
+func (s *Service) PeerBandwidth(overlay swarm.Address) ([]p2p.ProtocolBandwidth, error) {
+	if _, found := s.peers.peerID(overlay); !found {
+		return nil, p2p.ErrPeerNotFound
+	}
+
+	stats := s.bandwidth.Peer(overlay)
+	bandwidth := make([]p2p.ProtocolBandwidth, 0, len(stats))
+	for _, st := range stats {
+		bandwidth = append(bandwidth, p2p.ProtocolBandwidth{
+			Protocol: st.Protocol,
+			BytesIn:  st.In,
+			BytesOut: st.Out,
+		})
+	}
+	return bandwidth, nil
 }
 
 func (s *Service) Peers() []p2p.Peer {
@@ -832,6 +1126,9 @@ func (s *Service) NewStream(ctx context.Context, overlay swarm.Address, headers
 	}
 
 	stream := newStream(streamlibp2p)
+	// the stream is used within the request, so the waits for the limits
+	// end with it
+	stream.meter = s.newMeter(ctx, overlay, protocolName)
 
 	// tracing: add span context header
 	if headers == nil {
@@ -868,6 +1165,11 @@ func (s *Service) newStreamForPeerID(ctx context.Context, peerID libp2ppeer.ID,
 }
 
 func (s *Service) Close() error {
+	if s.mdns != nil {
+		if err := s.mdns.Close(); err != nil {
+			return err
+		}
+	}
 	if err := s.libp2pPeerstore.Close(); err != nil {
 		return err
 	}
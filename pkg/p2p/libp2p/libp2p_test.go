@@ -32,6 +32,7 @@ type libp2pServiceOpts struct {
 	MockPeerKey *ecdsa.PrivateKey
 	libp2pOpts  libp2p.Options
 	lightNodes  *lightnode.Container
+	addr        string // listen address, all interfaces if empty
 }
 
 // newService constructs a new libp2p service.
@@ -52,6 +53,9 @@ func newService(t *testing.T, networkID uint64, o libp2pServiceOpts) (s *libp2p.
 	}
 
 	addr := ":0"
+	if o.addr != "" {
+		addr = o.addr
+	}
 
 	if o.Logger == nil {
 		o.Logger = logging.New(io.Discard, 0)
@@ -165,6 +169,34 @@ func serviceUnderlayAddress(t *testing.T, s *libp2p.Service) multiaddr.Multiaddr
 	return addrs[0]
 }
 
+// serviceLoopbackAddress returns the IPv4 loopback underlay of the service
+// that uses the given transport protocol, either TCP or QUIC.
+func serviceLoopbackAddress(t *testing.T, s *libp2p.Service, transport int) multiaddr.Multiaddr {
+	t.Helper()
+
+	addrs, err := s.Addresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range addrs {
+		if ip, err := addr.ValueForProtocol(multiaddr.P_IP4); err != nil || ip != "127.0.0.1" {
+			continue
+		}
+		if _, err := addr.ValueForProtocol(transport); err != nil {
+			continue
+		}
+		if _, err := addr.ValueForProtocol(multiaddr.P_QUIC); err == nil && transport != multiaddr.P_QUIC {
+			continue
+		}
+		if _, err := addr.ValueForProtocol(multiaddr.P_WS); err == nil {
+			continue
+		}
+		return addr
+	}
+	t.Fatalf("no loopback address with transport %d in %v", transport, addrs)
+	return nil
+}
+
 type MockSenderMatcher struct {
 	BlockHash []byte
 }
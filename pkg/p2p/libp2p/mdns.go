@@ -0,0 +1,109 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/libp2p/go-libp2p-core/network"
+	libp2ppeer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+const mdnsConnectTimeout = 15 * time.Second
+
+// mdnsServiceName returns the name of the mDNS service advertised on the
+// local network. It contains the network ID so that only nodes of the same
+// network discover each other.
+func mdnsServiceName(networkID uint64) string {
+	return fmt.Sprintf("_swarm-%d._udp", networkID)
+}
+
+// mdnsNotifee connects to the peers discovered on the local network and
+// passes the connections to the notifier.
+type mdnsNotifee struct {
+	s                 *Service
+	allowPrivateCIDRs bool
+}
+
+// HandlePeerFound implements the mdns.Notifee interface.
+func (n *mdnsNotifee) HandlePeerFound(info libp2ppeer.AddrInfo) {
+	s := n.s
+
+	select {
+	case <-s.ready:
+	case <-s.halt:
+		return
+	case <-s.ctx.Done():
+		return
+	}
+
+	if info.ID == s.host.ID() || s.host.Network().Connectedness(info.ID) == network.Connected {
+		return
+	}
+
+	underlay, err := n.underlay(info)
+	if err != nil {
+		s.logger.Debugf("mdns: peer %s: %v", info.ID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, mdnsConnectTimeout)
+	defer cancel()
+
+	bzzAddress, err := s.Connect(ctx, underlay)
+	if err != nil {
+		if !errors.Is(err, p2p.ErrAlreadyConnected) {
+			s.logger.Debugf("mdns: connect to peer %s: %v", underlay, err)
+		}
+		return
+	}
+	s.metrics.MDNSDiscoveredPeerCount.Inc()
+	s.logger.Debugf("mdns: discovered peer %s", bzzAddress.ShortString())
+
+	overlay := bzzAddress.Overlay
+	if s.notifier == nil {
+		return
+	}
+
+	peer := p2p.Peer{Address: overlay, FullNode: true, EthereumAddress: bzzAddress.EthereumAddress}
+	if !s.notifier.Pick(peer) {
+		_ = s.Disconnect(overlay, "mdns discovered peer not picked")
+		// the peer is stored in the address book by the connect and may be
+		// connected later by the topology
+		if s.addPeersHandler != nil {
+			s.addPeersHandler(overlay)
+		}
+		return
+	}
+
+	if err := s.notifier.Connected(ctx, peer, false); err != nil {
+		s.logger.Debugf("mdns: notifier.Connected: peer %s: %v", overlay, err)
+		_ = s.Disconnect(overlay, "unable to signal connection notifier")
+	}
+}
+
+// underlay returns the TCP underlay of the discovered peer, ignoring private
+// addresses unless they are allowed.
+func (n *mdnsNotifee) underlay(info libp2ppeer.AddrInfo) (ma.Multiaddr, error) {
+	for _, a := range info.Addrs {
+		if _, err := a.ValueForProtocol(ma.P_TCP); err != nil {
+			continue
+		}
+		if _, err := a.ValueForProtocol(ma.P_WS); err == nil {
+			continue
+		}
+		if !n.allowPrivateCIDRs && !manet.IsPublicAddr(a) {
+			continue
+		}
+		return buildUnderlayAddress(a, info.ID)
+	}
+	return nil, errors.New("no usable address")
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p_test
+
+import (
+	"testing"
+
+	"github.com/holisticode/bee/pkg/p2p/libp2p"
+	libp2ppeer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestMDNSUnderlay(t *testing.T) {
+	id, err := libp2ppeer.Decode("16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addrs := func(ss ...string) []ma.Multiaddr {
+		t.Helper()
+		var addrs []ma.Multiaddr
+		for _, s := range ss {
+			a, err := ma.NewMultiaddr(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			addrs = append(addrs, a)
+		}
+		return addrs
+	}
+
+	for _, tc := range []struct {
+		name              string
+		addrs             []ma.Multiaddr
+		allowPrivateCIDRs bool
+		want              string
+	}{
+		{
+			name:              "private allowed",
+			addrs:             addrs("/ip4/192.168.1.10/udp/1634/quic", "/ip4/192.168.1.10/tcp/1634/ws", "/ip4/192.168.1.10/tcp/1634"),
+			allowPrivateCIDRs: true,
+			want:              "/ip4/192.168.1.10/tcp/1634/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA",
+		},
+		{
+			name:  "private not allowed",
+			addrs: addrs("/ip4/192.168.1.10/tcp/1634", "/ip4/1.1.1.1/tcp/1634"),
+			want:  "/ip4/1.1.1.1/tcp/1634/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA",
+		},
+		{
+			name:  "no usable address",
+			addrs: addrs("/ip4/192.168.1.10/tcp/1634", "/ip4/127.0.0.1/tcp/1634"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := libp2p.MDNSUnderlay(libp2ppeer.AddrInfo{ID: id, Addrs: tc.addrs}, tc.allowPrivateCIDRs)
+			if tc.want == "" {
+				if err == nil {
+					t.Fatalf("got underlay %s, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tc.want {
+				t.Fatalf("got underlay %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -23,7 +23,12 @@ type metrics struct {
 	ConnectBreakerCount        prometheus.Counter
 	UnexpectedProtocolReqCount prometheus.Counter
 	KickedOutPeersCount        prometheus.Counter
+	ConnectionLimitRejectCount prometheus.Counter
+	NetworkBanRejectCount      prometheus.Counter
+	MDNSDiscoveredPeerCount    prometheus.Counter
 	HeadersExchangeDuration    prometheus.Histogram
+	ReceivedBytes              *prometheus.CounterVec
+	SentBytes                  *prometheus.CounterVec
 }
 
 func newMetrics() metrics {
@@ -90,12 +95,42 @@ func newMetrics() metrics {
 			Name:      "kickedout_peers_count",
 			Help:      "Number of total kicked-out peers.",
 		}),
+		ConnectionLimitRejectCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "connection_limit_reject_count",
+			Help:      "Number of connections rejected for exceeding the connection limits.",
+		}),
+		NetworkBanRejectCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "network_ban_reject_count",
+			Help:      "Number of connections rejected for an address in a blocklisted network.",
+		}),
+		MDNSDiscoveredPeerCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "mdns_discovered_peer_count",
+			Help:      "Number of peers discovered and connected on the local network.",
+		}),
 		HeadersExchangeDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
 			Name:      "headers_exchange_duration",
 			Help:      "The duration spent exchanging the headers.",
 		}),
+		ReceivedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "received_bytes",
+			Help:      "Number of bytes received over protocol streams by protocol.",
+		}, []string{"protocol"}),
+		SentBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "sent_bytes",
+			Help:      "Number of bytes sent over protocol streams by protocol.",
+		}, []string{"protocol"}),
 	}
 }
 
@@ -0,0 +1,38 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/libp2p/go-libp2p-core/pnet"
+)
+
+// networkKeyLength is the length of a pre-shared private network key.
+const networkKeyLength = 32
+
+// GenerateNetworkKey writes a new random pre-shared private network key in
+// the format of the libp2p swarm.key files.
+func GenerateNetworkKey(w io.Writer) error {
+	key := make([]byte, networkKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "/key/swarm/psk/1.0.0/\n/base16/\n%s\n", hex.EncodeToString(key))
+	return err
+}
+
+// ReadNetworkKey reads a pre-shared private network key in the format of the
+// libp2p swarm.key files.
+func ReadNetworkKey(r io.Reader) ([]byte, error) {
+	key, err := pnet.DecodeV1PSK(r)
+	if err != nil {
+		return nil, fmt.Errorf("network key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/holisticode/bee/pkg/p2p/libp2p"
+)
+
+func TestNetworkKey(t *testing.T) {
+	var buf bytes.Buffer
+	if err := libp2p.GenerateNetworkKey(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "/key/swarm/psk/1.0.0/\n/base16/\n") {
+		t.Fatalf("unexpected key file %q", buf.String())
+	}
+
+	key, err := libp2p.ReadNetworkKey(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != 32 {
+		t.Fatalf("got key length %d, want 32", len(key))
+	}
+
+	if _, err := libp2p.ReadNetworkKey(strings.NewReader("invalid")); err == nil {
+		t.Fatal("expected error reading an invalid key")
+	}
+}
+
+func TestConnectPrivateNetwork(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newKey := func() []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := libp2p.GenerateNetworkKey(&buf); err != nil {
+			t.Fatal(err)
+		}
+		key, err := libp2p.ReadNetworkKey(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	key := newKey()
+
+	s1, overlay1 := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		FullNode:          true,
+		PrivateNetworkKey: key,
+	}})
+	s2, _ := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		PrivateNetworkKey: key,
+	}})
+	s3, _ := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		PrivateNetworkKey: newKey(),
+	}})
+	s4, _ := newService(t, 1, libp2pServiceOpts{})
+
+	addr := serviceUnderlayAddress(t, s1)
+
+	// peers without the key of the network can not connect
+	if _, err := s3.Connect(ctx, addr); err == nil {
+		t.Fatal("connected with a different network key")
+	}
+	if _, err := s4.Connect(ctx, addr); err == nil {
+		t.Fatal("connected without a network key")
+	}
+	expectPeers(t, s3)
+	expectPeers(t, s4)
+
+	if _, err := s2.Connect(ctx, addr); err != nil {
+		t.Fatal(err)
+	}
+	expectPeers(t, s2, overlay1)
+}
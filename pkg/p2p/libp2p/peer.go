@@ -7,6 +7,7 @@ package libp2p
 import (
 	"bytes"
 	"context"
+	"net"
 	"sort"
 	"sync"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/libp2p/go-libp2p-core/network"
 	libp2ppeer "github.com/libp2p/go-libp2p-core/peer"
 	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
 )
 
 type peerRegistry struct {
@@ -205,6 +207,28 @@ func (r *peerRegistry) isConnected(peerID libp2ppeer.ID, remoteAddr ma.Multiaddr
 	return swarm.ZeroAddress, false
 }
 
+// inNetwork returns the peers with a connection from an IP address in the
+// network.
+func (r *peerRegistry) inNetwork(network *net.IPNet) (overlays []swarm.Address) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for peerID, conns := range r.connections {
+		overlay, ok := r.overlays[peerID]
+		if !ok {
+			continue
+		}
+		for c := range conns {
+			ip, err := manet.ToIP(c.RemoteMultiaddr())
+			if err == nil && network.Contains(ip) {
+				overlays = append(overlays, overlay)
+				break
+			}
+		}
+	}
+	return overlays
+}
+
 func (r *peerRegistry) remove(overlay swarm.Address) (found, full bool, peerID libp2ppeer.ID) {
 	r.mu.Lock()
 	peerID, found = r.underlays[overlay.ByteString()]
@@ -7,6 +7,7 @@ package libp2p_test
 import (
 	"context"
 	"errors"
+	"io"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -54,6 +55,69 @@ func TestNewStream(t *testing.T) {
 
 // TestNewStream_OnlyFull tests that the handler gets the full
 // node information communicated correctly.
+func TestPeerBandwidth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		FullNode: true,
+	}})
+
+	s2, overlay2 := newService(t, 1, libp2pServiceOpts{})
+
+	handled := make(chan struct{})
+	if err := s1.AddProtocol(newTestProtocol(func(_ context.Context, p p2p.Peer, stream p2p.Stream) error {
+		defer close(handled)
+		defer stream.FullClose()
+		_, err := io.ReadFull(stream, make([]byte, 5))
+		return err
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s2.PeerBandwidth(overlay1); !errors.Is(err, p2p.ErrPeerNotFound) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrPeerNotFound)
+	}
+
+	if _, err := s2.Connect(ctx, serviceUnderlayAddress(t, s1)); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := s2.NewStream(ctx, overlay1, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.FullClose(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-handled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream not handled")
+	}
+
+	// the traffic includes the exchanged headers
+	sent, err := s2.PeerBandwidth(overlay1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0].Protocol != testProtocolName || sent[0].BytesOut < 5 {
+		t.Fatalf("got sent traffic %+v, want at least 5 bytes over %s", sent, testProtocolName)
+	}
+
+	received, err := s1.PeerBandwidth(overlay2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[0].BytesIn < 5 {
+		t.Fatalf("got received traffic %+v, want at least 5 bytes over %s", received, testProtocolName)
+	}
+}
+
 func TestNewStream_OnlyFull(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
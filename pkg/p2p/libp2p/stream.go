@@ -5,12 +5,17 @@
 package libp2p
 
 import (
+	"context"
 	"errors"
 	"io"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/p2p/libp2p/internal/bandwidth"
 	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -23,6 +28,59 @@ type stream struct {
 	network.Stream
 	headers         map[string][]byte
 	responseHeaders map[string][]byte
+	meter           *meter
+}
+
+// meter counts and limits the traffic of a stream. The waits for the limits
+// end with the stream deadlines and when the stream is closed or reset.
+type meter struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	account  *bandwidth.Account
+	received prometheus.Counter
+	sent     prometheus.Counter
+
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+// context returns the context of a wait bounded by the deadline.
+func (m *meter) context(deadline *time.Time) (context.Context, context.CancelFunc) {
+	m.mu.Lock()
+	d := *deadline
+	m.mu.Unlock()
+
+	if d.IsZero() {
+		return m.ctx, func() {}
+	}
+	return context.WithDeadline(m.ctx, d)
+}
+
+// wait waits for the limits with f, reporting an exceeded deadline as the
+// stream does.
+func (m *meter) wait(deadline *time.Time, f func(context.Context) error) error {
+	ctx, cancel := m.context(deadline)
+	defer cancel()
+
+	err := f(ctx)
+	if err != nil && m.ctx.Err() == nil {
+		// the wait ended or would end after the stream deadline
+		return os.ErrDeadlineExceeded
+	}
+	return err
+}
+
+func (m *meter) setDeadline(read, write bool, t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if read {
+		m.readDeadline = t
+	}
+	if write {
+		m.writeDeadline = t
+	}
 }
 
 func NewStream(s network.Stream) p2p.Stream {
@@ -32,6 +90,75 @@ func NewStream(s network.Stream) p2p.Stream {
 func newStream(s network.Stream) *stream {
 	return &stream{Stream: s}
 }
+
+func (s *stream) Read(p []byte) (int, error) {
+	if s.meter == nil {
+		return s.Stream.Read(p)
+	}
+	// slow down reading to apply back pressure on the sender, the size of
+	// the read is not known in advance, so it is charged afterwards
+	if err := s.meter.wait(&s.meter.readDeadline, s.meter.account.Ready); err != nil {
+		return 0, err
+	}
+	n, err := s.Stream.Read(p)
+	if n > 0 {
+		s.meter.account.Received(n)
+		s.meter.account.Charge(n)
+		s.meter.received.Add(float64(n))
+	}
+	return n, err
+}
+
+func (s *stream) Write(p []byte) (int, error) {
+	if s.meter == nil {
+		return s.Stream.Write(p)
+	}
+	if err := s.meter.wait(&s.meter.writeDeadline, func(ctx context.Context) error {
+		return s.meter.account.Wait(ctx, len(p))
+	}); err != nil {
+		return 0, err
+	}
+	n, err := s.Stream.Write(p)
+	s.meter.account.Sent(n)
+	s.meter.sent.Add(float64(n))
+	return n, err
+}
+
+func (s *stream) SetDeadline(t time.Time) error {
+	if s.meter != nil {
+		s.meter.setDeadline(true, true, t)
+	}
+	return s.Stream.SetDeadline(t)
+}
+
+func (s *stream) SetReadDeadline(t time.Time) error {
+	if s.meter != nil {
+		s.meter.setDeadline(true, false, t)
+	}
+	return s.Stream.SetReadDeadline(t)
+}
+
+func (s *stream) SetWriteDeadline(t time.Time) error {
+	if s.meter != nil {
+		s.meter.setDeadline(false, true, t)
+	}
+	return s.Stream.SetWriteDeadline(t)
+}
+
+func (s *stream) Close() error {
+	if s.meter != nil {
+		s.meter.cancel()
+	}
+	return s.Stream.Close()
+}
+
+func (s *stream) Reset() error {
+	if s.meter != nil {
+		s.meter.cancel()
+	}
+	return s.Stream.Reset()
+}
+
 func (s *stream) Headers() p2p.Headers {
 	return s.headers
 }
@@ -7,6 +7,7 @@ package mock
 import (
 	"context"
 	"errors"
+	"net"
 	"time"
 
 	"github.com/holisticode/bee/pkg/bzz"
@@ -27,6 +28,13 @@ type Service struct {
 	setWelcomeMessageFunc func(string) error
 	getWelcomeMessageFunc func() string
 	blocklistFunc         func(swarm.Address, time.Duration, string) error
+	blocklistByFunc       func(string, swarm.Address, time.Duration, string) error
+	blocklistEntriesFunc  func() ([]p2p.BlocklistedPeer, error)
+	unblocklistFunc       func(swarm.Address) error
+	blocklistNetworkFunc  func(*net.IPNet, time.Duration, string) error
+	unblocklistNetFunc    func(*net.IPNet) error
+	blocklistedNetsFunc   func() ([]p2p.BlocklistedNetwork, error)
+	peerBandwidthFunc     func(swarm.Address) ([]p2p.ProtocolBandwidth, error)
 	welcomeMessage        string
 }
 
@@ -92,6 +100,55 @@ func WithBlocklistFunc(f func(swarm.Address, time.Duration, string) error) Optio
 	})
 }
 
+// WithBlocklistByFunc sets the mock implementation of the BlocklistBy function
+func WithBlocklistByFunc(f func(string, swarm.Address, time.Duration, string) error) Option {
+	return optionFunc(func(s *Service) {
+		s.blocklistByFunc = f
+	})
+}
+
+// WithBlocklistEntriesFunc sets the mock implementation of the BlocklistEntries function
+func WithBlocklistEntriesFunc(f func() ([]p2p.BlocklistedPeer, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.blocklistEntriesFunc = f
+	})
+}
+
+// WithUnblocklistFunc sets the mock implementation of the Unblocklist function
+func WithUnblocklistFunc(f func(swarm.Address) error) Option {
+	return optionFunc(func(s *Service) {
+		s.unblocklistFunc = f
+	})
+}
+
+// WithBlocklistNetworkFunc sets the mock implementation of the BlocklistNetwork function
+func WithBlocklistNetworkFunc(f func(*net.IPNet, time.Duration, string) error) Option {
+	return optionFunc(func(s *Service) {
+		s.blocklistNetworkFunc = f
+	})
+}
+
+// WithUnblocklistNetworkFunc sets the mock implementation of the UnblocklistNetwork function
+func WithUnblocklistNetworkFunc(f func(*net.IPNet) error) Option {
+	return optionFunc(func(s *Service) {
+		s.unblocklistNetFunc = f
+	})
+}
+
+// WithBlocklistedNetworksFunc sets the mock implementation of the BlocklistedNetworks function
+func WithBlocklistedNetworksFunc(f func() ([]p2p.BlocklistedNetwork, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.blocklistedNetsFunc = f
+	})
+}
+
+// WithPeerBandwidthFunc sets the mock implementation of the PeerBandwidth function
+func WithPeerBandwidthFunc(f func(swarm.Address) ([]p2p.ProtocolBandwidth, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.peerBandwidthFunc = f
+	})
+}
+
 // New will create a new mock P2P Service with the given options
 func New(opts ...Option) *Service {
 	s := new(Service)
@@ -173,6 +230,67 @@ func (s *Service) Blocklist(overlay swarm.Address, duration time.Duration, reaso
 	return s.blocklistFunc(overlay, duration, reason)
 }
 
+func (s *Service) BlocklistBy(subsystem string, overlay swarm.Address, duration time.Duration, reason string) error {
+	if s.blocklistByFunc == nil {
+		return s.Blocklist(overlay, duration, reason)
+	}
+	return s.blocklistByFunc(subsystem, overlay, duration, reason)
+}
+
+// BlocklistEntries returns the entries of the configured function, or the
+// peers returned by BlocklistedPeers if it is not configured.
+func (s *Service) BlocklistEntries() ([]p2p.BlocklistedPeer, error) {
+	if s.blocklistEntriesFunc != nil {
+		return s.blocklistEntriesFunc()
+	}
+	peers, err := s.BlocklistedPeers()
+	if err != nil {
+		return nil, err
+	}
+	var entries []p2p.BlocklistedPeer
+	for _, p := range peers {
+		entries = append(entries, p2p.BlocklistedPeer{Peer: p})
+	}
+	return entries, nil
+}
+
+func (s *Service) Unblocklist(overlay swarm.Address) error {
+	if s.unblocklistFunc == nil {
+		return errors.New("function unblocklist not configured")
+	}
+	return s.unblocklistFunc(overlay)
+}
+
+func (s *Service) BlocklistNetwork(network *net.IPNet, duration time.Duration, reason string) error {
+	if s.blocklistNetworkFunc == nil {
+		return errors.New("function blocklist network not configured")
+	}
+	return s.blocklistNetworkFunc(network, duration, reason)
+}
+
+func (s *Service) UnblocklistNetwork(network *net.IPNet) error {
+	if s.unblocklistNetFunc == nil {
+		return errors.New("function unblocklist network not configured")
+	}
+	return s.unblocklistNetFunc(network)
+}
+
+func (s *Service) BlocklistedNetworks() ([]p2p.BlocklistedNetwork, error) {
+	if s.blocklistedNetsFunc == nil {
+		return nil, nil
+	}
+	return s.blocklistedNetsFunc()
+}
+
+// PeerBandwidth returns the traffic of the configured function, or
+// ErrPeerNotFound if it is not configured.
+func (s *Service) PeerBandwidth(overlay swarm.Address) ([]p2p.ProtocolBandwidth, error) {
+	if s.peerBandwidthFunc == nil {
+		return nil, p2p.ErrPeerNotFound
+	}
+	return s.peerBandwidthFunc(overlay)
+}
+
 func (s *Service) SetPickyNotifier(f p2p.PickyNotifier) {
 	s.notifierFunc = f
 }
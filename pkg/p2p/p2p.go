@@ -9,6 +9,7 @@ package p2p
 import (
 	"context"
 	"io"
+	"net"
 	"time"
 
 	"github.com/holisticode/bee/pkg/bzz"
@@ -55,6 +56,62 @@ type Blocklister interface {
 	Blocklist(overlay swarm.Address, duration time.Duration, reason string) error
 }
 
+// SubsystemBlocklister is a Blocklister which records the subsystem which
+// initiated the blocklisting of a peer.
+type SubsystemBlocklister interface {
+	BlocklistBy(subsystem string, overlay swarm.Address, duration time.Duration, reason string) error
+}
+
+// BlocklistBy blocklists the peer on behalf of the subsystem. The subsystem
+// is recorded if the Blocklister is also a SubsystemBlocklister.
+func BlocklistBy(b Blocklister, subsystem string, overlay swarm.Address, duration time.Duration, reason string) error {
+	if sb, ok := b.(SubsystemBlocklister); ok {
+		return sb.BlocklistBy(subsystem, overlay, duration, reason)
+	}
+	return b.Blocklist(overlay, duration, reason)
+}
+
+// BlocklistEntry describes why, by whom and for how long a peer or a network
+// is blocklisted.
+type BlocklistEntry struct {
+	Reason    string
+	Subsystem string
+	Timestamp time.Time
+	// Duration of the ban, zero for a permanent one.
+	Duration time.Duration
+}
+
+// BlocklistedPeer is a blocklisted peer together with its blocklist entry.
+type BlocklistedPeer struct {
+	Peer
+	BlocklistEntry
+}
+
+// BlocklistedNetwork is a blocklisted range of underlay IP addresses together
+// with its blocklist entry.
+type BlocklistedNetwork struct {
+	Network *net.IPNet
+	BlocklistEntry
+}
+
+// BlocklistManager allows the node operator to inspect and manage the
+// blocklist.
+type BlocklistManager interface {
+	SubsystemBlocklister
+	// BlocklistEntries returns the currently blocklisted peers.
+	BlocklistEntries() ([]BlocklistedPeer, error)
+	// Unblocklist lifts the ban of the peer.
+	Unblocklist(overlay swarm.Address) error
+	// BlocklistNetwork disconnects the peers with underlays in the network
+	// and blocks connections from and to the network for the provided
+	// duration. Duration 0 is treated as an infinite duration.
+	BlocklistNetwork(network *net.IPNet, duration time.Duration, reason string) error
+	// UnblocklistNetwork lifts the ban of the network.
+	UnblocklistNetwork(network *net.IPNet) error
+	// BlocklistedNetworks returns the currently blocklisted networks.
+	BlocklistedNetworks() ([]BlocklistedNetwork, error)
+}
+
 type Halter interface {
 	// Halt new incoming connections while shutting down
 	Halt()
@@ -92,9 +149,26 @@ type Notifier interface {
 	AnnounceTo(ctx context.Context, addressee, peer swarm.Address, fullnode bool) error
 }
 
+// ProtocolBandwidth is the number of bytes exchanged with a peer over a
+// protocol.
+type ProtocolBandwidth struct {
+	Protocol string
+	BytesIn  uint64
+	BytesOut uint64
+}
+
+// BandwidthReporter reports the traffic exchanged with the peers.
+type BandwidthReporter interface {
+	// PeerBandwidth returns the traffic with the connected peer per
+	// protocol, or ErrPeerNotFound if the peer is not connected.
+	PeerBandwidth(overlay swarm.Address) ([]ProtocolBandwidth, error)
+}
+
 // DebugService extends the Service with method used for debugging.
 type DebugService interface {
 	Service
+	BlocklistManager
+	BandwidthReporter
 	SetWelcomeMessage(val string) error
 	GetWelcomeMessage() string
 }
@@ -136,6 +136,8 @@ func (r *Recorder) NewStream(ctx context.Context, addr swarm.Address, h p2p.Head
 	for i := len(r.middlewares) - 1; i >= 0; i-- {
 		handler = r.middlewares[i](handler)
 	}
+	// the handler reads the request headers like on a libp2p stream
+	streamIn.headers = h
 	if headler != nil {
 		streamOut.headers = headler(h, addr)
 	}
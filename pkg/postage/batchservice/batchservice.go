@@ -11,7 +11,10 @@ import (
 	"fmt"
 	"hash"
 	"math/big"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/postage"
 	"github.com/holisticode/bee/pkg/storage"
@@ -35,13 +38,19 @@ type batchService struct {
 
 	checksum hash.Hash // checksum hasher
 	resync   bool
+
+	publisher     events.Publisher
+	expiryWarning uint64          // number of blocks before expiry owned batches are announced
+	expiring      map[string]bool // owned batches already announced as expiring
+	synced        chan struct{}   // closed once the listener is synced
 }
 
 type Interface interface {
 	postage.EventUpdater
 }
 
-// New will create a new BatchService.
+// New will create a new BatchService. Owned batches which expire within
+// expiryWarning blocks are announced through the publisher, if given.
 func New(
 	stateStore storage.StateStorer,
 	storer postage.Storer,
@@ -49,6 +58,8 @@ func New(
 	listener postage.Listener,
 	owner []byte,
 	batchListener postage.BatchEventListener,
+	publisher events.Publisher,
+	expiryWarning uint64,
 	checksumFunc func() hash.Hash,
 	resync bool,
 ) (Interface, error) {
@@ -90,7 +101,20 @@ func New(
 		}
 	}
 
-	return &batchService{stateStore, storer, logger, listener, owner, batchListener, sum, resync}, nil
+	return &batchService{
+		stateStore:    stateStore,
+		storer:        storer,
+		logger:        logger,
+		listener:      listener,
+		owner:         owner,
+		batchListener: batchListener,
+		checksum:      sum,
+		resync:        resync,
+		publisher:     publisher,
+		expiryWarning: expiryWarning,
+		expiring:      make(map[string]bool),
+		synced:        make(chan struct{}),
+	}, nil
 }
 
 // Create will create a new batch with the given ID, owner value and depth and
@@ -219,6 +243,68 @@ func (svc *batchService) UpdateBlockNumber(blockNumber uint64) error {
 	}
 
 	svc.logger.Debugf("batch service: updated block height to %d", blockNumber)
+
+	if err := svc.announceExpiring(cs); err != nil {
+		svc.logger.Errorf("batch service: announce expiring batches: %v", err)
+	}
+	return nil
+}
+
+// announceExpiring publishes a BatchExpiring event for every owned batch
+// which expires within the warning period. Each batch is announced once
+// unless it is topped up beyond the warning period in the meantime.
+// Nothing is announced before the listener is synced, as the chain state
+// is outdated until then.
+func (svc *batchService) announceExpiring(cs *postage.ChainState) error {
+	if svc.publisher == nil || svc.expiryWarning == 0 || cs.CurrentPrice.Sign() == 0 {
+		return nil
+	}
+	select {
+	case <-svc.synced:
+	default:
+		return nil
+	}
+
+	limit := new(big.Int).SetUint64(svc.expiryWarning)
+	limit.Mul(limit, cs.CurrentPrice)
+	limit.Add(limit, cs.TotalAmount)
+
+	expiring := make(map[string]bool)
+	err := svc.storer.Iterate(func(b *postage.Batch) (bool, error) {
+		// batches are iterated in ascending order of value
+		if b.Value.Cmp(limit) >= 0 {
+			return true, nil
+		}
+		if !bytes.Equal(b.Owner, svc.owner) {
+			return false, nil
+		}
+
+		id := hex.EncodeToString(b.ID)
+		expiring[id] = true
+		if svc.expiring[id] {
+			return false, nil
+		}
+
+		blocksLeft := new(big.Int).Sub(b.Value, cs.TotalAmount)
+		blocksLeft.Div(blocksLeft, cs.CurrentPrice)
+		if blocksLeft.Sign() < 0 {
+			blocksLeft.SetUint64(0)
+		}
+		svc.publisher.Publish(events.Event{
+			Type: events.BatchExpiring,
+			Time: time.Now(),
+			Data: events.BatchData{
+				BatchID:    id,
+				Owner:      common.BytesToAddress(b.Owner),
+				BlocksLeft: blocksLeft.Uint64(),
+			},
+		})
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	svc.expiring = expiring
 	return nil
 }
 func (svc *batchService) TransactionStart() error {
@@ -255,7 +341,12 @@ func (svc *batchService) Start(startBlock uint64) (<-chan struct{}, error) {
 	if cs.Block > startBlock {
 		startBlock = cs.Block
 	}
-	return svc.listener.Listen(startBlock+1, svc), nil
+	synced := svc.listener.Listen(startBlock+1, svc)
+	go func() {
+		<-synced
+		close(svc.synced)
+	}()
+	return synced, nil
 }
 
 // updateChecksum updates the batchservice checksum once an event gets
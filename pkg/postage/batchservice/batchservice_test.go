@@ -6,13 +6,16 @@ package batchservice_test
 
 import (
 	"bytes"
+	"encoding/hex"
 	"errors"
 	"hash"
 	"io"
 	"math/big"
 	"math/rand"
 	"testing"
+	"time"
 
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/postage"
 	"github.com/holisticode/bee/pkg/postage/batchservice"
@@ -31,8 +34,12 @@ var (
 type mockListener struct {
 }
 
-func (*mockListener) Listen(from uint64, updater postage.EventUpdater) <-chan struct{} { return nil }
-func (*mockListener) Close() error                                                     { return nil }
+func (*mockListener) Listen(from uint64, updater postage.EventUpdater) <-chan struct{} {
+	synced := make(chan struct{})
+	close(synced)
+	return synced
+}
+func (*mockListener) Close() error { return nil }
 
 func newMockListener() *mockListener {
 	return &mockListener{}
@@ -482,6 +489,73 @@ func TestBatchServiceUpdateBlockNumber(t *testing.T) {
 	}
 }
 
+func TestBatchServiceExpiringEvents(t *testing.T) {
+	owner := postagetesting.MustNewID()
+	batch := postagetesting.MustNewBatch(postagetesting.WithOwner(owner))
+	batch.Value = big.NewInt(150)
+
+	var published []events.Event
+	publisher := events.PublisherFunc(func(e events.Event) {
+		published = append(published, e)
+	})
+
+	s := mocks.NewStateStore()
+	store := mock.New(
+		mock.WithBatch(batch),
+		mock.WithChainState(&postage.ChainState{
+			Block:        1,
+			CurrentPrice: big.NewInt(10),
+			TotalAmount:  big.NewInt(100),
+		}),
+	)
+	svc, err := batchservice.New(s, store, testLog, newMockListener(), owner, nil, publisher, 10, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no events are published before the listener is synced
+	if err := svc.UpdateBlockNumber(2); err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 0 {
+		t.Fatalf("got %d events before sync, want 0", len(published))
+	}
+
+	synced, err := svc.Start(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-synced
+	time.Sleep(10 * time.Millisecond)
+
+	// total amount 120, the batch expires in 3 blocks
+	if err := svc.UpdateBlockNumber(3); err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 1 {
+		t.Fatalf("got %d events, want 1", len(published))
+	}
+	e := published[0]
+	if e.Type != events.BatchExpiring {
+		t.Fatalf("got event type %s, want %s", e.Type, events.BatchExpiring)
+	}
+	data := e.Data.(events.BatchData)
+	if data.BatchID != hex.EncodeToString(batch.ID) {
+		t.Fatalf("got batch id %s, want %x", data.BatchID, batch.ID)
+	}
+	if data.BlocksLeft != 3 {
+		t.Fatalf("got %d blocks left, want 3", data.BlocksLeft)
+	}
+
+	// the batch is announced only once
+	if err := svc.UpdateBlockNumber(4); err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 1 {
+		t.Fatalf("got %d events, want 1", len(published))
+	}
+}
+
 func TestTransactionOk(t *testing.T) {
 	svc, store, s := newTestStoreAndService(t)
 	if _, err := svc.Start(10); err != nil {
@@ -496,7 +570,7 @@ func TestTransactionOk(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	svc2, err := batchservice.New(s, store, testLog, newMockListener(), nil, nil, nil, false)
+	svc2, err := batchservice.New(s, store, testLog, newMockListener(), nil, nil, nil, 0, nil, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -519,7 +593,7 @@ func TestTransactionFail(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	svc2, err := batchservice.New(s, store, testLog, newMockListener(), nil, nil, nil, false)
+	svc2, err := batchservice.New(s, store, testLog, newMockListener(), nil, nil, nil, 0, nil, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -536,7 +610,7 @@ func TestChecksum(t *testing.T) {
 	s := mocks.NewStateStore()
 	store := mock.New()
 	mockHash := &hs{}
-	svc, err := batchservice.New(s, store, testLog, newMockListener(), nil, nil, func() hash.Hash { return mockHash }, false)
+	svc, err := batchservice.New(s, store, testLog, newMockListener(), nil, nil, nil, 0, func() hash.Hash { return mockHash }, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -556,7 +630,7 @@ func TestChecksumResync(t *testing.T) {
 	s := mocks.NewStateStore()
 	store := mock.New()
 	mockHash := &hs{}
-	svc, err := batchservice.New(s, store, testLog, newMockListener(), nil, nil, func() hash.Hash { return mockHash }, true)
+	svc, err := batchservice.New(s, store, testLog, newMockListener(), nil, nil, nil, 0, func() hash.Hash { return mockHash }, true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -574,7 +648,7 @@ func TestChecksumResync(t *testing.T) {
 	// now start a new instance and check that the value gets read from statestore
 	store2 := mock.New()
 	mockHash2 := &hs{}
-	_, err = batchservice.New(s, store2, testLog, newMockListener(), nil, nil, func() hash.Hash { return mockHash2 }, false)
+	_, err = batchservice.New(s, store2, testLog, newMockListener(), nil, nil, nil, 0, func() hash.Hash { return mockHash2 }, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -586,7 +660,7 @@ func TestChecksumResync(t *testing.T) {
 	// when resyncing
 	store3 := mock.New()
 	mockHash3 := &hs{}
-	_, err = batchservice.New(s, store3, testLog, newMockListener(), nil, nil, func() hash.Hash { return mockHash3 }, true)
+	_, err = batchservice.New(s, store3, testLog, newMockListener(), nil, nil, nil, 0, func() hash.Hash { return mockHash3 }, true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -604,7 +678,7 @@ func newTestStoreAndServiceWithListener(
 	t.Helper()
 	s := mocks.NewStateStore()
 	store := mock.New(opts...)
-	svc, err := batchservice.New(s, store, testLog, newMockListener(), owner, batchListener, nil, false)
+	svc, err := batchservice.New(s, store, testLog, newMockListener(), owner, batchListener, nil, 0, nil, false)
 	if err != nil {
 		t.Fatal(err)
 	}
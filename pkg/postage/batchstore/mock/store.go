@@ -156,6 +156,15 @@ func (bs *BatchStore) Exists(id []byte) (bool, error) {
 	return bytes.Equal(bs.id, id), nil
 }
 
+// Iterate calls the callback with the stored batch, if any.
+func (bs *BatchStore) Iterate(cb func(*postage.Batch) (bool, error)) error {
+	if bs.batch == nil {
+		return nil
+	}
+	_, err := cb(bs.batch)
+	return err
+}
+
 func (bs *BatchStore) Reset() error {
 	bs.resetCallCount++
 	return nil
@@ -29,11 +29,15 @@ package batchstore
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/big"
 	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/postage"
 	"github.com/holisticode/bee/pkg/storage"
 	"github.com/holisticode/bee/pkg/swarm"
@@ -127,7 +131,10 @@ func (s *store) Unreserve(cb postage.UnreserveIteratorFn) error {
 
 // evictExpired is called when PutChainState is called (and there is 'settlement')
 func (s *store) evictExpired() error {
-	var toDelete [][]byte
+	var (
+		toDelete [][]byte
+		expired  []*postage.Batch
+	)
 
 	// set until to total or inner whichever is greater
 	until := new(big.Int)
@@ -181,6 +188,7 @@ func (s *store) evictExpired() error {
 		// if batch has no value then delete it
 		if b.Value.Cmp(s.cs.TotalAmount) <= 0 {
 			toDelete = append(toDelete, b.ID)
+			expired = append(expired, b)
 		}
 		return false, nil
 	})
@@ -200,7 +208,23 @@ func (s *store) evictExpired() error {
 	if err = s.store.Put(reserveStateKey, s.rs); err != nil {
 		return err
 	}
-	return s.delete(toDelete...)
+	if err = s.delete(toDelete...); err != nil {
+		return err
+	}
+
+	if s.publisher != nil {
+		for _, b := range expired {
+			s.publisher.Publish(events.Event{
+				Type: events.BatchExpired,
+				Time: time.Now(),
+				Data: events.BatchData{
+					BatchID: hex.EncodeToString(b.ID),
+					Owner:   common.BytesToAddress(b.Owner),
+				},
+			})
+		}
+	}
+	return nil
 }
 
 // tier represents the sections of the reserve that can be  described as value intervals
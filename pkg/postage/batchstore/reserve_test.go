@@ -58,7 +58,7 @@ func setupBatchStore(t *testing.T) (postage.Storer, map[string]uint8) {
 		return unreserveFunc(b, swarm.MaxPO+1)
 	}
 
-	bStore, _ := batchstore.New(stateStore, evictFn, logger)
+	bStore, _ := batchstore.New(stateStore, evictFn, nil, logger)
 	bStore.SetRadiusSetter(noopRadiusSetter{})
 	batchstore.SetUnreserveFunc(bStore, unreserveFunc)
 
@@ -768,7 +768,7 @@ func TestUnreserveItemSequence(t *testing.T) {
 	evictFn := func(b []byte) error {
 		return unreserveFunc(b, swarm.MaxPO+1)
 	}
-	bStore, _ := batchstore.New(stateStore, evictFn, logger)
+	bStore, _ := batchstore.New(stateStore, evictFn, nil, logger)
 	bStore.SetRadiusSetter(noopRadiusSetter{})
 	batchstore.SetUnreserveFunc(bStore, unreserveFunc)
 
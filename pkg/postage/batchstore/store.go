@@ -12,6 +12,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/postage"
 	"github.com/holisticode/bee/pkg/storage"
@@ -40,6 +41,7 @@ type store struct {
 	evictFn     evictFn       // evict function
 	queueIdx    uint64        // unreserve queue cardinality
 	metrics     metrics       // metrics
+	publisher   events.Publisher
 	logger      logging.Logger
 
 	radiusSetter postage.RadiusSetter // setter for radius notifications
@@ -47,7 +49,8 @@ type store struct {
 
 // New constructs a new postage batch store.
 // It initialises both chain state and reserve state from the persistent state store
-func New(st storage.StateStorer, ev evictFn, logger logging.Logger) (postage.Storer, error) {
+// Evicted expired batches are announced through the publisher, if given.
+func New(st storage.StateStorer, ev evictFn, publisher events.Publisher, logger logging.Logger) (postage.Storer, error) {
 	cs := &postage.ChainState{}
 	err := st.Get(chainStateKey, cs)
 	if err != nil {
@@ -75,12 +78,13 @@ func New(st storage.StateStorer, ev evictFn, logger logging.Logger) (postage.Sto
 	}
 
 	s := &store{
-		store:   st,
-		cs:      cs,
-		rs:      rs,
-		evictFn: ev,
-		metrics: newMetrics(),
-		logger:  logger,
+		store:     st,
+		cs:        cs,
+		rs:        rs,
+		evictFn:   ev,
+		metrics:   newMetrics(),
+		publisher: publisher,
+		logger:    logger,
 	}
 
 	s.unreserveFn = s.unreserve
@@ -198,6 +202,18 @@ func (s *store) SetRadiusSetter(r postage.RadiusSetter) {
 	s.radiusSetter = r
 }
 
+// Iterate calls the callback for every batch in the batch store in
+// ascending order of value until the callback returns true.
+func (s *store) Iterate(cb func(*postage.Batch) (bool, error)) error {
+	return s.store.Iterate(valueKeyPrefix, func(key, _ []byte) (bool, error) {
+		b, err := s.Get(valueKeyToID(key))
+		if err != nil {
+			return true, err
+		}
+		return cb(b)
+	})
+}
+
 // Exists reports whether batch referenced by the give id exists.
 func (s *store) Exists(id []byte) (bool, error) {
 	switch err := s.store.Get(batchKey(id), new(postage.Batch)); {
@@ -36,6 +36,9 @@ type Storer interface {
 	SetRadiusSetter(RadiusSetter)
 	Unreserve(UnreserveIteratorFn) error
 	Exists(id []byte) (bool, error)
+	// Iterate calls the callback with every batch in ascending order of
+	// value until the callback returns true.
+	Iterate(func(*Batch) (bool, error)) error
 
 	Reset() error
 }
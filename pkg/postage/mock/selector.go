@@ -0,0 +1,28 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/postage"
+)
+
+type mockBatchSelector struct {
+	err error
+}
+
+// NewBatchSelector returns a new mock batch selector which hands out mock
+// stampers, or the given error if it is not nil.
+func NewBatchSelector(err error) postage.BatchSelector {
+	return &mockBatchSelector{err: err}
+}
+
+// Stamper implements the BatchSelector interface.
+func (m *mockBatchSelector) Stamper(crypto.Signer) (postage.Stamper, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return NewStamper(), nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+// ErrUnknownSelectionPolicy is the error returned when a batch selection
+// policy name can not be parsed.
+var ErrUnknownSelectionPolicy = errors.New("unknown batch selection policy")
+
+// SelectionPolicy determines how a postage batch is chosen for uploads
+// which do not specify one explicitly.
+type SelectionPolicy int
+
+const (
+	// SelectDefault prefers the configured default batch and falls over
+	// to the batch with the longest time to live.
+	SelectDefault SelectionPolicy = iota + 1
+	// SelectMostCapacity chooses, for every chunk, the batch with the most
+	// free slots in the collision bucket of the chunk.
+	SelectMostCapacity
+	// SelectLongestTTL prefers the batch with the highest remaining balance.
+	SelectLongestTTL
+)
+
+// String implements the fmt.Stringer interface.
+func (p SelectionPolicy) String() string {
+	switch p {
+	case SelectDefault:
+		return "default"
+	case SelectMostCapacity:
+		return "capacity"
+	case SelectLongestTTL:
+		return "ttl"
+	default:
+		return "none"
+	}
+}
+
+// ParseSelectionPolicy parses the textual representation of a selection
+// policy. The empty string and "none" result in the zero value, which
+// disables automatic batch selection.
+func ParseSelectionPolicy(s string) (SelectionPolicy, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return 0, nil
+	case "default":
+		return SelectDefault, nil
+	case "capacity":
+		return SelectMostCapacity, nil
+	case "ttl":
+		return SelectLongestTTL, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownSelectionPolicy, s)
+	}
+}
+
+// BatchSelector provides stampers for uploads which do not carry a postage
+// batch ID.
+type BatchSelector interface {
+	// Stamper returns a Stamper which issues stamps from the usable batches
+	// in the order given by the selection policy, falling over to the next
+	// one when a collision bucket is full.
+	Stamper(crypto.Signer) (Stamper, error)
+}
+
+type batchSelector struct {
+	post      Service
+	store     Storer
+	policy    SelectionPolicy
+	defaultID []byte
+}
+
+// NewBatchSelector constructs a BatchSelector for the given policy.
+// The defaultID is only taken into account with the SelectDefault policy.
+func NewBatchSelector(post Service, store Storer, policy SelectionPolicy, defaultID []byte) BatchSelector {
+	return &batchSelector{
+		post:      post,
+		store:     store,
+		policy:    policy,
+		defaultID: defaultID,
+	}
+}
+
+// Stamper implements the BatchSelector interface.
+func (bs *batchSelector) Stamper(signer crypto.Signer) (Stamper, error) {
+	issuers, err := bs.issuers()
+	if err != nil {
+		return nil, err
+	}
+	if len(issuers) == 0 {
+		return nil, fmt.Errorf("select batch: %w", ErrNotUsable)
+	}
+	return &fallbackStamper{
+		issuers:  issuers,
+		signer:   signer,
+		capacity: bs.policy == SelectMostCapacity,
+	}, nil
+}
+
+// issuers returns the usable stamp issuers ordered by the selection policy.
+func (bs *batchSelector) issuers() ([]*StampIssuer, error) {
+	type candidate struct {
+		issuer  *StampIssuer
+		balance *big.Int
+	}
+
+	var candidates []candidate
+	for _, st := range bs.post.StampIssuers() {
+		if !bs.post.IssuerUsable(st) {
+			continue
+		}
+		b, err := bs.store.Get(st.ID())
+		if err != nil {
+			// expired batches are evicted from the store
+			exists, e := bs.store.Exists(st.ID())
+			if e == nil && !exists {
+				continue
+			}
+			return nil, fmt.Errorf("get batch: %w", err)
+		}
+		candidates = append(candidates, candidate{issuer: st, balance: b.Value})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if bs.policy == SelectDefault && len(bs.defaultID) > 0 {
+			ai, bi := bytes.Equal(a.issuer.ID(), bs.defaultID), bytes.Equal(b.issuer.ID(), bs.defaultID)
+			if ai != bi {
+				return ai
+			}
+		}
+		if bs.policy == SelectMostCapacity {
+			af, bf := a.issuer.free(), b.issuer.free()
+			if af != bf {
+				return af > bf
+			}
+		}
+		return a.balance.Cmp(b.balance) > 0
+	})
+
+	issuers := make([]*StampIssuer, 0, len(candidates))
+	for _, c := range candidates {
+		issuers = append(issuers, c.issuer)
+	}
+	return issuers, nil
+}
+
+// fallbackStamper issues stamps from the first of its issuers which still
+// has room in the collision bucket of the chunk.
+type fallbackStamper struct {
+	issuers  []*StampIssuer
+	signer   crypto.Signer
+	capacity bool // order issuers by free slots in the target bucket
+}
+
+// Stamp implements the Stamper interface.
+func (fs *fallbackStamper) Stamp(addr swarm.Address) (*Stamp, error) {
+	issuers := fs.issuers
+	if fs.capacity {
+		issuers = make([]*StampIssuer, len(fs.issuers))
+		copy(issuers, fs.issuers)
+		sort.SliceStable(issuers, func(i, j int) bool {
+			return issuers[i].bucketFree(addr) > issuers[j].bucketFree(addr)
+		})
+	}
+
+	for _, st := range issuers {
+		stamp, err := NewStamper(st, fs.signer).Stamp(addr)
+		if errors.Is(err, ErrBucketFull) {
+			continue
+		}
+		return stamp, err
+	}
+	return nil, ErrBucketFull
+}
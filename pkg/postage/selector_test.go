@@ -0,0 +1,198 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage_test
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/postage"
+	pstoremock "github.com/holisticode/bee/pkg/postage/batchstore/mock"
+	postagetesting "github.com/holisticode/bee/pkg/postage/testing"
+	storemock "github.com/holisticode/bee/pkg/statestore/mock"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+// batchStore serves several batches to the selector.
+type batchStore struct {
+	*pstoremock.BatchStore
+	batches map[string]*postage.Batch
+}
+
+func (bs *batchStore) Get(id []byte) (*postage.Batch, error) {
+	b, ok := bs.batches[string(id)]
+	if !ok {
+		return nil, errors.New("no such id")
+	}
+	return b, nil
+}
+
+func (bs *batchStore) Exists(id []byte) (bool, error) {
+	_, ok := bs.batches[string(id)]
+	return ok, nil
+}
+
+func TestParseSelectionPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want postage.SelectionPolicy
+	}{
+		{"", 0},
+		{"none", 0},
+		{"default", postage.SelectDefault},
+		{"capacity", postage.SelectMostCapacity},
+		{"TTL", postage.SelectLongestTTL},
+	} {
+		got, err := postage.ParseSelectionPolicy(tc.in)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Fatalf("parse %q: got %v, want %v", tc.in, got, tc.want)
+		}
+	}
+
+	if _, err := postage.ParseSelectionPolicy("cheapest"); !errors.Is(err, postage.ErrUnknownSelectionPolicy) {
+		t.Fatalf("got error %v, want %v", err, postage.ErrUnknownSelectionPolicy)
+	}
+}
+
+func TestBatchSelector(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+
+	cs := postagetesting.NewChainState()
+	cs.Block += uint64(postage.BlockThreshold + 1)
+	start := cs.Block - uint64(postage.BlockThreshold+1)
+
+	// setup creates three usable batches with increasing balances, one
+	// unusable and one expired batch.
+	setup := func(t *testing.T) (postage.Service, *batchStore, [][]byte) {
+		t.Helper()
+
+		store := &batchStore{
+			BatchStore: pstoremock.New(pstoremock.WithChainState(cs)),
+			batches:    make(map[string]*postage.Batch),
+		}
+		ps, err := postage.NewService(storemock.NewStateStore(), store, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([][]byte, 5)
+		for i := range ids {
+			ids[i] = postagetesting.MustNewID()
+			block := start
+			if i == 3 {
+				block = cs.Block
+			}
+			if err := ps.Add(postage.NewStampIssuer("", "", ids[i], big.NewInt(0), 4, 2, block, true)); err != nil {
+				t.Fatal(err)
+			}
+			if i == 4 {
+				continue
+			}
+			store.batches[string(ids[i])] = &postage.Batch{ID: ids[i], Value: big.NewInt(int64(i + 1))}
+		}
+		return ps, store, ids
+	}
+
+	stampAll := func(t *testing.T, stamper postage.Stamper, addrs ...swarm.Address) [][]byte {
+		t.Helper()
+		var used [][]byte
+		for _, addr := range addrs {
+			stamp, err := stamper.Stamp(addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			used = append(used, stamp.BatchID())
+		}
+		return used
+	}
+
+	// addresses in the first collision bucket
+	addr := func(b byte) swarm.Address {
+		return swarm.NewAddress(append([]byte{0, b}, make([]byte, 30)...))
+	}
+
+	t.Run("ttl", func(t *testing.T) {
+		ps, store, ids := setup(t)
+		stamper, err := postage.NewBatchSelector(ps, store, postage.SelectLongestTTL, nil).Stamper(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		used := stampAll(t, stamper, addr(1), addr(2), addr(3), addr(4), addr(5))
+		for i, want := range [][]byte{ids[2], ids[2], ids[2], ids[2], ids[1]} {
+			if !bytes.Equal(used[i], want) {
+				t.Fatalf("stamp %d: got batch %x, want %x", i, used[i], want)
+			}
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		ps, store, ids := setup(t)
+		stamper, err := postage.NewBatchSelector(ps, store, postage.SelectDefault, ids[0]).Stamper(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		used := stampAll(t, stamper, addr(1), addr(2), addr(3), addr(4), addr(5))
+		for i, want := range [][]byte{ids[0], ids[0], ids[0], ids[0], ids[2]} {
+			if !bytes.Equal(used[i], want) {
+				t.Fatalf("stamp %d: got batch %x, want %x", i, used[i], want)
+			}
+		}
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		ps, store, ids := setup(t)
+		stamper, err := postage.NewBatchSelector(ps, store, postage.SelectMostCapacity, nil).Stamper(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		used := stampAll(t, stamper, addr(1), addr(2), addr(3))
+		seen := make(map[string]bool)
+		for _, id := range used {
+			seen[string(id)] = true
+		}
+		for _, id := range ids[:3] {
+			if !seen[string(id)] {
+				t.Fatalf("expected stamps to be spread over the batches, batch %x unused", id)
+			}
+		}
+	})
+
+	t.Run("all full", func(t *testing.T) {
+		ps, store, _ := setup(t)
+		stamper, err := postage.NewBatchSelector(ps, store, postage.SelectLongestTTL, nil).Stamper(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 12; i++ {
+			if _, err := stamper.Stamp(addr(byte(i))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := stamper.Stamp(addr(12)); !errors.Is(err, postage.ErrBucketFull) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrBucketFull)
+		}
+	})
+
+	t.Run("no usable batch", func(t *testing.T) {
+		ps, err := postage.NewService(storemock.NewStateStore(), pstoremock.New(pstoremock.WithChainState(cs)), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = postage.NewBatchSelector(ps, pstoremock.New(), postage.SelectLongestTTL, nil).Stamper(signer)
+		if !errors.Is(err, postage.ErrNotUsable) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrNotUsable)
+		}
+	})
+}
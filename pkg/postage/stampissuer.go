@@ -169,3 +169,23 @@ func (si *StampIssuer) Buckets() []uint32 {
 	si.bucketMu.Unlock()
 	return b
 }
+
+// free returns the number of stamps which can still be issued
+// across all the collision buckets.
+func (si *StampIssuer) free() uint64 {
+	si.bucketMu.Lock()
+	defer si.bucketMu.Unlock()
+	var used uint64
+	for _, v := range si.data.Buckets {
+		used += uint64(v)
+	}
+	return uint64(len(si.data.Buckets))*uint64(si.BucketUpperBound()) - used
+}
+
+// bucketFree returns the number of stamps which can still be issued
+// in the collision bucket of the given address.
+func (si *StampIssuer) bucketFree(addr swarm.Address) uint32 {
+	si.bucketMu.Lock()
+	defer si.bucketMu.Unlock()
+	return si.BucketUpperBound() - si.data.Buckets[toBucket(si.BucketDepth(), addr)]
+}
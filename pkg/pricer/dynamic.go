@@ -0,0 +1,311 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pricer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+var (
+	// interval in which the price table is recomputed
+	priceTableUpdateInterval = 30 * time.Second
+	// relative change of the price of any proximity order needed to
+	// announce a new price table
+	priceTableChangeThreshold = 0.1
+	// relative surcharge at full load
+	maxLoadSurcharge = 1.0
+	// relative surcharge for the proximity orders in highest demand
+	maxDemandSurcharge = 0.5
+	// weight of the previous intervals in the moving average of the demand
+	demandDecay = 0.5
+	// number of chunks served per second which is considered full load
+	servedChunksCapacity = 500.0
+	// share of the serving capacity a single proximity order needs to be
+	// requested at to be charged the full demand surcharge
+	popularBinShare = 0.25
+	// number of recent price tables peers may still price requests with
+	priceTableHistory uint64 = 4
+)
+
+// ErrInvalidPriceTable is returned if a peer announces a price table which
+// does not have a price for each proximity order.
+var ErrInvalidPriceTable = errors.New("invalid price table")
+
+// Load reports the utilisation of a local resource between 0 (idle) and 1
+// (saturated).
+type Load interface {
+	Load() float64
+}
+
+// LoadFunc is an adapter to allow the use of ordinary functions as Load.
+type LoadFunc func() float64
+
+// Load calls f().
+func (f LoadFunc) Load() float64 {
+	return f()
+}
+
+// PriceTableBroadcaster announces our price table to all connected peers.
+type PriceTableBroadcaster interface {
+	BroadcastPriceTable(ctx context.Context)
+}
+
+// DynamicPricer is a Pricer which raises the fixed prices per proximity
+// order with the local load and with the demand for chunks of each proximity
+// order. Prices are exchanged with peers as price tables numbered by epochs.
+// Requests carry the epoch of the table the requester priced them with, so
+// that both sides agree on the price while a new table is being announced.
+// Peers which did not announce a table are assumed to charge fixed prices.
+type DynamicPricer struct {
+	overlay swarm.Address
+	base    []uint64
+	logger  logging.Logger
+
+	mtx         sync.Mutex
+	epoch       uint64                    // epoch of the announced price table
+	table       []uint64                  // announced price table
+	tables      map[uint64][]uint64       // recently announced price tables by epoch
+	requests    []uint64                  // requests per proximity order in the current interval
+	demand      []float64                 // moving average of the requests per proximity order
+	served      float64                   // moving average of the requests per interval
+	sent        map[string]sentPriceTable // price tables acknowledged by peers
+	received    map[string]priceTable     // price tables announced by peers
+	load        Load
+	broadcaster PriceTableBroadcaster
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+type priceTable struct {
+	epoch  uint64
+	prices []uint64
+}
+
+// sentPriceTable is the price table last acknowledged by a peer and the
+// epoch acknowledged before it. A peer switches to a table before we receive
+// its acknowledgement, so requests priced with any epoch since the previous
+// one are charged as priced.
+type sentPriceTable struct {
+	priceTable
+	previous uint64
+}
+
+// NewDynamicPricer returns a new DynamicPricer which starts from the prices
+// of a FixedPricer with the given price per proximity order.
+func NewDynamicPricer(overlay swarm.Address, poPrice uint64, logger logging.Logger) *DynamicPricer {
+	base := make([]uint64, swarm.MaxPO+1)
+	for po := range base {
+		base[po] = uint64(int(swarm.MaxPO)-po+1) * poPrice
+	}
+	p := &DynamicPricer{
+		overlay:  overlay,
+		base:     base,
+		logger:   logger,
+		epoch:    1,
+		table:    append([]uint64(nil), base...),
+		tables:   make(map[uint64][]uint64),
+		requests: make([]uint64, len(base)),
+		demand:   make([]float64, len(base)),
+		sent:     make(map[string]sentPriceTable),
+		received: make(map[string]priceTable),
+		quit:     make(chan struct{}),
+	}
+	p.tables[p.epoch] = p.table
+
+	p.wg.Add(1)
+	go p.updateLoop()
+
+	return p
+}
+
+// SetLoad sets the local load the prices are adjusted by, in addition to the
+// rate of served chunks.
+func (p *DynamicPricer) SetLoad(load Load) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.load = load
+}
+
+// SetBroadcaster sets the PriceTableBroadcaster used to announce price
+// changes.
+func (p *DynamicPricer) SetBroadcaster(broadcaster PriceTableBroadcaster) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.broadcaster = broadcaster
+}
+
+// PeerPrice implements Pricer.
+func (p *DynamicPricer) PeerPrice(peer, chunk swarm.Address) (uint64, uint64) {
+	po := swarm.Proximity(peer.Bytes(), chunk.Bytes())
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if table, ok := p.received[peer.ByteString()]; ok {
+		return table.prices[po], table.epoch
+	}
+	return p.base[po], 0
+}
+
+// Price implements Pricer. Every call is counted as a request for the chunk.
+// The request is charged from the table of the given epoch if the peer may
+// still price with it, and from the table last acknowledged by the peer
+// otherwise.
+func (p *DynamicPricer) Price(peer, chunk swarm.Address, epoch uint64) uint64 {
+	po := swarm.Proximity(p.overlay.Bytes(), chunk.Bytes())
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.requests[po]++
+	sent, ok := p.sent[peer.ByteString()]
+	if table, known := p.tables[epoch]; known && epoch >= sent.previous {
+		return table[po]
+	}
+	if ok {
+		return sent.prices[po]
+	}
+	return p.base[po]
+}
+
+// PriceTable returns the prices per proximity order announced to peers and
+// the epoch of the table.
+func (p *DynamicPricer) PriceTable() (uint64, []uint64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.epoch, append([]uint64(nil), p.table...)
+}
+
+// NotifyPriceTable is called when a peer announced its price table.
+// Announcements of epochs older than the current table are ignored.
+func (p *DynamicPricer) NotifyPriceTable(peer swarm.Address, epoch uint64, table []uint64) error {
+	if len(table) != len(p.base) {
+		return ErrInvalidPriceTable
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if current, ok := p.received[peer.ByteString()]; ok && current.epoch > epoch {
+		return nil
+	}
+	p.received[peer.ByteString()] = priceTable{
+		epoch:  epoch,
+		prices: append([]uint64(nil), table...),
+	}
+	return nil
+}
+
+// NotifyPriceTableSent is called when a peer received our price table.
+func (p *DynamicPricer) NotifyPriceTableSent(peer swarm.Address, epoch uint64, table []uint64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	sent, ok := p.sent[peer.ByteString()]
+	if ok && sent.epoch >= epoch {
+		return
+	}
+	p.sent[peer.ByteString()] = sentPriceTable{
+		priceTable: priceTable{
+			epoch:  epoch,
+			prices: append([]uint64(nil), table...),
+		},
+		previous: sent.epoch,
+	}
+}
+
+// ResetPriceTables forgets the price tables exchanged with the peer.
+func (p *DynamicPricer) ResetPriceTables(peer swarm.Address) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	delete(p.sent, peer.ByteString())
+	delete(p.received, peer.ByteString())
+}
+
+func (p *DynamicPricer) updateLoop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(priceTableUpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !p.update() {
+				continue
+			}
+			p.mtx.Lock()
+			broadcaster := p.broadcaster
+			p.mtx.Unlock()
+			if broadcaster != nil {
+				ctx, cancel := context.WithTimeout(context.Background(), priceTableUpdateInterval)
+				broadcaster.BroadcastPriceTable(ctx)
+				cancel()
+			}
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// update recomputes the prices from the requests of the past interval. It
+// reports whether the price table changed enough to be announced.
+func (p *DynamicPricer) update() bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	var served uint64
+	for po, n := range p.requests {
+		p.demand[po] = demandDecay*p.demand[po] + (1-demandDecay)*float64(n)
+		p.requests[po] = 0
+		served += n
+	}
+	p.served = demandDecay*p.served + (1-demandDecay)*float64(served)
+
+	capacity := servedChunksCapacity * priceTableUpdateInterval.Seconds()
+	load := p.served / capacity
+	if p.load != nil {
+		load = math.Max(load, p.load.Load())
+	}
+	load = math.Min(math.Max(load, 0), 1)
+
+	table := make([]uint64, len(p.base))
+	changed := false
+	for po, price := range p.base {
+		popularity := math.Min(p.demand[po]/(capacity*popularBinShare), 1)
+		f := float64(price) * (1 + maxLoadSurcharge*load) * (1 + maxDemandSurcharge*popularity)
+		table[po] = uint64(math.Round(f))
+
+		if old := float64(p.table[po]); table[po] != p.table[po] && math.Abs(float64(table[po])-old) >= old*priceTableChangeThreshold {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return false
+	}
+
+	p.epoch++
+	p.logger.Debugf("pricer: price table of epoch %d changed to %v at load %.2f", p.epoch, table, load)
+	p.table = table
+	p.tables[p.epoch] = table
+	if p.epoch > priceTableHistory {
+		delete(p.tables, p.epoch-priceTableHistory)
+	}
+	return true
+}
+
+// Close stops updating the prices.
+func (p *DynamicPricer) Close() error {
+	close(p.quit)
+	p.wg.Wait()
+	return nil
+}
@@ -0,0 +1,232 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pricer_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/p2p/streamtest"
+	"github.com/holisticode/bee/pkg/pricer"
+	"github.com/holisticode/bee/pkg/pricing"
+	"github.com/holisticode/bee/pkg/swarm"
+	"github.com/holisticode/bee/pkg/swarm/test"
+)
+
+func TestDynamicPricerPeerPrice(t *testing.T) {
+	logger := logging.New(io.Discard, 0)
+	overlay := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	peer := swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.MustParseHexAddress("c000000000000000000000000000000000000000000000000000000000000000")
+
+	p := pricer.NewDynamicPricer(overlay, 10, logger)
+	defer p.Close()
+
+	fixed, _ := pricer.NewFixedPricer(overlay, 10).PeerPrice(peer, chunk)
+
+	if got, epoch := p.PeerPrice(peer, chunk); got != fixed || epoch != 0 {
+		t.Fatalf("got price %d at epoch %d without price table, want %d at epoch 0", got, epoch, fixed)
+	}
+
+	err := p.NotifyPriceTable(peer, 1, []uint64{1, 2, 3})
+	if !errors.Is(err, pricer.ErrInvalidPriceTable) {
+		t.Fatalf("got error %v, want %v", err, pricer.ErrInvalidPriceTable)
+	}
+
+	table := make([]uint64, swarm.MaxPO+1)
+	for po := range table {
+		table[po] = uint64(1000 + po)
+	}
+	if err := p.NotifyPriceTable(peer, 2, table); err != nil {
+		t.Fatal(err)
+	}
+
+	po := swarm.Proximity(peer.Bytes(), chunk.Bytes())
+	if got, epoch := p.PeerPrice(peer, chunk); got != table[po] || epoch != 2 {
+		t.Fatalf("got price %d at epoch %d with price table, want %d at epoch 2", got, epoch, table[po])
+	}
+
+	// announcements arriving out of order do not replace newer tables
+	if err := p.NotifyPriceTable(peer, 1, make([]uint64, swarm.MaxPO+1)); err != nil {
+		t.Fatal(err)
+	}
+	if got, epoch := p.PeerPrice(peer, chunk); got != table[po] || epoch != 2 {
+		t.Fatalf("got price %d at epoch %d after older announcement, want %d at epoch 2", got, epoch, table[po])
+	}
+
+	p.ResetPriceTables(peer)
+
+	if got, epoch := p.PeerPrice(peer, chunk); got != fixed || epoch != 0 {
+		t.Fatalf("got price %d at epoch %d after reset, want %d at epoch 0", got, epoch, fixed)
+	}
+}
+
+func TestDynamicPricerLoad(t *testing.T) {
+	logger := logging.New(io.Discard, 0)
+	overlay := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	peer := swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.MustParseHexAddress("c000000000000000000000000000000000000000000000000000000000000000")
+
+	p := pricer.NewDynamicPricer(overlay, 10, logger)
+	defer p.Close()
+
+	base := p.Price(peer, chunk, 0)
+
+	if p.Update() {
+		t.Fatal("expected price table to be unchanged when idle")
+	}
+
+	p.SetLoad(pricer.LoadFunc(func() float64 { return 1 }))
+
+	if !p.Update() {
+		t.Fatal("expected price table to change under full load")
+	}
+
+	po := swarm.Proximity(overlay.Bytes(), chunk.Bytes())
+	epoch, table := p.PriceTable()
+	if table[po] <= base {
+		t.Fatalf("got price %d under full load, want more than %d", table[po], base)
+	}
+
+	// the peer is charged the new prices only after it received them
+	if got := p.Price(peer, chunk, 0); got != base {
+		t.Fatalf("got price %d before the price table was sent, want %d", got, base)
+	}
+
+	// unless it priced the request with them already
+	if got := p.Price(peer, chunk, epoch); got != table[po] {
+		t.Fatalf("got price %d for a request priced at epoch %d, want %d", got, epoch, table[po])
+	}
+
+	p.NotifyPriceTableSent(peer, epoch, table)
+
+	if got := p.Price(peer, chunk, 0); got != table[po] {
+		t.Fatalf("got price %d after the price table was sent, want %d", got, table[po])
+	}
+}
+
+type thresholdObserver struct{}
+
+func (thresholdObserver) NotifyPaymentThreshold(swarm.Address, *big.Int) error {
+	return nil
+}
+
+// announcedPricer calls announced after it switched to a price table
+// announced by a peer and before the announcement is acknowledged.
+type announcedPricer struct {
+	*pricer.DynamicPricer
+	announced func()
+}
+
+func (p *announcedPricer) NotifyPriceTable(peer swarm.Address, epoch uint64, table []uint64) error {
+	if err := p.DynamicPricer.NotifyPriceTable(peer, epoch, table); err != nil {
+		return err
+	}
+	p.announced()
+	return nil
+}
+
+func TestDynamicPricerAnnouncement(t *testing.T) {
+	logger := logging.New(io.Discard, 0)
+	threshold := big.NewInt(100000)
+	server := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	client := swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000")
+
+	serverPricer := pricer.NewDynamicPricer(server, 10, logger)
+	defer serverPricer.Close()
+	clientPricer := pricer.NewDynamicPricer(client, 10, logger)
+	defer clientPricer.Close()
+
+	// balances of the client as seen by the server and by the client
+	var (
+		balancesMu    sync.Mutex
+		serverBalance uint64
+		clientBalance uint64
+	)
+	request := func() {
+		chunk := test.RandomAddress()
+		price, epoch := clientPricer.PeerPrice(server, chunk)
+		charged := serverPricer.Price(client, chunk, epoch)
+
+		balancesMu.Lock()
+		defer balancesMu.Unlock()
+		clientBalance += price
+		serverBalance += charged
+	}
+
+	clientService := pricing.New(nil, logger, threshold, big.NewInt(1000))
+	clientService.SetPaymentThresholdObserver(thresholdObserver{})
+	clientService.SetPriceTableObserver(&announcedPricer{
+		DynamicPricer: clientPricer,
+		announced: func() {
+			for i := 0; i < 10; i++ {
+				request()
+			}
+		},
+	})
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(clientService.Protocol(), clientService.PriceTableProtocol()),
+		streamtest.WithBaseAddr(server),
+	)
+
+	serverService := pricing.New(recorder, logger, threshold, big.NewInt(1000))
+	serverService.SetPriceTableObserver(serverPricer)
+
+	if err := serverService.Protocol().ConnectOut(context.Background(), p2p.Peer{Address: client}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a request priced with the first table which arrives after the second
+	// one was acknowledged
+	inFlight := test.RandomAddress()
+	inFlightPrice, inFlightEpoch := clientPricer.PeerPrice(server, inFlight)
+
+	serverPricer.SetLoad(pricer.LoadFunc(func() float64 { return 1 }))
+	if !serverPricer.Update() {
+		t.Fatal("expected price table to change under full load")
+	}
+	epoch, _ := serverPricer.PriceTable()
+
+	quit := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-quit:
+					return
+				default:
+					request()
+				}
+			}
+		}()
+	}
+
+	serverService.BroadcastPriceTable(context.Background())
+	close(quit)
+	wg.Wait()
+
+	if _, got := clientPricer.PeerPrice(server, inFlight); got != epoch {
+		t.Fatalf("got price table of epoch %d, want %d", got, epoch)
+	}
+
+	balancesMu.Lock()
+	defer balancesMu.Unlock()
+	clientBalance += inFlightPrice
+	serverBalance += serverPricer.Price(client, inFlight, inFlightEpoch)
+
+	if serverBalance != clientBalance {
+		t.Fatalf("got server balance %d, client balance %d", serverBalance, clientBalance)
+	}
+}
@@ -8,4 +8,5 @@ const (
 	PriceFieldName  = priceFieldName
 	TargetFieldName = targetFieldName
 	IndexFieldName  = indexFieldName
+	EpochFieldName  = epochFieldName
 )
@@ -15,6 +15,7 @@ const (
 	priceFieldName  = "price"
 	targetFieldName = "target"
 	indexFieldName  = "index"
+	epochFieldName  = "price-epoch"
 )
 
 var (
@@ -26,6 +27,8 @@ var (
 	ErrNoTargetHeader = errors.New("no target header")
 	// ErrNoPriceHeader denotes p2p.Header lacking specified field
 	ErrNoPriceHeader = errors.New("no price header")
+	// ErrNoEpochHeader denotes p2p.Header lacking specified field
+	ErrNoEpochHeader = errors.New("no price epoch header")
 )
 
 // Headers, utility functions
@@ -44,6 +47,19 @@ func MakePricingHeaders(chunkPrice uint64, addr swarm.Address) (p2p.Headers, err
 	return headers, nil
 }
 
+// MakeEpochHeaders used by requester to tell the epoch of the price table the
+// request is priced with
+func MakeEpochHeaders(epoch uint64) p2p.Headers {
+
+	epochInBytes := make([]byte, 8)
+
+	binary.BigEndian.PutUint64(epochInBytes, epoch)
+
+	return p2p.Headers{
+		epochFieldName: epochInBytes,
+	}
+}
+
 func MakePricingResponseHeaders(chunkPrice uint64, addr swarm.Address, index uint8) (p2p.Headers, error) {
 
 	chunkPriceInBytes := make([]byte, 8)
@@ -130,3 +146,16 @@ func ParsePriceHeader(receivedHeaders p2p.Headers) (uint64, error) {
 	receivedPrice := binary.BigEndian.Uint64(receivedHeaders[priceFieldName])
 	return receivedPrice, nil
 }
+
+func ParseEpochHeader(receivedHeaders p2p.Headers) (uint64, error) {
+	if receivedHeaders[epochFieldName] == nil {
+		return 0, ErrNoEpochHeader
+	}
+
+	if len(receivedHeaders[epochFieldName]) != 8 {
+		return 0, ErrFieldLength
+	}
+
+	epoch := binary.BigEndian.Uint64(receivedHeaders[epochFieldName])
+	return epoch, nil
+}
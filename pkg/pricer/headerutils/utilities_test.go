@@ -157,6 +157,31 @@ func TestParsePriceHeader(t *testing.T) {
 
 }
 
+func TestEpochHeaders(t *testing.T) {
+	makeHeaders := headerutils.MakeEpochHeaders(uint64(5348))
+
+	expectedHeaders := p2p.Headers{
+		headerutils.EpochFieldName: []byte{0, 0, 0, 0, 0, 0, 20, 228},
+	}
+
+	if !reflect.DeepEqual(makeHeaders, expectedHeaders) {
+		t.Fatalf("Made headers not as expected, got %+v, want %+v", makeHeaders, expectedHeaders)
+	}
+
+	parsedEpoch, err := headerutils.ParseEpochHeader(makeHeaders)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsedEpoch != uint64(5348) {
+		t.Fatalf("Epoch mismatch, got %v, want %v", parsedEpoch, 5348)
+	}
+
+	if _, err := headerutils.ParseEpochHeader(p2p.Headers{}); err != headerutils.ErrNoEpochHeader {
+		t.Fatalf("got error %v, want %v", err, headerutils.ErrNoEpochHeader)
+	}
+}
+
 func TestReadMalformedHeaders(t *testing.T) {
 	toReadHeaders := p2p.Headers{
 		headerutils.IndexFieldName:  []byte{11, 0},
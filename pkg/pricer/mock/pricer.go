@@ -20,10 +20,10 @@ func NewMockService(price, peerPrice uint64) *MockPricer {
 	}
 }
 
-func (pricer *MockPricer) PeerPrice(peer, chunk swarm.Address) uint64 {
-	return pricer.peerPrice
+func (pricer *MockPricer) PeerPrice(peer, chunk swarm.Address) (uint64, uint64) {
+	return pricer.peerPrice, 0
 }
 
-func (pricer *MockPricer) Price(chunk swarm.Address) uint64 {
+func (pricer *MockPricer) Price(peer, chunk swarm.Address, epoch uint64) uint64 {
 	return pricer.price
 }
@@ -10,10 +10,14 @@ import (
 
 // Pricer returns pricing information for chunk hashes.
 type Interface interface {
-	// PeerPrice is the price the peer charges for a given chunk hash.
-	PeerPrice(peer, chunk swarm.Address) uint64
-	// Price is the price we charge for a given chunk hash.
-	Price(chunk swarm.Address) uint64
+	// PeerPrice is the price the peer charges for a given chunk hash. It
+	// also returns the epoch of the peer's price table the price is taken
+	// from, which is sent along with the request, or 0 for the base prices.
+	PeerPrice(peer, chunk swarm.Address) (price, epoch uint64)
+	// Price is the price we charge the peer for a given chunk hash. The
+	// epoch is the one of our price table the peer priced the request with,
+	// or 0 if it did not tell.
+	Price(peer, chunk swarm.Address, epoch uint64) uint64
 }
 
 // FixedPricer is a Pricer that has a fixed price for chunks.
@@ -31,11 +35,15 @@ func NewFixedPricer(overlay swarm.Address, poPrice uint64) *FixedPricer {
 }
 
 // PeerPrice implements Pricer.
-func (pricer *FixedPricer) PeerPrice(peer, chunk swarm.Address) uint64 {
-	return uint64(swarm.MaxPO-swarm.Proximity(peer.Bytes(), chunk.Bytes())+1) * pricer.poPrice
+func (pricer *FixedPricer) PeerPrice(peer, chunk swarm.Address) (uint64, uint64) {
+	return pricer.price(peer, chunk), 0
 }
 
 // Price implements Pricer.
-func (pricer *FixedPricer) Price(chunk swarm.Address) uint64 {
-	return pricer.PeerPrice(pricer.overlay, chunk)
+func (pricer *FixedPricer) Price(_, chunk swarm.Address, _ uint64) uint64 {
+	return pricer.price(pricer.overlay, chunk)
+}
+
+func (pricer *FixedPricer) price(overlay, chunk swarm.Address) uint64 {
+	return uint64(swarm.MaxPO-swarm.Proximity(overlay.Bytes(), chunk.Bytes())+1) * pricer.poPrice
 }
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/holisticode/bee/pkg/logging"
@@ -22,11 +23,17 @@ const (
 	protocolName    = "pricing"
 	protocolVersion = "1.0.0"
 	streamName      = "pricing"
+	// price tables are exchanged on a stream of a newer protocol version,
+	// so that peers that only speak 1.0.0 keep exchanging payment thresholds
+	priceTableProtocolVersion = "1.1.0"
+	priceTableStreamName      = "pricetable"
 )
 
 var (
 	// ErrThresholdTooLow says that the proposed payment threshold is too low for even a single reserve.
 	ErrThresholdTooLow = errors.New("threshold too low")
+	// ErrPriceTableNotAcknowledged says that the peer did not accept the announced price table.
+	ErrPriceTableNotAcknowledged = errors.New("price table not acknowledged")
 )
 
 var _ Interface = (*Service)(nil)
@@ -41,12 +48,28 @@ type PaymentThresholdObserver interface {
 	NotifyPaymentThreshold(peer swarm.Address, paymentThreshold *big.Int) error
 }
 
+// PriceTableObserver is used for exchanging the prices per proximity order
+// with peers.
+type PriceTableObserver interface {
+	// PriceTable returns the price table to announce to peers and its epoch.
+	PriceTable() (epoch uint64, table []uint64)
+	// NotifyPriceTable is called when a peer announced its price table.
+	NotifyPriceTable(peer swarm.Address, epoch uint64, table []uint64) error
+	// NotifyPriceTableSent is called when a peer acknowledged our price table.
+	NotifyPriceTableSent(peer swarm.Address, epoch uint64, table []uint64)
+	// ResetPriceTables is called when a peer disconnects.
+	ResetPriceTables(peer swarm.Address)
+}
+
 type Service struct {
 	streamer                 p2p.Streamer
 	logger                   logging.Logger
 	paymentThreshold         *big.Int
 	minPaymentThreshold      *big.Int
 	paymentThresholdObserver PaymentThresholdObserver
+	priceTableObserver       PriceTableObserver
+	peersMu                  sync.Mutex
+	peers                    map[string]swarm.Address
 }
 
 func New(streamer p2p.Streamer, logger logging.Logger, paymentThreshold, minThreshold *big.Int) *Service {
@@ -55,6 +78,7 @@ func New(streamer p2p.Streamer, logger logging.Logger, paymentThreshold, minThre
 		logger:              logger,
 		paymentThreshold:    paymentThreshold,
 		minPaymentThreshold: minThreshold,
+		peers:               make(map[string]swarm.Address),
 	}
 }
 
@@ -68,8 +92,26 @@ func (s *Service) Protocol() p2p.ProtocolSpec {
 				Handler: s.handler,
 			},
 		},
-		ConnectIn:  s.init,
-		ConnectOut: s.init,
+		ConnectIn:     s.init,
+		ConnectOut:    s.init,
+		DisconnectIn:  s.disconnect,
+		DisconnectOut: s.disconnect,
+	}
+}
+
+// PriceTableProtocol returns the protocol version that exchanges price
+// tables. It is registered next to the Protocol so that peers without it
+// are still served on 1.0.0.
+func (s *Service) PriceTableProtocol() p2p.ProtocolSpec {
+	return p2p.ProtocolSpec{
+		Name:    protocolName,
+		Version: priceTableProtocolVersion,
+		StreamSpecs: []p2p.StreamSpec{
+			{
+				Name:    priceTableStreamName,
+				Handler: s.priceTableHandler,
+			},
+		},
 	}
 }
 
@@ -103,12 +145,67 @@ func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (e
 	return s.paymentThresholdObserver.NotifyPaymentThreshold(p.Address, paymentThreshold)
 }
 
+func (s *Service) priceTableHandler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	w, r := protobuf.NewWriterAndReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			_ = stream.FullClose()
+		}
+	}()
+
+	var req pb.AnnouncePriceTable
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		s.logger.Debugf("could not receive price table announcement from peer %v", p.Address)
+		return fmt.Errorf("read request from peer %v: %w", p.Address, err)
+	}
+
+	s.logger.Tracef("received price table announcement from peer %v of %v at epoch %d", p.Address, req.ProximityPrice, req.Epoch)
+
+	// without an observer the prices are not in effect, so the announcement
+	// is not acknowledged and the peer keeps charging its base prices
+	if s.priceTableObserver == nil {
+		return nil
+	}
+	if err := s.priceTableObserver.NotifyPriceTable(p.Address, req.Epoch, req.ProximityPrice); err != nil {
+		return err
+	}
+
+	if err := w.WriteMsgWithContext(ctx, &pb.PriceTableAck{Epoch: req.Epoch}); err != nil {
+		return fmt.Errorf("write price table ack to peer %v: %w", p.Address, err)
+	}
+	return nil
+}
+
 func (s *Service) init(ctx context.Context, p p2p.Peer) error {
 	err := s.AnnouncePaymentThreshold(ctx, p.Address, s.paymentThreshold)
 	if err != nil {
 		s.logger.Warningf("could not send payment threshold announcement to peer %v", p.Address)
+		return err
 	}
-	return err
+
+	s.peersMu.Lock()
+	s.peers[p.Address.ByteString()] = p.Address
+	s.peersMu.Unlock()
+
+	if s.priceTableObserver == nil {
+		return nil
+	}
+	epoch, table := s.priceTableObserver.PriceTable()
+	s.announcePriceTable(ctx, p.Address, epoch, table)
+	return nil
+}
+
+func (s *Service) disconnect(p p2p.Peer) error {
+	s.peersMu.Lock()
+	delete(s.peers, p.Address.ByteString())
+	s.peersMu.Unlock()
+
+	if s.priceTableObserver != nil {
+		s.priceTableObserver.ResetPriceTables(p.Address)
+	}
+	return nil
 }
 
 // AnnouncePaymentThreshold announces the payment threshold to per
@@ -137,7 +234,98 @@ func (s *Service) AnnouncePaymentThreshold(ctx context.Context, peer swarm.Addre
 	return err
 }
 
+// AnnouncePriceTable announces our prices per proximity order to the peer.
+// It returns once the peer acknowledged the epoch of the announcement. The
+// peer prices its requests with the new table from the moment it received it
+// and tells the epoch along with each request. Peers that only speak protocol
+// version 1.0.0 return a p2p.IncompatibleStreamError.
+func (s *Service) AnnouncePriceTable(ctx context.Context, peer swarm.Address, epoch uint64, table []uint64) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, priceTableProtocolVersion, priceTableStreamName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	s.logger.Tracef("sending price table announcement to peer %v of %v at epoch %d", peer, table, epoch)
+	w, r := protobuf.NewWriterAndReader(stream)
+	err = w.WriteMsgWithContext(ctx, &pb.AnnouncePriceTable{
+		ProximityPrice: table,
+		Epoch:          epoch,
+	})
+	if err != nil {
+		return err
+	}
+
+	var ack pb.PriceTableAck
+	if err := r.ReadMsgWithContext(ctx, &ack); err != nil {
+		return fmt.Errorf("%w: %v", ErrPriceTableNotAcknowledged, err)
+	}
+	if ack.Epoch != epoch {
+		return fmt.Errorf("%w: acknowledged epoch %d, want %d", ErrPriceTableNotAcknowledged, ack.Epoch, epoch)
+	}
+	return nil
+}
+
+// announcePriceTable announces the price table to the peer and notifies the
+// observer if the peer acknowledged it. Peers without price tables keep
+// being charged the base prices.
+func (s *Service) announcePriceTable(ctx context.Context, peer swarm.Address, epoch uint64, table []uint64) {
+	err := s.AnnouncePriceTable(ctx, peer, epoch, table)
+	if err != nil {
+		var incompatible *p2p.IncompatibleStreamError
+		if errors.As(err, &incompatible) {
+			s.logger.Tracef("peer %v does not support price tables", peer)
+			return
+		}
+		s.logger.Debugf("could not send price table announcement to peer %v: %v", peer, err)
+		return
+	}
+	s.priceTableObserver.NotifyPriceTableSent(peer, epoch, table)
+}
+
+// BroadcastPriceTable announces the current price table to all connected
+// peers.
+func (s *Service) BroadcastPriceTable(ctx context.Context) {
+	if s.priceTableObserver == nil {
+		return
+	}
+
+	s.peersMu.Lock()
+	peers := make([]swarm.Address, 0, len(s.peers))
+	for _, peer := range s.peers {
+		peers = append(peers, peer)
+	}
+	s.peersMu.Unlock()
+
+	epoch, table := s.priceTableObserver.PriceTable()
+
+	var wg sync.WaitGroup
+	for _, peer := range peers {
+		wg.Add(1)
+		go func(peer swarm.Address) {
+			defer wg.Done()
+			s.announcePriceTable(ctx, peer, epoch, table)
+		}(peer)
+	}
+	wg.Wait()
+}
+
 // SetPaymentThresholdObserver sets the PaymentThresholdObserver to be used when receiving a new payment threshold
 func (s *Service) SetPaymentThresholdObserver(observer PaymentThresholdObserver) {
 	s.paymentThresholdObserver = observer
 }
+
+// SetPriceTableObserver sets the PriceTableObserver used for exchanging price
+// tables with peers. Without an observer no price tables are announced.
+func (s *Service) SetPriceTableObserver(observer PriceTableObserver) {
+	s.priceTableObserver = observer
+}
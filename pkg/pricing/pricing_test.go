@@ -10,6 +10,7 @@ import (
 	"errors"
 	"io"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/holisticode/bee/pkg/logging"
@@ -154,3 +155,194 @@ func TestAnnouncePaymentWithInsufficientThreshold(t *testing.T) {
 		t.Fatal("unexpected call to the observer")
 	}
 }
+
+type testPriceTableObserver struct {
+	epoch    uint64
+	table    []uint64
+	received map[string]priceTable
+	sent     map[string]priceTable
+}
+
+type priceTable struct {
+	epoch uint64
+	table []uint64
+}
+
+func newTestPriceTableObserver(epoch uint64, table []uint64) *testPriceTableObserver {
+	return &testPriceTableObserver{
+		epoch:    epoch,
+		table:    table,
+		received: make(map[string]priceTable),
+		sent:     make(map[string]priceTable),
+	}
+}
+
+func (t *testPriceTableObserver) PriceTable() (uint64, []uint64) {
+	return t.epoch, t.table
+}
+
+func (t *testPriceTableObserver) NotifyPriceTable(peer swarm.Address, epoch uint64, table []uint64) error {
+	t.received[peer.String()] = priceTable{epoch: epoch, table: table}
+	return nil
+}
+
+func (t *testPriceTableObserver) NotifyPriceTableSent(peer swarm.Address, epoch uint64, table []uint64) {
+	t.sent[peer.String()] = priceTable{epoch: epoch, table: table}
+}
+
+func (t *testPriceTableObserver) ResetPriceTables(peer swarm.Address) {}
+
+func TestAnnouncePriceTable(t *testing.T) {
+	logger := logging.New(io.Discard, 0)
+	testThreshold := big.NewInt(100000)
+	observer := newTestPriceTableObserver(0, nil)
+
+	recipient := pricing.New(nil, logger, testThreshold, big.NewInt(1000))
+	recipient.SetPriceTableObserver(observer)
+
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(recipient.Protocol(), recipient.PriceTableProtocol()),
+		streamtest.WithBaseAddr(peerID),
+	)
+
+	payer := pricing.New(recorder, logger, testThreshold, big.NewInt(1000))
+
+	table := []uint64{30, 20, 10}
+
+	err := payer.AnnouncePriceTable(context.Background(), peerID, 3, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := recorder.Records(peerID, "pricing", "1.1.0", "pricetable")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l := len(records); l != 1 {
+		t.Fatalf("got %v records, want %v", l, 1)
+	}
+
+	messages, err := protobuf.ReadMessages(
+		bytes.NewReader(records[0].In()),
+		func() protobuf.Message { return new(pb.AnnouncePriceTable) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(messages) != 1 {
+		t.Fatalf("got %v messages, want %v", len(messages), 1)
+	}
+
+	sent := messages[0].(*pb.AnnouncePriceTable)
+	if !reflect.DeepEqual(sent.ProximityPrice, table) {
+		t.Fatalf("got message with price table %v, want %v", sent.ProximityPrice, table)
+	}
+	if sent.Epoch != 3 {
+		t.Fatalf("got message with epoch %d, want %d", sent.Epoch, 3)
+	}
+
+	acks, err := protobuf.ReadMessages(
+		bytes.NewReader(records[0].Out()),
+		func() protobuf.Message { return new(pb.PriceTableAck) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acks) != 1 || acks[0].(*pb.PriceTableAck).Epoch != 3 {
+		t.Fatalf("got acks %v, want one of epoch %d", acks, 3)
+	}
+
+	got, ok := observer.received[peerID.String()]
+	if !ok {
+		t.Fatal("expected observer to be called")
+	}
+	if got.epoch != 3 || !reflect.DeepEqual(got.table, table) {
+		t.Fatalf("observer called with wrong price table. got %v at epoch %d, want %v at epoch %d", got.table, got.epoch, table, 3)
+	}
+}
+
+func TestAnnouncePriceTableNotAcknowledged(t *testing.T) {
+	logger := logging.New(io.Discard, 0)
+	testThreshold := big.NewInt(100000)
+
+	// the recipient has no observer to put the prices in effect
+	recipient := pricing.New(nil, logger, testThreshold, big.NewInt(1000))
+
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(recipient.Protocol(), recipient.PriceTableProtocol()),
+		streamtest.WithBaseAddr(peerID),
+	)
+
+	payer := pricing.New(recorder, logger, testThreshold, big.NewInt(1000))
+
+	err := payer.AnnouncePriceTable(context.Background(), peerID, 1, []uint64{30, 20, 10})
+	if !errors.Is(err, pricing.ErrPriceTableNotAcknowledged) {
+		t.Fatalf("got error %v, want %v", err, pricing.ErrPriceTableNotAcknowledged)
+	}
+}
+
+func TestPriceTableSentOnConnect(t *testing.T) {
+	logger := logging.New(io.Discard, 0)
+	testThreshold := big.NewInt(100000)
+	table := []uint64{30, 20, 10}
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+
+	for _, tc := range []struct {
+		name      string
+		protocols func(*pricing.Service) []p2p.ProtocolSpec
+		sent      bool
+	}{
+		{
+			name: "acknowledged",
+			protocols: func(s *pricing.Service) []p2p.ProtocolSpec {
+				return []p2p.ProtocolSpec{s.Protocol(), s.PriceTableProtocol()}
+			},
+			sent: true,
+		},
+		{
+			name: "peer without price tables",
+			protocols: func(s *pricing.Service) []p2p.ProtocolSpec {
+				return []p2p.ProtocolSpec{s.Protocol()}
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			recipient := pricing.New(nil, logger, testThreshold, big.NewInt(1000))
+			recipient.SetPaymentThresholdObserver(&testThresholdObserver{})
+			recipient.SetPriceTableObserver(newTestPriceTableObserver(0, nil))
+
+			recorder := streamtest.New(
+				streamtest.WithProtocols(tc.protocols(recipient)...),
+				streamtest.WithBaseAddr(peerID),
+				streamtest.WithStreamError(func(_ swarm.Address, _, version, _ string) error {
+					if !tc.sent && version != "1.0.0" {
+						return p2p.NewIncompatibleStreamError(streamtest.ErrStreamNotSupported)
+					}
+					return nil
+				}),
+			)
+
+			observer := newTestPriceTableObserver(2, table)
+			payer := pricing.New(recorder, logger, testThreshold, big.NewInt(1000))
+			payer.SetPriceTableObserver(observer)
+
+			if err := payer.Protocol().ConnectOut(context.Background(), p2p.Peer{Address: peerID}); err != nil {
+				t.Fatal(err)
+			}
+
+			got, ok := observer.sent[peerID.String()]
+			if ok != tc.sent {
+				t.Fatalf("got price table sent %v, want %v", ok, tc.sent)
+			}
+			if tc.sent && (got.epoch != 2 || !reflect.DeepEqual(got.table, table)) {
+				t.Fatalf("got sent price table %v at epoch %d, want %v at epoch %d", got.table, got.epoch, table, 2)
+			}
+		})
+	}
+}
@@ -3,4 +3,7 @@ package pusher
 var (
 	RetryInterval = &retryInterval
 	RetryCount    = &retryCount
+
+	FailureTTL           = &failureTTL
+	FailurePruneInterval = &failurePruneInterval
 )
@@ -0,0 +1,188 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pusher
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/holisticode/bee/pkg/storage"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+// Failure describes a chunk of a tag which could not be pushed yet.
+type Failure struct {
+	Address     swarm.Address
+	Error       string
+	Attempts    int
+	LastAttempt time.Time
+}
+
+// FailureTracker reports and retries the chunks of a tag which could not be
+// pushed.
+type FailureTracker interface {
+	// Failures returns the chunks of the tag which failed their last push
+	// attempt.
+	Failures(tagID uint32) []Failure
+	// Retry enqueues the failed chunks of the tag for a new push attempt and
+	// returns the number of enqueued chunks.
+	Retry(ctx context.Context, tagID uint32) (int, error)
+}
+
+var _ FailureTracker = (*Service)(nil)
+
+var (
+	failureTTL           = 24 * time.Hour // time after the last attempt in which a failure is forgotten
+	failurePruneInterval = time.Minute    // minimal interval between scans for failures to forget
+)
+
+type failures struct {
+	mtx       sync.Mutex
+	tags      map[uint32]map[string]*Failure
+	tagExists func(uint32) bool
+	pruned    time.Time // time of the last scan for failures to forget
+}
+
+// newFailures creates the failures, forgetting the failures of the tags for
+// which tagExists returns false.
+func newFailures(tagExists func(uint32) bool) *failures {
+	return &failures{
+		tags:      make(map[uint32]map[string]*Failure),
+		tagExists: tagExists,
+	}
+}
+
+// prune forgets the failures of deleted tags and the failures which have not
+// been attempted again within the ttl, at most once in the prune interval.
+// Chunks are abandoned by not retrying them. Must be called with the mutex
+// held.
+func (f *failures) prune() {
+	now := time.Now()
+	if now.Sub(f.pruned) < failurePruneInterval {
+		return
+	}
+	f.pruned = now
+
+	for tagID, chunks := range f.tags {
+		if !f.tagExists(tagID) {
+			delete(f.tags, tagID)
+			continue
+		}
+		for key, failure := range chunks {
+			if now.Sub(failure.LastAttempt) > failureTTL {
+				delete(chunks, key)
+			}
+		}
+		if len(chunks) == 0 {
+			delete(f.tags, tagID)
+		}
+	}
+}
+
+// record registers a failed push attempt of a tagged chunk.
+func (f *failures) record(ch swarm.Chunk, err error) {
+	if ch.TagID() == 0 {
+		return
+	}
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.prune()
+
+	chunks, ok := f.tags[ch.TagID()]
+	if !ok {
+		chunks = make(map[string]*Failure)
+		f.tags[ch.TagID()] = chunks
+	}
+	key := ch.Address().ByteString()
+	failure, ok := chunks[key]
+	if !ok {
+		failure = &Failure{Address: ch.Address()}
+		chunks[key] = failure
+	}
+	failure.Error = err.Error()
+	failure.Attempts++
+	failure.LastAttempt = time.Now()
+}
+
+// remove forgets the failures of a successfully pushed chunk.
+func (f *failures) remove(ch swarm.Chunk) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	chunks, ok := f.tags[ch.TagID()]
+	if !ok {
+		return
+	}
+	delete(chunks, ch.Address().ByteString())
+	if len(chunks) == 0 {
+		delete(f.tags, ch.TagID())
+	}
+}
+
+// list returns the failures of the tag ordered by address.
+func (f *failures) list(tagID uint32) []Failure {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.prune()
+
+	list := make([]Failure, 0, len(f.tags[tagID]))
+	for _, failure := range f.tags[tagID] {
+		list = append(list, *failure)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Address.String() < list[j].Address.String()
+	})
+	return list
+}
+
+// Failures implements the FailureTracker interface.
+func (s *Service) Failures(tagID uint32) []Failure {
+	return s.failures.list(tagID)
+}
+
+// Retry implements the FailureTracker interface. The shallow receipt attempts
+// of the chunks are reset, so that each of them gets the full number of
+// pushsync attempts again. Chunks which are already being pushed are skipped.
+func (s *Service) Retry(ctx context.Context, tagID uint32) (int, error) {
+	var ops []*Op
+	for _, failure := range s.failures.list(tagID) {
+		ch, err := s.storer.Get(ctx, storage.ModeGetSync, failure.Address)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				s.failures.remove(swarm.NewChunk(failure.Address, nil).WithTagID(tagID))
+				continue
+			}
+			return 0, err
+		}
+		if s.inflight.set(failure.Address.Bytes()) {
+			continue
+		}
+		s.attempts.reset(failure.Address)
+		ops = append(ops, &Op{Chunk: ch.WithTagID(tagID)})
+	}
+
+	if len(ops) == 0 {
+		return 0, nil
+	}
+
+	c := make(chan *Op)
+	s.AddFeed(c)
+	go func() {
+		defer close(c)
+		for _, op := range ops {
+			select {
+			case c <- op:
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+	return len(ops), nil
+}
@@ -57,3 +57,10 @@ func (a *attempts) try(ch swarm.Address) bool {
 	}
 	return true
 }
+
+// reset forgets the sync attempts of a chunk.
+func (a *attempts) reset(ch swarm.Address) {
+	a.mtx.Lock()
+	delete(a.attempts, ch.ByteString())
+	a.mtx.Unlock()
+}
@@ -16,6 +16,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/holisticode/bee/pkg/accounting"
 	"github.com/holisticode/bee/pkg/crypto"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/postage"
@@ -33,6 +34,7 @@ type Op struct {
 	Chunk  swarm.Chunk
 	Err    chan error
 	Direct bool
+	Budget string // key of the spending budget the push is charged to, if any
 }
 
 type OpChan <-chan *Op
@@ -50,6 +52,7 @@ type Service struct {
 	chunksWorkerQuitC chan struct{}
 	inflight          *inflight
 	attempts          *attempts
+	failures          *failures
 	sem               chan struct{}
 	smugler           chan OpChan
 }
@@ -80,6 +83,7 @@ func New(networkID uint64, storer storage.Storer, depther topology.NeighborhoodD
 		chunksWorkerQuitC: make(chan struct{}),
 		inflight:          newInflight(),
 		attempts:          &attempts{attempts: make(map[string]int)},
+		failures:          newFailures(tagExists(tagger)),
 		sem:               make(chan struct{}, concurrentPushes),
 		smugler:           make(chan OpChan),
 	}
@@ -87,6 +91,30 @@ func New(networkID uint64, storer storage.Storer, depther topology.NeighborhoodD
 	return p
 }
 
+// tagExists returns a function which reports whether the tag exists.
+func tagExists(tagger *tags.Tags) func(uint32) bool {
+	return func(uid uint32) bool {
+		if tagger == nil {
+			return true
+		}
+		_, err := tagger.Get(uid)
+		return !errors.Is(err, tags.ErrNotFound)
+	}
+}
+
+// budget returns the key of the spending budget the push is charged to.
+// Deferred uploads are charged to the budget recorded on their tag.
+func (s *Service) budget(op *Op) string {
+	if op.Budget != "" || op.Chunk.TagID() == 0 {
+		return op.Budget
+	}
+	t, err := s.tag.Get(op.Chunk.TagID())
+	if err != nil {
+		return ""
+	}
+	return t.Budget()
+}
+
 // chunksWorker is a loop that keeps looking for chunks that are locally uploaded ( by monitoring pushIndex )
 // and pushes them to the closest peer and get a receipt.
 func (s *Service) chunksWorker(warmupTime time.Duration, tracer *tracing.Tracer) {
@@ -127,6 +155,9 @@ func (s *Service) chunksWorker(warmupTime time.Duration, tracer *tracing.Tracer)
 	push := func(op *Op) {
 		s.metrics.TotalToPush.Inc()
 		ctx, logger := ctxLogger()
+		if budget := s.budget(op); budget != "" {
+			ctx = accounting.WithBudgetKey(ctx, budget)
+		}
 		startTime := time.Now()
 		wg.Add(1)
 		go func() {
@@ -143,6 +174,7 @@ func (s *Service) chunksWorker(warmupTime time.Duration, tracer *tracing.Tracer)
 				if op.Err != nil {
 					op.Err <- err
 				}
+				s.failures.record(op.Chunk, err)
 				repeat()
 				s.metrics.TotalErrors.Inc()
 				s.metrics.ErrorTime.Observe(time.Since(startTime).Seconds())
@@ -152,6 +184,7 @@ func (s *Service) chunksWorker(warmupTime time.Duration, tracer *tracing.Tracer)
 			if op.Err != nil {
 				op.Err <- nil
 			}
+			s.failures.remove(op.Chunk)
 			s.metrics.TotalSynced.Inc()
 		}()
 	}
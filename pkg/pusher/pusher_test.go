@@ -8,13 +8,18 @@ import (
 	"context"
 	"errors"
 	"io"
+	"math/big"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/accounting"
 	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/p2p"
+	p2pmock "github.com/holisticode/bee/pkg/p2p/mock"
 	"github.com/holisticode/bee/pkg/postage"
 	statestore "github.com/holisticode/bee/pkg/statestore/mock"
 	"github.com/holisticode/bee/pkg/topology"
@@ -398,6 +403,230 @@ func TestPusherRetryShallow(t *testing.T) {
 	t.Fatalf("timed out waiting for retries. got %d want %d", c, *pusher.RetryCount)
 }
 
+// TestFailuresRetry tests that failed pushes of tagged chunks are tracked
+// and cleared once a retry succeeds.
+func TestFailuresRetry(t *testing.T) {
+	var (
+		triggerPeer = swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+		closestPeer = swarm.MustParseHexAddress("f000000000000000000000000000000000000000000000000000000000000000")
+		key, _      = crypto.GenerateSecp256k1Key()
+		signer      = crypto.NewDefaultSigner(key)
+		failing     = int32(1)
+		errPush     = errors.New("push failed")
+	)
+	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		if atomic.LoadInt32(&failing) == 1 {
+			return nil, errPush
+		}
+		signature, _ := signer.Sign(chunk.Address().Bytes())
+		receipt := &pushsync.Receipt{
+			Address:   swarm.NewAddress(chunk.Address().Bytes()),
+			Signature: signature,
+			BlockHash: block,
+		}
+		return receipt, nil
+	})
+
+	mtags, p, storer := createPusher(t, triggerPeer, pushSyncService, defaultMockValidStamp, mock.WithClosestPeer(closestPeer), mock.WithNeighborhoodDepth(0))
+	defer storer.Close()
+	defer p.Close()
+
+	ta, err := mtags.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunk := testingc.GenerateTestRandomChunk().WithTagID(ta.Uid)
+
+	_, err = storer.Put(context.Background(), storage.ModePutUpload, chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var failures []pusher.Failure
+	for i := 0; i < noOfRetries; i++ {
+		time.Sleep(50 * time.Millisecond)
+
+		failures = p.Failures(ta.Uid)
+		if len(failures) > 0 {
+			break
+		}
+	}
+	if len(failures) != 1 {
+		t.Fatalf("got %d failures, want 1", len(failures))
+	}
+	if !failures[0].Address.Equal(chunk.Address()) {
+		t.Fatalf("got address %s, want %s", failures[0].Address, chunk.Address())
+	}
+	if failures[0].Error != errPush.Error() {
+		t.Fatalf("got error %q, want %q", failures[0].Error, errPush.Error())
+	}
+	if failures[0].Attempts < 1 {
+		t.Fatalf("got %d attempts, want at least 1", failures[0].Attempts)
+	}
+	if got := p.Failures(ta.Uid + 1); len(got) != 0 {
+		t.Fatalf("got %d failures for other tag, want 0", len(got))
+	}
+
+	atomic.StoreInt32(&failing, 0)
+
+	if _, err := p.Retry(context.Background(), ta.Uid); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < noOfRetries; i++ {
+		time.Sleep(50 * time.Millisecond)
+
+		err = checkIfModeSet(chunk.Address(), storage.ModeSetSync, storer)
+		if err == nil && len(p.Failures(ta.Uid)) == 0 {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Failures(ta.Uid); len(got) != 0 {
+		t.Fatalf("got %d failures after retry, want 0", len(got))
+	}
+}
+
+// TestDeferredPushBudget tests that the pushes of a deferred upload are
+// charged to the spending budget recorded on its tag and refused once the
+// budget is exceeded.
+func TestDeferredPushBudget(t *testing.T) {
+	var (
+		triggerPeer = swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+		closestPeer = swarm.MustParseHexAddress("f000000000000000000000000000000000000000000000000000000000000000")
+		key, _      = crypto.GenerateSecp256k1Key()
+		signer      = crypto.NewDefaultSigner(key)
+		logger      = logging.New(io.Discard, 0)
+	)
+
+	store := statestore.NewStateStore()
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(big.NewInt(1000000), 10, 0, logger, store, nil, big.NewInt(1000), p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer acc.Close()
+	acc.SetBudgets(accounting.BudgetLimits{}, accounting.BudgetLimits{Total: big.NewInt(1)})
+	acc.Connect(p2p.Peer{Address: closestPeer})
+
+	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		creditAction, err := acc.PrepareCredit(closestPeer, 1, true, accounting.Cause{Budget: accounting.BudgetKey(ctx)})
+		if err != nil {
+			return nil, err
+		}
+		defer creditAction.Cleanup()
+		if err := creditAction.Apply(); err != nil {
+			return nil, err
+		}
+		signature, _ := signer.Sign(chunk.Address().Bytes())
+		receipt := &pushsync.Receipt{
+			Address:   swarm.NewAddress(chunk.Address().Bytes()),
+			Signature: signature,
+			BlockHash: block,
+		}
+		return receipt, nil
+	})
+
+	mtags, p, storer := createPusher(t, triggerPeer, pushSyncService, defaultMockValidStamp, mock.WithClosestPeer(closestPeer), mock.WithNeighborhoodDepth(0))
+	defer storer.Close()
+	defer p.Close()
+
+	ta, err := mtags.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ta.SetBudget("key:1234")
+
+	chunks := []swarm.Chunk{
+		testingc.GenerateTestRandomChunk().WithTagID(ta.Uid),
+		testingc.GenerateTestRandomChunk().WithTagID(ta.Uid),
+	}
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	var failures []pusher.Failure
+	for i := 0; i < noOfRetries; i++ {
+		time.Sleep(50 * time.Millisecond)
+
+		failures = p.Failures(ta.Uid)
+		if ta.Get(tags.StateSynced) == 1 && len(failures) == 1 {
+			break
+		}
+	}
+	if got := ta.Get(tags.StateSynced); got != 1 {
+		t.Fatalf("got %d synced chunks, want 1 within the budget", got)
+	}
+	if len(failures) != 1 {
+		t.Fatalf("got %d failures, want 1", len(failures))
+	}
+	if !strings.Contains(failures[0].Error, accounting.ErrBudgetExceeded.Error()) {
+		t.Fatalf("got error %q, want %q", failures[0].Error, accounting.ErrBudgetExceeded)
+	}
+
+	budgets, err := acc.Budgets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var charged bool
+	for _, b := range budgets {
+		if b.Key == "key:1234" && b.Total.Cmp(big.NewInt(1)) == 0 {
+			charged = true
+		}
+	}
+	if !charged {
+		t.Fatalf("got budgets %+v, want the push charged to the budget of the tag", budgets)
+	}
+}
+
+// TestFailuresOfDeletedTag tests that the failures of a deleted tag are
+// forgotten.
+func TestFailuresOfDeletedTag(t *testing.T) {
+	defer func(d time.Duration) {
+		*pusher.FailurePruneInterval = d
+	}(*pusher.FailurePruneInterval)
+	*pusher.FailurePruneInterval = 0
+
+	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("f000000000000000000000000000000000000000000000000000000000000000")
+	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		return nil, errors.New("push failed")
+	})
+
+	mtags, p, storer := createPusher(t, triggerPeer, pushSyncService, defaultMockValidStamp, mock.WithClosestPeer(closestPeer), mock.WithNeighborhoodDepth(0))
+	defer storer.Close()
+	defer p.Close()
+
+	ta, err := mtags.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := testingc.GenerateTestRandomChunk().WithTagID(ta.Uid)
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < noOfRetries; i++ {
+		time.Sleep(50 * time.Millisecond)
+		if len(p.Failures(ta.Uid)) > 0 {
+			break
+		}
+	}
+	if got := p.Failures(ta.Uid); len(got) != 1 {
+		t.Fatalf("got %d failures, want 1", len(got))
+	}
+
+	mtags.Delete(ta.Uid)
+
+	if got := p.Failures(ta.Uid); len(got) != 0 {
+		t.Fatalf("got %d failures of the deleted tag, want 0", len(got))
+	}
+}
+
 // TestChunkWithInvalidStampSkipped tests that chunks with invalid stamps are skipped in pusher
 func TestChunkWithInvalidStampSkipped(t *testing.T) {
 	// create a trigger  and a closestpeer
@@ -21,7 +21,9 @@ import (
 	"github.com/holisticode/bee/pkg/p2p/protobuf"
 	"github.com/holisticode/bee/pkg/postage"
 	"github.com/holisticode/bee/pkg/pricer"
+	"github.com/holisticode/bee/pkg/pricer/headerutils"
 	"github.com/holisticode/bee/pkg/pushsync/pb"
+	"github.com/holisticode/bee/pkg/reputation"
 	"github.com/holisticode/bee/pkg/soc"
 	"github.com/holisticode/bee/pkg/storage"
 	"github.com/holisticode/bee/pkg/swarm"
@@ -85,6 +87,7 @@ type PushSync struct {
 	isFullNode     bool
 	warmupPeriod   time.Time
 	skipList       *peerSkipList
+	reputation     reputation.Recorder
 }
 
 type receiptResult struct {
@@ -119,6 +122,12 @@ func New(address swarm.Address, blockHash []byte, streamer p2p.StreamerDisconnec
 	return ps
 }
 
+// SetReputation sets the reputation which records the outcome of pushing
+// chunks to peers.
+func (ps *PushSync) SetReputation(r reputation.Recorder) {
+	ps.reputation = r
+}
+
 func (s *PushSync) Protocol() p2p.ProtocolSpec {
 	return p2p.ProtocolSpec{
 		Name:    protocolName,
@@ -177,7 +186,9 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 		return swarm.ErrInvalidChunk
 	}
 
-	price := ps.pricer.Price(chunkAddress)
+	// requests without an epoch are charged the prices acknowledged by the peer
+	epoch, _ := headerutils.ParseEpochHeader(stream.Headers())
+	price := ps.pricer.Price(p.Address, chunkAddress, epoch)
 
 	// if the peer is closer to the chunk, AND it's a full node, we were selected for replication. Return early.
 	if p.FullNode {
@@ -217,7 +228,7 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 				return fmt.Errorf("chunk store: %w", err)
 			}
 
-			debit, err := ps.accounting.PrepareDebit(p.Address, price)
+			debit, err := ps.accounting.PrepareDebit(p.Address, price, accounting.Cause{Protocol: protocolName, Chunk: chunkAddress})
 			if err != nil {
 				return fmt.Errorf("prepare debit to peer %s before writeback: %w", p.Address.String(), err)
 			}
@@ -277,7 +288,7 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 			}
 
 			// return back receipt
-			debit, err := ps.accounting.PrepareDebit(p.Address, price)
+			debit, err := ps.accounting.PrepareDebit(p.Address, price, accounting.Cause{Protocol: protocolName, Chunk: chunkAddress})
 			if err != nil {
 				return fmt.Errorf("prepare debit to peer %s before writeback: %w", p.Address.String(), err)
 			}
@@ -298,7 +309,7 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 
 	ps.metrics.Forwarder.Inc()
 
-	debit, err := ps.accounting.PrepareDebit(p.Address, price)
+	debit, err := ps.accounting.PrepareDebit(p.Address, price, accounting.Cause{Protocol: protocolName, Chunk: chunkAddress})
 	if err != nil {
 		return fmt.Errorf("prepare debit to peer %s before writeback: %w", p.Address.String(), err)
 	}
@@ -418,6 +429,7 @@ func (ps *PushSync) pushToClosest(ctx context.Context, ch swarm.Chunk, origin bo
 		case result := <-resultChan:
 
 			ps.measurePushPeer(result.pushTime, result.err, origin)
+			ps.recordReputation(result)
 
 			if result.err == nil {
 				close(doneChan)
@@ -427,6 +439,11 @@ func (ps *PushSync) pushToClosest(ctx context.Context, ch swarm.Chunk, origin bo
 			ps.metrics.TotalFailedSendAttempts.Inc()
 			logger.Debugf("pushsync: could not push to peer %s: %v", result.peer, result.err)
 
+			// no other peer can be paid either
+			if errors.Is(result.err, accounting.ErrBudgetExceeded) {
+				return nil, result.err
+			}
+
 			// pushPeer returned early, do not count as an attempt
 			if !result.attempted {
 				allowedAttempts++
@@ -461,6 +478,20 @@ func (ps *PushSync) measurePushPeer(t time.Time, err error, origin bool) {
 	ps.metrics.PushToPeerTime.WithLabelValues(status).Observe(time.Since(t).Seconds())
 }
 
+// recordReputation records the outcome of pushing a chunk to a peer. Pushes
+// that were not attempted do not reflect on the peer.
+func (ps *PushSync) recordReputation(result receiptResult) {
+	if ps.reputation == nil {
+		return
+	}
+	switch {
+	case result.err == nil:
+		ps.reputation.Success(result.peer, protocolName, time.Since(result.pushTime))
+	case result.attempted:
+		ps.reputation.Failure(result.peer, protocolName)
+	}
+}
+
 func (ps *PushSync) pushPeer(ctx context.Context, resultChan chan<- receiptResult, doneChan <-chan struct{}, peer swarm.Address, ch swarm.Chunk, origin bool) {
 
 	var (
@@ -479,10 +510,10 @@ func (ps *PushSync) pushPeer(ctx context.Context, resultChan chan<- receiptResul
 	}()
 
 	// compute the price we pay for this receipt and reserve it for the rest of this function
-	receiptPrice := ps.pricer.PeerPrice(peer, ch.Address())
+	receiptPrice, epoch := ps.pricer.PeerPrice(peer, ch.Address())
 
 	// Reserve to see whether we can make the request
-	creditAction, err := ps.accounting.PrepareCredit(peer, receiptPrice, origin)
+	creditAction, err := ps.accounting.PrepareCredit(peer, receiptPrice, origin, accounting.Cause{Protocol: protocolName, Chunk: ch.Address(), Budget: accounting.BudgetKey(ctx)})
 	if err != nil {
 		err = fmt.Errorf("reserve balance for peer %s: %w", peer, err)
 		return
@@ -494,7 +525,7 @@ func (ps *PushSync) pushPeer(ctx context.Context, resultChan chan<- receiptResul
 		return
 	}
 
-	streamer, err := ps.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	streamer, err := ps.streamer.NewStream(ctx, peer, headerutils.MakeEpochHeaders(epoch), protocolName, protocolVersion, streamName)
 	if err != nil {
 		err = fmt.Errorf("new stream for peer %s: %w", peer, err)
 		return
@@ -587,11 +618,12 @@ func (ps *PushSync) pushToNeighbour(ctx context.Context, peer swarm.Address, ch
 	}()
 
 	// price for neighborhood replication
-	receiptPrice := ps.pricer.PeerPrice(peer, ch.Address())
+	receiptPrice, epoch := ps.pricer.PeerPrice(peer, ch.Address())
 
 	// decouple the span data from the original context so it doesn't get
 	// cancelled, then glue the stuff on the new context
 	span := tracing.FromContext(ctx)
+	budget := accounting.BudgetKey(ctx)
 
 	ctx, cancel := context.WithTimeout(context.Background(), replicationTTL)
 	defer cancel()
@@ -601,14 +633,14 @@ func (ps *PushSync) pushToNeighbour(ctx context.Context, peer swarm.Address, ch
 	spanInner, _, ctx := ps.tracer.StartSpanFromContext(ctx, "pushsync-replication", ps.logger, opentracing.Tag{Key: "address", Value: ch.Address().String()})
 	defer spanInner.Finish()
 
-	creditAction, err := ps.accounting.PrepareCredit(peer, receiptPrice, origin)
+	creditAction, err := ps.accounting.PrepareCredit(peer, receiptPrice, origin, accounting.Cause{Protocol: protocolName, Chunk: ch.Address(), Budget: budget})
 	if err != nil {
 		err = fmt.Errorf("reserve balance for peer %s: %w", peer.String(), err)
 		return
 	}
 	defer creditAction.Cleanup()
 
-	streamer, err := ps.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	streamer, err := ps.streamer.NewStream(ctx, peer, headerutils.MakeEpochHeaders(epoch), protocolName, protocolVersion, streamName)
 	if err != nil {
 		err = fmt.Errorf("new stream for peer %s: %w", peer.String(), err)
 		return
@@ -0,0 +1,366 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package reputation scores peers by how well they served the requests of
+// this node so that routing can prefer reliable peers. The recorded
+// interactions decay over time, so that old faults are forgiven, and the
+// reputation of disconnected peers is forgotten once it has decayed.
+package reputation
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+const (
+	// NeutralScore is the score of peers without any recorded interactions.
+	NeutralScore = 0.5
+
+	// weight of the success rate in the score, the rest is the latency
+	successWeight = 0.7
+	// latency at which the latency factor of the score is one half
+	referenceLatency = 500 * time.Millisecond
+	// smoothing factor of the latency moving average
+	latencyEWMAFactor = 0.25
+	// penalties subtracted from the score
+	invalidChunkPenalty = 0.5
+	disputePenalty      = 0.25
+	// time in which the weight of the recorded interactions halves
+	decayHalfLife = time.Hour
+	// weight of the recorded interactions below which the reputation of a
+	// disconnected peer is forgotten
+	forgetWeight = 0.1
+	// minimal interval between scans for reputations to forget
+	pruneInterval = time.Minute
+)
+
+// Scorer scores peers.
+type Scorer interface {
+	// Score returns the score of the peer between 0 and 1.
+	Score(peer swarm.Address) float64
+}
+
+// Recorder records the outcome of interactions with peers.
+type Recorder interface {
+	// Success records a request to the peer over the protocol that was
+	// answered within the latency.
+	Success(peer swarm.Address, protocol string, latency time.Duration)
+	// Failure records a request to the peer over the protocol that failed.
+	Failure(peer swarm.Address, protocol string)
+	// InvalidChunk records an invalid chunk delivered by the peer.
+	InvalidChunk(peer swarm.Address)
+	// Dispute records an accounting dispute with the peer.
+	Dispute(peer swarm.Address)
+}
+
+// Interface is the reputation subsystem.
+type Interface interface {
+	Scorer
+	Recorder
+	// Snapshot returns the reputation of the peer. It returns false if
+	// nothing has been recorded for the peer.
+	Snapshot(peer swarm.Address) (Snapshot, bool)
+	// Connected records that the peer is connected.
+	Connected(peer swarm.Address)
+	// Disconnected records that the peer is disconnected, its reputation
+	// is forgotten once it has decayed.
+	Disconnected(peer swarm.Address)
+}
+
+// ProtocolSnapshot is the reputation of a peer for a single protocol.
+type ProtocolSnapshot struct {
+	Successes   uint64
+	Failures    uint64
+	LatencyEWMA time.Duration
+}
+
+// Snapshot is the reputation of a peer. The counts are decayed.
+type Snapshot struct {
+	Score         float64
+	Protocols     map[string]ProtocolSnapshot
+	InvalidChunks uint64
+	Disputes      uint64
+}
+
+type protocolStats struct {
+	successes   float64
+	failures    float64
+	latencyEWMA time.Duration
+}
+
+type peerStats struct {
+	protocols     map[string]*protocolStats
+	invalidChunks float64
+	disputes      float64
+	decayed       time.Time // time of the last decay
+	disconnected  bool
+}
+
+// decay decays the recorded interactions to the time now.
+func (p *peerStats) decay(now time.Time) {
+	elapsed := now.Sub(p.decayed)
+	if elapsed <= 0 {
+		return
+	}
+	p.decayed = now
+
+	f := math.Exp2(-float64(elapsed) / float64(decayHalfLife))
+	for _, s := range p.protocols {
+		s.successes *= f
+		s.failures *= f
+	}
+	p.invalidChunks *= f
+	p.disputes *= f
+}
+
+// weight returns the weight of the recorded interactions.
+func (p *peerStats) weight() float64 {
+	w := p.invalidChunks + p.disputes
+	for _, s := range p.protocols {
+		w += s.successes + s.failures
+	}
+	return w
+}
+
+// score combines the success rate and the latency of all protocols with the
+// penalties for invalid chunks and disputes.
+func (p *peerStats) score() float64 {
+	var (
+		successes, failures float64
+		latency             float64
+		latencies           int
+	)
+	for _, s := range p.protocols {
+		successes += s.successes
+		failures += s.failures
+		if s.successes > 0 {
+			latency += float64(s.latencyEWMA)
+			latencies++
+		}
+	}
+
+	// the success rate is laplace smoothed so that a few requests do not
+	// move the score to an extreme
+	successRate := (successes + 1) / (successes + failures + 2)
+
+	latencyFactor := NeutralScore
+	if latencies > 0 {
+		latency /= float64(latencies)
+		latencyFactor = float64(referenceLatency) / (float64(referenceLatency) + latency)
+	}
+
+	score := successWeight*successRate + (1-successWeight)*latencyFactor
+	score -= p.invalidChunks*invalidChunkPenalty + p.disputes*disputePenalty
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+// Service keeps the reputation of peers in memory.
+type Service struct {
+	mtx     sync.Mutex
+	peers   map[string]*peerStats
+	pruned  time.Time // time of the last scan for reputations to forget
+	timeNow func() time.Time
+}
+
+var _ Interface = (*Service)(nil)
+
+// New creates a new reputation Service.
+func New() *Service {
+	return &Service{
+		peers:   make(map[string]*peerStats),
+		timeNow: time.Now,
+	}
+}
+
+// peer returns the decayed stats of the peer. Must be called with the mutex
+// held.
+func (s *Service) peer(peer swarm.Address) *peerStats {
+	now := s.timeNow()
+	p, ok := s.peers[peer.ByteString()]
+	if !ok {
+		p = &peerStats{
+			protocols: make(map[string]*protocolStats),
+			decayed:   now,
+		}
+		s.peers[peer.ByteString()] = p
+	}
+	p.decay(now)
+	// only connected peers interact
+	p.disconnected = false
+	return p
+}
+
+// lookup returns the decayed stats of the peer if there are any. Must be
+// called with the mutex held.
+func (s *Service) lookup(peer swarm.Address) (*peerStats, bool) {
+	p, ok := s.peers[peer.ByteString()]
+	if !ok {
+		return nil, false
+	}
+	p.decay(s.timeNow())
+	return p, true
+}
+
+// protocol returns the stats of the peer for the protocol. Must be called
+// with the mutex held.
+func (s *Service) protocol(peer swarm.Address, protocol string) *protocolStats {
+	p := s.peer(peer)
+	ps, ok := p.protocols[protocol]
+	if !ok {
+		ps = new(protocolStats)
+		p.protocols[protocol] = ps
+	}
+	return ps
+}
+
+// Success implements the Recorder interface.
+func (s *Service) Success(peer swarm.Address, protocol string, latency time.Duration) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	ps := s.protocol(peer, protocol)
+	if ps.successes == 0 {
+		ps.latencyEWMA = latency
+	} else {
+		ps.latencyEWMA = time.Duration(latencyEWMAFactor*float64(latency) + (1-latencyEWMAFactor)*float64(ps.latencyEWMA))
+	}
+	ps.successes++
+}
+
+// Failure implements the Recorder interface.
+func (s *Service) Failure(peer swarm.Address, protocol string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.protocol(peer, protocol).failures++
+}
+
+// InvalidChunk implements the Recorder interface.
+func (s *Service) InvalidChunk(peer swarm.Address) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.peer(peer).invalidChunks++
+}
+
+// Dispute implements the Recorder interface.
+func (s *Service) Dispute(peer swarm.Address) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.peer(peer).disputes++
+}
+
+// Score implements the Scorer interface.
+func (s *Service) Score(peer swarm.Address) float64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	p, ok := s.lookup(peer)
+	if !ok {
+		return NeutralScore
+	}
+	return p.score()
+}
+
+// Snapshot implements the Interface.
+func (s *Service) Snapshot(peer swarm.Address) (Snapshot, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	p, ok := s.lookup(peer)
+	if !ok {
+		return Snapshot{}, false
+	}
+
+	ss := Snapshot{
+		Score:         p.score(),
+		Protocols:     make(map[string]ProtocolSnapshot, len(p.protocols)),
+		InvalidChunks: count(p.invalidChunks),
+		Disputes:      count(p.disputes),
+	}
+	for name, ps := range p.protocols {
+		ss.Protocols[name] = ProtocolSnapshot{
+			Successes:   count(ps.successes),
+			Failures:    count(ps.failures),
+			LatencyEWMA: ps.latencyEWMA,
+		}
+	}
+	return ss, true
+}
+
+// count rounds the decayed count.
+func count(c float64) uint64 {
+	return uint64(math.Round(c))
+}
+
+// Connected implements the Interface.
+func (s *Service) Connected(peer swarm.Address) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if p, ok := s.peers[peer.ByteString()]; ok {
+		p.disconnected = false
+	}
+}
+
+// Disconnected implements the Interface.
+func (s *Service) Disconnected(peer swarm.Address) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if p, ok := s.lookup(peer); ok {
+		p.disconnected = true
+		if p.weight() < forgetWeight {
+			delete(s.peers, peer.ByteString())
+		}
+	}
+	s.prune()
+}
+
+// prune forgets the reputations of the disconnected peers which have decayed,
+// at most once in the prune interval. Must be called with the mutex held.
+func (s *Service) prune() {
+	now := s.timeNow()
+	if now.Sub(s.pruned) < pruneInterval {
+		return
+	}
+	s.pruned = now
+
+	for key, p := range s.peers {
+		if !p.disconnected {
+			continue
+		}
+		p.decay(now)
+		if p.weight() < forgetWeight {
+			delete(s.peers, key)
+		}
+	}
+}
+
+// Prefer reports whether peer is a better candidate than current to route a
+// request for addr to. A peer is better if it is in a higher proximity order
+// to addr or, among equally close candidates, if it has a higher score.
+// Candidates with the same score are compared by distance. Without a scorer
+// only the distance is compared.
+func Prefer(s Scorer, addr, peer, current swarm.Address) (bool, error) {
+	if s != nil {
+		peerPO := swarm.Proximity(addr.Bytes(), peer.Bytes())
+		currentPO := swarm.Proximity(addr.Bytes(), current.Bytes())
+		if peerPO != currentPO {
+			return peerPO > currentPO, nil
+		}
+		peerScore, currentScore := s.Score(peer), s.Score(current)
+		if peerScore != currentScore {
+			return peerScore > currentScore, nil
+		}
+	}
+	return peer.Closer(addr, current)
+}
@@ -0,0 +1,186 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reputation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/holisticode/bee/pkg/reputation"
+	"github.com/holisticode/bee/pkg/swarm"
+	"github.com/holisticode/bee/pkg/swarm/test"
+)
+
+func TestScore(t *testing.T) {
+	s := reputation.New()
+
+	unknown := test.RandomAddress()
+	if got := s.Score(unknown); got != reputation.NeutralScore {
+		t.Fatalf("got score %v for unknown peer, want %v", got, reputation.NeutralScore)
+	}
+	if _, ok := s.Snapshot(unknown); ok {
+		t.Fatal("got snapshot for unknown peer")
+	}
+
+	fast, slow, failing := test.RandomAddress(), test.RandomAddress(), test.RandomAddress()
+	for i := 0; i < 10; i++ {
+		s.Success(fast, "retrieval", 10*time.Millisecond)
+		s.Success(slow, "retrieval", 5*time.Second)
+		s.Failure(failing, "retrieval")
+	}
+
+	if !(s.Score(fast) > s.Score(slow)) {
+		t.Fatalf("fast peer score %v not above slow peer score %v", s.Score(fast), s.Score(slow))
+	}
+	if !(s.Score(slow) > s.Score(failing)) {
+		t.Fatalf("slow peer score %v not above failing peer score %v", s.Score(slow), s.Score(failing))
+	}
+	if !(s.Score(failing) < reputation.NeutralScore) {
+		t.Fatalf("failing peer score %v not below neutral", s.Score(failing))
+	}
+
+	before := s.Score(fast)
+	s.Dispute(fast)
+	afterDispute := s.Score(fast)
+	if !(afterDispute < before) {
+		t.Fatalf("score %v did not drop after dispute from %v", afterDispute, before)
+	}
+	s.InvalidChunk(fast)
+	s.InvalidChunk(fast)
+	if got := s.Score(fast); got != 0 {
+		t.Fatalf("got score %v after invalid chunks, want 0", got)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	s := reputation.New()
+	// the recorded interactions do not decay on a stopped clock
+	now := time.Unix(1000000, 0)
+	s.SetTimeNow(func() time.Time { return now })
+	peer := test.RandomAddress()
+
+	s.Success(peer, "retrieval", 100*time.Millisecond)
+	s.Success(peer, "retrieval", 500*time.Millisecond)
+	s.Failure(peer, "retrieval")
+	s.Failure(peer, "pushsync")
+	s.InvalidChunk(peer)
+	s.Dispute(peer)
+
+	ss, ok := s.Snapshot(peer)
+	if !ok {
+		t.Fatal("missing snapshot")
+	}
+	if ss.Score != s.Score(peer) {
+		t.Fatalf("got snapshot score %v, want %v", ss.Score, s.Score(peer))
+	}
+	if ss.InvalidChunks != 1 || ss.Disputes != 1 {
+		t.Fatalf("got %d invalid chunks and %d disputes, want 1 and 1", ss.InvalidChunks, ss.Disputes)
+	}
+
+	retrieval := ss.Protocols["retrieval"]
+	if retrieval.Successes != 2 || retrieval.Failures != 1 {
+		t.Fatalf("got retrieval %+v", retrieval)
+	}
+	// the moving average starts at the first latency and moves towards the
+	// following ones
+	if retrieval.LatencyEWMA != 200*time.Millisecond {
+		t.Fatalf("got latency %v, want %v", retrieval.LatencyEWMA, 200*time.Millisecond)
+	}
+
+	pushsync := ss.Protocols["pushsync"]
+	if pushsync.Successes != 0 || pushsync.Failures != 1 {
+		t.Fatalf("got pushsync %+v", pushsync)
+	}
+}
+
+func TestDecay(t *testing.T) {
+	s := reputation.New()
+	now := time.Unix(1000000, 0)
+	s.SetTimeNow(func() time.Time { return now })
+
+	peer := test.RandomAddress()
+	for i := 0; i < 8; i++ {
+		s.Failure(peer, "retrieval")
+	}
+	s.InvalidChunk(peer)
+	bad := s.Score(peer)
+	if bad != 0 {
+		t.Fatalf("got score %v, want 0", bad)
+	}
+
+	now = now.Add(reputation.DecayHalfLife)
+	ss, _ := s.Snapshot(peer)
+	if f := ss.Protocols["retrieval"].Failures; f != 4 {
+		t.Fatalf("got %d failures after a half life, want 4", f)
+	}
+
+	// the fault is forgiven in time
+	now = now.Add(10 * reputation.DecayHalfLife)
+	if got := s.Score(peer); got <= bad || got < reputation.NeutralScore-0.01 {
+		t.Fatalf("got score %v after decay, want about %v", got, reputation.NeutralScore)
+	}
+}
+
+func TestForget(t *testing.T) {
+	s := reputation.New()
+	now := time.Unix(1000000, 0)
+	s.SetTimeNow(func() time.Time { return now })
+
+	connected, disconnected := test.RandomAddress(), test.RandomAddress()
+	s.Failure(connected, "retrieval")
+	s.Failure(disconnected, "retrieval")
+	s.Disconnected(disconnected)
+
+	// the reputation of disconnected peers is kept until it has decayed
+	if _, ok := s.Snapshot(disconnected); !ok {
+		t.Fatal("reputation of the recently disconnected peer is forgotten")
+	}
+
+	now = now.Add(10 * reputation.DecayHalfLife)
+	s.Disconnected(test.RandomAddress())
+
+	if _, ok := s.Snapshot(disconnected); ok {
+		t.Fatal("decayed reputation of the disconnected peer is kept")
+	}
+	if _, ok := s.Snapshot(connected); !ok {
+		t.Fatal("reputation of the connected peer is forgotten")
+	}
+}
+
+func TestPrefer(t *testing.T) {
+	s := reputation.New()
+
+	addr := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	nearest := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // po 3 to addr
+	near := swarm.MustParseHexAddress("6800000000000000000000000000000000000000000000000000000000000000")    // po 3 to addr
+	far := swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000")     // po 2 to addr
+
+	prefer := func(scorer reputation.Scorer, peer, current swarm.Address, want bool) {
+		t.Helper()
+		got, err := reputation.Prefer(scorer, addr, peer, current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("prefer %s over %s: got %v, want %v", peer, current, got, want)
+		}
+	}
+
+	// equal scores are compared by distance
+	prefer(s, nearest, near, true)
+	prefer(s, near, nearest, false)
+
+	s.Success(near, "retrieval", time.Millisecond)
+	s.Success(far, "retrieval", time.Millisecond)
+	s.Success(far, "retrieval", time.Millisecond)
+
+	// a better score wins within the same proximity order
+	prefer(s, near, nearest, true)
+	prefer(nil, near, nearest, false)
+
+	// but not across proximity orders
+	prefer(s, far, near, false)
+	prefer(s, near, far, true)
+}
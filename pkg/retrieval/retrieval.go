@@ -12,6 +12,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/holisticode/bee/pkg/accounting"
@@ -21,6 +22,8 @@ import (
 	"github.com/holisticode/bee/pkg/p2p/protobuf"
 	"github.com/holisticode/bee/pkg/postage"
 	"github.com/holisticode/bee/pkg/pricer"
+	"github.com/holisticode/bee/pkg/pricer/headerutils"
+	"github.com/holisticode/bee/pkg/reputation"
 	pb "github.com/holisticode/bee/pkg/retrieval/pb"
 	"github.com/holisticode/bee/pkg/soc"
 	"github.com/holisticode/bee/pkg/storage"
@@ -65,6 +68,8 @@ type Service struct {
 	tracer        *tracing.Tracer
 	caching       bool
 	validStamp    postage.ValidStampFn
+	inflight      int64 // number of requests from peers being served
+	reputation    reputation.Interface
 }
 
 func New(addr swarm.Address, storer storage.Storer, streamer p2p.Streamer, chunkPeerer topology.EachPeerer, logger logging.Logger, accounting accounting.Interface, pricer pricer.Interface, tracer *tracing.Tracer, forwarderCaching bool, validStamp postage.ValidStampFn) *Service {
@@ -83,6 +88,12 @@ func New(addr swarm.Address, storer storage.Storer, streamer p2p.Streamer, chunk
 	}
 }
 
+// SetReputation sets the reputation which records the outcome of the requests
+// to peers and is used to prefer well-scored peers.
+func (s *Service) SetReputation(r reputation.Interface) {
+	s.reputation = r
+}
+
 func (s *Service) Protocol() p2p.ProtocolSpec {
 	return p2p.ProtocolSpec{
 		Name:    protocolName,
@@ -143,6 +154,8 @@ func (s *Service) RetrieveChunk(ctx context.Context, addr swarm.Address, origin
 				// create a new context without cancelation but
 				// set the tracing span to the new context from the context of the first caller
 				ctx := tracing.WithContext(context.Background(), tracing.FromContext(topCtx))
+				// and keep charging the budget of the first caller
+				ctx = accounting.WithBudgetKey(ctx, accounting.BudgetKey(topCtx))
 
 				// get the tracing span
 				span, _, ctx := s.tracer.StartSpanFromContext(ctx, "retrieve-chunk", s.logger, opentracing.Tag{Key: "address", Value: addr.String()})
@@ -179,6 +192,10 @@ func (s *Service) RetrieveChunk(ctx context.Context, addr swarm.Address, origin
 			case <-ticker.C:
 				// break
 			case res := <-resultC:
+				if errors.Is(res.err, accounting.ErrBudgetExceeded) {
+					s.logger.Tracef("retrieval: failed to get chunk %s: %v", addr, res.err)
+					return nil, res.err
+				}
 				if errors.Is(res.err, topology.ErrNotFound) {
 					if sp.Saturated() {
 						// if no peer is available, and none skipped temporarily
@@ -273,10 +290,10 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, sp *ski
 	}
 
 	// compute the peer's price for this chunk for price header
-	chunkPrice := s.pricer.PeerPrice(peer, addr)
+	chunkPrice, epoch := s.pricer.PeerPrice(peer, addr)
 
 	// Reserve to see whether we can request the chunk
-	creditAction, err := s.accounting.PrepareCredit(peer, chunkPrice, originated)
+	creditAction, err := s.accounting.PrepareCredit(peer, chunkPrice, originated, accounting.Cause{Protocol: protocolName, Chunk: addr, Budget: accounting.BudgetKey(ctx)})
 	if err != nil {
 		sp.AddOverdraft(peer)
 		return nil, peer, false, err
@@ -287,7 +304,7 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, sp *ski
 
 	s.logger.Tracef("retrieval: requesting chunk %s from peer %s", addr, peer)
 
-	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	stream, err := s.streamer.NewStream(ctx, peer, headerutils.MakeEpochHeaders(epoch), protocolName, protocolVersion, streamName)
 	if err != nil {
 		s.metrics.TotalErrors.Inc()
 		return nil, peer, false, fmt.Errorf("new stream: %w", err)
@@ -312,6 +329,9 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, sp *ski
 	var d pb.Delivery
 	if err := r.ReadMsgWithContext(ctx, &d); err != nil {
 		s.metrics.TotalErrors.Inc()
+		if s.reputation != nil {
+			s.reputation.Failure(peer, protocolName)
+		}
 		return nil, peer, true, fmt.Errorf("read delivery: %w peer %s", err, peer.String())
 	}
 	s.metrics.ChunkRetrieveTime.Observe(time.Since(startTimer).Seconds())
@@ -327,6 +347,9 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, sp *ski
 		if !soc.Valid(chunk) {
 			s.metrics.InvalidChunkRetrieved.Inc()
 			s.metrics.TotalErrors.Inc()
+			if s.reputation != nil {
+				s.reputation.InvalidChunk(peer)
+			}
 			return nil, peer, true, swarm.ErrInvalidChunk
 		}
 	}
@@ -337,6 +360,9 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, sp *ski
 		return nil, peer, true, err
 	}
 	s.metrics.ChunkPrice.Observe(float64(chunkPrice))
+	if s.reputation != nil {
+		s.reputation.Success(peer, protocolName, time.Since(startTimer))
+	}
 	return chunk, peer, true, err
 }
 
@@ -346,7 +372,14 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, sp *ski
 // the chunk than this node is, could also be returned, allowing the upstream
 // retrieve request.
 func (s *Service) closestPeer(addr swarm.Address, skipPeers []swarm.Address, allowUpstream bool) (swarm.Address, error) {
-	closest := swarm.Address{}
+	var scorer reputation.Scorer
+	if s.reputation != nil {
+		scorer = s.reputation
+	}
+
+	// closest is the peer chosen for the request while nearest is the peer
+	// nearest to the chunk which decides whether the request goes upstream
+	closest, nearest := swarm.Address{}, swarm.Address{}
 	err := s.peerSuggester.EachPeerRev(func(peer swarm.Address, po uint8) (bool, bool, error) {
 		for _, a := range skipPeers {
 			if a.Equal(peer) {
@@ -354,14 +387,21 @@ func (s *Service) closestPeer(addr swarm.Address, skipPeers []swarm.Address, all
 			}
 		}
 		if closest.IsZero() {
-			closest = peer
+			closest, nearest = peer, peer
 			return false, false, nil
 		}
-		closer, err := peer.Closer(addr, closest)
+		closer, err := peer.Closer(addr, nearest)
 		if err != nil {
-			return false, false, fmt.Errorf("distance compare error. addr %s closest %s peer %s: %w", addr.String(), closest.String(), peer.String(), err)
+			return false, false, fmt.Errorf("distance compare error. addr %s closest %s peer %s: %w", addr.String(), nearest.String(), peer.String(), err)
 		}
 		if closer {
+			nearest = peer
+		}
+		prefer, err := reputation.Prefer(scorer, addr, peer, closest)
+		if err != nil {
+			return false, false, fmt.Errorf("distance compare error. addr %s closest %s peer %s: %w", addr.String(), closest.String(), peer.String(), err)
+		}
+		if prefer {
 			closest = peer
 		}
 		return false, false, nil
@@ -378,9 +418,9 @@ func (s *Service) closestPeer(addr swarm.Address, skipPeers []swarm.Address, all
 		return closest, nil
 	}
 
-	closer, err := closest.Closer(addr, s.addr)
+	closer, err := nearest.Closer(addr, s.addr)
 	if err != nil {
-		return swarm.Address{}, fmt.Errorf("distance compare addr %s closest %s base address %s: %w", addr.String(), closest.String(), s.addr.String(), err)
+		return swarm.Address{}, fmt.Errorf("distance compare addr %s closest %s base address %s: %w", addr.String(), nearest.String(), s.addr.String(), err)
 	}
 	if closer {
 		return swarm.Address{}, topology.ErrNotFound
@@ -389,7 +429,16 @@ func (s *Service) closestPeer(addr swarm.Address, skipPeers []swarm.Address, all
 	return closest, nil
 }
 
+// InflightRequests returns the number of chunk requests from peers which are
+// currently being served.
+func (s *Service) InflightRequests() int64 {
+	return atomic.LoadInt64(&s.inflight)
+}
+
 func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	atomic.AddInt64(&s.inflight, 1)
+	defer atomic.AddInt64(&s.inflight, -1)
+
 	w, r := protobuf.NewWriterAndReader(stream)
 	defer func() {
 		if err != nil {
@@ -428,8 +477,10 @@ func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (e
 		return fmt.Errorf("stamp marshal: %w", err)
 	}
 
-	chunkPrice := s.pricer.Price(chunk.Address())
-	debit, err := s.accounting.PrepareDebit(p.Address, chunkPrice)
+	// requests without an epoch are charged the prices acknowledged by the peer
+	epoch, _ := headerutils.ParseEpochHeader(stream.Headers())
+	chunkPrice := s.pricer.Price(p.Address, chunk.Address(), epoch)
+	debit, err := s.accounting.PrepareDebit(p.Address, chunkPrice, accounting.Cause{Protocol: protocolName, Chunk: chunk.Address()})
 	if err != nil {
 		return fmt.Errorf("prepare debit to peer %s before writeback: %w", p.Address.String(), err)
 	}
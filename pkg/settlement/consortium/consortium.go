@@ -0,0 +1,382 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package consortium implements a settlement driver for operators who trust
+// each other and settle without a chain.
+//
+// Instead of cheques a node pays with IOUs, signed promises over the
+// cumulative amount owed to a peer. The IOUs are only accepted from the
+// configured members of the consortium and the last one of every peer is kept
+// as the proof of what to settle out of band.
+package consortium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/p2p/protobuf"
+	"github.com/holisticode/bee/pkg/settlement"
+	"github.com/holisticode/bee/pkg/settlement/consortium/pb"
+	"github.com/holisticode/bee/pkg/settlement/driver"
+	"github.com/holisticode/bee/pkg/storage"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+// DriverName is the name of the consortium settlement driver.
+const DriverName = "consortium"
+
+const (
+	protocolName    = "consortium"
+	protocolVersion = "1.0.0"
+	streamName      = "iou"
+
+	totalSentPrefix     = "consortium_total_sent_"
+	totalReceivedPrefix = "consortium_total_received_"
+)
+
+var (
+	// pay timeout
+	payTimeout = 10 * time.Second
+)
+
+var (
+	// ErrNoMembers is returned if the driver is opened without members.
+	ErrNoMembers = errors.New("consortium without members")
+	// ErrNotMember is the error if an IOU is not signed by a member.
+	ErrNotMember = errors.New("iou not signed by a consortium member")
+	// ErrWrongIOU is the error if an IOU is not issued by the peer to us.
+	ErrWrongIOU = errors.New("iou not issued by peer to us")
+	// ErrIOUNotIncreasing is the error if an IOU does not exceed the last
+	// one of the peer.
+	ErrIOUNotIncreasing = errors.New("iou amount not increasing")
+)
+
+func init() {
+	driver.Register(DriverName, func(o driver.Options) (driver.Driver, error) {
+		if len(o.Members) == 0 {
+			return nil, ErrNoMembers
+		}
+		return New(o.Overlay, o.Signer, o.Streamer, o.Store, o.Accounting, o.Logger, o.Members), nil
+	})
+}
+
+// IOU is a signed promise of the issuer over the cumulative amount owed to
+// the beneficiary.
+type IOU struct {
+	Issuer           swarm.Address
+	Beneficiary      swarm.Address
+	CumulativeAmount *big.Int
+	Signature        []byte
+}
+
+// Service is the consortium settlement driver.
+type Service struct {
+	overlay    swarm.Address
+	signer     crypto.Signer
+	streamer   p2p.Streamer
+	store      storage.StateStorer
+	accounting settlement.Accounting
+	logger     logging.Logger
+	members    map[common.Address]struct{}
+
+	locksMu sync.Mutex
+	locks   map[string]*sync.Mutex // held while paying to or receiving from a peer
+}
+
+// New creates a new consortium Service accepting IOUs signed by the members.
+func New(overlay swarm.Address, signer crypto.Signer, streamer p2p.Streamer, store storage.StateStorer, accounting settlement.Accounting, logger logging.Logger, members []common.Address) *Service {
+	m := make(map[common.Address]struct{}, len(members))
+	for _, a := range members {
+		m[a] = struct{}{}
+	}
+	return &Service{
+		overlay:    overlay,
+		signer:     signer,
+		streamer:   streamer,
+		store:      store,
+		accounting: accounting,
+		logger:     logger,
+		members:    m,
+		locks:      make(map[string]*sync.Mutex),
+	}
+}
+
+// Name returns the name of the consortium settlement driver.
+func (s *Service) Name() string {
+	return DriverName
+}
+
+func (s *Service) Protocol() p2p.ProtocolSpec {
+	return p2p.ProtocolSpec{
+		Name:    protocolName,
+		Version: protocolVersion,
+		StreamSpecs: []p2p.StreamSpec{
+			{
+				Name:    streamName,
+				Handler: s.handler,
+			},
+		},
+	}
+}
+
+// peerLock returns the lock of the peer, creating it if needed.
+func (s *Service) peerLock(peer swarm.Address) *sync.Mutex {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	l, ok := s.locks[peer.ByteString()]
+	if !ok {
+		l = new(sync.Mutex)
+		s.locks[peer.ByteString()] = l
+	}
+	return l
+}
+
+// iouData returns the data an IOU is signed over.
+func iouData(issuer, beneficiary swarm.Address, cumulativeAmount *big.Int) []byte {
+	data := make([]byte, 0, len(issuer.Bytes())+len(beneficiary.Bytes())+32)
+	data = append(data, issuer.Bytes()...)
+	data = append(data, beneficiary.Bytes()...)
+	return append(data, common.LeftPadBytes(cumulativeAmount.Bytes(), 32)...)
+}
+
+// recoverIssuer returns the Ethereum address the IOU is signed with.
+func recoverIssuer(iou *IOU) (common.Address, error) {
+	pubKey, err := crypto.Recover(iou.Signature, iouData(iou.Issuer, iou.Beneficiary, iou.CumulativeAmount))
+	if err != nil {
+		return common.Address{}, err
+	}
+	ethAddr, err := crypto.NewEthereumAddress(*pubKey)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return common.BytesToAddress(ethAddr), nil
+}
+
+func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	w, r := protobuf.NewWriterAndReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	var req pb.IOU
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read iou from peer %v: %w", p.Address, err)
+	}
+
+	iou := &IOU{
+		Issuer:           swarm.NewAddress(req.Issuer),
+		Beneficiary:      swarm.NewAddress(req.Beneficiary),
+		CumulativeAmount: new(big.Int).SetBytes(req.CumulativeAmount),
+		Signature:        req.Signature,
+	}
+	if !iou.Issuer.Equal(p.Address) || !iou.Beneficiary.Equal(s.overlay) {
+		return ErrWrongIOU
+	}
+	issuer, err := recoverIssuer(iou)
+	if err != nil {
+		return fmt.Errorf("recover iou issuer of peer %v: %w", p.Address, err)
+	}
+	if _, ok := s.members[issuer]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotMember, issuer)
+	}
+
+	l := s.peerLock(p.Address)
+	l.Lock()
+	defer l.Unlock()
+
+	last, err := s.lastIOU(totalReceivedPrefix, p.Address)
+	if err != nil {
+		return err
+	}
+	amount := new(big.Int).Sub(iou.CumulativeAmount, last.CumulativeAmount)
+	if amount.Sign() <= 0 {
+		return ErrIOUNotIncreasing
+	}
+
+	if err := s.store.Put(totalKey(totalReceivedPrefix, p.Address), iou); err != nil {
+		return err
+	}
+
+	s.logger.Tracef("consortium: received iou from peer %v of %d", p.Address, amount)
+	if err := s.accounting.NotifyPaymentReceived(p.Address, amount); err != nil {
+		return err
+	}
+
+	if err := w.WriteMsgWithContext(ctx, &pb.IOUAck{}); err != nil {
+		return fmt.Errorf("write iou ack to peer %v: %w", p.Address, err)
+	}
+	return nil
+}
+
+// Pay issues an IOU over the amount to the peer.
+func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount *big.Int) {
+	err := s.pay(ctx, peer, amount)
+	if err != nil {
+		s.logger.Debugf("consortium: pay peer %v: %v", peer, err)
+	}
+	s.accounting.NotifyPaymentSent(peer, amount, err)
+}
+
+func (s *Service) pay(ctx context.Context, peer swarm.Address, amount *big.Int) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, payTimeout)
+	defer cancel()
+
+	l := s.peerLock(peer)
+	l.Lock()
+	defer l.Unlock()
+
+	last, err := s.lastIOU(totalSentPrefix, peer)
+	if err != nil {
+		return err
+	}
+	cumulativeAmount := new(big.Int).Add(last.CumulativeAmount, amount)
+	signature, err := s.signer.Sign(iouData(s.overlay, peer, cumulativeAmount))
+	if err != nil {
+		return err
+	}
+
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	w, r := protobuf.NewWriterAndReader(stream)
+	err = w.WriteMsgWithContext(ctx, &pb.IOU{
+		Issuer:           s.overlay.Bytes(),
+		Beneficiary:      peer.Bytes(),
+		CumulativeAmount: cumulativeAmount.Bytes(),
+		Signature:        signature,
+	})
+	if err != nil {
+		return fmt.Errorf("write iou: %w", err)
+	}
+
+	var ack pb.IOUAck
+	if err = r.ReadMsgWithContext(ctx, &ack); err != nil {
+		return fmt.Errorf("read iou ack: %w", err)
+	}
+
+	return s.store.Put(totalKey(totalSentPrefix, peer), &IOU{
+		Issuer:           s.overlay,
+		Beneficiary:      peer,
+		CumulativeAmount: cumulativeAmount,
+		Signature:        signature,
+	})
+}
+
+func totalKey(prefix string, peer swarm.Address) string {
+	return prefix + peer.String()
+}
+
+func totalKeyPeer(key []byte, prefix string) (swarm.Address, error) {
+	return swarm.ParseHexAddress(strings.TrimPrefix(string(key), prefix))
+}
+
+// lastIOU returns the last IOU stored under the prefix for the peer or an
+// empty one if there is none.
+func (s *Service) lastIOU(prefix string, peer swarm.Address) (*IOU, error) {
+	var iou IOU
+	err := s.store.Get(totalKey(prefix, peer), &iou)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+		iou.CumulativeAmount = big.NewInt(0)
+	}
+	return &iou, nil
+}
+
+// LastSentIOU returns the last IOU issued to the peer, nil if there is none.
+func (s *Service) LastSentIOU(peer swarm.Address) (*IOU, error) {
+	return s.storedIOU(totalSentPrefix, peer)
+}
+
+// LastReceivedIOU returns the last IOU received from the peer, nil if there
+// is none.
+func (s *Service) LastReceivedIOU(peer swarm.Address) (*IOU, error) {
+	return s.storedIOU(totalReceivedPrefix, peer)
+}
+
+func (s *Service) storedIOU(prefix string, peer swarm.Address) (*IOU, error) {
+	var iou IOU
+	err := s.store.Get(totalKey(prefix, peer), &iou)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &iou, nil
+}
+
+// TotalSent returns the total amount sent to a peer
+func (s *Service) TotalSent(peer swarm.Address) (*big.Int, error) {
+	return s.total(totalSentPrefix, peer)
+}
+
+// TotalReceived returns the total amount received from a peer
+func (s *Service) TotalReceived(peer swarm.Address) (*big.Int, error) {
+	return s.total(totalReceivedPrefix, peer)
+}
+
+func (s *Service) total(prefix string, peer swarm.Address) (*big.Int, error) {
+	iou, err := s.storedIOU(prefix, peer)
+	if err != nil {
+		return nil, err
+	}
+	if iou == nil {
+		return nil, settlement.ErrPeerNoSettlements
+	}
+	return iou.CumulativeAmount, nil
+}
+
+// SettlementsSent returns sent settlements for each individual known peer
+func (s *Service) SettlementsSent() (map[string]*big.Int, error) {
+	return s.settlements(totalSentPrefix)
+}
+
+// SettlementsReceived returns received settlements for each individual known peer
+func (s *Service) SettlementsReceived() (map[string]*big.Int, error) {
+	return s.settlements(totalReceivedPrefix)
+}
+
+func (s *Service) settlements(prefix string) (map[string]*big.Int, error) {
+	result := make(map[string]*big.Int)
+	err := s.store.Iterate(prefix, func(key, val []byte) (stop bool, err error) {
+		peer, err := totalKeyPeer(key, prefix)
+		if err != nil {
+			return false, fmt.Errorf("parse address from key: %s: %w", string(key), err)
+		}
+		var iou IOU
+		if err := s.store.Get(string(key), &iou); err != nil {
+			return false, err
+		}
+		result[peer.String()] = iou.CumulativeAmount
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package consortium_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/p2p/streamtest"
+	"github.com/holisticode/bee/pkg/settlement"
+	"github.com/holisticode/bee/pkg/settlement/consortium"
+	"github.com/holisticode/bee/pkg/settlement/driver"
+	"github.com/holisticode/bee/pkg/statestore/mock"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+type testAccounting struct {
+	received chan *big.Int
+	sent     chan error
+}
+
+func newTestAccounting() *testAccounting {
+	return &testAccounting{
+		received: make(chan *big.Int, 1),
+		sent:     make(chan error, 1),
+	}
+}
+
+func (a *testAccounting) PeerDebt(peer swarm.Address) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (a *testAccounting) NotifyPaymentReceived(peer swarm.Address, amount *big.Int) error {
+	a.received <- amount
+	return nil
+}
+
+func (a *testAccounting) NotifyPaymentSent(peer swarm.Address, amount *big.Int, err error) {
+	a.sent <- err
+}
+
+func (a *testAccounting) NotifyRefreshmentReceived(peer swarm.Address, amount *big.Int) error {
+	return nil
+}
+
+func (a *testAccounting) Connect(peer p2p.Peer) {}
+
+func (a *testAccounting) Disconnect(peer swarm.Address) {}
+
+type member struct {
+	overlay swarm.Address
+	signer  crypto.Signer
+	address common.Address
+}
+
+func newMember(t *testing.T, overlay string) member {
+	t.Helper()
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ethAddr, err := crypto.NewEthereumAddress(key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return member{
+		overlay: swarm.MustParseHexAddress(overlay),
+		signer:  crypto.NewDefaultSigner(key),
+		address: common.BytesToAddress(ethAddr),
+	}
+}
+
+// newPair creates a payer and a recipient accepting IOUs from the members.
+func newPair(t *testing.T, payer, recipient member, members []common.Address) (*consortium.Service, *testAccounting, *consortium.Service, *testAccounting) {
+	t.Helper()
+	logger := logging.New(ioutil.Discard, 0)
+
+	recipientAccounting := newTestAccounting()
+	recipientStore := mock.NewStateStore()
+	t.Cleanup(func() { recipientStore.Close() })
+	recipientService := consortium.New(recipient.overlay, recipient.signer, nil, recipientStore, recipientAccounting, logger, members)
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(recipientService.Protocol()),
+		streamtest.WithBaseAddr(payer.overlay),
+	)
+
+	payerAccounting := newTestAccounting()
+	payerStore := mock.NewStateStore()
+	t.Cleanup(func() { payerStore.Close() })
+	payerService := consortium.New(payer.overlay, payer.signer, recorder, payerStore, payerAccounting, logger, members)
+
+	return payerService, payerAccounting, recipientService, recipientAccounting
+}
+
+func TestPay(t *testing.T) {
+	payer := newMember(t, "aaaa")
+	recipient := newMember(t, "bbbb")
+	payerService, payerAccounting, recipientService, recipientAccounting := newPair(t, payer, recipient, []common.Address{payer.address, recipient.address})
+
+	for _, amount := range []int64{10, 20} {
+		payerService.Pay(context.Background(), recipient.overlay, big.NewInt(amount))
+
+		select {
+		case err := <-payerAccounting.sent:
+			if err != nil {
+				t.Fatalf("payment of %d failed: %v", amount, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("payment not reported")
+		}
+
+		select {
+		case received := <-recipientAccounting.received:
+			if received.Int64() != amount {
+				t.Fatalf("got received amount %d, want %d", received, amount)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("payment not received")
+		}
+	}
+
+	totalSent, err := payerService.TotalSent(recipient.overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totalSent.Int64() != 30 {
+		t.Fatalf("got total sent %d, want 30", totalSent)
+	}
+
+	totalReceived, err := recipientService.TotalReceived(payer.overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totalReceived.Int64() != 30 {
+		t.Fatalf("got total received %d, want 30", totalReceived)
+	}
+
+	received, err := recipientService.SettlementsReceived()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[payer.overlay.String()].Int64() != 30 {
+		t.Fatalf("got settlements received %v", received)
+	}
+
+	iou, err := recipientService.LastReceivedIOU(payer.overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !iou.Issuer.Equal(payer.overlay) || !iou.Beneficiary.Equal(recipient.overlay) || iou.CumulativeAmount.Int64() != 30 {
+		t.Fatalf("got last received iou %+v", iou)
+	}
+
+	if _, err := payerService.TotalReceived(recipient.overlay); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+}
+
+func TestPayNotMember(t *testing.T) {
+	payer := newMember(t, "aaaa")
+	recipient := newMember(t, "bbbb")
+	payerService, payerAccounting, recipientService, recipientAccounting := newPair(t, payer, recipient, []common.Address{recipient.address})
+
+	payerService.Pay(context.Background(), recipient.overlay, big.NewInt(10))
+
+	select {
+	case err := <-payerAccounting.sent:
+		if err == nil {
+			t.Fatal("expected payment to fail")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("payment not reported")
+	}
+
+	select {
+	case <-recipientAccounting.received:
+		t.Fatal("unexpected payment received")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if _, err := payerService.TotalSent(recipient.overlay); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+	if _, err := recipientService.TotalReceived(payer.overlay); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if _, err := driver.Open(consortium.DriverName, driver.Options{}); !errors.Is(err, consortium.ErrNoMembers) {
+		t.Fatalf("got error %v, want %v", err, consortium.ErrNoMembers)
+	}
+
+	d, err := driver.Open(consortium.DriverName, driver.Options{
+		Members: []common.Address{common.HexToAddress("0xab")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := d.(driver.ProtocolDriver); !ok {
+		t.Fatal("consortium is not a protocol driver")
+	}
+}
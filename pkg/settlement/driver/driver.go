@@ -0,0 +1,450 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package driver provides a registry of monetary settlement mechanisms.
+//
+// A settlement driver is registered by name, typically from the init function
+// of the package implementing it, and enabled by listing its name in the node
+// options. Connected peers exchange the names of the drivers they support and
+// payments to a peer are made with the first enabled driver the peer
+// supports, unless the operator selected another one for the peer.
+//
+// Swap settles with cheques on a chain, the consortium driver settles without
+// one between operators who trust each other.
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hashicorp/go-multierror"
+	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/p2p/protobuf"
+	"github.com/holisticode/bee/pkg/settlement"
+	"github.com/holisticode/bee/pkg/settlement/driver/pb"
+	"github.com/holisticode/bee/pkg/storage"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+const (
+	protocolName    = "settlementdriver"
+	protocolVersion = "1.0.0"
+	streamName      = "negotiate"
+
+	// prefix of the persistence keys of the drivers selected per peer
+	selectionPrefix = "settlement_driver_selection_"
+)
+
+var (
+	// negotiation timeout
+	negotiateTimeout = 10 * time.Second
+	// maximum number of driver names accepted from a peer
+	maxDrivers = 16
+)
+
+var (
+	// ErrUnknownDriver is returned if a driver is neither registered nor
+	// enabled.
+	ErrUnknownDriver = errors.New("unknown settlement driver")
+	// ErrDriverExists is returned if a driver of the same name is already
+	// enabled.
+	ErrDriverExists = errors.New("settlement driver already enabled")
+	// ErrNoDriver is returned if no enabled driver is supported by the peer.
+	ErrNoDriver = errors.New("no settlement driver for peer")
+	// ErrUnsupportedDriver is returned if a driver is selected for a peer
+	// which does not support it.
+	ErrUnsupportedDriver = errors.New("settlement driver not supported by peer")
+)
+
+// Driver is a monetary settlement mechanism.
+type Driver interface {
+	settlement.Interface
+	// Name identifies the driver in the negotiation with peers.
+	Name() string
+	// Pay settles the amount with the peer. The outcome has to be reported
+	// to Accounting.NotifyPaymentSent.
+	Pay(ctx context.Context, peer swarm.Address, amount *big.Int)
+}
+
+// ProtocolDriver is a Driver which exchanges messages with peers over its own
+// protocol.
+type ProtocolDriver interface {
+	Driver
+	Protocol() p2p.ProtocolSpec
+}
+
+// Options are the dependencies passed to a Factory.
+type Options struct {
+	Overlay    swarm.Address
+	Signer     crypto.Signer
+	Streamer   p2p.Streamer
+	Store      storage.StateStorer
+	Accounting settlement.Accounting
+	Logger     logging.Logger
+	// Members are the Ethereum addresses of the operators settling with each
+	// other out of band, for drivers settling without a chain.
+	Members []common.Address
+	// Backend holds the services of a driver settling on a chain, nil if the
+	// node runs without one. Its type is defined by the driver.
+	Backend interface{}
+}
+
+// Factory creates a driver. Drivers which hold resources also implement
+// io.Closer.
+type Factory func(o Options) (Driver, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register makes a driver factory available by name. It panics if a factory
+// of the same name is registered twice.
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if factory == nil {
+		panic("settlement driver: nil factory " + name)
+	}
+	if _, ok := factories[name]; ok {
+		panic("settlement driver: factory registered twice " + name)
+	}
+	factories[name] = factory
+}
+
+// Open creates a driver with the factory registered by name.
+func Open(name string, o Options) (Driver, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[name]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, name)
+	}
+	return factory(o)
+}
+
+// PeerDrivers is the state of the negotiation with a connected peer.
+type PeerDrivers struct {
+	Peer swarm.Address
+	// Supported are the drivers supported by the peer.
+	Supported []string
+	// Selected is the driver selected by the operator, if any.
+	Selected string
+	// Driver is the driver payments to the peer are made with, if any.
+	Driver string
+}
+
+// Service negotiates the settlement drivers with peers and dispatches
+// payments to the driver of the peer.
+type Service struct {
+	streamer   p2p.Streamer
+	store      storage.StateStorer
+	logger     logging.Logger
+	accounting settlement.Accounting
+	fallback   []string
+
+	mtx     sync.Mutex
+	drivers []Driver            // in order of preference
+	peers   map[string][]string // drivers supported by connected peers
+}
+
+// New creates a new Service. Peers which do not negotiate settlement drivers
+// are assumed to support the fallback drivers.
+func New(streamer p2p.Streamer, store storage.StateStorer, logger logging.Logger, fallback []string) *Service {
+	return &Service{
+		streamer: streamer,
+		store:    store,
+		logger:   logger,
+		fallback: fallback,
+		peers:    make(map[string][]string),
+	}
+}
+
+// SetAccounting sets the accounting failed payments are reported to.
+func (s *Service) SetAccounting(accounting settlement.Accounting) {
+	s.accounting = accounting
+}
+
+// Add enables the driver. Drivers are preferred in the order they are added.
+func (s *Service) Add(d Driver) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, e := range s.drivers {
+		if e.Name() == d.Name() {
+			return fmt.Errorf("%w: %s", ErrDriverExists, d.Name())
+		}
+	}
+	s.drivers = append(s.drivers, d)
+	return nil
+}
+
+// Names returns the names of the enabled drivers in order of preference.
+func (s *Service) Names() []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.names()
+}
+
+// names returns the names of the enabled drivers. The lock must be held when
+// called.
+func (s *Service) names() []string {
+	names := make([]string, 0, len(s.drivers))
+	for _, d := range s.drivers {
+		names = append(names, d.Name())
+	}
+	return names
+}
+
+func (s *Service) Protocol() p2p.ProtocolSpec {
+	return p2p.ProtocolSpec{
+		Name:    protocolName,
+		Version: protocolVersion,
+		StreamSpecs: []p2p.StreamSpec{
+			{
+				Name:    streamName,
+				Handler: s.handler,
+			},
+		},
+		ConnectIn:     s.initIn,
+		ConnectOut:    s.init,
+		DisconnectIn:  s.disconnect,
+		DisconnectOut: s.disconnect,
+	}
+}
+
+// init is called on outgoing connections and exchanges the supported drivers
+// with the peer. A failed negotiation does not fail the connection, the peer
+// is assumed to support the fallback drivers instead.
+func (s *Service) init(ctx context.Context, p p2p.Peer) error {
+	supported, err := s.negotiate(ctx, p.Address)
+	if err != nil {
+		s.logger.Debugf("settlement driver: negotiate with peer %v: %v", p.Address, err)
+		supported = s.fallback
+	}
+	s.setSupported(p.Address, supported)
+	return nil
+}
+
+// initIn is called on incoming connections. The peer negotiates the drivers,
+// until then or if it never does it is assumed to support the fallback
+// drivers.
+func (s *Service) initIn(_ context.Context, p p2p.Peer) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.peers[p.Address.ByteString()]; !ok {
+		s.peers[p.Address.ByteString()] = append([]string(nil), s.fallback...)
+	}
+	return nil
+}
+
+func (s *Service) negotiate(ctx context.Context, peer swarm.Address) (supported []string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, negotiateTimeout)
+	defer cancel()
+
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	w, r := protobuf.NewWriterAndReader(stream)
+	if err := w.WriteMsgWithContext(ctx, &pb.Drivers{Names: s.Names()}); err != nil {
+		return nil, fmt.Errorf("write drivers: %w", err)
+	}
+
+	var resp pb.Drivers
+	if err := r.ReadMsgWithContext(ctx, &resp); err != nil {
+		return nil, fmt.Errorf("read drivers: %w", err)
+	}
+	return resp.Names, nil
+}
+
+func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	w, r := protobuf.NewWriterAndReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	var req pb.Drivers
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read drivers from peer %v: %w", p.Address, err)
+	}
+
+	if err := w.WriteMsgWithContext(ctx, &pb.Drivers{Names: s.Names()}); err != nil {
+		return fmt.Errorf("write drivers to peer %v: %w", p.Address, err)
+	}
+
+	s.setSupported(p.Address, req.Names)
+	return nil
+}
+
+func (s *Service) setSupported(peer swarm.Address, supported []string) {
+	if len(supported) > maxDrivers {
+		supported = supported[:maxDrivers]
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.peers[peer.ByteString()] = append([]string(nil), supported...)
+}
+
+func (s *Service) disconnect(p p2p.Peer) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.peers, p.Address.ByteString())
+	return nil
+}
+
+func selectionKey(peer swarm.Address) string {
+	return selectionPrefix + peer.String()
+}
+
+// selected returns the driver selected for the peer by the operator or an
+// empty string if there is none.
+func (s *Service) selected(peer swarm.Address) (string, error) {
+	var name string
+	err := s.store.Get(selectionKey(peer), &name)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return "", err
+	}
+	return name, nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Driver returns the driver payments to the peer are made with. This is the
+// driver selected by the operator or otherwise the first enabled driver
+// supported by the peer.
+func (s *Service) Driver(peer swarm.Address) (Driver, error) {
+	selected, err := s.selected(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	supported, ok := s.peers[peer.ByteString()]
+	if !ok {
+		return nil, ErrNoDriver
+	}
+	for _, d := range s.drivers {
+		if selected != "" && d.Name() != selected {
+			continue
+		}
+		if contains(supported, d.Name()) {
+			return d, nil
+		}
+	}
+	if selected != "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, selected)
+	}
+	return nil, ErrNoDriver
+}
+
+// Select selects the driver payments to the peer are made with, overriding
+// the negotiated one. An empty name clears the selection.
+func (s *Service) Select(peer swarm.Address, name string) error {
+	if name == "" {
+		return s.store.Delete(selectionKey(peer))
+	}
+	s.mtx.Lock()
+	enabled := contains(s.names(), name)
+	s.mtx.Unlock()
+	if !enabled {
+		return fmt.Errorf("%w: %s", ErrUnknownDriver, name)
+	}
+	return s.store.Put(selectionKey(peer), name)
+}
+
+// Peers returns the state of the negotiation with all connected peers.
+func (s *Service) Peers() ([]PeerDrivers, error) {
+	s.mtx.Lock()
+	addresses := make([]swarm.Address, 0, len(s.peers))
+	for k := range s.peers {
+		addresses = append(addresses, swarm.NewAddress([]byte(k)))
+	}
+	s.mtx.Unlock()
+
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].String() < addresses[j].String()
+	})
+
+	peers := make([]PeerDrivers, 0, len(addresses))
+	for _, peer := range addresses {
+		selected, err := s.selected(peer)
+		if err != nil {
+			return nil, err
+		}
+		s.mtx.Lock()
+		supported, ok := s.peers[peer.ByteString()]
+		s.mtx.Unlock()
+		if !ok {
+			// disconnected in the meantime
+			continue
+		}
+		p := PeerDrivers{
+			Peer:      peer,
+			Supported: supported,
+			Selected:  selected,
+		}
+		if d, err := s.Driver(peer); err == nil {
+			p.Driver = d.Name()
+		}
+		peers = append(peers, p)
+	}
+	return peers, nil
+}
+
+// Pay makes the payment with the driver of the peer.
+func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount *big.Int) {
+	d, err := s.Driver(peer)
+	if err != nil {
+		s.logger.Debugf("settlement driver: pay peer %v: %v", peer, err)
+		s.accounting.NotifyPaymentSent(peer, amount, err)
+		return
+	}
+	d.Pay(ctx, peer, amount)
+}
+
+// Close closes the enabled drivers which hold resources.
+func (s *Service) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var mErr error
+	for _, d := range s.drivers {
+		if c, ok := d.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				mErr = multierror.Append(mErr, fmt.Errorf("%s: %w", d.Name(), err))
+			}
+		}
+	}
+	return mErr
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package driver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/p2p/streamtest"
+	"github.com/holisticode/bee/pkg/settlement/driver"
+	statestore "github.com/holisticode/bee/pkg/statestore/mock"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+type testDriver struct {
+	name string
+
+	mtx  sync.Mutex
+	paid []swarm.Address
+}
+
+func (d *testDriver) Name() string { return d.name }
+
+func (d *testDriver) Pay(ctx context.Context, peer swarm.Address, amount *big.Int) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.paid = append(d.paid, peer)
+}
+
+func (d *testDriver) TotalSent(peer swarm.Address) (*big.Int, error)     { return big.NewInt(0), nil }
+func (d *testDriver) TotalReceived(peer swarm.Address) (*big.Int, error) { return big.NewInt(0), nil }
+func (d *testDriver) SettlementsSent() (map[string]*big.Int, error)      { return nil, nil }
+func (d *testDriver) SettlementsReceived() (map[string]*big.Int, error)  { return nil, nil }
+
+type testAccounting struct {
+	sentErr error
+}
+
+func (a *testAccounting) PeerDebt(peer swarm.Address) (*big.Int, error) { return big.NewInt(0), nil }
+func (a *testAccounting) NotifyPaymentReceived(peer swarm.Address, amount *big.Int) error {
+	return nil
+}
+func (a *testAccounting) NotifyPaymentSent(peer swarm.Address, amount *big.Int, receivedError error) {
+	a.sentErr = receivedError
+}
+func (a *testAccounting) NotifyRefreshmentReceived(peer swarm.Address, amount *big.Int) error {
+	return nil
+}
+func (a *testAccounting) Connect(peer p2p.Peer)         {}
+func (a *testAccounting) Disconnect(peer swarm.Address) {}
+
+func newService(t *testing.T, streamer p2p.Streamer, drivers ...driver.Driver) *driver.Service {
+	t.Helper()
+
+	store := statestore.NewStateStore()
+	t.Cleanup(func() { _ = store.Close() })
+
+	s := driver.New(streamer, store, logging.New(io.Discard, 0), []string{"fallback"})
+	for _, d := range drivers {
+		if err := s.Add(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func waitDriver(t *testing.T, s *driver.Service, peer swarm.Address, want string) {
+	t.Helper()
+
+	var (
+		d   driver.Driver
+		err error
+	)
+	for i := 0; i < 100; i++ {
+		if d, err = s.Driver(peer); err == nil && d.Name() == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("driver for peer %v: %v", peer, err)
+	}
+	t.Fatalf("got driver %s for peer %v, want %s", d.Name(), peer, want)
+}
+
+func TestNegotiate(t *testing.T) {
+	var (
+		initiatorAddr = swarm.MustParseHexAddress("01")
+		responderAddr = swarm.MustParseHexAddress("02")
+		a             = &testDriver{name: "a"}
+		b             = &testDriver{name: "b"}
+		c             = &testDriver{name: "c"}
+	)
+
+	responder := newService(t, nil, a, b)
+	recorder := streamtest.New(
+		streamtest.WithProtocols(responder.Protocol()),
+		streamtest.WithBaseAddr(initiatorAddr),
+	)
+	initiator := newService(t, recorder, c, b)
+	accounting := &testAccounting{}
+	initiator.SetAccounting(accounting)
+
+	if err := initiator.Protocol().ConnectOut(context.Background(), p2p.Peer{Address: responderAddr}); err != nil {
+		t.Fatal(err)
+	}
+
+	// both sides pay with the only driver supported by the other side
+	waitDriver(t, initiator, responderAddr, "b")
+	waitDriver(t, responder, initiatorAddr, "b")
+
+	peers, err := initiator.Peers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []driver.PeerDrivers{{
+		Peer:      responderAddr,
+		Supported: []string{"a", "b"},
+		Driver:    "b",
+	}}
+	if !reflect.DeepEqual(peers, want) {
+		t.Fatalf("got peers %+v, want %+v", peers, want)
+	}
+
+	initiator.Pay(context.Background(), responderAddr, big.NewInt(10))
+	if len(b.paid) != 1 || !b.paid[0].Equal(responderAddr) {
+		t.Fatalf("got payments %v, want payment to %v", b.paid, responderAddr)
+	}
+
+	// a driver the peer does not support can be selected but not paid with
+	if err := initiator.Select(responderAddr, "c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := initiator.Driver(responderAddr); !errors.Is(err, driver.ErrUnsupportedDriver) {
+		t.Fatalf("got error %v, want %v", err, driver.ErrUnsupportedDriver)
+	}
+	initiator.Pay(context.Background(), responderAddr, big.NewInt(10))
+	if !errors.Is(accounting.sentErr, driver.ErrUnsupportedDriver) {
+		t.Fatalf("got payment error %v, want %v", accounting.sentErr, driver.ErrUnsupportedDriver)
+	}
+
+	// clearing the selection returns to the negotiated driver
+	if err := initiator.Select(responderAddr, ""); err != nil {
+		t.Fatal(err)
+	}
+	waitDriver(t, initiator, responderAddr, "b")
+
+	if err := initiator.Select(responderAddr, "unknown"); !errors.Is(err, driver.ErrUnknownDriver) {
+		t.Fatalf("got error %v, want %v", err, driver.ErrUnknownDriver)
+	}
+
+	// disconnected peers have no driver
+	if err := initiator.Protocol().DisconnectOut(p2p.Peer{Address: responderAddr}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := initiator.Driver(responderAddr); !errors.Is(err, driver.ErrNoDriver) {
+		t.Fatalf("got error %v, want %v", err, driver.ErrNoDriver)
+	}
+}
+
+func TestNegotiateFallback(t *testing.T) {
+	peer := swarm.MustParseHexAddress("02")
+
+	recorder := streamtest.New(
+		streamtest.WithStreamError(func(swarm.Address, string, string, string) error {
+			return errors.New("protocol not supported")
+		}),
+	)
+	s := newService(t, recorder, &testDriver{name: "a"}, &testDriver{name: "fallback"})
+
+	if err := s.Protocol().ConnectOut(context.Background(), p2p.Peer{Address: peer}); err != nil {
+		t.Fatal(err)
+	}
+	waitDriver(t, s, peer, "fallback")
+
+	// peers connecting to us are assumed to support the fallback drivers
+	// until they negotiate
+	inbound := swarm.MustParseHexAddress("03")
+	if err := s.Protocol().ConnectIn(context.Background(), p2p.Peer{Address: inbound}); err != nil {
+		t.Fatal(err)
+	}
+	waitDriver(t, s, inbound, "fallback")
+}
+
+func TestRegister(t *testing.T) {
+	d := &testDriver{name: "registered"}
+	driver.Register("registered", func(o driver.Options) (driver.Driver, error) {
+		return d, nil
+	})
+
+	got, err := driver.Open("registered", driver.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != d {
+		t.Fatalf("got driver %v, want %v", got, d)
+	}
+
+	if _, err := driver.Open("unregistered", driver.Options{}); !errors.Is(err, driver.ErrUnknownDriver) {
+		t.Fatalf("got error %v, want %v", err, driver.ErrUnknownDriver)
+	}
+
+	s := newService(t, nil, d)
+	if err := s.Add(&testDriver{name: "registered"}); !errors.Is(err, driver.ErrDriverExists) {
+		t.Fatalf("got error %v, want %v", err, driver.ErrDriverExists)
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package history records dated settlement events and aggregates them into
+// income and expense reports.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/storage"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+const (
+	// prefix of the persistence keys of the settlement events, followed by
+	// the UTC day and the timestamp of the event
+	eventPrefix = "settlement_history_event_"
+	// key of the oldest UTC day events are retained for
+	oldestDayKey = "settlement_history_oldest_day"
+	// layout of the UTC days in the event keys
+	dayLayout = "20060102"
+)
+
+// pruneInterval is the minimum interval between the removals of the events
+// older than the retention period.
+var pruneInterval = time.Hour
+
+// ErrInvalidGroupBy is returned if a report is requested with an unknown
+// grouping.
+var ErrInvalidGroupBy = errors.New("invalid group by")
+
+// EventType is the kind of a settlement event.
+type EventType string
+
+const (
+	// RefreshmentSent is a time based settlement sent to a peer.
+	RefreshmentSent EventType = "refreshment-sent"
+	// RefreshmentReceived is a time based settlement received from a peer.
+	RefreshmentReceived EventType = "refreshment-received"
+	// ChequeSent is a cheque issued to a peer.
+	ChequeSent EventType = "cheque-sent"
+	// ChequeReceived is a cheque received from a peer.
+	ChequeReceived EventType = "cheque-received"
+	// Cashout is a transaction cashing the cheques of a chequebook.
+	Cashout EventType = "cashout"
+)
+
+// Event is a single settlement event.
+//
+// The amount of refreshments is in accounting units while the amount of
+// cheques and cashouts is in BZZ. The amount of the first cheque of a
+// chequebook to a peer exceeds the value of the settled units at the
+// exchange rate by the deduction.
+type Event struct {
+	Timestamp  time.Time      `json:"timestamp"`
+	Type       EventType      `json:"type"`
+	Peer       swarm.Address  `json:"peer"`
+	Chequebook common.Address `json:"chequebook"` // only set for cheques and cashouts
+	Amount     *big.Int       `json:"amount"`
+	Units      *big.Int       `json:"units,omitempty"` // accounting units settled by cheques
+	TxHash     common.Hash    `json:"transactionHash"` // only set for cashouts
+}
+
+// Valuer values an event both in accounting units and in BZZ. It returns
+// false if the event can not be valued.
+type Valuer func(e Event) (units, bzz *big.Int, ok bool)
+
+// GroupBy is the grouping of the events in a report.
+type GroupBy string
+
+const (
+	// GroupByDay groups the events by the UTC day they happened on.
+	GroupByDay GroupBy = "day"
+	// GroupByPeer groups the events by peer. Cashouts for which the peer is
+	// not known are grouped by chequebook instead.
+	GroupByPeer GroupBy = "peer"
+)
+
+// ReportEntry is the sum of the settlement events in a group. The amounts
+// are also summed in the other denomination for the events that could be
+// valued.
+type ReportEntry struct {
+	Group                   string
+	RefreshmentsSent        *big.Int
+	RefreshmentsReceived    *big.Int
+	ChequesSent             *big.Int
+	ChequesReceived         *big.Int
+	Cashed                  *big.Int
+	RefreshmentsSentBZZ     *big.Int
+	RefreshmentsReceivedBZZ *big.Int
+	ChequesSentUnits        *big.Int
+	ChequesReceivedUnits    *big.Int
+	CashedUnits             *big.Int
+	Events                  int
+	Unvalued                int // events that could not be valued
+}
+
+func newReportEntry(group string) *ReportEntry {
+	return &ReportEntry{
+		Group:                   group,
+		RefreshmentsSent:        big.NewInt(0),
+		RefreshmentsReceived:    big.NewInt(0),
+		ChequesSent:             big.NewInt(0),
+		ChequesReceived:         big.NewInt(0),
+		Cashed:                  big.NewInt(0),
+		RefreshmentsSentBZZ:     big.NewInt(0),
+		RefreshmentsReceivedBZZ: big.NewInt(0),
+		ChequesSentUnits:        big.NewInt(0),
+		ChequesReceivedUnits:    big.NewInt(0),
+		CashedUnits:             big.NewInt(0),
+	}
+}
+
+func (r *ReportEntry) add(e Event, value Valuer) {
+	units, bzz, ok := big.NewInt(0), big.NewInt(0), false
+	if value != nil {
+		if u, b, valued := value(e); valued {
+			units, bzz, ok = u, b, true
+		}
+	}
+	if !ok {
+		r.Unvalued++
+	}
+	switch e.Type {
+	case RefreshmentSent:
+		r.RefreshmentsSent.Add(r.RefreshmentsSent, e.Amount)
+		r.RefreshmentsSentBZZ.Add(r.RefreshmentsSentBZZ, bzz)
+	case RefreshmentReceived:
+		r.RefreshmentsReceived.Add(r.RefreshmentsReceived, e.Amount)
+		r.RefreshmentsReceivedBZZ.Add(r.RefreshmentsReceivedBZZ, bzz)
+	case ChequeSent:
+		r.ChequesSent.Add(r.ChequesSent, e.Amount)
+		r.ChequesSentUnits.Add(r.ChequesSentUnits, units)
+	case ChequeReceived:
+		r.ChequesReceived.Add(r.ChequesReceived, e.Amount)
+		r.ChequesReceivedUnits.Add(r.ChequesReceivedUnits, units)
+	case Cashout:
+		r.Cashed.Add(r.Cashed, e.Amount)
+		r.CashedUnits.Add(r.CashedUnits, units)
+	}
+	r.Events++
+}
+
+// History records settlement events in the state store. The events are
+// retained for the retention period, or forever if it is zero.
+type History struct {
+	store     storage.StateStorer
+	retention time.Duration
+	timeNow   func() time.Time
+
+	mtx       sync.Mutex
+	last      int64     // timestamp of the last event key in nanoseconds
+	lastPrune time.Time // time of the last removal of expired events
+}
+
+// New creates a new History that retains the events for the retention
+// period.
+func New(store storage.StateStorer, retention time.Duration) *History {
+	return &History{
+		store:     store,
+		retention: retention,
+		timeNow:   time.Now,
+	}
+}
+
+// day truncates the time to the UTC day.
+func day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// dayPrefix returns the common prefix of the storage keys of the events
+// recorded on the UTC day of the time.
+func dayPrefix(t time.Time) string {
+	return eventPrefix + t.UTC().Format(dayLayout) + "_"
+}
+
+// eventKey returns the storage key of an event recorded at the given time.
+// Timestamps are zero padded to keep the keys ordered.
+func eventKey(t time.Time, nanos int64) string {
+	return fmt.Sprintf("%s%020d", dayPrefix(t), nanos)
+}
+
+// oldestDay returns the oldest UTC day with retained events. It is false if
+// no events were recorded yet.
+func (h *History) oldestDay() (time.Time, bool, error) {
+	var oldest time.Time
+	err := h.store.Get(oldestDayKey, &oldest)
+	if errors.Is(err, storage.ErrNotFound) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return oldest, true, nil
+}
+
+// Record records a settlement event at the current time.
+func (h *History) Record(typ EventType, peer swarm.Address, chequebook common.Address, amount *big.Int, txHash common.Hash) error {
+	return h.record(Event{
+		Type:       typ,
+		Peer:       peer,
+		Chequebook: chequebook,
+		Amount:     new(big.Int).Set(amount),
+		TxHash:     txHash,
+	})
+}
+
+// RecordCheque records a cheque sent or received at the current time
+// together with the accounting units it settled.
+func (h *History) RecordCheque(typ EventType, peer swarm.Address, chequebook common.Address, amount, units *big.Int) error {
+	return h.record(Event{
+		Type:       typ,
+		Peer:       peer,
+		Chequebook: chequebook,
+		Amount:     new(big.Int).Set(amount),
+		Units:      new(big.Int).Set(units),
+	})
+}
+
+func (h *History) record(e Event) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	now := h.timeNow().UTC()
+	// keys have to be unique, events recorded within the same nanosecond
+	// are spread over the following nanoseconds
+	nanos := now.UnixNano()
+	if nanos <= h.last {
+		nanos = h.last + 1
+	}
+
+	if _, ok, err := h.oldestDay(); err != nil {
+		return err
+	} else if !ok {
+		if err := h.store.Put(oldestDayKey, day(now)); err != nil {
+			return err
+		}
+	}
+
+	e.Timestamp = now
+	if err := h.store.Put(eventKey(now, nanos), e); err != nil {
+		return err
+	}
+	h.last = nanos
+
+	if h.retention > 0 && now.Sub(h.lastPrune) >= pruneInterval {
+		if err := h.prune(now); err != nil {
+			return fmt.Errorf("prune: %w", err)
+		}
+		h.lastPrune = now
+	}
+	return nil
+}
+
+// prune removes the events of the UTC days that passed the retention period
+// by the time now. It must be called with the lock held.
+func (h *History) prune(now time.Time) error {
+	oldest, ok, err := h.oldestDay()
+	if err != nil || !ok {
+		return err
+	}
+	expiry := day(now.Add(-h.retention))
+	for d := oldest; d.Before(expiry); d = d.AddDate(0, 0, 1) {
+		var keys []string
+		err := h.store.Iterate(dayPrefix(d), func(key, _ []byte) (bool, error) {
+			keys = append(keys, string(key))
+			return false, nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := h.store.Delete(key); err != nil {
+				return err
+			}
+		}
+		if err := h.store.Put(oldestDayKey, d.AddDate(0, 0, 1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Events returns the events recorded in the interval [from, to) from oldest
+// to newest. Only the days of the interval with retained events are read.
+func (h *History) Events(from, to time.Time) ([]Event, error) {
+	h.mtx.Lock()
+	oldest, ok, err := h.oldestDay()
+	now := h.timeNow()
+	h.mtx.Unlock()
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	first := day(from)
+	if first.Before(oldest) {
+		first = oldest
+	}
+	// no events are recorded after now
+	if last := now.Add(time.Nanosecond); to.After(last) {
+		to = last
+	}
+
+	var events []Event
+	for d := first; d.Before(to); d = d.AddDate(0, 0, 1) {
+		// the keys order the events of a day even if they were recorded
+		// within the same nanosecond
+		var keys []string
+		dayEvents := make(map[string]Event)
+		err := h.store.Iterate(dayPrefix(d), func(key, val []byte) (bool, error) {
+			var e Event
+			if err := json.Unmarshal(val, &e); err != nil {
+				return true, fmt.Errorf("unmarshal event %q: %w", string(key), err)
+			}
+			if !e.Timestamp.Before(from) && e.Timestamp.Before(to) {
+				keys = append(keys, string(key))
+				dayEvents[string(key)] = e
+			}
+			return false, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			events = append(events, dayEvents[key])
+		}
+	}
+	return events, nil
+}
+
+// Report sums the events recorded in the interval [from, to) per group,
+// valued with the valuer if it is not nil. The entries are ordered by group.
+func (h *History) Report(from, to time.Time, groupBy GroupBy, value Valuer) ([]ReportEntry, error) {
+	var group func(Event) string
+	switch groupBy {
+	case GroupByDay:
+		group = func(e Event) string {
+			return e.Timestamp.UTC().Format("2006-01-02")
+		}
+	case GroupByPeer:
+		group = func(e Event) string {
+			if e.Peer.IsZero() {
+				return e.Chequebook.String()
+			}
+			return e.Peer.String()
+		}
+	default:
+		return nil, ErrInvalidGroupBy
+	}
+
+	events, err := h.Events(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]*ReportEntry)
+	for _, e := range events {
+		g := group(e)
+		entry, ok := entries[g]
+		if !ok {
+			entry = newReportEntry(g)
+			entries[g] = entry
+		}
+		entry.add(e, value)
+	}
+
+	report := make([]ReportEntry, 0, len(entries))
+	for _, entry := range entries {
+		report = append(report, *entry)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Group < report[j].Group
+	})
+	return report, nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package history_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/settlement/history"
+	"github.com/holisticode/bee/pkg/statestore/mock"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+func TestHistory(t *testing.T) {
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	h := history.New(store, 0)
+
+	var (
+		day1       = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+		day2       = day1.Add(24 * time.Hour)
+		peer1      = swarm.MustParseHexAddress("01")
+		peer2      = swarm.MustParseHexAddress("02")
+		chequebook = common.HexToAddress("aa")
+		now        = day1
+	)
+	h.SetTimeNow(func() time.Time { return now })
+
+	record := func(typ history.EventType, peer swarm.Address, amount int64) {
+		t.Helper()
+		if err := h.Record(typ, peer, common.Address{}, big.NewInt(amount), common.Hash{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	record(history.RefreshmentReceived, peer1, 10)
+	record(history.RefreshmentSent, peer2, 20)
+	record(history.ChequeReceived, peer1, 100)
+	now = day2
+	record(history.ChequeSent, peer2, 50)
+	record(history.RefreshmentReceived, peer1, 5)
+	// a cashout of which the peer is not known
+	if err := h.Record(history.Cashout, swarm.ZeroAddress, chequebook, big.NewInt(100), common.HexToHash("ff")); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := h.Events(day1, day2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want %d", len(events), 3)
+	}
+	if events[2].Type != history.ChequeReceived || !events[2].Peer.Equal(peer1) || events[2].Amount.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("got event %+v", events[2])
+	}
+
+	report, err := h.Report(day1, day2.Add(time.Hour), history.GroupByDay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 2 {
+		t.Fatalf("got %d report entries, want %d", len(report), 2)
+	}
+	if report[0].Group != "2021-06-01" || report[0].Events != 3 ||
+		report[0].RefreshmentsReceived.Cmp(big.NewInt(10)) != 0 ||
+		report[0].RefreshmentsSent.Cmp(big.NewInt(20)) != 0 ||
+		report[0].ChequesReceived.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("got report entry %+v", report[0])
+	}
+	if report[1].Group != "2021-06-02" || report[1].Events != 3 ||
+		report[1].ChequesSent.Cmp(big.NewInt(50)) != 0 ||
+		report[1].Cashed.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("got report entry %+v", report[1])
+	}
+
+	report, err = h.Report(day1, day2.Add(time.Hour), history.GroupByPeer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{peer1.String(), peer2.String(), chequebook.String()}
+	if len(report) != len(want) {
+		t.Fatalf("got %d report entries, want %d", len(report), len(want))
+	}
+	for i, group := range want {
+		if report[i].Group != group {
+			t.Fatalf("got group %s at %d, want %s", report[i].Group, i, group)
+		}
+	}
+	if report[0].RefreshmentsReceived.Cmp(big.NewInt(15)) != 0 {
+		t.Fatalf("got refreshments received %d, want %d", report[0].RefreshmentsReceived, 15)
+	}
+
+	_, err = h.Report(day1, day2, "week", nil)
+	if !errors.Is(err, history.ErrInvalidGroupBy) {
+		t.Fatalf("got error %v, want %v", err, history.ErrInvalidGroupBy)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	h := history.New(store, 48*time.Hour)
+
+	var (
+		day1 = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+		peer = swarm.MustParseHexAddress("01")
+		now  = day1
+	)
+	h.SetTimeNow(func() time.Time { return now })
+
+	for i := 0; i < 4; i++ {
+		now = day1.AddDate(0, 0, i)
+		if err := h.Record(history.RefreshmentReceived, peer, common.Address{}, big.NewInt(int64(i)), common.Hash{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events, err := h.Events(time.Time{}, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the events of the first day passed the retention period
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want %d", len(events), 3)
+	}
+	if events[0].Amount.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("got oldest event %+v", events[0])
+	}
+
+	var stored int
+	if err := store.Iterate("settlement_history_event_", func(_, _ []byte) (bool, error) {
+		stored++
+		return false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if stored != 3 {
+		t.Fatalf("got %d stored events, want %d", stored, 3)
+	}
+}
+
+func TestReportValuer(t *testing.T) {
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	h := history.New(store, 0)
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	h.SetTimeNow(func() time.Time { return now })
+
+	peer := swarm.MustParseHexAddress("01")
+	if err := h.Record(history.RefreshmentReceived, peer, common.Address{}, big.NewInt(10), common.Hash{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.RecordCheque(history.ChequeReceived, peer, common.HexToAddress("aa"), big.NewInt(120), big.NewInt(20)); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Record(history.Cashout, peer, common.HexToAddress("aa"), big.NewInt(120), common.HexToHash("ff")); err != nil {
+		t.Fatal(err)
+	}
+
+	// values refreshments at 5 BZZ per unit and cheques by their settled
+	// units, cashouts can not be valued
+	value := func(e history.Event) (*big.Int, *big.Int, bool) {
+		switch {
+		case e.Type == history.RefreshmentReceived:
+			return e.Amount, new(big.Int).Mul(e.Amount, big.NewInt(5)), true
+		case e.Units != nil:
+			return e.Units, e.Amount, true
+		}
+		return nil, nil, false
+	}
+
+	report, err := h.Report(now, now.Add(time.Hour), history.GroupByDay, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 1 {
+		t.Fatalf("got %d report entries, want %d", len(report), 1)
+	}
+	r := report[0]
+	if r.RefreshmentsReceivedBZZ.Cmp(big.NewInt(50)) != 0 ||
+		r.ChequesReceivedUnits.Cmp(big.NewInt(20)) != 0 ||
+		r.CashedUnits.Sign() != 0 ||
+		r.Events != 3 || r.Unvalued != 1 {
+		t.Fatalf("got report entry %+v", r)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"math/big"
 
+	"github.com/holisticode/bee/pkg/p2p"
 	"github.com/holisticode/bee/pkg/swarm"
 )
 
@@ -32,6 +33,6 @@ type Accounting interface {
 	NotifyPaymentReceived(peer swarm.Address, amount *big.Int) error
 	NotifyPaymentSent(peer swarm.Address, amount *big.Int, receivedError error)
 	NotifyRefreshmentReceived(peer swarm.Address, amount *big.Int) error
-	Connect(peer swarm.Address)
+	Connect(peer p2p.Peer)
 	Disconnect(peer swarm.Address)
 }
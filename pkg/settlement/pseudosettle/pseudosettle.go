@@ -13,10 +13,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/p2p"
 	"github.com/holisticode/bee/pkg/p2p/protobuf"
 	"github.com/holisticode/bee/pkg/settlement"
+	"github.com/holisticode/bee/pkg/settlement/history"
 	pb "github.com/holisticode/bee/pkg/settlement/pseudosettle/pb"
 	"github.com/holisticode/bee/pkg/storage"
 	"github.com/holisticode/bee/pkg/swarm"
@@ -43,6 +45,7 @@ type Service struct {
 	logger           logging.Logger
 	store            storage.StateStorer
 	accounting       settlement.Accounting
+	history          *history.History
 	metrics          metrics
 	refreshRate      *big.Int
 	lightRefreshRate *big.Int
@@ -105,7 +108,7 @@ func (s *Service) init(ctx context.Context, p p2p.Peer) error {
 		s.peers[p.Address.String()] = peerData
 	}
 
-	go s.accounting.Connect(p.Address)
+	go s.accounting.Connect(p)
 	return nil
 }
 
@@ -245,6 +248,7 @@ func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (e
 	receivedPaymentF64, _ := big.NewFloat(0).SetInt(paymentAmount).Float64()
 	s.metrics.TotalReceivedPseudoSettlements.Add(receivedPaymentF64)
 	s.metrics.ReceivedPseudoSettlements.Inc()
+	s.recordHistory(history.RefreshmentReceived, p.Address, paymentAmount)
 	return s.accounting.NotifyRefreshmentReceived(p.Address, paymentAmount)
 }
 
@@ -363,6 +367,7 @@ func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount, checkAllo
 	amountFloat, _ := new(big.Float).SetInt(acceptedAmount).Float64()
 	s.metrics.TotalSentPseudoSettlements.Add(amountFloat)
 	s.metrics.SentPseudoSettlements.Inc()
+	s.recordHistory(history.RefreshmentSent, peer, acceptedAmount)
 
 	return acceptedAmount, lastTime.CheckTimestamp, nil
 }
@@ -371,6 +376,22 @@ func (s *Service) SetAccounting(accounting settlement.Accounting) {
 	s.accounting = accounting
 }
 
+// SetHistory sets the history refreshments are recorded in.
+func (s *Service) SetHistory(history *history.History) {
+	s.history = history
+}
+
+// recordHistory records a refreshment in the history, if any. Failures are
+// only logged as the refreshment itself has already been persisted.
+func (s *Service) recordHistory(typ history.EventType, peer swarm.Address, amount *big.Int) {
+	if s.history == nil || amount.Sign() <= 0 {
+		return
+	}
+	if err := s.history.Record(typ, peer, common.Address{}, amount, common.Hash{}); err != nil {
+		s.logger.Errorf("pseudosettle: record %s for peer %v: %v", typ, peer, err)
+	}
+}
+
 // TotalSent returns the total amount sent to a peer
 func (s *Service) TotalSent(peer swarm.Address) (totalSent *big.Int, err error) {
 	var lastTime lastPayment
@@ -0,0 +1,144 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/storage"
+)
+
+// ChequeAudit is a cheque found in the state store together with the result
+// of verifying it.
+type ChequeAudit struct {
+	*SignedCheque
+	// Issuer is the address recovered from the signature of the cheque.
+	Issuer common.Address
+	// Err is set if the cheque is not valid.
+	Err error
+}
+
+// Audit is the result of auditing the cheques persisted in a state store.
+type Audit struct {
+	// Chequebook is the chequebook of the node, it is zero if the node has
+	// not deployed one.
+	Chequebook common.Address
+	// Sent are the last cheques issued to every beneficiary.
+	Sent []ChequeAudit
+	// Received are the last cheques received from every chequebook.
+	Received []ChequeAudit
+	// TotalIssued is the amount issued as recorded by the chequebook.
+	TotalIssued *big.Int
+	// SentCumulativePayout is the sum of the cumulative payouts of the sent
+	// cheques. It equals TotalIssued unless the state store is inconsistent.
+	SentCumulativePayout *big.Int
+	// ReceivedCumulativePayout is the sum of the cumulative payouts of the
+	// received cheques.
+	ReceivedCumulativePayout *big.Int
+}
+
+// Valid reports whether all cheques are valid and the cumulative payouts of
+// the sent cheques add up to the amount issued.
+func (a *Audit) Valid() bool {
+	for _, c := range append(append([]ChequeAudit{}, a.Sent...), a.Received...) {
+		if c.Err != nil {
+			return false
+		}
+	}
+	return a.TotalIssued.Cmp(a.SentCumulativePayout) == 0
+}
+
+// AuditCheques verifies the signatures and addresses of the last sent and
+// received cheques persisted in the store and recomputes their cumulative
+// payouts. It does not need access to the blockchain, so whether the
+// chequebooks can actually cover the cheques is not checked.
+func AuditCheques(store storage.StateStorer, chainID int64) (*Audit, error) {
+	audit := &Audit{
+		SentCumulativePayout:     big.NewInt(0),
+		ReceivedCumulativePayout: big.NewInt(0),
+	}
+
+	err := store.Get(chequebookKey, &audit.Chequebook)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("load chequebook: %w", err)
+	}
+
+	audit.TotalIssued, err = (&service{store: store}).totalIssued()
+	if err != nil {
+		return nil, fmt.Errorf("load total issued: %w", err)
+	}
+
+	sent, err := (&service{store: store}).LastCheques()
+	if err != nil {
+		return nil, fmt.Errorf("load sent cheques: %w", err)
+	}
+	for _, beneficiary := range sortedAddresses(sent) {
+		cheque := sent[beneficiary]
+		c := auditCheque(cheque, chainID)
+		switch {
+		case c.Err != nil:
+		case cheque.Beneficiary != beneficiary:
+			c.Err = fmt.Errorf("%w: stored for beneficiary %x", ErrWrongBeneficiary, beneficiary)
+		case cheque.Chequebook != audit.Chequebook:
+			c.Err = fmt.Errorf("%w: issued by chequebook %x instead of %x", ErrChequeInvalid, cheque.Chequebook, audit.Chequebook)
+		}
+		if cheque.CumulativePayout != nil {
+			audit.SentCumulativePayout.Add(audit.SentCumulativePayout, cheque.CumulativePayout)
+		}
+		audit.Sent = append(audit.Sent, c)
+	}
+
+	received, err := (&chequeStore{store: store}).LastCheques()
+	if err != nil {
+		return nil, fmt.Errorf("load received cheques: %w", err)
+	}
+	for _, chequebook := range sortedAddresses(received) {
+		cheque := received[chequebook]
+		c := auditCheque(cheque, chainID)
+		if c.Err == nil && cheque.Chequebook != chequebook {
+			c.Err = fmt.Errorf("%w: stored for chequebook %x", ErrChequeInvalid, chequebook)
+		}
+		if cheque.CumulativePayout != nil {
+			audit.ReceivedCumulativePayout.Add(audit.ReceivedCumulativePayout, cheque.CumulativePayout)
+		}
+		audit.Received = append(audit.Received, c)
+	}
+
+	return audit, nil
+}
+
+// sortedAddresses returns the addresses the cheques are stored for in
+// ascending order.
+func sortedAddresses(cheques map[common.Address]*SignedCheque) []common.Address {
+	addresses := make([]common.Address, 0, len(cheques))
+	for address := range cheques {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i].Bytes(), addresses[j].Bytes()) < 0
+	})
+	return addresses
+}
+
+// auditCheque recovers the issuer of the cheque and checks its payout.
+func auditCheque(cheque *SignedCheque, chainID int64) ChequeAudit {
+	c := ChequeAudit{SignedCheque: cheque}
+	if cheque.CumulativePayout == nil || cheque.CumulativePayout.Sign() <= 0 {
+		c.Err = fmt.Errorf("%w: cumulative payout not positive", ErrChequeInvalid)
+		return c
+	}
+	issuer, err := RecoverCheque(cheque, chainID)
+	if err != nil {
+		c.Err = fmt.Errorf("%w: recover issuer: %v", ErrChequeInvalid, err)
+		return c
+	}
+	c.Issuer = issuer
+	return c
+}
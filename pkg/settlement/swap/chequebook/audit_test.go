@@ -0,0 +1,123 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
+	storemock "github.com/holisticode/bee/pkg/statestore/mock"
+)
+
+func TestAuditCheques(t *testing.T) {
+	chainID := int64(1)
+	store := storemock.NewStateStore()
+	t.Cleanup(func() { _ = store.Close() })
+
+	newSigner := func() (chequebook.ChequeSigner, common.Address) {
+		t.Helper()
+		privKey, err := crypto.GenerateSecp256k1Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ethAddress, err := crypto.NewEthereumAddress(privKey.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chequebook.NewChequeSigner(crypto.NewDefaultSigner(privKey), chainID), common.BytesToAddress(ethAddress)
+	}
+	sign := func(signer chequebook.ChequeSigner, cheque chequebook.Cheque) *chequebook.SignedCheque {
+		t.Helper()
+		signature, err := signer.Sign(&cheque)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &chequebook.SignedCheque{Cheque: cheque, Signature: signature}
+	}
+
+	ownSigner, owner := newSigner()
+	peerSigner, peerOwner := newSigner()
+	ownChequebook := common.HexToAddress("0xaa")
+	peerChequebook := common.HexToAddress("0xbb")
+	otherChequebook := common.HexToAddress("0xcc")
+	beneficiary1 := common.HexToAddress("0x01")
+	beneficiary2 := common.HexToAddress("0x02")
+
+	put := func(key string, v interface{}) {
+		t.Helper()
+		if err := store.Put(key, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	put(chequebook.ChequebookKey, ownChequebook)
+	put(chequebook.TotalIssuedKey, big.NewInt(300))
+	put(chequebook.LastIssuedChequeKey(beneficiary1), sign(ownSigner, chequebook.Cheque{
+		Chequebook:       ownChequebook,
+		Beneficiary:      beneficiary1,
+		CumulativePayout: big.NewInt(100),
+	}))
+	put(chequebook.LastIssuedChequeKey(beneficiary2), sign(ownSigner, chequebook.Cheque{
+		Chequebook:       ownChequebook,
+		Beneficiary:      beneficiary2,
+		CumulativePayout: big.NewInt(200),
+	}))
+	put(chequebook.LastReceivedChequeKey(peerChequebook), sign(peerSigner, chequebook.Cheque{
+		Chequebook:       peerChequebook,
+		Beneficiary:      owner,
+		CumulativePayout: big.NewInt(50),
+	}))
+
+	audit, err := chequebook.AuditCheques(store, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !audit.Valid() {
+		t.Fatalf("audit not valid: %+v", audit)
+	}
+	if audit.Chequebook != ownChequebook {
+		t.Fatalf("got chequebook %x, want %x", audit.Chequebook, ownChequebook)
+	}
+	if len(audit.Sent) != 2 || audit.Sent[0].Beneficiary != beneficiary1 || audit.Sent[1].Beneficiary != beneficiary2 {
+		t.Fatalf("got sent cheques %+v", audit.Sent)
+	}
+	for _, c := range audit.Sent {
+		if c.Issuer != owner {
+			t.Fatalf("got issuer %x, want %x", c.Issuer, owner)
+		}
+	}
+	if audit.SentCumulativePayout.Cmp(big.NewInt(300)) != 0 {
+		t.Fatalf("got sent cumulative payout %d, want 300", audit.SentCumulativePayout)
+	}
+	if len(audit.Received) != 1 || audit.Received[0].Issuer != peerOwner {
+		t.Fatalf("got received cheques %+v", audit.Received)
+	}
+	if audit.ReceivedCumulativePayout.Cmp(big.NewInt(50)) != 0 {
+		t.Fatalf("got received cumulative payout %d, want 50", audit.ReceivedCumulativePayout)
+	}
+
+	// a cheque stored for a different chequebook than it is for
+	put(chequebook.LastReceivedChequeKey(otherChequebook), sign(peerSigner, chequebook.Cheque{
+		Chequebook:       peerChequebook,
+		Beneficiary:      owner,
+		CumulativePayout: big.NewInt(10),
+	}))
+	// the total issued does not add up anymore
+	put(chequebook.TotalIssuedKey, big.NewInt(400))
+
+	audit, err = chequebook.AuditCheques(store, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audit.Valid() {
+		t.Fatal("audit valid")
+	}
+	if len(audit.Received) != 2 || !errors.Is(audit.Received[1].Err, chequebook.ErrChequeInvalid) {
+		t.Fatalf("got received cheques %+v", audit.Received)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/sctx"
+	"github.com/holisticode/bee/pkg/settlement/history"
+	"github.com/holisticode/bee/pkg/storage"
+	"github.com/holisticode/bee/pkg/swarm"
+	"github.com/holisticode/bee/pkg/transaction"
+)
+
+const (
+	// prefix for the persistence key of the auto cashout decisions
+	autoCashoutDecisionPrefix = "swap_autocashout_decision_"
+)
+
+var (
+	// maximum number of cashout transactions sent in one round
+	maxCashoutsPerRound = 10
+	// timeout of a single cashout round
+	autoCashoutRoundTimeout = 5 * time.Minute
+)
+
+// AutoCashoutDecision records a cashout sent by the AutoCashout.
+type AutoCashoutDecision struct {
+	Chequebook common.Address `json:"chequebook"`
+	Timestamp  int64          `json:"timestamp"`
+	Uncashed   *big.Int       `json:"uncashed"`   // uncashed amount at the time of the decision
+	GasPrice   *big.Int       `json:"gasPrice"`   // gas price the cashout was sent with
+	GasCost    *big.Int       `json:"gasCost"`    // estimated cost of the cashout transaction in wei
+	GasCostBZZ *big.Int       `json:"gasCostBzz"` // estimated cost of the cashout transaction in BZZ base units
+	TxHash     common.Hash    `json:"transactionHash"`
+}
+
+// NativeRater values the native currency of the chain in BZZ.
+type NativeRater interface {
+	// NativeRate returns how many BZZ base units one wei is worth.
+	NativeRate() (*big.Float, error)
+}
+
+// AutoCashout periodically cashes the cheques of all chequebooks whose
+// uncashed amount exceeds a multiple of the estimated gas cost of the cashout
+// transaction.
+//
+// The gas cost is denominated in the native currency of the chain and is
+// valued in BZZ at the native rate of the price oracle before it is compared
+// with the uncashed amount.
+type AutoCashout struct {
+	store       storage.StateStorer
+	backend     transaction.Backend
+	cashout     CashoutService
+	chequeStore ChequeStore
+	recipient   common.Address
+	history     *history.History
+	rates       NativeRater
+	gasMultiple *big.Float
+	logger      logging.Logger
+	timeNow     func() time.Time
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewAutoCashout creates a new AutoCashout which checks for cheques worth
+// cashing every interval and cashes them out to the recipient. Cashouts are
+// recorded in the history if it is not nil.
+func NewAutoCashout(
+	store storage.StateStorer,
+	backend transaction.Backend,
+	cashout CashoutService,
+	chequeStore ChequeStore,
+	recipient common.Address,
+	history *history.History,
+	rates NativeRater,
+	gasMultiple float64,
+	interval time.Duration,
+	logger logging.Logger,
+) *AutoCashout {
+	a := &AutoCashout{
+		store:       store,
+		backend:     backend,
+		cashout:     cashout,
+		chequeStore: chequeStore,
+		recipient:   recipient,
+		history:     history,
+		rates:       rates,
+		gasMultiple: big.NewFloat(gasMultiple),
+		logger:      logger,
+		timeNow:     time.Now,
+		quit:        make(chan struct{}),
+	}
+
+	a.wg.Add(1)
+	go a.loop(interval)
+
+	return a
+}
+
+func autoCashoutDecisionKey(chequebook common.Address) string {
+	return fmt.Sprintf("%s%x", autoCashoutDecisionPrefix, chequebook)
+}
+
+func (a *AutoCashout) loop(interval time.Duration) {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), autoCashoutRoundTimeout)
+			go func() {
+				select {
+				case <-a.quit:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			if err := a.round(ctx); err != nil {
+				a.logger.Debugf("auto cashout: %v", err)
+				a.logger.Error("auto cashout: round failed")
+			}
+			cancel()
+		case <-a.quit:
+			return
+		}
+	}
+}
+
+// round cashes the cheques of all chequebooks worth cashing, starting with the
+// highest uncashed amount. All cashout transactions of a round are sent with
+// the same gas price.
+func (a *AutoCashout) round(ctx context.Context) error {
+	gasPrice, err := a.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return fmt.Errorf("suggest gas price: %w", err)
+	}
+	gasCost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(cashoutGasLimit))
+
+	rate, err := a.rates.NativeRate()
+	if err != nil {
+		return fmt.Errorf("native rate: %w", err)
+	}
+	gasCostBZZ, _ := new(big.Float).Mul(new(big.Float).SetInt(gasCost), rate).Int(nil)
+	threshold, _ := new(big.Float).Mul(new(big.Float).SetInt(gasCostBZZ), a.gasMultiple).Int(nil)
+
+	cheques, err := a.chequeStore.LastCheques()
+	if err != nil {
+		return fmt.Errorf("last cheques: %w", err)
+	}
+
+	type candidate struct {
+		chequebook common.Address
+		uncashed   *big.Int
+	}
+	var candidates []candidate
+	for chequebook := range cheques {
+		status, err := a.cashout.CashoutStatus(ctx, chequebook)
+		if err != nil {
+			a.logger.Debugf("auto cashout: cashout status of chequebook %x: %v", chequebook, err)
+			continue
+		}
+		// do not cash again while a previous cashout is pending
+		if status.Last != nil && status.Last.Result == nil && !status.Last.Reverted {
+			continue
+		}
+		if status.UncashedAmount.Cmp(threshold) <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{chequebook: chequebook, uncashed: status.UncashedAmount})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].uncashed.Cmp(candidates[j].uncashed) > 0
+	})
+	if len(candidates) > maxCashoutsPerRound {
+		candidates = candidates[:maxCashoutsPerRound]
+	}
+
+	ctx = sctx.SetGasPrice(ctx, gasPrice)
+	for _, c := range candidates {
+		txHash, err := a.cashout.CashCheque(ctx, c.chequebook, a.recipient)
+		if err != nil {
+			a.logger.Debugf("auto cashout: cash cheque of chequebook %x: %v", c.chequebook, err)
+			a.logger.Errorf("auto cashout: cannot cash cheque of chequebook %x", c.chequebook)
+			continue
+		}
+
+		a.logger.Infof("auto cashout: cashing %d from chequebook %x in transaction %x", c.uncashed, c.chequebook, txHash)
+
+		err = a.store.Put(autoCashoutDecisionKey(c.chequebook), AutoCashoutDecision{
+			Chequebook: c.chequebook,
+			Timestamp:  a.timeNow().Unix(),
+			Uncashed:   c.uncashed,
+			GasPrice:   gasPrice,
+			GasCost:    gasCost,
+			GasCostBZZ: gasCostBZZ,
+			TxHash:     txHash,
+		})
+		if err != nil {
+			return fmt.Errorf("persist decision: %w", err)
+		}
+
+		if a.history != nil {
+			if err := a.history.Record(history.Cashout, swarm.ZeroAddress, c.chequebook, c.uncashed, txHash); err != nil {
+				a.logger.Errorf("auto cashout: record cashout of chequebook %x: %v", c.chequebook, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Decisions returns the last cashout decision for every chequebook.
+func (a *AutoCashout) Decisions() ([]AutoCashoutDecision, error) {
+	var decisions []AutoCashoutDecision
+	err := a.store.Iterate(autoCashoutDecisionPrefix, func(key, val []byte) (bool, error) {
+		var d AutoCashoutDecision
+		if err := json.Unmarshal(val, &d); err != nil {
+			return true, fmt.Errorf("unmarshal decision %q: %w", string(key), err)
+		}
+		decisions = append(decisions, d)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].Timestamp > decisions[j].Timestamp
+	})
+	return decisions, nil
+}
+
+// Close stops cashing out cheques.
+func (a *AutoCashout) Close() error {
+	close(a.quit)
+	a.wg.Wait()
+	return nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/sctx"
+	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
+	chequestoremock "github.com/holisticode/bee/pkg/settlement/swap/chequestore/mock"
+	"github.com/holisticode/bee/pkg/settlement/swap/priceoracle"
+	priceoraclemock "github.com/holisticode/bee/pkg/settlement/swap/priceoracle/mock"
+	storemock "github.com/holisticode/bee/pkg/statestore/mock"
+	"github.com/holisticode/bee/pkg/transaction"
+	"github.com/holisticode/bee/pkg/transaction/backendmock"
+)
+
+type cashoutMock struct {
+	mtx      sync.Mutex
+	statuses map[common.Address]*chequebook.CashoutStatus
+	cashed   []common.Address
+}
+
+func (m *cashoutMock) CashCheque(ctx context.Context, chequebookAddress, recipient common.Address) (common.Hash, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if sctx.GetGasPrice(ctx) == nil {
+		return common.Hash{}, context.Canceled
+	}
+	m.cashed = append(m.cashed, chequebookAddress)
+	return common.BytesToHash(chequebookAddress.Bytes()), nil
+}
+
+func (m *cashoutMock) CashoutStatus(ctx context.Context, chequebookAddress common.Address) (*chequebook.CashoutStatus, error) {
+	return m.statuses[chequebookAddress], nil
+}
+
+type nativeRaterFunc func() (*big.Float, error)
+
+func (f nativeRaterFunc) NativeRate() (*big.Float, error) {
+	return f()
+}
+
+func newChequeStore(statuses map[common.Address]*chequebook.CashoutStatus) chequebook.ChequeStore {
+	return chequestoremock.NewChequeStore(
+		chequestoremock.WithLastChequesFunc(func() (map[common.Address]*chequebook.SignedCheque, error) {
+			cheques := make(map[common.Address]*chequebook.SignedCheque)
+			for address := range statuses {
+				cheques[address] = &chequebook.SignedCheque{}
+			}
+			return cheques, nil
+		}),
+	)
+}
+
+func newGasPriceBackend(gasPrice *big.Int) transaction.Backend {
+	return backendmock.New(
+		backendmock.WithSuggestGasPriceFunc(func(ctx context.Context) (*big.Int, error) {
+			return gasPrice, nil
+		}),
+	)
+}
+
+func TestAutoCashout(t *testing.T) {
+	defer func(n int) {
+		*chequebook.MaxCashoutsPerRound = n
+	}(*chequebook.MaxCashoutsPerRound)
+	*chequebook.MaxCashoutsPerRound = 2
+
+	var (
+		// the estimated gas cost is 300000
+		gasPrice = big.NewInt(1)
+		// cheques are worth cashing above 600000
+		gasMultiple = 2.0
+
+		small   = common.HexToAddress("01")
+		medium  = common.HexToAddress("02")
+		large   = common.HexToAddress("03")
+		largest = common.HexToAddress("04")
+		pending = common.HexToAddress("05")
+	)
+
+	cashout := &cashoutMock{
+		statuses: map[common.Address]*chequebook.CashoutStatus{
+			small:   {UncashedAmount: big.NewInt(600000)},
+			medium:  {UncashedAmount: big.NewInt(700000)},
+			large:   {UncashedAmount: big.NewInt(800000)},
+			largest: {UncashedAmount: big.NewInt(900000)},
+			pending: {
+				Last:           &chequebook.LastCashout{},
+				UncashedAmount: big.NewInt(1000000),
+			},
+		},
+	}
+	chequeStore := newChequeStore(cashout.statuses)
+	backend := newGasPriceBackend(gasPrice)
+	priceOracle := priceoraclemock.New(big.NewInt(1), big.NewInt(0))
+
+	store := storemock.NewStateStore()
+	defer store.Close()
+
+	autoCashout := chequebook.NewAutoCashout(store, backend, cashout, chequeStore, common.HexToAddress("ff"), nil, priceOracle, gasMultiple, time.Hour, logging.New(io.Discard, 0))
+	defer autoCashout.Close()
+
+	if err := autoCashout.Round(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []common.Address{largest, large}
+	if len(cashout.cashed) != len(want) {
+		t.Fatalf("got %d cashouts, want %d", len(cashout.cashed), len(want))
+	}
+	for i, address := range want {
+		if cashout.cashed[i] != address {
+			t.Fatalf("got cashout %d of chequebook %x, want %x", i, cashout.cashed[i], address)
+		}
+	}
+
+	decisions, err := autoCashout.Decisions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != len(want) {
+		t.Fatalf("got %d decisions, want %d", len(decisions), len(want))
+	}
+	for _, d := range decisions {
+		if d.GasCost.Cmp(big.NewInt(300000)) != 0 {
+			t.Fatalf("got gas cost %d, want %d", d.GasCost, 300000)
+		}
+		if d.GasCostBZZ.Cmp(big.NewInt(300000)) != 0 {
+			t.Fatalf("got gas cost %d BZZ, want %d", d.GasCostBZZ, 300000)
+		}
+		if d.TxHash != common.BytesToHash(d.Chequebook.Bytes()) {
+			t.Fatalf("got transaction %x for chequebook %x", d.TxHash, d.Chequebook)
+		}
+		if d.Uncashed.Cmp(cashout.statuses[d.Chequebook].UncashedAmount) != 0 {
+			t.Fatalf("got uncashed amount %d for chequebook %x, want %d", d.Uncashed, d.Chequebook, cashout.statuses[d.Chequebook].UncashedAmount)
+		}
+	}
+}
+
+func TestAutoCashoutNativeRate(t *testing.T) {
+	var (
+		// the estimated gas cost is 300000 wei
+		gasPrice = big.NewInt(1)
+		// one wei is worth 3 BZZ base units, so the gas cost is 900000
+		nativeRate = big.NewFloat(3)
+		// cheques are worth cashing above 1800000
+		gasMultiple = 2.0
+
+		cheap    = common.HexToAddress("01")
+		valuable = common.HexToAddress("02")
+	)
+
+	cashout := &cashoutMock{
+		statuses: map[common.Address]*chequebook.CashoutStatus{
+			cheap:    {UncashedAmount: big.NewInt(1000000)},
+			valuable: {UncashedAmount: big.NewInt(2000000)},
+		},
+	}
+
+	priceOracle := priceoraclemock.New(big.NewInt(1), big.NewInt(0))
+	priceOracle.SetNativeRate(nativeRate)
+
+	store := storemock.NewStateStore()
+	defer store.Close()
+
+	autoCashout := chequebook.NewAutoCashout(store, newGasPriceBackend(gasPrice), cashout, newChequeStore(cashout.statuses), common.HexToAddress("ff"), nil, priceOracle, gasMultiple, time.Hour, logging.New(io.Discard, 0))
+	defer autoCashout.Close()
+
+	if err := autoCashout.Round(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cashout.cashed) != 1 || cashout.cashed[0] != valuable {
+		t.Fatalf("got cashouts %x, want %x", cashout.cashed, valuable)
+	}
+
+	decisions, err := autoCashout.Decisions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 1 {
+		t.Fatalf("got %d decisions, want 1", len(decisions))
+	}
+	if decisions[0].GasCost.Cmp(big.NewInt(300000)) != 0 {
+		t.Fatalf("got gas cost %d, want %d", decisions[0].GasCost, 300000)
+	}
+	if decisions[0].GasCostBZZ.Cmp(big.NewInt(900000)) != 0 {
+		t.Fatalf("got gas cost %d BZZ, want %d", decisions[0].GasCostBZZ, 900000)
+	}
+}
+
+func TestAutoCashoutNoNativeRate(t *testing.T) {
+	cheque := common.HexToAddress("01")
+	cashout := &cashoutMock{
+		statuses: map[common.Address]*chequebook.CashoutStatus{
+			cheque: {UncashedAmount: big.NewInt(1000000000)},
+		},
+	}
+	rates := nativeRaterFunc(func() (*big.Float, error) {
+		return nil, priceoracle.ErrNoNativeRate
+	})
+
+	store := storemock.NewStateStore()
+	defer store.Close()
+
+	autoCashout := chequebook.NewAutoCashout(store, newGasPriceBackend(big.NewInt(1)), cashout, newChequeStore(cashout.statuses), common.HexToAddress("ff"), nil, rates, 2, time.Hour, logging.New(io.Discard, 0))
+	defer autoCashout.Close()
+
+	if err := autoCashout.Round(context.Background()); !errors.Is(err, priceoracle.ErrNoNativeRate) {
+		t.Fatalf("got error %v, want %v", err, priceoracle.ErrNoNativeRate)
+	}
+	if len(cashout.cashed) != 0 {
+		t.Fatalf("got %d cashouts without a native rate", len(cashout.cashed))
+	}
+}
@@ -24,6 +24,9 @@ var (
 	ErrNoCashout = errors.New("no prior cashout")
 )
 
+// gas limit of cashout transactions if none is given
+const cashoutGasLimit = 300000
+
 // CashoutService is the service responsible for managing cashout actions
 type CashoutService interface {
 	// CashCheque sends a cashing transaction for the last cheque of the chequebook
@@ -143,7 +146,7 @@ func (s *cashoutService) CashCheque(ctx context.Context, chequebook, recipient c
 	lim := sctx.GetGasLimit(ctx)
 	if lim == 0 {
 		// fix for out of gas errors
-		lim = 300000
+		lim = cashoutGasLimit
 	}
 	request := &transaction.TxRequest{
 		To:          &chequebook,
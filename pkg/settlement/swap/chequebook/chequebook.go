@@ -13,6 +13,7 @@ import (
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/sctx"
 	"github.com/holisticode/bee/pkg/settlement/swap/erc20"
 	"github.com/holisticode/bee/pkg/storage"
@@ -74,10 +75,13 @@ type service struct {
 	store               storage.StateStorer
 	chequeSigner        ChequeSigner
 	totalIssuedReserved *big.Int
+	lowBalance          *events.BalanceMonitor
 }
 
 // New creates a new chequebook service for the provided chequebook contract.
-func New(transactionService transaction.Service, address, ownerAddress common.Address, store storage.StateStorer, chequeSigner ChequeSigner, erc20Service erc20.Service) (Service, error) {
+// If publisher and lowBalance are given, an event is published once the
+// available balance drops below lowBalance after issuing a cheque.
+func New(transactionService transaction.Service, address, ownerAddress common.Address, store storage.StateStorer, chequeSigner ChequeSigner, erc20Service erc20.Service, publisher events.Publisher, lowBalance *big.Int) (Service, error) {
 	return &service{
 		transactionService:  transactionService,
 		address:             address,
@@ -87,6 +91,7 @@ func New(transactionService transaction.Service, address, ownerAddress common.Ad
 		store:               store,
 		chequeSigner:        chequeSigner,
 		totalIssuedReserved: big.NewInt(0),
+		lowBalance:          events.NewBalanceMonitor(publisher, events.ChequebookBalanceLow, address, lowBalance),
 	}, nil
 }
 
@@ -242,7 +247,13 @@ func (s *service) Issue(ctx context.Context, beneficiary common.Address, amount
 		return nil, err
 	}
 	totalIssued = totalIssued.Add(totalIssued, amount)
-	return availableBalance, s.store.Put(totalIssuedKey, totalIssued)
+	err = s.store.Put(totalIssuedKey, totalIssued)
+	if err != nil {
+		return availableBalance, err
+	}
+
+	s.lowBalance.Check(availableBalance)
+	return availableBalance, nil
 }
 
 // returns the total amount in cheques issued so far
@@ -13,6 +13,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
 	erc20mock "github.com/holisticode/bee/pkg/settlement/swap/erc20/mock"
 	storemock "github.com/holisticode/bee/pkg/statestore/mock"
@@ -30,6 +31,8 @@ func TestChequebookAddress(t *testing.T) {
 		nil,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -53,6 +56,8 @@ func TestChequebookBalance(t *testing.T) {
 		nil,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -97,6 +102,8 @@ func TestChequebookDeposit(t *testing.T) {
 				return txHash, nil
 			}),
 		),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -132,6 +139,8 @@ func TestChequebookWaitForDeposit(t *testing.T) {
 		nil,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -163,6 +172,8 @@ func TestChequebookWaitForDepositReverted(t *testing.T) {
 		nil,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -204,6 +215,8 @@ func TestChequebookIssue(t *testing.T) {
 		store,
 		chequeSigner,
 		erc20mock.New(),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -344,6 +357,8 @@ func TestChequebookIssueErrorSend(t *testing.T) {
 		store,
 		chequeSigner,
 		erc20mock.New(),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -386,6 +401,8 @@ func TestChequebookIssueOutOfFunds(t *testing.T) {
 		store,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -426,6 +443,8 @@ func TestChequebookWithdraw(t *testing.T) {
 		store,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -460,6 +479,8 @@ func TestChequebookWithdrawInsufficientFunds(t *testing.T) {
 		store,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -489,3 +510,59 @@ func TestStateStoreKeys(t *testing.T) {
 		t.Fatalf("wrong last received cheque key. wanted %s, got %s", expected, chequebook.LastReceivedChequeKey(address))
 	}
 }
+
+func TestChequebookIssueLowBalance(t *testing.T) {
+	address := common.HexToAddress("0xabcd")
+	beneficiary := common.HexToAddress("0xdddd")
+	ownerAdress := common.HexToAddress("0xfff")
+	store := storemock.NewStateStore()
+
+	var published []events.Event
+	publisher := events.PublisherFunc(func(e events.Event) {
+		published = append(published, e)
+	})
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(100).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(0).FillBytes(make([]byte, 32)), "totalPaidOut"),
+			),
+		),
+		address,
+		ownerAdress,
+		store,
+		&chequeSignerMock{
+			sign: func(cheque *chequebook.Cheque) ([]byte, error) {
+				return common.Hex2Bytes("0xffff"), nil
+			},
+		},
+		erc20mock.New(),
+		publisher,
+		big.NewInt(50),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = chequebookService.Issue(context.Background(), beneficiary, big.NewInt(80), func(cheque *chequebook.SignedCheque) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(published) != 1 {
+		t.Fatalf("got %d events, want 1", len(published))
+	}
+	if published[0].Type != events.ChequebookBalanceLow {
+		t.Fatalf("got event type %s, want %s", published[0].Type, events.ChequebookBalanceLow)
+	}
+	data := published[0].Data.(events.BalanceData)
+	if data.Address != address {
+		t.Fatalf("got address %x, want %x", data.Address, address)
+	}
+	if data.Balance.Cmp(big.NewInt(20)) != 0 {
+		t.Fatalf("got balance %d, want 20", data.Balance)
+	}
+}
@@ -1,7 +1,28 @@
 package chequebook
 
+import (
+	"context"
+	"time"
+)
+
 var (
 	LastIssuedChequeKey   = lastIssuedChequeKey
 	LastReceivedChequeKey = lastReceivedChequeKey
 	CashoutActionKey      = cashoutActionKey
+	ChequebookKey         = chequebookKey
+	TotalIssuedKey        = totalIssuedKey
 )
+
+var MaxCashoutsPerRound = &maxCashoutsPerRound
+
+func (a *AutoCashout) Round(ctx context.Context) error {
+	return a.round(ctx)
+}
+
+func (r *Refiller) Check(ctx context.Context) error {
+	return r.check(ctx)
+}
+
+func (r *Refiller) SetTimeNow(f func() time.Time) {
+	r.timeNow = f
+}
@@ -21,6 +21,7 @@ type Service struct {
 	chequebookIssueFunc            func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error)
 	chequebookWithdrawFunc         func(ctx context.Context, amount *big.Int) (hash common.Hash, err error)
 	chequebookDepositFunc          func(ctx context.Context, amount *big.Int) (hash common.Hash, err error)
+	chequebookWaitForDepositFunc   func(ctx context.Context, txHash common.Hash) error
 	lastChequeFunc                 func(common.Address) (*chequebook.SignedCheque, error)
 	lastChequesFunc                func() (map[common.Address]*chequebook.SignedCheque, error)
 }
@@ -50,6 +51,12 @@ func WithChequebookDepositFunc(f func(ctx context.Context, amount *big.Int) (has
 	})
 }
 
+func WithChequebookWaitForDepositFunc(f func(ctx context.Context, txHash common.Hash) error) Option {
+	return optionFunc(func(s *Service) {
+		s.chequebookWaitForDepositFunc = f
+	})
+}
+
 func WithChequebookIssueFunc(f func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error)) Option {
 	return optionFunc(func(s *Service) {
 		s.chequebookIssueFunc = f
@@ -108,6 +115,9 @@ func (s *Service) Deposit(ctx context.Context, amount *big.Int) (hash common.Has
 
 // WaitForDeposit mocks the chequebook .WaitForDeposit function
 func (s *Service) WaitForDeposit(ctx context.Context, txHash common.Hash) error {
+	if s.chequebookWaitForDepositFunc != nil {
+		return s.chequebookWaitForDepositFunc(ctx, txHash)
+	}
 	return errors.New("Error")
 }
 
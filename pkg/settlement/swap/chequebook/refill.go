@@ -0,0 +1,304 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/settlement/swap/erc20"
+	"github.com/holisticode/bee/pkg/storage"
+)
+
+const (
+	// persistence key of the refills made by the Refiller
+	refillsKey = "swap_chequebook_refills"
+)
+
+var (
+	// interval in which the available balance is checked
+	refillCheckInterval = time.Minute
+	// timeout of a single refill including waiting for the deposit
+	refillTimeout = 10 * time.Minute
+	// maximum number of refills kept in the history
+	maxRefills = 100
+)
+
+// ErrInvalidRefillPolicy is returned if the refill policy cannot be applied.
+var ErrInvalidRefillPolicy = errors.New("invalid refill policy")
+
+// RefillPolicy describes when and how much the chequebook is refilled.
+type RefillPolicy struct {
+	// Floor is the available balance below which the chequebook is refilled.
+	Floor *big.Int
+	// Target is the available balance the chequebook is refilled to.
+	Target *big.Int
+	// Cap is the maximum amount deposited within Period.
+	Cap    *big.Int
+	Period time.Duration
+}
+
+// Refill records a deposit made by the Refiller.
+type Refill struct {
+	Timestamp        int64       `json:"timestamp"`
+	Amount           *big.Int    `json:"amount"`
+	AvailableBalance *big.Int    `json:"availableBalance"` // available balance before the deposit
+	TxHash           common.Hash `json:"transactionHash"`
+	Pending          bool        `json:"pending,omitempty"` // set while waiting for the deposit
+	Error            string      `json:"error,omitempty"`   // set if the deposit did not succeed
+}
+
+// Refiller deposits tokens from the wallet of the node into the chequebook
+// whenever the available balance of the chequebook drops below the floor of
+// the policy.
+type Refiller struct {
+	chequebook Service
+	erc20      erc20.Service
+	owner      common.Address
+	store      storage.StateStorer
+	policy     RefillPolicy
+	logger     logging.Logger
+	timeNow    func() time.Time
+
+	refillMtx sync.Mutex // serializes refills
+	mtx       sync.Mutex // serializes access to the history
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewRefiller creates a new Refiller which refills the chequebook from the
+// wallet of the owner according to the policy.
+func NewRefiller(
+	chequebook Service,
+	erc20 erc20.Service,
+	owner common.Address,
+	store storage.StateStorer,
+	policy RefillPolicy,
+	logger logging.Logger,
+) (*Refiller, error) {
+	if policy.Floor == nil || policy.Target == nil || policy.Cap == nil {
+		return nil, fmt.Errorf("%w: floor, target and cap are required", ErrInvalidRefillPolicy)
+	}
+	if policy.Target.Cmp(policy.Floor) <= 0 {
+		return nil, fmt.Errorf("%w: target has to be above floor", ErrInvalidRefillPolicy)
+	}
+	if policy.Cap.Sign() <= 0 || policy.Period <= 0 {
+		return nil, fmt.Errorf("%w: cap and period have to be positive", ErrInvalidRefillPolicy)
+	}
+
+	r := &Refiller{
+		chequebook: chequebook,
+		erc20:      erc20,
+		owner:      owner,
+		store:      store,
+		policy:     policy,
+		logger:     logger,
+		timeNow:    time.Now,
+		quit:       make(chan struct{}),
+	}
+
+	r.wg.Add(1)
+	go r.loop()
+
+	return r, nil
+}
+
+func (r *Refiller) loop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(refillCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), refillTimeout)
+			go func() {
+				select {
+				case <-r.quit:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			if err := r.check(ctx); err != nil {
+				r.logger.Debugf("chequebook refill: %v", err)
+				r.logger.Error("chequebook refill: refill failed")
+			}
+			cancel()
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// check refills the chequebook up to the target if its available balance is
+// below the floor. The deposit is limited by what is left of the cap in the
+// current period and by the balance of the wallet.
+func (r *Refiller) check(ctx context.Context) error {
+	r.refillMtx.Lock()
+	defer r.refillMtx.Unlock()
+
+	available, err := r.chequebook.AvailableBalance(ctx)
+	if err != nil {
+		return fmt.Errorf("available balance: %w", err)
+	}
+	if available.Cmp(r.policy.Floor) >= 0 {
+		return nil
+	}
+
+	r.mtx.Lock()
+	refills, err := r.load()
+	r.mtx.Unlock()
+	if err != nil {
+		return err
+	}
+
+	now := r.timeNow()
+	since := now.Add(-r.policy.Period).Unix()
+	deposited := big.NewInt(0)
+	for _, refill := range refills {
+		if refill.Timestamp > since {
+			deposited.Add(deposited, refill.Amount)
+		}
+	}
+
+	amount := new(big.Int).Sub(r.policy.Target, available)
+	if left := new(big.Int).Sub(r.policy.Cap, deposited); left.Cmp(amount) < 0 {
+		amount = left
+	}
+	if amount.Sign() <= 0 {
+		r.logger.Debugf("chequebook refill: cap of %d reached", r.policy.Cap)
+		return nil
+	}
+
+	balance, err := r.erc20.BalanceOf(ctx, r.owner)
+	if err != nil {
+		return fmt.Errorf("wallet balance: %w", err)
+	}
+	if balance.Cmp(amount) < 0 {
+		amount = balance
+	}
+	if amount.Sign() <= 0 {
+		r.logger.Warning("chequebook refill: no tokens in the wallet to refill the chequebook")
+		return nil
+	}
+
+	txHash, err := r.chequebook.Deposit(ctx, amount)
+	if err != nil {
+		return fmt.Errorf("deposit: %w", err)
+	}
+
+	r.logger.Infof("chequebook refill: depositing %d in transaction %x", amount, txHash)
+
+	// the deposit is recorded before waiting for it and kept even if
+	// waiting fails as the transaction may still succeed and has to count
+	// towards the cap
+	if err := r.record(Refill{
+		Timestamp:        now.Unix(),
+		Amount:           amount,
+		AvailableBalance: available,
+		TxHash:           txHash,
+		Pending:          true,
+	}); err != nil {
+		return err
+	}
+
+	waitErr := r.chequebook.WaitForDeposit(ctx, txHash)
+	if err := r.settle(txHash, waitErr); err != nil {
+		return err
+	}
+	if waitErr != nil {
+		return fmt.Errorf("wait for deposit %x: %w", txHash, waitErr)
+	}
+	return nil
+}
+
+// record appends the refill to the history.
+func (r *Refiller) record(refill Refill) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	refills, err := r.load()
+	if err != nil {
+		return err
+	}
+	refills = append(refills, refill)
+	if len(refills) > maxRefills {
+		refills = refills[len(refills)-maxRefills:]
+	}
+	if err := r.store.Put(refillsKey, refills); err != nil {
+		return fmt.Errorf("persist refill: %w", err)
+	}
+	return nil
+}
+
+// settle records the result of waiting for the deposit of the refill in the
+// transaction.
+func (r *Refiller) settle(txHash common.Hash, waitErr error) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	refills, err := r.load()
+	if err != nil {
+		return err
+	}
+	for i := range refills {
+		if refills[i].TxHash != txHash {
+			continue
+		}
+		refills[i].Pending = false
+		if waitErr != nil {
+			refills[i].Error = waitErr.Error()
+		}
+	}
+	if err := r.store.Put(refillsKey, refills); err != nil {
+		return fmt.Errorf("persist refill: %w", err)
+	}
+	return nil
+}
+
+// load returns the persisted refills from oldest to newest.
+func (r *Refiller) load() ([]Refill, error) {
+	var refills []Refill
+	err := r.store.Get(refillsKey, &refills)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("load refills: %w", err)
+	}
+	return refills, nil
+}
+
+// Policy returns the refill policy.
+func (r *Refiller) Policy() RefillPolicy {
+	return r.policy
+}
+
+// Refills returns the refills made from newest to oldest.
+func (r *Refiller) Refills() ([]Refill, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	refills, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(refills)-1; i < j; i, j = i+1, j-1 {
+		refills[i], refills[j] = refills[j], refills[i]
+	}
+	return refills, nil
+}
+
+// Close stops refilling the chequebook.
+func (r *Refiller) Close() error {
+	close(r.quit)
+	r.wg.Wait()
+	return nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
+	chequebookmock "github.com/holisticode/bee/pkg/settlement/swap/chequebook/mock"
+	erc20mock "github.com/holisticode/bee/pkg/settlement/swap/erc20/mock"
+	storemock "github.com/holisticode/bee/pkg/statestore/mock"
+)
+
+func TestRefiller(t *testing.T) {
+	var (
+		owner     = common.HexToAddress("ff")
+		available = big.NewInt(50)
+		wallet    = big.NewInt(1000)
+		deposits  []*big.Int
+		now       = time.Unix(1000000, 0)
+	)
+
+	chequebookService := chequebookmock.NewChequebook(
+		chequebookmock.WithChequebookAvailableBalanceFunc(func(ctx context.Context) (*big.Int, error) {
+			return available, nil
+		}),
+		chequebookmock.WithChequebookDepositFunc(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+			deposits = append(deposits, amount)
+			return common.BigToHash(amount), nil
+		}),
+		chequebookmock.WithChequebookWaitForDepositFunc(func(ctx context.Context, txHash common.Hash) error {
+			return nil
+		}),
+	)
+	erc20 := erc20mock.New(
+		erc20mock.WithBalanceOfFunc(func(ctx context.Context, address common.Address) (*big.Int, error) {
+			if address != owner {
+				return nil, errors.New("wrong address")
+			}
+			return wallet, nil
+		}),
+	)
+
+	store := storemock.NewStateStore()
+	defer store.Close()
+
+	refiller, err := chequebook.NewRefiller(chequebookService, erc20, owner, store, chequebook.RefillPolicy{
+		Floor:  big.NewInt(100),
+		Target: big.NewInt(300),
+		Cap:    big.NewInt(400),
+		Period: time.Hour,
+	}, logging.New(io.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer refiller.Close()
+	refiller.SetTimeNow(func() time.Time { return now })
+
+	check := func(want ...int64) {
+		t.Helper()
+		if err := refiller.Check(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if len(deposits) != len(want) {
+			t.Fatalf("got %d deposits, want %d", len(deposits), len(want))
+		}
+		for i, w := range want {
+			if deposits[i].Cmp(big.NewInt(w)) != 0 {
+				t.Fatalf("got deposit %d of %d, want %d", i, deposits[i], w)
+			}
+		}
+	}
+
+	// refilled up to the target
+	check(250)
+
+	// above the floor nothing is deposited
+	available = big.NewInt(100)
+	check(250)
+
+	// limited by what is left of the cap
+	available = big.NewInt(0)
+	check(250, 150)
+
+	// the cap is exhausted within the period
+	check(250, 150)
+
+	// limited by the wallet balance in the next period
+	now = now.Add(time.Hour)
+	wallet = big.NewInt(120)
+	check(250, 150, 120)
+
+	refills, err := refiller.Refills()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int64{120, 150, 250}
+	if len(refills) != len(want) {
+		t.Fatalf("got %d refills, want %d", len(refills), len(want))
+	}
+	for i, w := range want {
+		if refills[i].Amount.Cmp(big.NewInt(w)) != 0 {
+			t.Fatalf("got refill %d of %d, want %d", i, refills[i].Amount, w)
+		}
+		if refills[i].TxHash != common.BigToHash(big.NewInt(w)) {
+			t.Fatalf("got refill %d in transaction %x", i, refills[i].TxHash)
+		}
+	}
+}
+
+func TestRefillerInvalidPolicy(t *testing.T) {
+	_, err := chequebook.NewRefiller(chequebookmock.NewChequebook(), erc20mock.New(), common.Address{}, nil, chequebook.RefillPolicy{
+		Floor:  big.NewInt(100),
+		Target: big.NewInt(100),
+		Cap:    big.NewInt(100),
+		Period: time.Hour,
+	}, logging.New(io.Discard, 0))
+	if !errors.Is(err, chequebook.ErrInvalidRefillPolicy) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrInvalidRefillPolicy)
+	}
+}
+
+func TestRefillerPendingDeposit(t *testing.T) {
+	var (
+		owner   = common.HexToAddress("ff")
+		waiting = make(chan struct{})
+		release = make(chan struct{})
+	)
+
+	chequebookService := chequebookmock.NewChequebook(
+		chequebookmock.WithChequebookAvailableBalanceFunc(func(ctx context.Context) (*big.Int, error) {
+			return big.NewInt(0), nil
+		}),
+		chequebookmock.WithChequebookDepositFunc(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+			return common.BigToHash(amount), nil
+		}),
+		chequebookmock.WithChequebookWaitForDepositFunc(func(ctx context.Context, txHash common.Hash) error {
+			close(waiting)
+			<-release
+			return errors.New("reverted")
+		}),
+	)
+	erc20 := erc20mock.New(
+		erc20mock.WithBalanceOfFunc(func(ctx context.Context, address common.Address) (*big.Int, error) {
+			return big.NewInt(1000), nil
+		}),
+	)
+
+	store := storemock.NewStateStore()
+	defer store.Close()
+
+	refiller, err := chequebook.NewRefiller(chequebookService, erc20, owner, store, chequebook.RefillPolicy{
+		Floor:  big.NewInt(100),
+		Target: big.NewInt(300),
+		Cap:    big.NewInt(400),
+		Period: time.Hour,
+	}, logging.New(io.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer refiller.Close()
+
+	checkErr := make(chan error, 1)
+	go func() {
+		checkErr <- refiller.Check(context.Background())
+	}()
+	<-waiting
+
+	// the history is available while waiting for the deposit
+	refills, err := refiller.Refills()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refills) != 1 || !refills[0].Pending {
+		t.Fatalf("got refills %+v, want one pending refill", refills)
+	}
+
+	close(release)
+	if err := <-checkErr; err == nil {
+		t.Fatal("expected wait for deposit error")
+	}
+
+	refills, err = refiller.Refills()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refills) != 1 || refills[0].Pending || refills[0].Error != "reverted" {
+		t.Fatalf("got refills %+v, want one failed refill", refills)
+	}
+}
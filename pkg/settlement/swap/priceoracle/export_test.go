@@ -0,0 +1,18 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package priceoracle
+
+import (
+	"math/big"
+	"time"
+)
+
+func Record(s Service, exchangeRate, deduction *big.Int) error {
+	return s.(*service).record(exchangeRate, deduction)
+}
+
+func SetTimeNow(s Service, f func() time.Time) {
+	s.(*service).timeNow = f
+}
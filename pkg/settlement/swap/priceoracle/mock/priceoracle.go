@@ -7,19 +7,25 @@ package mock
 import (
 	"context"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/settlement/swap/priceoracle"
 )
 
 type Service struct {
 	rate   *big.Int
 	deduct *big.Int
+	native *big.Float
 }
 
+// New returns a mock price oracle with the given rates. The native currency
+// is worth one BZZ base unit per wei until set otherwise.
 func New(rate, deduct *big.Int) Service {
 	return Service{
 		rate:   rate,
 		deduct: deduct,
+		native: big.NewFloat(1),
 	}
 }
 
@@ -34,6 +40,24 @@ func (s Service) CurrentRates() (exchangeRate, deduction *big.Int, err error) {
 	return s.rate, s.deduct, nil
 }
 
+// History returns the mocked rates as the only rate ever in effect.
+func (s Service) History(from, to time.Time) ([]priceoracle.Rate, error) {
+	return []priceoracle.Rate{
+		{
+			ExchangeRate: s.rate,
+			Deduction:    s.deduct,
+		},
+	}, nil
+}
+
+func (s Service) NativeRate() (*big.Float, error) {
+	return new(big.Float).Set(s.native), nil
+}
+
+func (s Service) SetNativeRate(rate *big.Float) {
+	s.native.Set(rate)
+}
+
 func (s Service) Close() error {
 	return nil
 }
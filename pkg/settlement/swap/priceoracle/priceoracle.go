@@ -6,30 +6,59 @@ package priceoracle
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/storage"
 	"github.com/holisticode/bee/pkg/transaction"
 	"github.com/ethersphere/go-price-oracle-abi/priceoracleabi"
 )
 
+const (
+	// prefix of the persistence keys of the recorded rates
+	ratePrefix = "priceoracle_rate_"
+)
+
 var (
 	errDecodeABI = errors.New("could not decode abi data")
+	// ErrNoNativeRate is returned if the rate of the native currency of the
+	// chain was not set.
+	ErrNoNativeRate = errors.New("native exchange rate not set")
 )
 
+// Rate is the exchange rate and deduction reported by the oracle from a point
+// in time on.
+type Rate struct {
+	Timestamp    time.Time `json:"timestamp"`
+	ExchangeRate *big.Int  `json:"exchangeRate"`
+	Deduction    *big.Int  `json:"deduction"`
+}
+
 type service struct {
 	logger             logging.Logger
 	priceOracleAddress common.Address
 	transactionService transaction.Service
+	store              storage.StateStorer
 	exchangeRate       *big.Int
 	deduction          *big.Int
 	timeDivisor        int64
+	timeNow            func() time.Time
 	quitC              chan struct{}
+
+	historyMu sync.Mutex
+	last      *Rate // last recorded rate
+
+	nativeMu   sync.Mutex
+	nativeRate *big.Float // BZZ base units one wei is worth
 }
 
 type Service interface {
@@ -39,6 +68,16 @@ type Service interface {
 	CurrentRates() (exchangeRate *big.Int, deduction *big.Int, err error)
 	// GetPrice retrieves latest available information from oracle
 	GetPrice(ctx context.Context) (*big.Int, *big.Int, error)
+	// History returns the rates that applied in the interval [from, to) from
+	// oldest to newest, starting with the rate in effect at from.
+	History(from, to time.Time) ([]Rate, error)
+	// NativeRate returns how many BZZ base units one wei of the native
+	// currency of the chain is worth.
+	NativeRate() (*big.Float, error)
+	// SetNativeRate sets the rate returned by NativeRate. The oracle contract
+	// only publishes the price of accounting units, so the rate of the native
+	// currency is configured by the node operator.
+	SetNativeRate(rate *big.Float)
 	Start()
 }
 
@@ -46,15 +85,19 @@ var (
 	priceOracleABI = transaction.ParseABIUnchecked(priceoracleabi.PriceOracleABIv0_1_0)
 )
 
-func New(logger logging.Logger, priceOracleAddress common.Address, transactionService transaction.Service, timeDivisor int64) Service {
+// New creates a new price oracle service. If store is not nil every change of
+// the rates is recorded in it.
+func New(logger logging.Logger, priceOracleAddress common.Address, transactionService transaction.Service, store storage.StateStorer, timeDivisor int64) Service {
 	return &service{
 		logger:             logger,
 		priceOracleAddress: priceOracleAddress,
 		transactionService: transactionService,
+		store:              store,
 		exchangeRate:       big.NewInt(0),
 		deduction:          nil,
 		quitC:              make(chan struct{}),
 		timeDivisor:        timeDivisor,
+		timeNow:            time.Now,
 	}
 }
 
@@ -75,6 +118,9 @@ func (s *service) Start() {
 				s.logger.Tracef("updated exchange rate to %d and deduction to %d", exchangeRate, deduction)
 				s.exchangeRate = exchangeRate
 				s.deduction = deduction
+				if err := s.record(exchangeRate, deduction); err != nil {
+					s.logger.Errorf("could not record price: %v", err)
+				}
 			}
 
 			ts := time.Now().Unix()
@@ -141,6 +187,123 @@ func (s *service) CurrentRates() (exchangeRate, deduction *big.Int, err error) {
 	return s.exchangeRate, s.deduction, nil
 }
 
+// rateKey returns the storage key of a rate recorded at the given time. The
+// timestamps are zero padded to keep the keys ordered.
+func rateKey(t time.Time) string {
+	return fmt.Sprintf("%s%020d", ratePrefix, t.UnixNano())
+}
+
+// record persists the rates if they differ from the last recorded ones.
+func (s *service) record(exchangeRate, deduction *big.Int) error {
+	if s.store == nil {
+		return nil
+	}
+
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	now := s.timeNow().UTC()
+	if s.last == nil {
+		rates, err := s.rates()
+		if err != nil {
+			return err
+		}
+		if len(rates) > 0 {
+			s.last = &rates[len(rates)-1]
+		}
+	}
+	if s.last != nil && s.last.ExchangeRate.Cmp(exchangeRate) == 0 && s.last.Deduction.Cmp(deduction) == 0 {
+		return nil
+	}
+
+	rate := Rate{
+		Timestamp:    now,
+		ExchangeRate: new(big.Int).Set(exchangeRate),
+		Deduction:    new(big.Int).Set(deduction),
+	}
+	if err := s.store.Put(rateKey(now), rate); err != nil {
+		return err
+	}
+	s.last = &rate
+	return nil
+}
+
+// rates returns all recorded rates from oldest to newest.
+func (s *service) rates() ([]Rate, error) {
+	var rates []Rate
+	err := s.store.Iterate(ratePrefix, func(key, val []byte) (bool, error) {
+		var r Rate
+		if err := json.Unmarshal(val, &r); err != nil {
+			return true, fmt.Errorf("unmarshal rate %q: %w", string(key), err)
+		}
+		rates = append(rates, r)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(rates, func(i, j int) bool {
+		return rates[i].Timestamp.Before(rates[j].Timestamp)
+	})
+	return rates, nil
+}
+
+func (s *service) History(from, to time.Time) ([]Rate, error) {
+	if s.store == nil {
+		return nil, nil
+	}
+
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	rates, err := s.rates()
+	if err != nil {
+		return nil, err
+	}
+
+	// the last rate recorded before the interval still applies at its start
+	start := sort.Search(len(rates), func(i int) bool {
+		return rates[i].Timestamp.After(from)
+	})
+	if start > 0 {
+		start--
+	}
+	end := sort.Search(len(rates), func(i int) bool {
+		return !rates[i].Timestamp.Before(to)
+	})
+	if start >= end {
+		return nil, nil
+	}
+	return rates[start:end], nil
+}
+
+// RateAt returns the rate in effect at the given time among rates ordered
+// from oldest to newest.
+func RateAt(rates []Rate, t time.Time) (Rate, bool) {
+	i := sort.Search(len(rates), func(i int) bool {
+		return rates[i].Timestamp.After(t)
+	})
+	if i == 0 {
+		return Rate{}, false
+	}
+	return rates[i-1], true
+}
+
+func (s *service) NativeRate() (*big.Float, error) {
+	s.nativeMu.Lock()
+	defer s.nativeMu.Unlock()
+	if s.nativeRate == nil {
+		return nil, ErrNoNativeRate
+	}
+	return new(big.Float).Set(s.nativeRate), nil
+}
+
+func (s *service) SetNativeRate(rate *big.Float) {
+	s.nativeMu.Lock()
+	defer s.nativeMu.Unlock()
+	s.nativeRate = new(big.Float).Set(rate)
+}
+
 func (s *service) Close() error {
 	close(s.quitC)
 	return nil
@@ -8,11 +8,14 @@ import (
 	"context"
 	"io"
 	"math/big"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/settlement/swap/priceoracle"
+	statestore "github.com/holisticode/bee/pkg/statestore/mock"
 	"github.com/holisticode/bee/pkg/transaction"
 	transactionmock "github.com/holisticode/bee/pkg/transaction/mock"
 	"github.com/ethersphere/go-price-oracle-abi/priceoracleabi"
@@ -43,6 +46,7 @@ func TestExchangeGetPrice(t *testing.T) {
 				"getPrice",
 			),
 		),
+		nil,
 		1,
 	)
 
@@ -59,3 +63,55 @@ func TestExchangeGetPrice(t *testing.T) {
 		t.Fatalf("got wrong deduce. wanted %d, got %d", expectedDeduce, deduce)
 	}
 }
+
+func TestHistory(t *testing.T) {
+	store := statestore.NewStateStore()
+	t.Cleanup(func() { _ = store.Close() })
+
+	ex := priceoracle.New(logging.New(io.Discard, 0), common.HexToAddress("0xabcd"), transactionmock.New(), store, 1)
+
+	start := time.Unix(1000, 0).UTC()
+	now := start
+	priceoracle.SetTimeNow(ex, func() time.Time { return now })
+
+	record := func(offset time.Duration, exchangeRate, deduction int64) {
+		t.Helper()
+		now = start.Add(offset)
+		if err := priceoracle.Record(ex, big.NewInt(exchangeRate), big.NewInt(deduction)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record(0, 100, 10)
+	record(time.Minute, 100, 10) // unchanged, not recorded
+	record(2*time.Minute, 110, 10)
+	record(3*time.Minute, 120, 0)
+
+	rates, err := ex.History(start.Add(90*time.Second), start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []priceoracle.Rate{
+		{Timestamp: start, ExchangeRate: big.NewInt(100), Deduction: big.NewInt(10)},
+		{Timestamp: start.Add(2 * time.Minute), ExchangeRate: big.NewInt(110), Deduction: big.NewInt(10)},
+		{Timestamp: start.Add(3 * time.Minute), ExchangeRate: big.NewInt(120), Deduction: big.NewInt(0)},
+	}
+	if !reflect.DeepEqual(rates, want) {
+		t.Fatalf("got rates %v, want %v", rates, want)
+	}
+
+	rate, ok := priceoracle.RateAt(rates, start.Add(150*time.Second))
+	if !ok || rate.ExchangeRate.Cmp(big.NewInt(110)) != 0 {
+		t.Fatalf("got rate %v at 150s, want exchange rate 110", rate)
+	}
+	if _, ok := priceoracle.RateAt(rates, start.Add(-time.Second)); ok {
+		t.Fatal("got rate before the first one was recorded")
+	}
+
+	rates, err = ex.History(start.Add(-time.Hour), start.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 0 {
+		t.Fatalf("got rates %v before the first one was recorded", rates)
+	}
+}
@@ -13,12 +13,17 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/settlement"
+	"github.com/holisticode/bee/pkg/settlement/driver"
+	"github.com/holisticode/bee/pkg/settlement/history"
 	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
 	"github.com/holisticode/bee/pkg/settlement/swap/swapprotocol"
 	"github.com/holisticode/bee/pkg/storage"
 	"github.com/holisticode/bee/pkg/swarm"
 )
 
+// DriverName is the name of swap as a settlement driver.
+const DriverName = "swap"
+
 var (
 	// ErrWrongChequebook is the error if a peer uses a different chequebook from before.
 	ErrWrongChequebook = errors.New("wrong chequebook")
@@ -26,8 +31,32 @@ var (
 	ErrUnknownBeneficary = errors.New("unknown beneficiary for peer")
 	// ErrChequeValueTooLow is the error a peer issued a cheque not covering 1 accounting credit
 	ErrChequeValueTooLow = errors.New("cheque value too low")
+	// ErrNoChain is the error if swap is opened as a settlement driver without a chain backend.
+	ErrNoChain = errors.New("swap without chain backend")
 )
 
+// Backend are the chain services swap is opened with as a settlement driver.
+type Backend struct {
+	Protocol    *swapprotocol.Service
+	Chequebook  chequebook.Service
+	ChequeStore chequebook.ChequeStore
+	Cashout     chequebook.CashoutService
+	Addressbook Addressbook
+	NetworkID   uint64
+}
+
+func init() {
+	driver.Register(DriverName, func(o driver.Options) (driver.Driver, error) {
+		b, ok := o.Backend.(*Backend)
+		if !ok || b == nil {
+			return nil, ErrNoChain
+		}
+		s := New(b.Protocol, o.Logger, o.Store, b.Chequebook, b.ChequeStore, b.Addressbook, b.NetworkID, b.Cashout, o.Accounting)
+		b.Protocol.SetSwap(s)
+		return s, nil
+	})
+}
+
 type Interface interface {
 	settlement.Interface
 	// LastSentCheque returns the last sent cheque for the peer
@@ -50,6 +79,7 @@ type Service struct {
 	logger      logging.Logger
 	store       storage.StateStorer
 	accounting  settlement.Accounting
+	history     *history.History
 	metrics     metrics
 	chequebook  chequebook.Service
 	chequeStore chequebook.ChequeStore
@@ -74,6 +104,11 @@ func New(proto swapprotocol.Interface, logger logging.Logger, store storage.Stat
 	}
 }
 
+// Name returns the name of swap as a settlement driver.
+func (s *Service) Name() string {
+	return DriverName
+}
+
 // ReceiveCheque is called by the swap protocol if a cheque is received.
 func (s *Service) ReceiveCheque(ctx context.Context, peer swarm.Address, cheque *chequebook.SignedCheque, exchangeRate, deduction *big.Int) (err error) {
 	// check this is the same chequebook for this peer as previously
@@ -111,6 +146,7 @@ func (s *Service) ReceiveCheque(ctx context.Context, peer swarm.Address, cheque
 	tot, _ := big.NewFloat(0).SetInt(receivedAmount).Float64()
 	s.metrics.TotalReceived.Add(tot)
 	s.metrics.ChequesReceived.Inc()
+	s.recordCheque(history.ChequeReceived, peer, cheque.Chequebook, receivedAmount, amount)
 
 	return s.accounting.NotifyPaymentReceived(peer, amount)
 }
@@ -132,12 +168,29 @@ func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount *big.Int)
 		return
 	}
 
+	// the value of the cheque in BZZ is only known from the increase of the
+	// cumulative payout
+	var lastPayout *big.Int
+	if s.history != nil {
+		if lastPayout, err = s.lastSentPayout(beneficiary); err != nil {
+			return
+		}
+	}
+
 	balance, err := s.proto.EmitCheque(ctx, peer, beneficiary, amount, s.chequebook.Issue)
 
 	if err != nil {
 		return
 	}
 
+	if s.history != nil {
+		if payout, err := s.lastSentPayout(beneficiary); err != nil {
+			s.logger.Errorf("swap: get last cheque sent to peer %v: %v", peer, err)
+		} else {
+			s.recordCheque(history.ChequeSent, peer, s.chequebook.Address(), payout.Sub(payout, lastPayout), amount)
+		}
+	}
+
 	bal, _ := big.NewFloat(0).SetInt(balance).Float64()
 	s.metrics.AvailableBalance.Set(bal)
 	s.accounting.NotifyPaymentSent(peer, amount, nil)
@@ -150,6 +203,46 @@ func (s *Service) SetAccounting(accounting settlement.Accounting) {
 	s.accounting = accounting
 }
 
+// SetHistory sets the history cheques and cashouts are recorded in.
+func (s *Service) SetHistory(history *history.History) {
+	s.history = history
+}
+
+// recordHistory records a settlement event in the history, if any. Failures
+// are only logged as the settlement itself has already been persisted.
+func (s *Service) recordHistory(typ history.EventType, peer swarm.Address, chequebookAddress common.Address, amount *big.Int, txHash common.Hash) {
+	if s.history == nil {
+		return
+	}
+	if err := s.history.Record(typ, peer, chequebookAddress, amount, txHash); err != nil {
+		s.logger.Errorf("swap: record %s for peer %v: %v", typ, peer, err)
+	}
+}
+
+// recordCheque records a cheque in the history, if any, together with the
+// accounting units it settled.
+func (s *Service) recordCheque(typ history.EventType, peer swarm.Address, chequebookAddress common.Address, amount, units *big.Int) {
+	if s.history == nil {
+		return
+	}
+	if err := s.history.RecordCheque(typ, peer, chequebookAddress, amount, units); err != nil {
+		s.logger.Errorf("swap: record %s for peer %v: %v", typ, peer, err)
+	}
+}
+
+// lastSentPayout returns the cumulative payout of the last cheque sent to the
+// beneficiary or zero if there is none.
+func (s *Service) lastSentPayout(beneficiary common.Address) (*big.Int, error) {
+	cheque, err := s.chequebook.LastCheque(beneficiary)
+	if err != nil {
+		if errors.Is(err, chequebook.ErrNoCheque) {
+			return big.NewInt(0), nil
+		}
+		return nil, err
+	}
+	return new(big.Int).Set(cheque.CumulativePayout), nil
+}
+
 // TotalSent returns the total amount sent to a peer
 func (s *Service) TotalSent(peer swarm.Address) (totalSent *big.Int, err error) {
 	beneficiary, known, err := s.addressbook.Beneficiary(peer)
@@ -334,7 +427,25 @@ func (s *Service) CashCheque(ctx context.Context, peer swarm.Address) (common.Ha
 	if !known {
 		return common.Hash{}, chequebook.ErrNoCheque
 	}
-	return s.cashout.CashCheque(ctx, chequebookAddress, s.chequebook.Address())
+
+	var uncashed *big.Int
+	if s.history != nil {
+		status, err := s.cashout.CashoutStatus(ctx, chequebookAddress)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		uncashed = status.UncashedAmount
+	}
+
+	txHash, err := s.cashout.CashCheque(ctx, chequebookAddress, s.chequebook.Address())
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if uncashed != nil {
+		s.recordHistory(history.Cashout, peer, chequebookAddress, uncashed, txHash)
+	}
+	return txHash, nil
 }
 
 // CashoutStatus gets the status of the latest cashout transaction for the peers chequebook
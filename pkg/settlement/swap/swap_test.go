@@ -15,6 +15,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/holisticode/bee/pkg/crypto"
 	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/settlement/driver"
+	"github.com/holisticode/bee/pkg/settlement/history"
 	"github.com/holisticode/bee/pkg/settlement/swap"
 	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
 	mockchequebook "github.com/holisticode/bee/pkg/settlement/swap/chequebook/mock"
@@ -82,7 +85,7 @@ func (t *testObserver) NotifyPaymentSent(peer swarm.Address, amount *big.Int, er
 	}
 }
 
-func (t *testObserver) Connect(peer swarm.Address) {
+func (t *testObserver) Connect(peer p2p.Peer) {
 
 }
 
@@ -424,6 +427,75 @@ func TestPay(t *testing.T) {
 	}
 }
 
+func TestPayHistory(t *testing.T) {
+	logger := logging.New(io.Discard, 0)
+	store := mockstore.NewStateStore()
+
+	amount := big.NewInt(50)
+	beneficiary := common.HexToAddress("0xcd")
+	chequebookAddress := common.HexToAddress("0xee")
+	peer := swarm.MustParseHexAddress("abcd")
+
+	addressbook := &addressbookMock{
+		beneficiary: func(p swarm.Address) (common.Address, bool, error) {
+			return beneficiary, true, nil
+		},
+	}
+
+	// the cumulative payout of the last cheque increases from 100 to 600
+	payout := big.NewInt(100)
+	chequebookService := mockchequebook.NewChequebook(
+		mockchequebook.WithChequebookAddressFunc(func() common.Address {
+			return chequebookAddress
+		}),
+		mockchequebook.WithLastChequeFunc(func(b common.Address) (*chequebook.SignedCheque, error) {
+			if b != beneficiary {
+				t.Fatal("querying last cheque for wrong beneficiary")
+			}
+			return &chequebook.SignedCheque{
+				Cheque: chequebook.Cheque{CumulativePayout: new(big.Int).Set(payout)},
+			}, nil
+		}),
+	)
+
+	h := history.New(store, 0)
+
+	swap := swap.New(
+		&swapProtocolMock{
+			emitCheque: func(ctx context.Context, p swarm.Address, b common.Address, a *big.Int, issueFunc swapprotocol.IssueFunc) (*big.Int, error) {
+				payout = big.NewInt(600)
+				return amount, nil
+			},
+		},
+		logger,
+		store,
+		chequebookService,
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		1,
+		&cashoutMock{},
+		newTestObserver(),
+	)
+	swap.SetHistory(h)
+
+	swap.Pay(context.Background(), peer, amount)
+
+	events, err := h.Events(time.Time{}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want %d", len(events), 1)
+	}
+	e := events[0]
+	if e.Type != history.ChequeSent || !e.Peer.Equal(peer) || e.Chequebook != chequebookAddress {
+		t.Fatalf("got event %+v", e)
+	}
+	if e.Amount.Cmp(big.NewInt(500)) != 0 {
+		t.Fatalf("got amount %d, want %d", e.Amount, 500)
+	}
+}
+
 func TestPayIssueError(t *testing.T) {
 	logger := logging.New(io.Discard, 0)
 	store := mockstore.NewStateStore()
@@ -785,3 +857,10 @@ func TestStateStoreKeys(t *testing.T) {
 		t.Fatalf("wrong peer deducted for key. wanted %s, got %s", expected, swap.PeerDeductedForKey(swarmAddress))
 	}
 }
+
+func TestOpenWithoutChain(t *testing.T) {
+	_, err := driver.Open(swap.DriverName, driver.Options{})
+	if !errors.Is(err, swap.ErrNoChain) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrNoChain)
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package simulation runs a network of full bee nodes in a single process
+// over an in-memory transport. The links between the nodes have controllable
+// latency and loss and the network can be partitioned, which allows testing
+// protocol changes from go test without deploying a cluster.
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/swarm"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	defaultNetworkID        = 1
+	defaultPaymentThreshold = 13500000
+)
+
+// Link describes the conditions of the link between two nodes.
+type Link struct {
+	// Latency is the one way delay of connecting, opening streams and of
+	// every write on a stream.
+	Latency time.Duration
+	// Loss is the probability between 0 and 1 that opening a stream fails
+	// with ErrStreamDropped.
+	Loss float64
+}
+
+// Options configures a Network.
+type Options struct {
+	// NetworkID of the nodes, defaults to 1.
+	NetworkID uint64
+	// Link is the default condition of the links between the nodes.
+	Link Link
+	// Seed of the random source deciding about lost streams.
+	Seed int64
+	// PaymentThreshold of the accounting of the nodes, defaults to the one
+	// of bee.
+	PaymentThreshold *big.Int
+	// Logger of all the nodes, defaults to discarding the logs.
+	Logger logging.Logger
+}
+
+type linkKey struct {
+	a, b string
+}
+
+func newLinkKey(a, b swarm.Address) linkKey {
+	x, y := a.ByteString(), b.ByteString()
+	if x > y {
+		x, y = y, x
+	}
+	return linkKey{a: x, b: y}
+}
+
+// Network is a set of nodes connected over an in-memory transport.
+type Network struct {
+	networkID        uint64
+	paymentThreshold *big.Int
+	logger           logging.Logger
+
+	mtx         sync.RWMutex
+	nodes       []*Node
+	underlays   map[string]*transport
+	overlays    map[string]*transport
+	defaultLink Link
+	links       map[linkKey]Link
+	partitions  map[string]int // partition of every node, nil if not partitioned
+	severed     [][2]*Node     // pairs of nodes disconnected by the partition
+
+	randMtx sync.Mutex
+	rand    *rand.Rand
+}
+
+// New creates a new empty Network.
+func New(o Options) *Network {
+	if o.NetworkID == 0 {
+		o.NetworkID = defaultNetworkID
+	}
+	if o.PaymentThreshold == nil {
+		o.PaymentThreshold = big.NewInt(defaultPaymentThreshold)
+	}
+	if o.Logger == nil {
+		o.Logger = logging.New(io.Discard, 0)
+	}
+	return &Network{
+		networkID:        o.NetworkID,
+		paymentThreshold: o.PaymentThreshold,
+		logger:           o.Logger,
+		underlays:        make(map[string]*transport),
+		overlays:         make(map[string]*transport),
+		defaultLink:      o.Link,
+		links:            make(map[linkKey]Link),
+		rand:             rand.New(rand.NewSource(o.Seed)),
+	}
+}
+
+// AddNodes starts count new nodes. Every new node is introduced to the nodes
+// which are already part of the network, which nodes it connects to is up to
+// its topology.
+func (n *Network) AddNodes(count int) ([]*Node, error) {
+	nodes := make([]*Node, 0, count)
+	for i := 0; i < count; i++ {
+		node, err := n.addNode()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func (n *Network) addNode() (*Node, error) {
+	n.mtx.Lock()
+	index := len(n.nodes)
+	n.mtx.Unlock()
+
+	underlay, err := ma.NewMultiaddr(fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", 10000+index))
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := newNode(n, underlay)
+	if err != nil {
+		return nil, fmt.Errorf("node %d: %w", index, err)
+	}
+
+	n.mtx.Lock()
+	known := append([]*Node(nil), n.nodes...)
+	n.nodes = append(n.nodes, node)
+	n.underlays[underlay.String()] = node.transport
+	n.overlays[node.Overlay().ByteString()] = node.transport
+	n.mtx.Unlock()
+
+	if err := node.start(); err != nil {
+		return nil, fmt.Errorf("node %d: %w", index, err)
+	}
+	for _, peer := range known {
+		if err := node.Introduce(peer); err != nil {
+			return nil, fmt.Errorf("node %d: %w", index, err)
+		}
+	}
+	return node, nil
+}
+
+// Nodes returns the nodes of the network in the order they were added.
+func (n *Network) Nodes() []*Node {
+	n.mtx.RLock()
+	defer n.mtx.RUnlock()
+
+	return append([]*Node(nil), n.nodes...)
+}
+
+func (n *Network) transportByUnderlay(addr ma.Multiaddr) (*transport, bool) {
+	n.mtx.RLock()
+	defer n.mtx.RUnlock()
+
+	t, ok := n.underlays[addr.String()]
+	return t, ok
+}
+
+func (n *Network) transportByOverlay(overlay swarm.Address) (*transport, bool) {
+	n.mtx.RLock()
+	defer n.mtx.RUnlock()
+
+	t, ok := n.overlays[overlay.ByteString()]
+	return t, ok
+}
+
+func (n *Network) nodeByOverlay(overlay swarm.Address) (*Node, bool) {
+	n.mtx.RLock()
+	defer n.mtx.RUnlock()
+
+	for _, node := range n.nodes {
+		if node.Overlay().Equal(overlay) {
+			return node, true
+		}
+	}
+	return nil, false
+}
+
+// SetLink sets the conditions of the link between two nodes.
+func (n *Network) SetLink(a, b *Node, l Link) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	n.links[newLinkKey(a.Overlay(), b.Overlay())] = l
+}
+
+// link returns the conditions of the link between the nodes with the given
+// overlays or ErrLinkDown if they are in different partitions.
+func (n *Network) link(a, b swarm.Address) (Link, error) {
+	n.mtx.RLock()
+	defer n.mtx.RUnlock()
+
+	if n.partitions != nil && n.partitions[a.ByteString()] != n.partitions[b.ByteString()] {
+		return Link{}, ErrLinkDown
+	}
+	if l, ok := n.links[newLinkKey(a, b)]; ok {
+		return l, nil
+	}
+	return n.defaultLink, nil
+}
+
+// drop decides whether a stream over the link is lost.
+func (n *Network) drop(l Link) bool {
+	if l.Loss <= 0 {
+		return false
+	}
+	n.randMtx.Lock()
+	defer n.randMtx.Unlock()
+
+	return n.rand.Float64() < l.Loss
+}
+
+// Partition splits the network into the given groups of nodes. The nodes
+// which are not part of any group form one more group. Nodes in different
+// groups are disconnected and can not reach each other until Heal is called.
+func (n *Network) Partition(groups ...[]*Node) {
+	n.mtx.Lock()
+	n.partitions = make(map[string]int)
+	for i, group := range groups {
+		for _, node := range group {
+			n.partitions[node.Overlay().ByteString()] = i + 1
+		}
+	}
+	nodes := append([]*Node(nil), n.nodes...)
+	n.mtx.Unlock()
+
+	for _, node := range nodes {
+		for _, p := range node.transport.Peers() {
+			if _, err := n.link(node.Overlay(), p.Address); err == nil {
+				continue
+			}
+			if err := node.transport.Disconnect(p.Address, "network partition"); err != nil {
+				continue
+			}
+			if peer, ok := n.nodeByOverlay(p.Address); ok {
+				n.mtx.Lock()
+				n.severed = append(n.severed, [2]*Node{node, peer})
+				n.mtx.Unlock()
+			}
+		}
+	}
+}
+
+// Heal removes the partitions of the network and reconnects the nodes which
+// were disconnected by the partition. The accounting blocklists the peers
+// which were disconnected while in debt for a while, Heal waits until these
+// can be reconnected.
+func (n *Network) Heal(ctx context.Context) error {
+	n.mtx.Lock()
+	n.partitions = nil
+	severed := n.severed
+	n.severed = nil
+	n.mtx.Unlock()
+
+	for _, pair := range severed {
+		var err error
+		if werr := waitFor(ctx, func() bool {
+			err = pair[0].connect(ctx, pair[1])
+			return !errors.Is(err, errBlocklisted)
+		}); werr != nil {
+			err = werr
+		}
+		if err != nil {
+			return fmt.Errorf("reconnect %s to %s: %w", pair[0].Overlay(), pair[1].Overlay(), err)
+		}
+	}
+	return nil
+}
+
+// WaitConnected waits until the topology of every node is connected to at
+// least min peers.
+func (n *Network) WaitConnected(ctx context.Context, min int) error {
+	return waitFor(ctx, func() bool {
+		for _, node := range n.Nodes() {
+			if node.connectedPeers() < min {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Holders returns the nodes which store the chunk with the given address.
+func (n *Network) Holders(ctx context.Context, addr swarm.Address) ([]*Node, error) {
+	var holders []*Node
+	for _, node := range n.Nodes() {
+		has, err := node.storer.Has(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			holders = append(holders, node)
+		}
+	}
+	return holders, nil
+}
+
+// Closest returns the node with the overlay closest to the address.
+func (n *Network) Closest(addr swarm.Address) (*Node, error) {
+	var closest *Node
+	for _, node := range n.Nodes() {
+		if closest == nil {
+			closest = node
+			continue
+		}
+		closer, err := node.Overlay().Closer(addr, closest.Overlay())
+		if err != nil {
+			return nil, err
+		}
+		if closer {
+			closest = node
+		}
+	}
+	if closest == nil {
+		return nil, errNoNodes
+	}
+	return closest, nil
+}
+
+// CheckBalances checks that the accounting of every pair of connected nodes
+// agrees on the balance between them, i.e. that the balance one node keeps
+// for the other is the negation of the balance kept by the other one.
+func (n *Network) CheckBalances() error {
+	nodes := n.Nodes()
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			ab, err := a.Balance(b)
+			if err != nil {
+				return err
+			}
+			ba, err := b.Balance(a)
+			if err != nil {
+				return err
+			}
+			if new(big.Int).Add(ab, ba).Sign() != 0 {
+				return fmt.Errorf("%w: %s has %d for %s but %s has %d", ErrUnbalanced, a.Overlay(), ab, b.Overlay(), b.Overlay(), ba)
+			}
+		}
+	}
+	return nil
+}
+
+// Close shuts all the nodes of the network down.
+func (n *Network) Close() error {
+	var errs []error
+	for _, node := range n.Nodes() {
+		if err := node.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("close network: %v", errs)
+	}
+	return nil
+}
+
+// waitFor polls the condition until it holds or the context is done.
+func waitFor(ctx context.Context, cond func() bool) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for !cond() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
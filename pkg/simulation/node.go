@@ -0,0 +1,386 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/holisticode/bee/pkg/accounting"
+	"github.com/holisticode/bee/pkg/addressbook"
+	"github.com/holisticode/bee/pkg/bzz"
+	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/file/joiner"
+	"github.com/holisticode/bee/pkg/file/pipeline/builder"
+	"github.com/holisticode/bee/pkg/hive"
+	"github.com/holisticode/bee/pkg/localstore"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/netstore"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/pingpong"
+	"github.com/holisticode/bee/pkg/postage"
+	postagetesting "github.com/holisticode/bee/pkg/postage/testing"
+	"github.com/holisticode/bee/pkg/pricer"
+	"github.com/holisticode/bee/pkg/pricing"
+	"github.com/holisticode/bee/pkg/puller"
+	"github.com/holisticode/bee/pkg/pullsync"
+	"github.com/holisticode/bee/pkg/pullsync/pullstorage"
+	"github.com/holisticode/bee/pkg/pusher"
+	"github.com/holisticode/bee/pkg/pushsync"
+	"github.com/holisticode/bee/pkg/retrieval"
+	"github.com/holisticode/bee/pkg/sctx"
+	"github.com/holisticode/bee/pkg/settlement/pseudosettle"
+	"github.com/holisticode/bee/pkg/shed"
+	mockstate "github.com/holisticode/bee/pkg/statestore/mock"
+	"github.com/holisticode/bee/pkg/storage"
+	"github.com/holisticode/bee/pkg/swarm"
+	"github.com/holisticode/bee/pkg/tags"
+	"github.com/holisticode/bee/pkg/topology"
+	"github.com/holisticode/bee/pkg/topology/kademlia"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	refreshRate      = int64(4500000)
+	lightRefreshRate = int64(450000)
+	basePrice        = 10000
+	reserveCapacity  = 1 << 20
+)
+
+var (
+	// ErrUnbalanced is returned by CheckBalances if two nodes disagree on
+	// the balance between them.
+	ErrUnbalanced = errors.New("unbalanced")
+
+	errNoNodes = errors.New("no nodes")
+
+	// the block hash the overlays of all the nodes are derived from
+	blockHash = make([]byte, 32)
+)
+
+// Node is a full bee node running the topology, syncing, retrieval and
+// accounting protocols over the in-memory transport.
+type Node struct {
+	overlay     swarm.Address
+	logger      logging.Logger
+	transport   *transport
+	stateStore  storage.StateStorer
+	addressbook addressbook.Interface
+	metricsDB   *shed.DB
+	storer      *localstore.DB
+	netstore    storage.Storer
+	tags        *tags.Tags
+	hive        *hive.Service
+	kad         *kademlia.Kad
+	accounting  *accounting.Accounting
+	pullSync    *pullsync.Syncer
+	puller      *puller.Puller
+	pusher      *pusher.Service
+}
+
+// validStamp accepts any well formed stamp. The nodes of a simulation do not
+// follow a blockchain so there are no batches to validate the stamps against.
+// The stamp is attached to a copy of the chunk as the protocols keep using
+// the passed chunk concurrently.
+func validStamp(ch swarm.Chunk, stampBytes []byte) (swarm.Chunk, error) {
+	stamp := new(postage.Stamp)
+	if err := stamp.UnmarshalBinary(stampBytes); err != nil {
+		return nil, err
+	}
+	return swarm.NewChunk(ch.Address(), ch.Data()).WithTagID(ch.TagID()).WithStamp(stamp), nil
+}
+
+func newNode(network *Network, underlay ma.Multiaddr) (n *Node, err error) {
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		return nil, err
+	}
+	signer := crypto.NewDefaultSigner(pk)
+
+	overlay, err := crypto.NewOverlayAddress(pk.PublicKey, network.networkID, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	bzzAddress, err := bzz.NewAddress(signer, underlay, overlay, network.networkID, nil)
+	if err != nil {
+		return nil, err
+	}
+	bzzAddress.EthereumAddress, err = crypto.NewEthereumAddress(pk.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	n = &Node{
+		overlay:    overlay,
+		logger:     network.logger,
+		stateStore: mockstate.NewStateStore(),
+	}
+	defer func() {
+		if err != nil {
+			_ = n.close()
+		}
+	}()
+
+	n.addressbook = addressbook.New(n.stateStore)
+	n.transport = newTransport(network, *bzzAddress, true, n.addressbook, n.logger)
+
+	n.metricsDB, err = shed.NewDB("", nil)
+	if err != nil {
+		return nil, fmt.Errorf("metrics db: %w", err)
+	}
+
+	n.tags = tags.NewTags(n.stateStore, n.logger)
+	// like in bee the tags are not passed to the localstore, the pusher
+	// counts the synced chunks
+	n.storer, err = localstore.New("", overlay.Bytes(), n.stateStore, &localstore.Options{
+		ReserveCapacity: reserveCapacity,
+	}, n.logger)
+	if err != nil {
+		return nil, fmt.Errorf("localstore: %w", err)
+	}
+
+	pingPong := pingpong.New(n.transport, n.logger, nil)
+	if err = n.transport.AddProtocol(pingPong.Protocol()); err != nil {
+		return nil, fmt.Errorf("pingpong service: %w", err)
+	}
+
+	n.hive, err = hive.New(n.transport, n.addressbook, network.networkID, false, true, n.logger)
+	if err != nil {
+		return nil, fmt.Errorf("hive: %w", err)
+	}
+	if err = n.transport.AddProtocol(n.hive.Protocol()); err != nil {
+		return nil, fmt.Errorf("hive service: %w", err)
+	}
+
+	n.kad, err = kademlia.New(overlay, n.addressbook, n.hive, n.transport, pingPong, n.metricsDB, n.logger, kademlia.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("kademlia: %w", err)
+	}
+	n.hive.SetAddPeersHandler(n.kad.AddPeers)
+
+	minThreshold := big.NewInt(2 * refreshRate)
+	pricingService := pricing.New(n.transport, n.logger, network.paymentThreshold, minThreshold)
+	if err = n.transport.AddProtocol(pricingService.Protocol()); err != nil {
+		return nil, fmt.Errorf("pricing service: %w", err)
+	}
+
+	n.accounting, err = accounting.NewAccounting(network.paymentThreshold, 25, 50, n.logger, n.stateStore, pricingService, big.NewInt(refreshRate), n.transport)
+	if err != nil {
+		return nil, fmt.Errorf("accounting: %w", err)
+	}
+	pricingService.SetPaymentThresholdObserver(n.accounting)
+
+	pseudosettleService := pseudosettle.New(n.transport, n.logger, n.stateStore, n.accounting, big.NewInt(refreshRate), big.NewInt(lightRefreshRate), n.transport)
+	if err = n.transport.AddProtocol(pseudosettleService.Protocol()); err != nil {
+		return nil, fmt.Errorf("pseudosettle service: %w", err)
+	}
+	n.accounting.SetRefreshFunc(pseudosettleService.Pay)
+
+	chunkPricer := pricer.NewFixedPricer(overlay, basePrice)
+	unwrap := func(swarm.Chunk) {}
+
+	retrieve := retrieval.New(overlay, n.storer, n.transport, n.kad, n.logger, n.accounting, chunkPricer, nil, false, validStamp)
+	if err = n.transport.AddProtocol(retrieve.Protocol()); err != nil {
+		return nil, fmt.Errorf("retrieval service: %w", err)
+	}
+	n.netstore = netstore.New(n.storer, validStamp, nil, retrieve, n.logger)
+
+	pushSync := pushsync.New(overlay, blockHash, n.transport, n.storer, n.kad, n.tags, true, unwrap, validStamp, n.logger, n.accounting, chunkPricer, signer, nil, 0)
+	if err = n.transport.AddProtocol(pushSync.Protocol()); err != nil {
+		return nil, fmt.Errorf("pushsync service: %w", err)
+	}
+	n.pusher = pusher.New(network.networkID, n.storer, n.kad, pushSync, validStamp, n.tags, n.logger, nil, 0)
+
+	n.pullSync = pullsync.New(n.transport, pullstorage.New(n.storer), unwrap, validStamp, n.logger)
+	if err = n.transport.AddProtocol(n.pullSync.Protocol()); err != nil {
+		return nil, fmt.Errorf("pullsync service: %w", err)
+	}
+	n.puller = puller.New(n.stateStore, n.kad, n.pullSync, n.logger, puller.Options{}, 0)
+
+	return n, nil
+}
+
+// start starts connecting the node to the network.
+func (n *Node) start() error {
+	n.transport.SetPickyNotifier(n.kad)
+	return n.kad.Start(context.Background())
+}
+
+// Overlay returns the overlay address of the node.
+func (n *Node) Overlay() swarm.Address {
+	return n.overlay
+}
+
+// Topology returns the kademlia of the node.
+func (n *Node) Topology() *kademlia.Kad {
+	return n.kad
+}
+
+// Accounting returns the accounting of the node.
+func (n *Node) Accounting() *accounting.Accounting {
+	return n.accounting
+}
+
+// Storer returns the local store of the node.
+func (n *Node) Storer() storage.Storer {
+	return n.storer
+}
+
+// connectedPeers returns the number of peers the topology of the node is
+// connected to.
+func (n *Node) connectedPeers() int {
+	var count int
+	_ = n.kad.EachPeer(func(swarm.Address, uint8) (bool, bool, error) {
+		count++
+		return false, false, nil
+	}, topology.Filter{})
+	return count
+}
+
+// Introduce makes the peer known to the node, the node connects to it if its
+// topology wants to.
+func (n *Node) Introduce(peer *Node) error {
+	if err := n.addressbook.Put(peer.Overlay(), peer.transport.address); err != nil {
+		return err
+	}
+	n.kad.AddPeers(peer.Overlay())
+	return nil
+}
+
+// connect connects the node to the peer unless they are connected already
+// and lets the topology of the node know about it, as it does when the
+// topology dials itself.
+func (n *Node) connect(ctx context.Context, peer *Node) error {
+	if n.transport.connected(peer.Overlay()) {
+		return nil
+	}
+	if _, err := n.transport.Connect(ctx, peer.transport.address.Underlay); err != nil {
+		if errors.Is(err, p2p.ErrAlreadyConnected) {
+			return nil
+		}
+		return err
+	}
+	if err := n.kad.Connected(ctx, p2p.Peer{Address: peer.Overlay(), FullNode: true}, true); err != nil {
+		_ = n.transport.Disconnect(peer.Overlay(), "topology rejected peer")
+		return err
+	}
+	return nil
+}
+
+// Upload splits the data into chunks, stores them on the node and waits
+// until all of them have been pushed to the network. It returns the address
+// of the root chunk.
+func (n *Node) Upload(ctx context.Context, data []byte) (swarm.Address, error) {
+	tag, err := n.tags.Create(0)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ctx = sctx.SetTag(ctx, tag)
+
+	pipe := builder.NewPipelineBuilder(ctx, stamper{n.storer}, storage.ModePutUpload, false)
+	addr, err := builder.FeedPipeline(ctx, pipe, bytes.NewReader(data))
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("upload: %w", err)
+	}
+	if _, err := tag.DoneSplit(addr); err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("upload: %w", err)
+	}
+	if err := tag.WaitTillDone(ctx, tags.StateSynced); err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("upload: wait for sync: %w", err)
+	}
+	return addr, nil
+}
+
+// Download retrieves the data with the given root address from the network.
+func (n *Node) Download(ctx context.Context, addr swarm.Address) ([]byte, error) {
+	j, _, err := joiner.New(ctx, n.netstore, addr)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	data, err := io.ReadAll(j)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	return data, nil
+}
+
+// Balance returns the balance the accounting of the node keeps for the peer.
+// It is zero if the node never exchanged services with the peer.
+func (n *Node) Balance(peer *Node) (*big.Int, error) {
+	balance, err := n.accounting.Balance(peer.Overlay())
+	if err != nil {
+		if errors.Is(err, accounting.ErrPeerNoBalance) {
+			return big.NewInt(0), nil
+		}
+		return nil, err
+	}
+	return balance, nil
+}
+
+// close shuts the node down.
+func (n *Node) close() error {
+	var errs []error
+	tryClose := func(c io.Closer, name string) {
+		if c == nil {
+			return
+		}
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if n.pusher != nil {
+		tryClose(n.pusher, "pusher")
+	}
+	if n.puller != nil {
+		tryClose(n.puller, "puller")
+	}
+	if n.pullSync != nil {
+		tryClose(n.pullSync, "pullsync")
+	}
+	if n.transport != nil {
+		n.transport.close()
+	}
+	if n.kad != nil {
+		tryClose(n.kad, "kademlia")
+	}
+	if n.hive != nil {
+		tryClose(n.hive, "hive")
+	}
+	if n.accounting != nil {
+		tryClose(n.accounting, "accounting")
+	}
+	if n.netstore != nil {
+		tryClose(n.netstore, "netstore")
+	}
+	if n.storer != nil {
+		tryClose(n.storer, "localstore")
+	}
+	if n.metricsDB != nil {
+		tryClose(n.metricsDB, "metrics db")
+	}
+	tryClose(n.stateStore, "statestore")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("close node %s: %v", n.overlay, errs)
+	}
+	return nil
+}
+
+// stamper attaches a stamp to every chunk put into the store.
+type stamper struct {
+	storage.Storer
+}
+
+func (s stamper) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) ([]bool, error) {
+	for i, ch := range chs {
+		chs[i] = ch.WithStamp(postagetesting.MustNewStamp())
+	}
+	return s.Storer.Put(ctx, mode, chs...)
+}
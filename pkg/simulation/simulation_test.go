@@ -0,0 +1,139 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simulation_test
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/holisticode/bee/pkg/simulation"
+	"github.com/holisticode/bee/pkg/swarm"
+)
+
+func newNetwork(t *testing.T, count int, o simulation.Options) (*simulation.Network, []*simulation.Node) {
+	t.Helper()
+
+	n := simulation.New(o)
+	t.Cleanup(func() {
+		if err := n.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	nodes, err := n.AddNodes(count)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := n.WaitConnected(ctx, count-1); err != nil {
+		t.Fatalf("wait connected: %v", err)
+	}
+	return n, nodes
+}
+
+func randomData(t *testing.T, size int) []byte {
+	t.Helper()
+
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestUploadDownload(t *testing.T) {
+	n, nodes := newNetwork(t, 5, simulation.Options{
+		Link: simulation.Link{Latency: time.Millisecond},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	data := randomData(t, 3*swarm.ChunkSize)
+	addr, err := nodes[0].Upload(ctx, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the chunk is stored by the node closest to it
+	closest, err := n.Closest(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	holders, err := n.Holders(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, h := range holders {
+		if h == closest {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("chunk %s not stored by the closest node %s", addr, closest.Overlay())
+	}
+
+	got, err := nodes[len(nodes)-1].Download(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("downloaded data differs from the uploaded data")
+	}
+
+	// the debits of the last deliveries may still be in flight
+	var balanceErr error
+	for i := 0; i < 20; i++ {
+		if balanceErr = n.CheckBalances(); balanceErr == nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if balanceErr != nil {
+		t.Fatal(balanceErr)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	n, nodes := newNetwork(t, 4, simulation.Options{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// the chunk stays within the partition of the uploader
+	n.Partition(nodes[:2], nodes[2:])
+
+	data := randomData(t, swarm.ChunkSize)
+	addr, err := nodes[0].Upload(ctx, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shortCtx, shortCancel := context.WithTimeout(ctx, time.Second)
+	defer shortCancel()
+	if _, err := nodes[3].Download(shortCtx, addr); err == nil {
+		t.Fatal("downloaded the chunk across a partition")
+	}
+
+	if err := n.Heal(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.WaitConnected(ctx, len(nodes)-1); err != nil {
+		t.Fatalf("wait connected after heal: %v", err)
+	}
+
+	got, err := nodes[3].Download(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("downloaded data differs from the uploaded data")
+	}
+}
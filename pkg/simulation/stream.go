@@ -0,0 +1,203 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simulation
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/holisticode/bee/pkg/p2p"
+)
+
+var (
+	// ErrStreamReset is returned by the reads and writes on a stream which
+	// has been reset by either side.
+	ErrStreamReset = errors.New("stream reset")
+	// ErrStreamClosed is returned by the writes on a stream which has been
+	// closed for writing.
+	ErrStreamClosed = errors.New("stream closed")
+	// ErrFullCloseTimeout is returned by FullClose if the other side does
+	// not close the stream in time.
+	ErrFullCloseTimeout = errors.New("full close timeout")
+
+	// timeout of waiting for the other side to close the stream in FullClose
+	fullCloseTimeout = 5 * time.Second
+)
+
+// segment is a write which is delivered to the reader once it is due.
+type segment struct {
+	data []byte
+	due  time.Time
+}
+
+// pipe is a one way, unbounded in-memory buffer which delays every write by
+// the latency of the link.
+type pipe struct {
+	mtx      sync.Mutex
+	cond     *sync.Cond
+	segments []segment
+	latency  time.Duration
+	closed   bool
+	closeDue time.Time
+	err      error
+}
+
+func newPipe(latency time.Duration) *pipe {
+	p := &pipe{latency: latency}
+	p.cond = sync.NewCond(&p.mtx)
+	return p
+}
+
+func (p *pipe) Read(b []byte) (int, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for {
+		if p.err != nil {
+			return 0, p.err
+		}
+		now := time.Now()
+		if len(p.segments) > 0 {
+			s := &p.segments[0]
+			if wait := s.due.Sub(now); wait > 0 {
+				p.sleep(wait)
+				continue
+			}
+			n := copy(b, s.data)
+			s.data = s.data[n:]
+			if len(s.data) == 0 {
+				p.segments = p.segments[1:]
+			}
+			return n, nil
+		}
+		if p.closed {
+			if wait := p.closeDue.Sub(now); wait > 0 {
+				p.sleep(wait)
+				continue
+			}
+			return 0, io.EOF
+		}
+		p.cond.Wait()
+	}
+}
+
+// sleep releases the lock for the given duration. Must be called with the
+// lock held.
+func (p *pipe) sleep(d time.Duration) {
+	p.mtx.Unlock()
+	time.Sleep(d)
+	p.mtx.Lock()
+}
+
+func (p *pipe) Write(b []byte) (int, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.err != nil {
+		return 0, p.err
+	}
+	if p.closed {
+		return 0, ErrStreamClosed
+	}
+	data := make([]byte, len(b))
+	copy(data, b)
+	p.segments = append(p.segments, segment{data: data, due: time.Now().Add(p.latency)})
+	p.cond.Broadcast()
+	return len(b), nil
+}
+
+// close closes the pipe for writing, the reader gets io.EOF once all the
+// written data is read.
+func (p *pipe) close() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.closed = true
+	p.closeDue = time.Now().Add(p.latency)
+	p.cond.Broadcast()
+}
+
+// reset discards the buffered data and fails all the following reads and
+// writes.
+func (p *pipe) reset() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.err == nil {
+		p.err = ErrStreamReset
+	}
+	p.segments = nil
+	p.cond.Broadcast()
+}
+
+// stream is one side of a bidirectional in-memory stream.
+type stream struct {
+	in              *pipe
+	out             *pipe
+	headers         p2p.Headers
+	responseHeaders p2p.Headers
+}
+
+// newStreamPair returns both sides of a stream over a link with the given
+// latency.
+func newStreamPair(latency time.Duration) (local, remote *stream) {
+	a, b := newPipe(latency), newPipe(latency)
+	return &stream{in: a, out: b}, &stream{in: b, out: a}
+}
+
+func (s *stream) Read(b []byte) (int, error) {
+	return s.in.Read(b)
+}
+
+func (s *stream) Write(b []byte) (int, error) {
+	return s.out.Write(b)
+}
+
+func (s *stream) Headers() p2p.Headers {
+	return s.headers
+}
+
+func (s *stream) ResponseHeaders() p2p.Headers {
+	return s.responseHeaders
+}
+
+// Close closes the stream for writing.
+func (s *stream) Close() error {
+	s.out.close()
+	return nil
+}
+
+// FullClose closes the stream for writing and waits for the other side to
+// close it too.
+func (s *stream) FullClose() error {
+	s.out.close()
+
+	// discard what the other side still writes until it closes
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, s.in)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(fullCloseTimeout):
+		s.in.reset()
+		return ErrFullCloseTimeout
+	}
+}
+
+// Reset aborts the stream in both directions.
+func (s *stream) Reset() error {
+	s.in.reset()
+	s.out.reset()
+	return nil
+}
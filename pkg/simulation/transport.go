@@ -0,0 +1,484 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/holisticode/bee/pkg/addressbook"
+	"github.com/holisticode/bee/pkg/bzz"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/p2p"
+	"github.com/holisticode/bee/pkg/swarm"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+var (
+	// ErrLinkDown is returned if two nodes are in different partitions.
+	ErrLinkDown = errors.New("link down")
+	// ErrStreamDropped is returned if a stream is lost on the link.
+	ErrStreamDropped = errors.New("stream dropped")
+
+	errUnknownUnderlay = errors.New("unknown underlay")
+	errHalted          = errors.New("halted")
+	errNotPicked       = errors.New("peer not picked")
+	errBlocklisted     = errors.New("peer blocklisted")
+)
+
+var (
+	_ p2p.Service              = (*transport)(nil)
+	_ p2p.StreamerDisconnecter = (*transport)(nil)
+	_ p2p.StreamerPinger       = (*transport)(nil)
+)
+
+// blocklistEntry is a blocklisted peer. The zero until never expires.
+type blocklistEntry struct {
+	peer  p2p.Peer
+	until time.Time
+}
+
+// transport is the in-memory p2p service of a node. It connects to and opens
+// streams on the transports of the other nodes in the network directly,
+// subject to the conditions of the links between them.
+type transport struct {
+	network     *Network
+	address     bzz.Address
+	fullNode    bool
+	addressbook addressbook.Putter
+	logger      logging.Logger
+	ctx         context.Context
+	cancel      context.CancelFunc
+
+	mtx       sync.RWMutex
+	protocols []p2p.ProtocolSpec
+	notifier  p2p.PickyNotifier
+	peers     map[string]p2p.Peer
+	blocklist map[string]blocklistEntry
+	halted    bool
+}
+
+func newTransport(network *Network, address bzz.Address, fullNode bool, addressbook addressbook.Putter, logger logging.Logger) *transport {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &transport{
+		network:     network,
+		address:     address,
+		fullNode:    fullNode,
+		addressbook: addressbook,
+		logger:      logger,
+		ctx:         ctx,
+		cancel:      cancel,
+		peers:       make(map[string]p2p.Peer),
+		blocklist:   make(map[string]blocklistEntry),
+	}
+}
+
+func (t *transport) overlay() swarm.Address {
+	return t.address.Overlay
+}
+
+func (t *transport) peer() p2p.Peer {
+	return p2p.Peer{
+		Address:         t.address.Overlay,
+		FullNode:        t.fullNode,
+		EthereumAddress: t.address.EthereumAddress,
+	}
+}
+
+func (t *transport) AddProtocol(p p2p.ProtocolSpec) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.protocols = append(t.protocols, p)
+	return nil
+}
+
+func (t *transport) protocolsCopy() []p2p.ProtocolSpec {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	return append([]p2p.ProtocolSpec(nil), t.protocols...)
+}
+
+func (t *transport) SetPickyNotifier(n p2p.PickyNotifier) {
+	t.mtx.Lock()
+	t.notifier = n
+	t.mtx.Unlock()
+
+	// every node in the simulation is reachable by all the others
+	n.UpdateReachability(p2p.ReachabilityStatusPublic)
+}
+
+func (t *transport) getNotifier() p2p.PickyNotifier {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	return t.notifier
+}
+
+// blocked reports whether the peer is blocklisted. Must be called with the
+// lock held.
+func (t *transport) blocked(overlay swarm.Address) bool {
+	e, ok := t.blocklist[overlay.ByteString()]
+	if !ok {
+		return false
+	}
+	return e.until.IsZero() || time.Now().Before(e.until)
+}
+
+func (t *transport) connected(overlay swarm.Address) bool {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	_, ok := t.peers[overlay.ByteString()]
+	return ok
+}
+
+func (t *transport) Connect(ctx context.Context, addr ma.Multiaddr) (*bzz.Address, error) {
+	remote, ok := t.network.transportByUnderlay(addr)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownUnderlay, addr)
+	}
+	if t.connected(remote.overlay()) {
+		return &remote.address, p2p.ErrAlreadyConnected
+	}
+	if !remote.fullNode {
+		return nil, p2p.ErrDialLightNode
+	}
+
+	link, err := t.network.link(t.overlay(), remote.overlay())
+	if err != nil {
+		return nil, err
+	}
+	if err := sleep(ctx, link.Latency); err != nil {
+		return nil, err
+	}
+
+	t.mtx.Lock()
+	if t.blocked(remote.overlay()) {
+		t.mtx.Unlock()
+		return nil, errBlocklisted
+	}
+	t.mtx.Unlock()
+
+	if err := remote.accept(t.peer()); err != nil {
+		return nil, fmt.Errorf("handshake: %w", err)
+	}
+
+	t.mtx.Lock()
+	t.peers[remote.overlay().ByteString()] = remote.peer()
+	t.mtx.Unlock()
+
+	if err := t.addressbook.Put(remote.overlay(), remote.address); err != nil {
+		_ = t.Disconnect(remote.overlay(), "failed storing peer in addressbook")
+		return nil, fmt.Errorf("storing bzz address: %w", err)
+	}
+
+	for _, p := range t.protocolsCopy() {
+		if p.ConnectOut == nil {
+			continue
+		}
+		if err := p.ConnectOut(ctx, remote.peer()); err != nil {
+			_ = t.Disconnect(remote.overlay(), "failed to process outbound connection notifier")
+			return nil, fmt.Errorf("connectOut: protocol: %s, version:%s: %w", p.Name, p.Version, err)
+		}
+	}
+
+	// every node of the simulation is reachable, report it right away so
+	// that the topology gossips about the peer from the start
+	t.reachable(remote.overlay())
+	go remote.connectedIn(t)
+
+	t.logger.Debugf("simulation: %s connected to peer %s (outbound)", t.overlay(), remote.overlay())
+	return &remote.address, nil
+}
+
+// accept registers an inbound connection from the peer.
+func (t *transport) accept(peer p2p.Peer) error {
+	t.mtx.RLock()
+	halted, blocked, notifier := t.halted, t.blocked(peer.Address), t.notifier
+	t.mtx.RUnlock()
+
+	if halted {
+		return errHalted
+	}
+	if blocked {
+		return errBlocklisted
+	}
+	if notifier != nil && !notifier.Pick(peer) {
+		return errNotPicked
+	}
+
+	t.mtx.Lock()
+	t.peers[peer.Address.ByteString()] = peer
+	t.mtx.Unlock()
+	return nil
+}
+
+// connectedIn notifies the protocols and the topology of the inbound
+// connection from the remote transport.
+func (t *transport) connectedIn(remote *transport) {
+	peer := remote.peer()
+
+	if err := t.addressbook.Put(peer.Address, remote.address); err != nil {
+		_ = t.Disconnect(peer.Address, "unable to persist peer in addressbook")
+		return
+	}
+
+	for _, p := range t.protocolsCopy() {
+		if p.ConnectIn == nil {
+			continue
+		}
+		if err := p.ConnectIn(t.ctx, peer); err != nil {
+			t.logger.Debugf("simulation: connectIn: protocol: %s, version:%s, peer: %s: %v", p.Name, p.Version, peer.Address, err)
+			_ = t.Disconnect(peer.Address, "failed to process inbound connection notifier")
+			return
+		}
+	}
+
+	t.reachable(peer.Address)
+
+	if n := t.getNotifier(); n != nil {
+		if err := n.Connected(t.ctx, peer, false); err != nil {
+			t.logger.Debugf("simulation: notifier.Connected: %s: %v", peer.Address, err)
+			_ = t.Disconnect(peer.Address, "unable to signal connection notifier")
+			return
+		}
+	}
+
+	t.logger.Debugf("simulation: %s connected to peer %s (inbound)", t.overlay(), peer.Address)
+}
+
+// reachable notifies the topology that a connected peer is reachable.
+func (t *transport) reachable(overlay swarm.Address) {
+	if n := t.getNotifier(); n != nil && t.connected(overlay) {
+		n.Reachable(overlay, p2p.ReachabilityStatusPublic)
+	}
+}
+
+func (t *transport) Disconnect(overlay swarm.Address, reason string) error {
+	t.logger.Tracef("simulation: %s disconnecting peer %s reason: %s", t.overlay(), overlay, reason)
+
+	t.mtx.Lock()
+	peer, found := t.peers[overlay.ByteString()]
+	delete(t.peers, overlay.ByteString())
+	t.mtx.Unlock()
+
+	if !found {
+		return p2p.ErrPeerNotFound
+	}
+
+	for _, p := range t.protocolsCopy() {
+		if p.DisconnectOut == nil {
+			continue
+		}
+		if err := p.DisconnectOut(peer); err != nil {
+			t.logger.Debugf("simulation: disconnectOut: protocol: %s, version:%s, peer: %s: %v", p.Name, p.Version, overlay, err)
+		}
+	}
+	if n := t.getNotifier(); n != nil {
+		n.Disconnected(peer)
+	}
+
+	if remote, ok := t.network.transportByOverlay(overlay); ok {
+		go remote.disconnected(t.overlay())
+	}
+	return nil
+}
+
+// disconnected handles the connection closed by the peer.
+func (t *transport) disconnected(overlay swarm.Address) {
+	t.mtx.Lock()
+	peer, found := t.peers[overlay.ByteString()]
+	delete(t.peers, overlay.ByteString())
+	t.mtx.Unlock()
+
+	if !found {
+		return
+	}
+
+	for _, p := range t.protocolsCopy() {
+		if p.DisconnectIn == nil {
+			continue
+		}
+		if err := p.DisconnectIn(peer); err != nil {
+			t.logger.Debugf("simulation: disconnectIn: protocol: %s, version:%s, peer: %s: %v", p.Name, p.Version, overlay, err)
+		}
+	}
+	if n := t.getNotifier(); n != nil {
+		n.Disconnected(peer)
+	}
+}
+
+func (t *transport) Blocklist(overlay swarm.Address, duration time.Duration, reason string) error {
+	t.logger.Tracef("simulation: %s blocklisting peer %s for %v reason: %s", t.overlay(), overlay, duration, reason)
+
+	t.mtx.Lock()
+	e := blocklistEntry{peer: p2p.Peer{Address: overlay}}
+	if p, ok := t.peers[overlay.ByteString()]; ok {
+		e.peer = p
+	}
+	if duration > 0 {
+		e.until = time.Now().Add(duration)
+	}
+	t.blocklist[overlay.ByteString()] = e
+	t.mtx.Unlock()
+
+	if err := t.Disconnect(overlay, reason); err != nil && !errors.Is(err, p2p.ErrPeerNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (t *transport) Peers() []p2p.Peer {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	peers := make([]p2p.Peer, 0, len(t.peers))
+	for _, p := range t.peers {
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+func (t *transport) BlocklistedPeers() ([]p2p.Peer, error) {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	var peers []p2p.Peer
+	for _, e := range t.blocklist {
+		if t.blocked(e.peer.Address) {
+			peers = append(peers, e.peer)
+		}
+	}
+	return peers, nil
+}
+
+func (t *transport) Addresses() ([]ma.Multiaddr, error) {
+	return []ma.Multiaddr{t.address.Underlay}, nil
+}
+
+func (t *transport) Halt() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.halted = true
+}
+
+func (t *transport) NewStream(ctx context.Context, overlay swarm.Address, headers p2p.Headers, protocolName, protocolVersion, streamName string) (p2p.Stream, error) {
+	if !t.connected(overlay) {
+		return nil, p2p.ErrPeerNotFound
+	}
+	remote, ok := t.network.transportByOverlay(overlay)
+	if !ok || !remote.connected(t.overlay()) {
+		return nil, p2p.ErrPeerNotFound
+	}
+
+	link, err := t.network.link(t.overlay(), overlay)
+	if err != nil {
+		return nil, err
+	}
+	if t.network.drop(link) {
+		return nil, ErrStreamDropped
+	}
+
+	var (
+		spec  p2p.StreamSpec
+		found bool
+	)
+	for _, p := range remote.protocolsCopy() {
+		if p.Name != protocolName || p.Version != protocolVersion {
+			continue
+		}
+		for _, ss := range p.StreamSpecs {
+			if ss.Name == streamName {
+				spec, found = ss, true
+			}
+		}
+	}
+	if !found {
+		return nil, p2p.NewIncompatibleStreamError(fmt.Errorf("stream %s not supported", p2p.NewSwarmStreamName(protocolName, protocolVersion, streamName)))
+	}
+
+	if err := sleep(ctx, link.Latency); err != nil {
+		return nil, err
+	}
+
+	local, remoteStream := newStreamPair(link.Latency)
+	remoteStream.headers = headers
+	if spec.Headler != nil {
+		local.responseHeaders = spec.Headler(headers, t.overlay())
+		remoteStream.responseHeaders = local.responseHeaders
+	}
+
+	go remote.handle(protocolName, spec, t.peer(), remoteStream)
+
+	return local, nil
+}
+
+// handle runs the handler of an inbound stream and sanctions the peer on the
+// errors which ask for it.
+func (t *transport) handle(protocolName string, spec p2p.StreamSpec, peer p2p.Peer, s *stream) {
+	err := spec.Handler(t.ctx, peer, s)
+	if err == nil {
+		return
+	}
+	_ = s.Reset()
+
+	var de *p2p.DisconnectError
+	if errors.As(err, &de) {
+		_ = t.Disconnect(peer.Address, de.Error())
+	}
+	var bpe *p2p.BlockPeerError
+	if errors.As(err, &bpe) {
+		if err := t.Blocklist(peer.Address, bpe.Duration(), bpe.Error()); err != nil {
+			t.logger.Debugf("simulation: blocklist peer %s: %v", peer.Address, err)
+		}
+	}
+	t.logger.Debugf("simulation: could not handle protocol %s: stream %s: peer %s: error: %v", protocolName, spec.Name, peer.Address, err)
+}
+
+func (t *transport) Ping(ctx context.Context, addr ma.Multiaddr) (time.Duration, error) {
+	remote, ok := t.network.transportByUnderlay(addr)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", errUnknownUnderlay, addr)
+	}
+	link, err := t.network.link(t.overlay(), remote.overlay())
+	if err != nil {
+		return 0, err
+	}
+	if err := sleep(ctx, 2*link.Latency); err != nil {
+		return 0, err
+	}
+	return 2 * link.Latency, nil
+}
+
+// close disconnects all the peers and cancels the running handlers.
+func (t *transport) close() {
+	t.Halt()
+	for _, p := range t.Peers() {
+		_ = t.Disconnect(p.Address, "shutting down")
+	}
+	t.cancel()
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -69,6 +69,12 @@ type Tag struct {
 	spanOnce   sync.Once           // make sure we close root span only once
 	stateStore storage.StateStorer // to persist the tag
 	logger     logging.Logger      // logger instance for logging
+
+	subsMu sync.Mutex                 // guards subs
+	subs   map[chan struct{}]struct{} // change notification subscribers
+
+	budgetMu sync.Mutex // guards budget
+	budget   string     // key of the spending budget pushing the chunks is charged to
 }
 
 // NewTag creates a new tag, and returns it
@@ -99,6 +105,22 @@ func (t *Tag) FinishRootSpan() {
 	})
 }
 
+// SetBudget sets the key of the spending budget pushing the chunks of the
+// tag is charged to.
+func (t *Tag) SetBudget(key string) {
+	t.budgetMu.Lock()
+	defer t.budgetMu.Unlock()
+	t.budget = key
+}
+
+// Budget returns the key of the spending budget pushing the chunks of the
+// tag is charged to, if any.
+func (t *Tag) Budget() string {
+	t.budgetMu.Lock()
+	defer t.budgetMu.Unlock()
+	return t.budget
+}
+
 // IncN increments the count for a state
 func (t *Tag) IncN(state State, n int64) error {
 	var v *int64
@@ -117,6 +139,7 @@ func (t *Tag) IncN(state State, n int64) error {
 		v = &t.Synced
 	}
 	atomic.AddInt64(v, n)
+	t.notify()
 
 	// check if syncing is over and persist the tag
 	if state == StateSynced {
@@ -183,6 +206,40 @@ func (t *Tag) WaitTillDone(ctx context.Context, s State) error {
 	}
 }
 
+// Subscribe returns a channel which receives a value whenever the counters
+// of the tag change. Notifications are coalesced, a receiver which falls
+// behind gets a single pending notification. The returned function cancels
+// the subscription.
+func (t *Tag) Subscribe() (c <-chan struct{}, unsubscribe func()) {
+	ch := make(chan struct{}, 1)
+
+	t.subsMu.Lock()
+	if t.subs == nil {
+		t.subs = make(map[chan struct{}]struct{})
+	}
+	t.subs[ch] = struct{}{}
+	t.subsMu.Unlock()
+
+	return ch, func() {
+		t.subsMu.Lock()
+		delete(t.subs, ch)
+		t.subsMu.Unlock()
+	}
+}
+
+// notify signals all subscribers that the counters have changed.
+func (t *Tag) notify() {
+	t.subsMu.Lock()
+	defer t.subsMu.Unlock()
+
+	for ch := range t.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
 // Done returns true if tag is complete wrt the state given as argument
 func (t *Tag) Done(s State) bool {
 	n, total, err := t.Status(s)
@@ -198,6 +255,7 @@ func (t *Tag) DoneSplit(address swarm.Address) (int64, error) {
 	if !address.Equal(swarm.ZeroAddress) {
 		t.Address = address
 	}
+	t.notify()
 
 	// persist the tag
 	err := t.saveTag()
@@ -260,6 +318,11 @@ func (tag *Tag) MarshalBinary() (data []byte, err error) {
 	buffer = append(buffer, intBuffer[:n]...)
 	buffer = append(buffer, tag.Address.Bytes()...)
 
+	budget := tag.Budget()
+	n = binary.PutVarint(intBuffer, int64(len(budget)))
+	buffer = append(buffer, intBuffer[:n]...)
+	buffer = append(buffer, budget...)
+
 	return buffer, nil
 }
 
@@ -286,6 +349,14 @@ func (tag *Tag) UnmarshalBinary(buffer []byte) error {
 	buffer = buffer[n:]
 	if t > 0 {
 		tag.Address = swarm.NewAddress(buffer[:t])
+		buffer = buffer[t:]
+	}
+
+	// tags stored before budgets were recorded end here
+	if len(buffer) > 0 {
+		t, n = binary.Varint(buffer)
+		buffer = buffer[n:]
+		tag.SetBudget(string(buffer[:t]))
 	}
 
 	return nil
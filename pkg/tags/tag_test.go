@@ -138,6 +138,42 @@ func TestTagETA(t *testing.T) {
 }
 
 // TestTagConcurrentIncrements tests Inc calls concurrently
+// TestTagSubscribe tests that subscribers are notified of counter changes
+func TestTagSubscribe(t *testing.T) {
+	tg := &Tag{Total: 10}
+
+	c, unsubscribe := tg.Subscribe()
+
+	if err := tg.Inc(StateSplit); err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.Inc(StateStored); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-c:
+	case <-time.After(time.Second):
+		t.Fatal("no notification received")
+	}
+	// notifications are coalesced
+	select {
+	case <-c:
+		t.Fatal("unexpected notification")
+	default:
+	}
+
+	unsubscribe()
+	if err := tg.Inc(StateSent); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-c:
+		t.Fatal("notification after unsubscribe")
+	default:
+	}
+}
+
 func TestTagConcurrentIncrements(t *testing.T) {
 	mockStatestore := statestore.NewStateStore()
 	logger := logging.New(io.Discard, 0)
@@ -224,6 +260,7 @@ func TestMarshallingWithAddr(t *testing.T) {
 	logger := logging.New(io.Discard, 0)
 	tg := NewTag(context.Background(), 111, 10, nil, mockStatestore, logger)
 	tg.Address = swarm.NewAddress([]byte{0, 1, 2, 3, 4, 5, 6})
+	tg.SetBudget("key:1234")
 
 	for _, f := range allStates {
 		err := tg.Inc(f)
@@ -265,6 +302,20 @@ func TestMarshallingWithAddr(t *testing.T) {
 	if !unmarshalledTag.Address.Equal(tg.Address) {
 		t.Fatalf("expected tag address to be %v got %v", unmarshalledTag.Address, tg.Address)
 	}
+
+	if unmarshalledTag.Budget() != tg.Budget() {
+		t.Fatalf("expected tag budget to be %q got %q", tg.Budget(), unmarshalledTag.Budget())
+	}
+
+	// tags stored before budgets were recorded have none
+	legacyTag := &Tag{}
+	err = legacyTag.UnmarshalBinary(b[:len(b)-len(tg.Budget())-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !legacyTag.Address.Equal(tg.Address) || legacyTag.Budget() != "" {
+		t.Fatalf("got legacy tag address %v with budget %q", legacyTag.Address, legacyTag.Budget())
+	}
 }
 
 // TestMarshallingNoAddress tests that marshalling and unmarshalling is done correctly
@@ -0,0 +1,370 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package graph converts the topology snapshots of nodes into graphs of the
+// nodes and their peers which can be merged into a network-wide picture and
+// written in formats understood by graph visualisation tools.
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/holisticode/bee/pkg/swarm"
+	"github.com/holisticode/bee/pkg/topology"
+)
+
+// ErrUnknownFormat is returned by ParseFormat for an unsupported format.
+var ErrUnknownFormat = errors.New("unknown graph format")
+
+// Format is the encoding of a written graph.
+type Format string
+
+const (
+	FormatJSON    Format = "json"
+	FormatDOT     Format = "dot"
+	FormatGraphML Format = "graphml"
+)
+
+// ParseFormat returns the graph format with the given name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatJSON, FormatDOT, FormatGraphML:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, s)
+}
+
+// Node is a node of the topology graph identified by its overlay address.
+type Node struct {
+	ID string `json:"id"`
+	// Reported is set for the nodes whose topology is in the graph, the
+	// other nodes are only known as their peers.
+	Reported     bool   `json:"reported"`
+	Depth        uint8  `json:"depth"`
+	Reachability string `json:"reachability,omitempty"`
+	// Light is set for the nodes which are connected as light nodes.
+	Light bool `json:"light,omitempty"`
+}
+
+// Edge leads from a reported node to one of its connected or known peers.
+type Edge struct {
+	Source    string `json:"source"`
+	Target    string `json:"target"`
+	Proximity uint8  `json:"proximity"`
+	Connected bool   `json:"connected"`
+	// Neighbor is set for the peers within the neighborhood depth of the
+	// source, which marks the depth boundary of the source.
+	Neighbor     bool   `json:"neighbor"`
+	Latency      int64  `json:"latency,omitempty"` // in milliseconds
+	Reachability string `json:"reachability,omitempty"`
+}
+
+// Graph is the topology graph of one or more nodes.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// New returns the graph of the node with the topology snapshot.
+func New(params *topology.KadParams) *Graph {
+	nodes := map[string]Node{
+		params.Base: {
+			ID:           params.Base,
+			Reported:     true,
+			Depth:        params.Depth,
+			Reachability: params.Reachability,
+		},
+	}
+	var edges []Edge
+
+	bins := reflect.ValueOf(params.Bins)
+	for po := 0; po < bins.NumField(); po++ {
+		bin := bins.Field(po).Interface().(topology.BinInfo)
+		for _, peers := range []struct {
+			infos     []*topology.PeerInfo
+			connected bool
+		}{
+			{infos: bin.ConnectedPeers, connected: true},
+			{infos: bin.DisconnectedPeers},
+		} {
+			for _, p := range peers.infos {
+				id := p.Address.String()
+				if _, ok := nodes[id]; !ok {
+					nodes[id] = Node{ID: id}
+				}
+				e := Edge{
+					Source:    params.Base,
+					Target:    id,
+					Proximity: uint8(po),
+					Connected: peers.connected,
+					Neighbor:  uint8(po) >= params.Depth,
+				}
+				if p.Metrics != nil {
+					e.Latency = p.Metrics.LatencyEWMA
+					e.Reachability = p.Metrics.Reachability
+				}
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	// light nodes are not in the bins, their proximity is derived from
+	// their address
+	base, err := swarm.ParseHexAddress(params.Base)
+	if err != nil {
+		base = swarm.ZeroAddress
+	}
+	for _, peers := range []struct {
+		infos     []*topology.PeerInfo
+		connected bool
+	}{
+		{infos: params.LightNodes.ConnectedPeers, connected: true},
+		{infos: params.LightNodes.DisconnectedPeers},
+	} {
+		for _, p := range peers.infos {
+			id := p.Address.String()
+			if n, ok := nodes[id]; !ok || !n.Reported {
+				nodes[id] = Node{ID: id, Light: true}
+			}
+			edges = append(edges, Edge{
+				Source:    params.Base,
+				Target:    id,
+				Proximity: swarm.Proximity(base.Bytes(), p.Address.Bytes()),
+				Connected: peers.connected,
+			})
+		}
+	}
+
+	return newGraph(nodes, edges)
+}
+
+// Merge merges the graphs into a single graph. The attributes of the nodes
+// are taken from the graphs which report them, a node is light if it is
+// connected as a light node in any of the graphs. An edge which is present in
+// more than one graph is connected if it is connected in any of them.
+func Merge(graphs ...*Graph) *Graph {
+	nodes := make(map[string]Node)
+	edges := make(map[[2]string]Edge)
+	for _, g := range graphs {
+		for _, n := range g.Nodes {
+			light := n.Light
+			if m, ok := nodes[n.ID]; ok {
+				light = light || m.Light
+				if m.Reported || !n.Reported {
+					n = m
+				}
+			}
+			n.Light = light
+			nodes[n.ID] = n
+		}
+		for _, e := range g.Edges {
+			key := [2]string{e.Source, e.Target}
+			if f, ok := edges[key]; ok && (f.Connected || !e.Connected) {
+				continue
+			}
+			edges[key] = e
+		}
+	}
+
+	merged := make([]Edge, 0, len(edges))
+	for _, e := range edges {
+		merged = append(merged, e)
+	}
+	return newGraph(nodes, merged)
+}
+
+// newGraph returns the graph of the nodes and edges sorted by their ids.
+func newGraph(nodes map[string]Node, edges []Edge) *Graph {
+	g := &Graph{
+		Nodes: make([]Node, 0, len(nodes)),
+		Edges: edges,
+	}
+	for _, n := range nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].Source != g.Edges[j].Source {
+			return g.Edges[i].Source < g.Edges[j].Source
+		}
+		return g.Edges[i].Target < g.Edges[j].Target
+	})
+	if g.Edges == nil {
+		g.Edges = []Edge{}
+	}
+	return g
+}
+
+// Write writes the graph to w in the format.
+func (g *Graph) Write(w io.Writer, f Format) error {
+	switch f {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(g)
+	case FormatDOT:
+		return g.writeDOT(w)
+	case FormatGraphML:
+		return g.writeGraphML(w)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownFormat, f)
+}
+
+// labelLength is the number of characters of the overlay address used as
+// the label of the nodes in DOT graphs.
+const labelLength = 8
+
+// writeDOT writes the graph in the Graphviz DOT language. Reported nodes are
+// drawn as double circles, light nodes as boxes, edges to known but not connected peers are
+// dashed and edges to the peers within the neighborhood depth are bold.
+func (g *Graph) writeDOT(w io.Writer) error {
+	ew := &errWriter{w: w}
+
+	ew.printf("digraph topology {\n")
+	for _, n := range g.Nodes {
+		label := n.ID
+		if len(label) > labelLength {
+			label = label[:labelLength]
+		}
+		switch {
+		case n.Reported:
+			ew.printf("  %q [label=%q, shape=doublecircle, depth=%d, reachability=%q];\n", n.ID, label, n.Depth, n.Reachability)
+		case n.Light:
+			ew.printf("  %q [label=%q, shape=box];\n", n.ID, label)
+		default:
+			ew.printf("  %q [label=%q, shape=circle];\n", n.ID, label)
+		}
+	}
+	for _, e := range g.Edges {
+		style := "solid"
+		if !e.Connected {
+			style = "dashed"
+		}
+		if e.Neighbor {
+			style += ",bold"
+		}
+		ew.printf("  %q -> %q [label=\"%d\", style=%q, proximity=%d, latency=%d, reachability=%q];\n", e.Source, e.Target, e.Proximity, style, e.Proximity, e.Latency, e.Reachability)
+	}
+	ew.printf("}\n")
+
+	return ew.err
+}
+
+// errWriter remembers the first write error and skips the following writes.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, a ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, a...)
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+var graphMLKeys = []graphMLKey{
+	{ID: "reported", For: "node", Name: "reported", Type: "boolean"},
+	{ID: "depth", For: "node", Name: "depth", Type: "int"},
+	{ID: "node_reachability", For: "node", Name: "reachability", Type: "string"},
+	{ID: "light", For: "node", Name: "light", Type: "boolean"},
+	{ID: "proximity", For: "edge", Name: "proximity", Type: "int"},
+	{ID: "connected", For: "edge", Name: "connected", Type: "boolean"},
+	{ID: "neighbor", For: "edge", Name: "neighbor", Type: "boolean"},
+	{ID: "latency", For: "edge", Name: "latency", Type: "long"},
+	{ID: "edge_reachability", For: "edge", Name: "reachability", Type: "string"},
+}
+
+// writeGraphML writes the graph in the GraphML format with the attributes
+// of the nodes and edges as typed data.
+func (g *Graph) writeGraphML(w io.Writer) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphMLKeys,
+	}
+	doc.Graph.ID = "topology"
+	doc.Graph.EdgeDefault = "directed"
+
+	for _, n := range g.Nodes {
+		node := graphMLNode{
+			ID: n.ID,
+			Data: []graphMLData{
+				{Key: "reported", Value: fmt.Sprint(n.Reported)},
+				{Key: "light", Value: fmt.Sprint(n.Light)},
+			},
+		}
+		if n.Reported {
+			node.Data = append(node.Data,
+				graphMLData{Key: "depth", Value: fmt.Sprint(n.Depth)},
+				graphMLData{Key: "node_reachability", Value: n.Reachability},
+			)
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: e.Source,
+			Target: e.Target,
+			Data: []graphMLData{
+				{Key: "proximity", Value: fmt.Sprint(e.Proximity)},
+				{Key: "connected", Value: fmt.Sprint(e.Connected)},
+				{Key: "neighbor", Value: fmt.Sprint(e.Neighbor)},
+				{Key: "latency", Value: fmt.Sprint(e.Latency)},
+				{Key: "edge_reachability", Value: e.Reachability},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,233 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graph_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/holisticode/bee/pkg/swarm"
+	"github.com/holisticode/bee/pkg/topology"
+	"github.com/holisticode/bee/pkg/topology/graph"
+)
+
+var (
+	base  = swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	peer1 = swarm.MustParseHexAddress("4a1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	peer2 = swarm.MustParseHexAddress("ca1f9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+)
+
+func snapshot() *topology.KadParams {
+	params := &topology.KadParams{
+		Base:         base.String(),
+		Depth:        3,
+		Reachability: "Public",
+	}
+	params.Bins.Bin0.ConnectedPeers = []*topology.PeerInfo{{
+		Address: peer1,
+		Metrics: &topology.MetricSnapshotView{LatencyEWMA: 25, Reachability: "Public"},
+	}}
+	params.Bins.Bin15.DisconnectedPeers = []*topology.PeerInfo{{Address: peer2}}
+	return params
+}
+
+func TestNew(t *testing.T) {
+	g := graph.New(snapshot())
+
+	want := &graph.Graph{
+		Nodes: []graph.Node{
+			{ID: peer1.String()},
+			{ID: base.String(), Reported: true, Depth: 3, Reachability: "Public"},
+			{ID: peer2.String()},
+		},
+		Edges: []graph.Edge{
+			{Source: base.String(), Target: peer1.String(), Proximity: 0, Connected: true, Latency: 25, Reachability: "Public"},
+			{Source: base.String(), Target: peer2.String(), Proximity: 15, Neighbor: true},
+		},
+	}
+	if !reflect.DeepEqual(g, want) {
+		t.Fatalf("got %+v, want %+v", g, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	g1 := graph.New(snapshot())
+
+	// the topology of the second peer, which is connected to the base
+	params := &topology.KadParams{Base: peer2.String(), Depth: 1, Reachability: "Private"}
+	params.Bins.Bin15.ConnectedPeers = []*topology.PeerInfo{{Address: base}}
+	g2 := graph.New(params)
+
+	g := graph.Merge(g1, g2)
+
+	if len(g.Nodes) != 3 {
+		t.Fatalf("got %d nodes, want 3", len(g.Nodes))
+	}
+	for _, n := range g.Nodes {
+		switch n.ID {
+		case base.String():
+			if !n.Reported || n.Depth != 3 {
+				t.Fatalf("unexpected base node %+v", n)
+			}
+		case peer2.String():
+			if !n.Reported || n.Depth != 1 || n.Reachability != "Private" {
+				t.Fatalf("reported attributes of the peer were not merged: %+v", n)
+			}
+		case peer1.String():
+			if n.Reported {
+				t.Fatalf("unreported node is reported: %+v", n)
+			}
+		}
+	}
+
+	if len(g.Edges) != 3 {
+		t.Fatalf("got %d edges, want 3", len(g.Edges))
+	}
+
+	// merging the same graph twice keeps the connected edges
+	g3 := graph.New(snapshot())
+	g3.Edges[1].Connected = true
+	if e := graph.Merge(g1, g3).Edges[1]; !e.Connected {
+		t.Fatalf("connected edge was not kept: %+v", e)
+	}
+	if e := graph.Merge(g3, g1).Edges[1]; !e.Connected {
+		t.Fatalf("connected edge was overwritten: %+v", e)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := graph.New(snapshot())
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := g.Write(&buf, graph.FormatJSON); err != nil {
+			t.Fatal(err)
+		}
+		var got graph.Graph
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(&got, g) {
+			t.Fatalf("got %+v, want %+v", got, g)
+		}
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := g.Write(&buf, graph.FormatDOT); err != nil {
+			t.Fatal(err)
+		}
+		dot := buf.String()
+		for _, want := range []string{
+			"digraph topology {\n",
+			`"` + base.String() + `" [label="ca1e9f39", shape=doublecircle, depth=3, reachability="Public"];`,
+			`"` + base.String() + `" -> "` + peer1.String() + `" [label="0", style="solid", proximity=0, latency=25, reachability="Public"];`,
+			`"` + base.String() + `" -> "` + peer2.String() + `" [label="15", style="dashed,bold", proximity=15, latency=0, reachability=""];`,
+		} {
+			if !strings.Contains(dot, want) {
+				t.Fatalf("dot graph %q does not contain %q", dot, want)
+			}
+		}
+	})
+
+	t.Run("graphml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := g.Write(&buf, graph.FormatGraphML); err != nil {
+			t.Fatal(err)
+		}
+		var doc struct {
+			Graph struct {
+				Nodes []struct {
+					ID string `xml:"id,attr"`
+				} `xml:"node"`
+				Edges []struct {
+					Source string `xml:"source,attr"`
+					Target string `xml:"target,attr"`
+					Data   []struct {
+						Key   string `xml:"key,attr"`
+						Value string `xml:",chardata"`
+					} `xml:"data"`
+				} `xml:"edge"`
+			} `xml:"graph"`
+		}
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.Graph.Nodes) != 3 || len(doc.Graph.Edges) != 2 {
+			t.Fatalf("got %d nodes and %d edges, want 3 and 2", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+		}
+		e := doc.Graph.Edges[1]
+		if e.Target != peer2.String() || e.Data[0].Key != "proximity" || e.Data[0].Value != "15" {
+			t.Fatalf("unexpected edge %+v", e)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := g.Write(&bytes.Buffer{}, "svg"); !errors.Is(err, graph.ErrUnknownFormat) {
+			t.Fatalf("got error %v, want %v", err, graph.ErrUnknownFormat)
+		}
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range []graph.Format{graph.FormatJSON, graph.FormatDOT, graph.FormatGraphML} {
+		got, err := graph.ParseFormat(string(f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != f {
+			t.Fatalf("got %s, want %s", got, f)
+		}
+	}
+	if _, err := graph.ParseFormat("svg"); !errors.Is(err, graph.ErrUnknownFormat) {
+		t.Fatalf("got error %v, want %v", err, graph.ErrUnknownFormat)
+	}
+}
+
+func TestNewLightNodes(t *testing.T) {
+	light := swarm.MustParseHexAddress("cb1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+
+	params := snapshot()
+	params.LightNodes.ConnectedPeers = []*topology.PeerInfo{{Address: light}}
+	g := graph.New(params)
+
+	var node *graph.Node
+	for i := range g.Nodes {
+		if g.Nodes[i].ID == light.String() {
+			node = &g.Nodes[i]
+		}
+	}
+	if node == nil || !node.Light || node.Reported {
+		t.Fatalf("got light node %+v", node)
+	}
+
+	want := graph.Edge{Source: base.String(), Target: light.String(), Proximity: 7, Connected: true}
+	if e := g.Edges[2]; e != want {
+		t.Fatalf("got edge %+v, want %+v", e, want)
+	}
+
+	// the light node stays light when it is merged with a graph which only
+	// knows it as a peer
+	other := &topology.KadParams{Base: peer1.String()}
+	other.Bins.Bin1.DisconnectedPeers = []*topology.PeerInfo{{Address: light}}
+	for _, n := range graph.Merge(graph.New(other), g).Nodes {
+		if n.ID == light.String() && !n.Light {
+			t.Fatalf("merged node is not light: %+v", n)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := g.Write(&buf, graph.FormatDOT); err != nil {
+		t.Fatal(err)
+	}
+	if want := `"` + light.String() + `" [label="cb1e9f39", shape=box];`; !strings.Contains(buf.String(), want) {
+		t.Fatalf("dot graph %q does not contain %q", buf.String(), want)
+	}
+}
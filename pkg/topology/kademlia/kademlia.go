@@ -18,10 +18,13 @@ import (
 
 	"github.com/holisticode/bee/pkg/addressbook"
 	"github.com/holisticode/bee/pkg/blocker"
+	"github.com/holisticode/bee/pkg/bzz"
 	"github.com/holisticode/bee/pkg/discovery"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/p2p"
 	"github.com/holisticode/bee/pkg/pingpong"
+	"github.com/holisticode/bee/pkg/reputation"
 	"github.com/holisticode/bee/pkg/shed"
 	"github.com/holisticode/bee/pkg/swarm"
 	"github.com/holisticode/bee/pkg/topology"
@@ -84,6 +87,9 @@ type Options struct {
 	PruneFunc        pruneFunc
 	StaticNodes      []swarm.Address
 	ReachabilityFunc peerFilterFunc
+	Reputation       reputation.Interface
+	EnableQUIC       bool
+	Publisher        events.Publisher
 }
 
 // Kad is the Swarm forwarding kademlia implementation.
@@ -121,6 +127,9 @@ type Kad struct {
 	blocker           *blocker.Blocker
 	reachability      p2p.ReachabilityStatus
 	peerFilter        peerFilterFunc
+	reputation        reputation.Interface // optional, prefers well-scored peers in routing
+	quic              bool                 // connect to peers over their QUIC underlays when advertised
+	publisher         events.Publisher     // optional, notified about reachability, depth and radius changes
 }
 
 // New returns a new Kademlia.
@@ -176,6 +185,9 @@ func New(
 		pinger:            pinger,
 		staticPeer:        isStaticPeer(o.StaticNodes),
 		peerFilter:        o.ReachabilityFunc,
+		reputation:        o.Reputation,
+		quic:              o.EnableQUIC,
+		publisher:         o.Publisher,
 	}
 
 	blocklistCallback := func(a swarm.Address) {
@@ -364,7 +376,7 @@ func (k *Kad) connectionAttemptsHandler(ctx context.Context, wg *sync.WaitGroup,
 			}
 		}
 
-		switch err = k.connect(ctx, peer.addr, bzzAddr.Underlay); {
+		switch err = k.connect(ctx, peer.addr, bzzAddr); {
 		case errors.Is(err, errPruneEntry):
 			k.logger.Debugf("kademlia: dial to light node with overlay %q and underlay %q", peer.addr, bzzAddr.Underlay)
 			remove(peer)
@@ -382,12 +394,15 @@ func (k *Kad) connectionAttemptsHandler(ctx context.Context, wg *sync.WaitGroup,
 		k.waitNext.Set(peer.addr, time.Now().Add(shortRetry), 0)
 
 		k.connectedPeers.Add(peer.addr)
+		if k.reputation != nil {
+			k.reputation.Connected(peer.addr)
+		}
 
 		k.metrics.TotalOutboundConnections.Inc()
 		k.collector.Record(peer.addr, im.PeerLogIn(time.Now(), im.PeerConnectionDirectionOutbound))
 
 		k.depthMu.Lock()
-		k.depth = recalcDepth(k.connectedPeers, k.radius, k.peerFilter)
+		k.updateDepth()
 		k.depthMu.Unlock()
 
 		k.logger.Debugf("kademlia: connected to peer: %q in bin: %d", peer.addr, peer.po)
@@ -626,7 +641,8 @@ func (k *Kad) recordPeerLatencies(ctx context.Context) {
 }
 
 // pruneOversaturatedBins disconnects out of depth peers from oversaturated bins
-// while maintaining the balance of the bin and favoring peers with longers connections
+// while maintaining the balance of the bin and favoring well reputed peers
+// with longer connections
 func (k *Kad) pruneOversaturatedBins(depth uint8) {
 
 	for i := range k.commonBinPrefixes {
@@ -653,20 +669,7 @@ func (k *Kad) pruneOversaturatedBins(depth uint8) {
 				continue
 			}
 
-			var smallestDuration time.Duration
-			var newestPeer swarm.Address
-			for _, peer := range peers {
-				ss := k.collector.Inspect(peer)
-				if ss == nil {
-					continue
-				}
-				duration := ss.SessionConnectionDuration
-				if smallestDuration == 0 || duration < smallestDuration {
-					smallestDuration = duration
-					newestPeer = peer
-				}
-			}
-			err := k.p2p.Disconnect(newestPeer, "pruned from oversaturated bin")
+			err := k.p2p.Disconnect(k.lowestValuePeer(peers), "pruned from oversaturated bin")
 			if err != nil {
 				k.logger.Debugf("prune disconnect fail %v", err)
 			}
@@ -909,7 +912,7 @@ func recalcDepth(peers *pslice.PSlice, radius uint8, filter peerFilterFunc) uint
 
 // connect connects to a peer and gossips its address to our connected peers,
 // as well as sends the peers we are connected to to the newly connected peer
-func (k *Kad) connect(ctx context.Context, peer swarm.Address, ma ma.Multiaddr) error {
+func (k *Kad) connect(ctx context.Context, peer swarm.Address, addr *bzz.Address) error {
 	k.logger.Infof("attempting to connect to peer %q", peer)
 
 	ctx, cancel := context.WithTimeout(ctx, peerConnectionAttemptTimeout)
@@ -917,7 +920,7 @@ func (k *Kad) connect(ctx context.Context, peer swarm.Address, ma ma.Multiaddr)
 
 	k.metrics.TotalOutboundConnectionAttempts.Inc()
 
-	switch i, err := k.p2p.Connect(ctx, ma); {
+	switch i, err := k.dial(ctx, addr); {
 	case errors.Is(err, p2p.ErrDialLightNode):
 		return errPruneEntry
 	case errors.Is(err, p2p.ErrAlreadyConnected):
@@ -966,6 +969,20 @@ func (k *Kad) connect(ctx context.Context, peer swarm.Address, ma ma.Multiaddr)
 	return k.Announce(ctx, peer, true)
 }
 
+// dial connects to the QUIC underlay of the peer when both sides support QUIC
+// and falls back to the underlay if the QUIC connection fails.
+func (k *Kad) dial(ctx context.Context, addr *bzz.Address) (*bzz.Address, error) {
+	if k.quic && addr.QUICUnderlay != nil {
+		i, err := k.p2p.Connect(ctx, addr.QUICUnderlay)
+		if err == nil || errors.Is(err, p2p.ErrDialLightNode) || errors.Is(err, p2p.ErrAlreadyConnected) || errors.Is(err, context.Canceled) {
+			return i, err
+		}
+		k.metrics.TotalQUICFallbacks.Inc()
+		k.logger.Debugf("kademlia: quic connection to peer %q failed, falling back to %q: %v", addr.Overlay, addr.Underlay, err)
+	}
+	return k.p2p.Connect(ctx, addr.Underlay)
+}
+
 // Announce a newly connected peer to our connected peers, but also
 // notify the peer about our already connected peers
 func (k *Kad) Announce(ctx context.Context, peer swarm.Address, fullnode bool) error {
@@ -1049,10 +1066,14 @@ func (k *Kad) Pick(peer p2p.Peer) bool {
 	}
 	po := swarm.Proximity(k.base.Bytes(), peer.Address.Bytes())
 	_, oversaturated := k.saturationFunc(po, k.knownPeers, k.connectedPeers, k.peerFilter)
-	// pick the peer if we are not oversaturated
+	// pick the peer if we are not oversaturated or if it can replace a
+	// badly reputed peer
 	if !oversaturated {
 		return true
 	}
+	if _, ok := k.replaceablePeer(po); ok {
+		return true
+	}
 	k.metrics.PickCallsFalse.Inc()
 	return false
 }
@@ -1104,11 +1125,15 @@ func (k *Kad) Connected(ctx context.Context, peer p2p.Peer, forceConnection bool
 
 	if _, overSaturated := k.saturationFunc(po, k.knownPeers, k.connectedPeers, k.peerFilter); overSaturated {
 		if k.bootnode {
-			randPeer, err := k.randomPeer(po)
+			evictPeer, err := k.evictionCandidate(po)
 			if err != nil {
-				return fmt.Errorf("failed to get random peer to kick-out: %w", err)
+				return fmt.Errorf("failed to get peer to kick-out: %w", err)
 			}
-			_ = k.p2p.Disconnect(randPeer, "kicking out random peer to accommodate node")
+			_ = k.p2p.Disconnect(evictPeer, "kicking out lowest value peer to accommodate node")
+			return k.onConnected(ctx, address)
+		}
+		if replaced, ok := k.replaceablePeer(po); ok {
+			_ = k.p2p.Disconnect(replaced, "kicking out badly reputed peer to accommodate node")
 			return k.onConnected(ctx, address)
 		}
 		if !forceConnection {
@@ -1126,11 +1151,14 @@ func (k *Kad) onConnected(ctx context.Context, addr swarm.Address) error {
 
 	k.knownPeers.Add(addr)
 	k.connectedPeers.Add(addr)
+	if k.reputation != nil {
+		k.reputation.Connected(addr)
+	}
 
 	k.waitNext.Remove(addr)
 
 	k.depthMu.Lock()
-	k.depth = recalcDepth(k.connectedPeers, k.radius, k.peerFilter)
+	k.updateDepth()
 	k.depthMu.Unlock()
 
 	k.notifyManageLoop()
@@ -1143,6 +1171,9 @@ func (k *Kad) Disconnected(peer p2p.Peer) {
 	k.logger.Debugf("kademlia: disconnected peer %s", peer.Address)
 
 	k.connectedPeers.Remove(peer.Address)
+	if k.reputation != nil {
+		k.reputation.Disconnected(peer.Address)
+	}
 
 	k.waitNext.SetTryAfter(peer.Address, time.Now().Add(timeToRetry))
 
@@ -1150,7 +1181,7 @@ func (k *Kad) Disconnected(peer p2p.Peer) {
 	k.collector.Record(peer.Address, im.PeerLogOut(time.Now()))
 
 	k.depthMu.Lock()
-	k.depth = recalcDepth(k.connectedPeers, k.radius, k.peerFilter)
+	k.updateDepth()
 	k.depthMu.Unlock()
 
 	k.notifyManageLoop()
@@ -1228,17 +1259,17 @@ func closestPeerFunc(closest *swarm.Address, addr swarm.Address, spf sanctionedP
 	}
 }
 
-// ClosestPeer returns the closest peer to a given address.
+// ClosestPeer returns the closest peer to a given address. Among the peers in
+// the same proximity order to the address, peers with a better reputation are
+// preferred.
 func (k *Kad) ClosestPeer(addr swarm.Address, includeSelf bool, filter topology.Filter, skipPeers ...swarm.Address) (swarm.Address, error) {
 	if k.connectedPeers.Length() == 0 {
 		return swarm.Address{}, topology.ErrNotFound
 	}
 
-	closest := swarm.ZeroAddress
-
-	if includeSelf && k.reachability == p2p.ReachabilityStatusPublic {
-		closest = k.base
-	}
+	// closest is the peer chosen for routing while nearest is the peer
+	// nearest to the address which decides whether this node is closer
+	closest, nearest := swarm.ZeroAddress, swarm.ZeroAddress
 
 	err := k.EachPeerRev(func(peer swarm.Address, po uint8) (bool, bool, error) {
 
@@ -1249,11 +1280,14 @@ func (k *Kad) ClosestPeer(addr swarm.Address, includeSelf bool, filter topology.
 		}
 
 		if closest.IsZero() {
-			closest = peer
+			closest, nearest = peer, peer
 			return false, false, nil
 		}
 
-		if closer, _ := peer.Closer(addr, closest); closer {
+		if closer, _ := peer.Closer(addr, nearest); closer {
+			nearest = peer
+		}
+		if prefer, _ := reputation.Prefer(k.scorer(), addr, peer, closest); prefer {
 			closest = peer
 		}
 		return false, false, nil
@@ -1263,18 +1297,32 @@ func (k *Kad) ClosestPeer(addr swarm.Address, includeSelf bool, filter topology.
 		return swarm.Address{}, err
 	}
 
-	if closest.IsZero() { // no peers
-		return swarm.Address{}, topology.ErrNotFound // only for light nodes
+	// check if self
+	if includeSelf && k.reachability == p2p.ReachabilityStatusPublic {
+		if nearest.IsZero() {
+			return swarm.Address{}, topology.ErrWantSelf
+		}
+		if closer, _ := k.base.Closer(addr, nearest); closer {
+			return swarm.Address{}, topology.ErrWantSelf
+		}
 	}
 
-	// check if self
-	if closest.Equal(k.base) {
-		return swarm.Address{}, topology.ErrWantSelf
+	if closest.IsZero() { // no peers
+		return swarm.Address{}, topology.ErrNotFound // only for light nodes
 	}
 
 	return closest, nil
 }
 
+// scorer returns the reputation scorer or nil if routing does not take the
+// reputation into account.
+func (k *Kad) scorer() reputation.Scorer {
+	if k.reputation == nil {
+		return nil
+	}
+	return k.reputation
+}
+
 // IsWithinDepth returns if an address is within the neighborhood depth of a node.
 func (k *Kad) IsWithinDepth(addr swarm.Address) bool {
 	return swarm.Proximity(k.base.Bytes(), addr.Bytes()) >= k.NeighborhoodDepth()
@@ -1328,9 +1376,20 @@ func (k *Kad) EachPeerRev(f topology.EachPeerFunc, filter topology.Filter) error
 func (k *Kad) Reachable(addr swarm.Address, status p2p.ReachabilityStatus) {
 	k.collector.Record(addr, im.PeerReachability(status))
 	k.logger.Tracef("kademlia: reachability of peer %s is %s", addr.String(), status.String())
+	if k.publisher != nil {
+		data := events.PeerData{
+			Overlay:      addr,
+			Bin:          swarm.Proximity(k.base.Bytes(), addr.Bytes()),
+			Reachability: status.String(),
+		}
+		if bzzAddr, err := k.addressBook.Get(addr); err == nil {
+			data.Underlay = bzzAddr.Underlay.String()
+		}
+		k.publish(events.PeerReachabilityChanged, data)
+	}
 	if status == p2p.ReachabilityStatusPublic {
 		k.depthMu.Lock()
-		k.depth = recalcDepth(k.connectedPeers, k.radius, k.peerFilter)
+		k.updateDepth()
 		k.depthMu.Unlock()
 		k.notifyManageLoop()
 	}
@@ -1412,13 +1471,36 @@ func (k *Kad) SetRadius(r uint8) {
 		return
 	}
 	k.radius = r
+	k.publish(events.RadiusChanged, events.RadiusData{Radius: r})
 	oldD := k.depth
-	k.depth = recalcDepth(k.connectedPeers, k.radius, k.peerFilter)
+	k.updateDepth()
 	if k.depth != oldD {
 		k.notifyManageLoop()
 	}
 }
 
+// updateDepth recalculates the depth and publishes its change. It must be
+// called with the depthMu lock held.
+func (k *Kad) updateDepth() {
+	oldD := k.depth
+	k.depth = recalcDepth(k.connectedPeers, k.radius, k.peerFilter)
+	if k.depth != oldD {
+		k.publish(events.DepthChanged, events.DepthData{Depth: k.depth})
+	}
+}
+
+// publish publishes the event if the publisher is set.
+func (k *Kad) publish(typ events.Type, data interface{}) {
+	if k.publisher == nil {
+		return
+	}
+	k.publisher.Publish(events.Event{
+		Type: typ,
+		Time: time.Now(),
+		Data: data,
+	})
+}
+
 func (k *Kad) Snapshot() *topology.KadParams {
 	var infos []topology.BinInfo
 	for i := int(swarm.MaxPO); i >= 0; i-- {
@@ -1432,8 +1514,9 @@ func (k *Kad) Snapshot() *topology.KadParams {
 		infos[po].ConnectedPeers = append(
 			infos[po].ConnectedPeers,
 			&topology.PeerInfo{
-				Address: addr,
-				Metrics: createMetricsSnapshotView(ss[addr.ByteString()]),
+				Address:    addr,
+				Metrics:    createMetricsSnapshotView(ss[addr.ByteString()]),
+				Reputation: k.reputationSnapshotView(addr),
 			},
 		)
 		return false, false, nil
@@ -1453,8 +1536,9 @@ func (k *Kad) Snapshot() *topology.KadParams {
 		infos[po].DisconnectedPeers = append(
 			infos[po].DisconnectedPeers,
 			&topology.PeerInfo{
-				Address: addr,
-				Metrics: createMetricsSnapshotView(ss[addr.ByteString()]),
+				Address:    addr,
+				Metrics:    createMetricsSnapshotView(ss[addr.ByteString()]),
+				Reputation: k.reputationSnapshotView(addr),
 			},
 		)
 		return false, false, nil
@@ -1590,7 +1674,9 @@ func randomSubset(addrs []swarm.Address, count int) ([]swarm.Address, error) {
 	return addrs[:count], nil
 }
 
-func (k *Kad) randomPeer(bin uint8) (swarm.Address, error) {
+// evictionCandidate returns the least valuable peer of the bin which is not
+// protected from being kicked out.
+func (k *Kad) evictionCandidate(bin uint8) (swarm.Address, error) {
 	peers := k.connectedPeers.BinPeers(bin)
 
 	for idx := 0; idx < len(peers); {
@@ -1606,12 +1692,47 @@ func (k *Kad) randomPeer(bin uint8) (swarm.Address, error) {
 		return swarm.ZeroAddress, errEmptyBin
 	}
 
-	rndIndx, err := random.Int(random.Reader, big.NewInt(int64(len(peers))))
+	return k.lowestValuePeer(peers), nil
+}
+
+// replaceablePeer returns the least valuable peer of the bin if its
+// reputation is worse than the one of an unknown peer, so that it is worth
+// replacing it with a new one.
+func (k *Kad) replaceablePeer(bin uint8) (swarm.Address, bool) {
+	if k.reputation == nil {
+		return swarm.ZeroAddress, false
+	}
+	peer, err := k.evictionCandidate(bin)
 	if err != nil {
-		return swarm.ZeroAddress, err
+		return swarm.ZeroAddress, false
 	}
+	return peer, k.reputation.Score(peer) < reputation.NeutralScore
+}
 
-	return peers[rndIndx.Int64()], nil
+// lowestValuePeer returns the peer which is the least valuable to stay
+// connected to. Peers with a lower reputation score are less valuable, among
+// equally scored peers the one with the shortest connection is the least
+// valuable.
+func (k *Kad) lowestValuePeer(peers []swarm.Address) swarm.Address {
+	var (
+		lowest      swarm.Address
+		lowestScore float64
+		lowestConn  time.Duration
+	)
+	for i, peer := range peers {
+		score := reputation.NeutralScore
+		if k.reputation != nil {
+			score = k.reputation.Score(peer)
+		}
+		var conn time.Duration
+		if ss := k.collector.Inspect(peer); ss != nil {
+			conn = ss.SessionConnectionDuration
+		}
+		if i == 0 || score < lowestScore || score == lowestScore && conn < lowestConn {
+			lowest, lowestScore, lowestConn = peer, score, conn
+		}
+	}
+	return lowest
 }
 
 // createMetricsSnapshotView creates new topology.MetricSnapshotView from the
@@ -1632,6 +1753,32 @@ func createMetricsSnapshotView(ss *im.Snapshot) *topology.MetricSnapshotView {
 	}
 }
 
+// reputationSnapshotView creates new topology.ReputationSnapshotView from the
+// reputation of the given peer with the latencies given in milliseconds.
+func (k *Kad) reputationSnapshotView(peer swarm.Address) *topology.ReputationSnapshotView {
+	if k.reputation == nil {
+		return nil
+	}
+	ss, ok := k.reputation.Snapshot(peer)
+	if !ok {
+		return nil
+	}
+	view := &topology.ReputationSnapshotView{
+		Score:         ss.Score,
+		Protocols:     make(map[string]topology.ProtocolReputationSnapshotView, len(ss.Protocols)),
+		InvalidChunks: ss.InvalidChunks,
+		Disputes:      ss.Disputes,
+	}
+	for name, p := range ss.Protocols {
+		view.Protocols[name] = topology.ProtocolReputationSnapshotView{
+			Successes:   p.Successes,
+			Failures:    p.Failures,
+			LatencyEWMA: p.LatencyEWMA.Milliseconds(),
+		}
+	}
+	return view
+}
+
 // isNetworkError is checking various conditions that relate to network problems.
 func isNetworkError(err error) bool {
 	var netOpErr *net.OpError
@@ -23,10 +23,12 @@ import (
 	"github.com/holisticode/bee/pkg/bzz"
 	beeCrypto "github.com/holisticode/bee/pkg/crypto"
 	"github.com/holisticode/bee/pkg/discovery/mock"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/p2p"
 	p2pmock "github.com/holisticode/bee/pkg/p2p/mock"
 	pingpongmock "github.com/holisticode/bee/pkg/pingpong/mock"
+	"github.com/holisticode/bee/pkg/reputation"
 	mockstate "github.com/holisticode/bee/pkg/statestore/mock"
 	"github.com/holisticode/bee/pkg/swarm"
 	"github.com/holisticode/bee/pkg/swarm/test"
@@ -663,6 +665,69 @@ func TestOversaturation(t *testing.T) {
 	}
 }
 
+// TestOversaturationReputation tests that a peer with a worse reputation than
+// an unknown peer is replaced by a new peer in an oversaturated bin.
+func TestOversaturationReputation(t *testing.T) {
+	defer func(p int) {
+		*kademlia.OverSaturationPeers = p
+	}(*kademlia.OverSaturationPeers)
+	*kademlia.OverSaturationPeers = 4
+
+	var (
+		conns                    int32 // how many connect calls were made to the p2p mock
+		rep                      = reputation.New()
+		base, kad, ab, _, signer = newTestKademlia(t, &conns, nil, kademlia.Options{
+			Reputation:       rep,
+			ReachabilityFunc: func(_ swarm.Address) bool { return false },
+		})
+	)
+	kad.SetRadius(swarm.MaxPO) // don't use radius for checks
+
+	if err := kad.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer kad.Close()
+
+	var bin0 []swarm.Address
+	for i := 0; i < 3; i++ {
+		for j := 0; j < *kademlia.OverSaturationPeers; j++ {
+			addr := test.RandomAddressAt(base, i)
+			connectOne(t, signer, kad, ab, addr, nil)
+			if i == 0 {
+				bin0 = append(bin0, addr)
+			}
+		}
+	}
+	kDepth(t, kad, 2)
+
+	// the bin is oversaturated with well behaving peers
+	addr := test.RandomAddressAt(base, 0)
+	if kad.Pick(p2p.Peer{Address: addr}) {
+		t.Fatal("should not pick the peer")
+	}
+	connectOne(t, signer, kad, ab, addr, topology.ErrOversaturated)
+
+	// a peer which delivered an invalid chunk is worse than an unknown one
+	bad := bin0[1]
+	rep.InvalidChunk(bad)
+
+	if !kad.Pick(p2p.Peer{Address: addr}) {
+		t.Fatal("should pick the peer replacing the badly reputed one")
+	}
+	before := atomic.LoadInt32(&conns)
+	connectOne(t, signer, kad, ab, addr, nil)
+	if got := atomic.LoadInt32(&conns); got != before-1 {
+		t.Fatalf("got %d connections, want the badly reputed peer disconnected", got)
+	}
+	removeOne(kad, bad)
+
+	// the bin is oversaturated with well behaving peers again
+	addr = test.RandomAddressAt(base, 0)
+	if kad.Pick(p2p.Peer{Address: addr}) {
+		t.Fatal("should not pick the peer")
+	}
+}
+
 func TestOversaturationBootnode(t *testing.T) {
 	defer func(p int) {
 		*kademlia.OverSaturationPeers = p
@@ -1048,6 +1113,170 @@ func TestAddressBookQuickPrune(t *testing.T) {
 	}
 }
 
+// TestQUICConnect tests that peers are connected over their QUIC underlays
+// when advertised, falling back to the underlay.
+func TestQUICConnect(t *testing.T) {
+	metricsDB, err := shed.NewDB("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := metricsDB.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var (
+		base   = test.RandomAddress()
+		pk, _  = beeCrypto.GenerateSecp256k1Key()
+		signer = beeCrypto.NewDefaultSigner(pk)
+		ab     = addressbook.New(mockstate.NewStateStore())
+		dials  = make(chan ma.Multiaddr, 10)
+		ppm    = pingpongmock.New(func(_ context.Context, _ swarm.Address, _ ...string) (time.Duration, error) {
+			return 0, nil
+		})
+	)
+
+	unreachableQUIC, err := ma.NewMultiaddr("/ip4/127.0.0.1/udp/1635/quic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p2ps := p2pmock.New(p2pmock.WithConnectFunc(func(ctx context.Context, addr ma.Multiaddr) (*bzz.Address, error) {
+		dials <- addr
+		if addr.Equal(unreachableQUIC) {
+			return nil, errors.New("non reachable node")
+		}
+		addresses, err := ab.Addresses()
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range addresses {
+			if a.Underlay.Equal(addr) || (a.QUICUnderlay != nil && a.QUICUnderlay.Equal(addr)) {
+				return &a, nil
+			}
+		}
+		return nil, errors.New("unknown address")
+	}))
+
+	kad, err := kademlia.New(base, ab, mock.NewDiscovery(), p2ps, ppm, metricsDB, logging.New(io.Discard, 0), kademlia.Options{EnableQUIC: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2ps.SetPickyNotifier(kad)
+
+	if err := kad.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer kad.Close()
+
+	for _, tc := range []struct {
+		name         string
+		quicUnderlay string
+		wantDials    []string
+	}{
+		{
+			name:         "quic",
+			quicUnderlay: "/ip4/127.0.0.1/udp/1634/quic",
+			wantDials:    []string{"/ip4/127.0.0.1/udp/1634/quic"},
+		},
+		{
+			name:         "fallback",
+			quicUnderlay: unreachableQUIC.String(),
+			wantDials:    []string{unreachableQUIC.String(), "/ip4/127.0.0.1/tcp/1634/dns/fallback"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			peer := test.RandomAddressAt(base, 1)
+			underlay, err := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/1634/dns/" + tc.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			quicUnderlay, err := ma.NewMultiaddr(tc.quicUnderlay)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bzzAddr, err := bzz.NewAddress(signer, underlay, peer, 0, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := bzzAddr.SetQUICUnderlay(signer, quicUnderlay, 0); err != nil {
+				t.Fatal(err)
+			}
+			if err := ab.Put(peer, *bzzAddr); err != nil {
+				t.Fatal(err)
+			}
+
+			kad.AddPeers(peer)
+
+			for _, want := range tc.wantDials {
+				select {
+				case got := <-dials:
+					if got.String() != want {
+						t.Fatalf("got dial to %s, want %s", got, want)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("timed out waiting for dial to %s", want)
+				}
+			}
+			waitPeers(t, kad, 1)
+			removeOne(kad, peer)
+		})
+	}
+}
+
+func TestPublishEvents(t *testing.T) {
+	published := make(chan events.Event, 10)
+	publisher := events.PublisherFunc(func(e events.Event) {
+		published <- e
+	})
+
+	var (
+		conns                    int32
+		base, kad, ab, _, signer = newTestKademlia(t, &conns, nil, kademlia.Options{Publisher: publisher})
+	)
+
+	if err := kad.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer kad.Close()
+
+	next := func(typ events.Type) events.Event {
+		t.Helper()
+		for {
+			select {
+			case e := <-published:
+				if e.Type == typ {
+					return e
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timeout waiting for %s event", typ)
+			}
+		}
+	}
+
+	kad.SetRadius(5)
+	if got := next(events.RadiusChanged).Data; got != (events.RadiusData{Radius: 5}) {
+		t.Fatalf("got radius event %+v, want radius 5", got)
+	}
+
+	addr := test.RandomAddressAt(base, 3)
+	addOne(t, signer, kad, ab, addr)
+	waitConn(t, &conns)
+
+	kad.Reachable(addr, p2p.ReachabilityStatusPublic)
+	data, ok := next(events.PeerReachabilityChanged).Data.(events.PeerData)
+	if !ok {
+		t.Fatal("unexpected reachability event data")
+	}
+	if !data.Overlay.Equal(addr) || data.Bin != 3 || data.Reachability != p2p.ReachabilityStatusPublic.String() {
+		t.Fatalf("unexpected reachability event data %+v", data)
+	}
+	if data.Underlay != underlayBase+addr.String() {
+		t.Fatalf("got underlay %s, want %s", data.Underlay, underlayBase+addr.String())
+	}
+}
+
 // TestClosestPeer tests that ClosestPeer method returns closest connected peer to a given address.
 func TestClosestPeer(t *testing.T) {
 	metricsDB, err := shed.NewDB("", nil)
@@ -1161,6 +1390,80 @@ func TestClosestPeer(t *testing.T) {
 	}
 }
 
+// TestClosestPeerReputation tests that among the peers in the same proximity
+// order to an address the one with the better reputation is preferred.
+func TestClosestPeerReputation(t *testing.T) {
+	var (
+		rep                   = reputation.New()
+		base                  = swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+		_, kad, ab, _, signer = newTestKademliaWithAddr(t, base, nil, nil, kademlia.Options{Reputation: rep})
+		chunk                 = swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000") // 0111 0000
+		nearest               = swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // 0110 0000 -> po 3 to chunk
+		near                  = swarm.MustParseHexAddress("6800000000000000000000000000000000000000000000000000000000000000") // 0110 1000 -> po 3 to chunk
+		farther               = swarm.MustParseHexAddress("6c00000000000000000000000000000000000000000000000000000000000000") // 0110 1100 -> po 3 to chunk
+		far                   = swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000") // 0100 0000 -> po 2 to chunk
+	)
+
+	if err := kad.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer kad.Close()
+
+	for _, peer := range []swarm.Address{nearest, near, farther, far} {
+		connectOne(t, signer, kad, ab, peer, nil)
+	}
+
+	closestPeer := func(want swarm.Address) {
+		t.Helper()
+		got, err := kad.ClosestPeer(chunk, false, topology.Filter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("got closest peer %s, want %s", got, want)
+		}
+	}
+
+	// without any reputation the nearest peer is chosen
+	closestPeer(nearest)
+
+	// peers in a lower proximity order are not chosen however well-scored
+	for i := 0; i < 10; i++ {
+		rep.Success(far, "retrieval", time.Millisecond)
+	}
+	closestPeer(nearest)
+
+	// a failing peer is passed over for an equally close neutral one
+	rep.Failure(nearest, "retrieval")
+	closestPeer(near)
+
+	// a well-scored peer is preferred over nearer neutral ones
+	rep.Success(farther, "pushsync", time.Millisecond)
+	closestPeer(farther)
+
+	// an invalid chunk outweighs the successes
+	rep.InvalidChunk(farther)
+	closestPeer(near)
+
+	// the reputation is part of the snapshot
+	var found bool
+	for _, p := range kad.Snapshot().Bins.Bin1.ConnectedPeers {
+		if !p.Address.Equal(farther) {
+			continue
+		}
+		found = true
+		if p.Reputation == nil {
+			t.Fatal("missing reputation")
+		}
+		if p.Reputation.InvalidChunks != 1 || p.Reputation.Protocols["pushsync"].Successes != 1 {
+			t.Fatalf("got reputation %+v", p.Reputation)
+		}
+	}
+	if !found {
+		t.Fatal("peer not in snapshot")
+	}
+}
+
 func TestKademlia_SubscribePeersChange(t *testing.T) {
 	testSignal := func(t *testing.T, k *kademlia.Kad, c <-chan struct{}) {
 		t.Helper()
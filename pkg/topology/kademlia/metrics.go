@@ -25,6 +25,7 @@ type metrics struct {
 	TotalOutboundConnections              prometheus.Counter
 	TotalOutboundConnectionAttempts       prometheus.Counter
 	TotalOutboundConnectionFailedAttempts prometheus.Counter
+	TotalQUICFallbacks                    prometheus.Counter
 	TotalBootNodesConnectionAttempts      prometheus.Counter
 	StartAddAddressBookOverlaysTime       prometheus.Histogram
 	PeerLatencyEWMA                       prometheus.Histogram
@@ -124,6 +125,12 @@ func newMetrics() metrics {
 			Name:      "total_outbound_connection_failed_attempts",
 			Help:      "Total outbound connection failed attempts made.",
 		}),
+		TotalQUICFallbacks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_quic_fallbacks",
+			Help:      "Total outbound connections that fell back from QUIC to the TCP underlay.",
+		}),
 		TotalBootNodesConnectionAttempts: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -22,6 +22,7 @@ type mock struct {
 	addPeersErr     error
 	isWithinFunc    func(c swarm.Address) bool
 	marshalJSONFunc func() ([]byte, error)
+	snapshot        *topology.KadParams
 	mtx             sync.Mutex
 }
 
@@ -67,6 +68,12 @@ func WithIsWithinFunc(f func(swarm.Address) bool) Option {
 	})
 }
 
+func WithSnapshot(params *topology.KadParams) Option {
+	return optionFunc(func(d *mock) {
+		d.snapshot = params
+	})
+}
+
 func NewTopologyDriver(opts ...Option) topology.Driver {
 	d := new(mock)
 	for _, o := range opts {
@@ -219,6 +226,9 @@ func (d *mock) EachPeerRev(f topology.EachPeerFunc, _ topology.Filter) (err erro
 }
 
 func (d *mock) Snapshot() *topology.KadParams {
+	if d.snapshot != nil {
+		return d.snapshot
+	}
 	return new(topology.KadParams)
 }
 
@@ -73,8 +73,9 @@ type EachPeerFunc func(swarm.Address, uint8) (stop, jumpToNext bool, err error)
 
 // PeerInfo is a view of peer information exposed to a user.
 type PeerInfo struct {
-	Address swarm.Address       `json:"address"`
-	Metrics *MetricSnapshotView `json:"metrics,omitempty"`
+	Address    swarm.Address           `json:"address"`
+	Metrics    *MetricSnapshotView     `json:"metrics,omitempty"`
+	Reputation *ReputationSnapshotView `json:"reputation,omitempty"`
 }
 
 // MetricSnapshotView represents snapshot of metrics counters in more human readable form.
@@ -88,6 +89,23 @@ type MetricSnapshotView struct {
 	Reachability               string  `json:"reachability"`
 }
 
+// ReputationSnapshotView represents the reputation of a peer used in routing
+// decisions.
+type ReputationSnapshotView struct {
+	Score         float64                                   `json:"score"`
+	Protocols     map[string]ProtocolReputationSnapshotView `json:"protocols"`
+	InvalidChunks uint64                                    `json:"invalidChunks"`
+	Disputes      uint64                                    `json:"disputes"`
+}
+
+// ProtocolReputationSnapshotView represents the reputation of a peer for a
+// single protocol.
+type ProtocolReputationSnapshotView struct {
+	Successes   uint64 `json:"successes"`
+	Failures    uint64 `json:"failures"`
+	LatencyEWMA int64  `json:"latencyEWMA"`
+}
+
 type BinInfo struct {
 	BinPopulation     uint        `json:"population"`
 	BinConnected      uint        `json:"connected"`
@@ -222,3 +222,9 @@ func WithNonceAtFunc(f func(ctx context.Context, account common.Address, blockNu
 		s.nonceAt = f
 	})
 }
+
+func WithBalanceAtFunc(f func(ctx context.Context, address common.Address, block *big.Int) (*big.Int, error)) Option {
+	return optionFunc(func(s *backendMock) {
+		s.balanceAt = f
+	})
+}
@@ -0,0 +1,502 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package backendsimulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holisticode/bee/pkg/transaction"
+)
+
+const (
+	// transferGas is the gas used by plain value transfers.
+	transferGas = 21000
+	// callGas is the gas estimate returned for contract calls.
+	callGas = 100000
+)
+
+var (
+	// ErrExecutionReverted is returned when a simulated contract reverts.
+	ErrExecutionReverted = errors.New("execution reverted")
+	// ErrWriteProtection is returned when a state changing method is
+	// invoked through a static call.
+	ErrWriteProtection = errors.New("write protection")
+	// ErrNonceMismatch is returned when a transaction does not carry the
+	// next nonce of its sender.
+	ErrNonceMismatch = errors.New("nonce mismatch")
+	// ErrInsufficientFunds is returned when the sender can not pay for the
+	// gas and value of a transaction.
+	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
+)
+
+// Contract is a smart contract simulated in Go.
+// Implementations must validate all preconditions before they change any
+// state since reverted calls are not rolled back.
+type Contract interface {
+	// Call executes the given call data in the environment of a call.
+	Call(env *Env, input []byte) ([]byte, error)
+}
+
+// Env is the environment a simulated contract is called in.
+type Env struct {
+	chain  *Chain
+	logs   *[]*types.Log
+	static bool
+
+	From  common.Address // the immediate caller
+	Self  common.Address // the address of the called contract
+	Block uint64         // the number of the block the call executes in
+}
+
+// Static reports whether the call must not change any state.
+func (e *Env) Static() bool {
+	return e.static
+}
+
+// ChainID returns the chain id of the simulated chain.
+func (e *Env) ChainID() *big.Int {
+	return new(big.Int).Set(e.chain.chainID)
+}
+
+// Emit records a log of the called contract.
+func (e *Env) Emit(topics []common.Hash, data []byte) {
+	if e.logs == nil {
+		return
+	}
+	*e.logs = append(*e.logs, &types.Log{
+		Address: e.Self,
+		Topics:  topics,
+		Data:    data,
+	})
+}
+
+// Call calls another contract with the called contract as the sender.
+func (e *Env) Call(to common.Address, input []byte) ([]byte, error) {
+	return e.chain.call(&Env{
+		chain:  e.chain,
+		logs:   e.logs,
+		static: e.static,
+		From:   e.Self,
+		Self:   to,
+		Block:  e.Block,
+	}, input)
+}
+
+// Create deploys a new contract with the called contract as the creator
+// and returns its address.
+func (e *Env) Create(contract Contract, code []byte) common.Address {
+	nonce := e.chain.nonce(e.Self)
+	address := crypto.CreateAddress(e.Self, nonce)
+	e.chain.setNonce(e.Self, nonce+1, e.Block)
+	e.chain.accounts[address] = &account{contract: contract, code: code}
+	return address
+}
+
+type account struct {
+	contract Contract
+	code     []byte
+}
+
+type nonceAt struct {
+	block uint64
+	nonce uint64
+}
+
+// Chain is an in-process blockchain which executes transactions against
+// contracts simulated in Go. Every transaction is mined in a block of its
+// own as soon as it is sent. The chain does not meter gas, every
+// transaction pays for its full gas limit.
+type Chain struct {
+	mu sync.Mutex
+
+	chainID  *big.Int
+	gasPrice *big.Int
+	signer   types.Signer
+
+	headers  []*types.Header
+	txs      map[common.Hash]*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+	logs     []types.Log
+	balances map[common.Address]*big.Int
+	nonces   map[common.Address][]nonceAt
+	accounts map[common.Address]*account
+}
+
+var _ transaction.Backend = (*Chain)(nil)
+
+// NewChain creates a simulated chain with a genesis block.
+func NewChain(chainID int64) *Chain {
+	c := &Chain{
+		chainID:  big.NewInt(chainID),
+		gasPrice: big.NewInt(1000000000),
+		signer:   types.LatestSignerForChainID(big.NewInt(chainID)),
+		txs:      make(map[common.Hash]*types.Transaction),
+		receipts: make(map[common.Hash]*types.Receipt),
+		balances: make(map[common.Address]*big.Int),
+		nonces:   make(map[common.Address][]nonceAt),
+		accounts: make(map[common.Address]*account),
+	}
+	c.headers = append(c.headers, &types.Header{
+		Number:     big.NewInt(0),
+		Time:       uint64(time.Now().Unix()),
+		Difficulty: big.NewInt(0),
+	})
+	return c
+}
+
+// Deploy places a contract with the given code at the given address.
+func (c *Chain) Deploy(address common.Address, contract Contract, code []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accounts[address] = &account{contract: contract, code: code}
+}
+
+// SetBalance sets the native balance of an account.
+func (c *Chain) SetBalance(address common.Address, balance *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.balances[address] = new(big.Int).Set(balance)
+}
+
+// SetGasPrice sets the gas price suggested by the chain.
+func (c *Chain) SetGasPrice(gasPrice *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gasPrice = new(big.Int).Set(gasPrice)
+}
+
+// Mine mines an empty block and returns its number.
+func (c *Chain) Mine() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mine().Number.Uint64()
+}
+
+// Exec calls a contract outside of a transaction, e.g. to set up the
+// genesis state, and mines the resulting logs in a new block.
+func (c *Chain) Exec(from, to common.Address, input []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var logs []*types.Log
+	number := c.latest().Number.Uint64() + 1
+	out, err := c.call(&Env{chain: c, logs: &logs, From: from, Self: to, Block: number}, input)
+	if err != nil {
+		return nil, err
+	}
+	header := c.mine()
+	c.addLogs(header, common.Hash{}, 0, logs)
+	return out, nil
+}
+
+func (c *Chain) latest() *types.Header {
+	return c.headers[len(c.headers)-1]
+}
+
+// mine appends a new block header, the caller must hold the lock.
+func (c *Chain) mine() *types.Header {
+	parent := c.latest()
+	now := uint64(time.Now().Unix())
+	if now < parent.Time {
+		now = parent.Time
+	}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, big.NewInt(1)),
+		Time:       now,
+		Difficulty: big.NewInt(0),
+	}
+	c.headers = append(c.headers, header)
+	return header
+}
+
+func (c *Chain) addLogs(header *types.Header, txHash common.Hash, txIndex uint, logs []*types.Log) {
+	for _, l := range logs {
+		l.BlockNumber = header.Number.Uint64()
+		l.BlockHash = header.Hash()
+		l.TxHash = txHash
+		l.TxIndex = txIndex
+		l.Index = uint(len(c.logs))
+		c.logs = append(c.logs, *l)
+	}
+}
+
+func (c *Chain) call(env *Env, input []byte) ([]byte, error) {
+	acc, ok := c.accounts[env.Self]
+	if !ok {
+		// calls to accounts without code succeed without output
+		return nil, nil
+	}
+	return acc.contract.Call(env, input)
+}
+
+func (c *Chain) balance(address common.Address) *big.Int {
+	if b, ok := c.balances[address]; ok {
+		return b
+	}
+	return big.NewInt(0)
+}
+
+func (c *Chain) nonce(address common.Address) uint64 {
+	return c.nonceAt(address, c.latest().Number.Uint64())
+}
+
+func (c *Chain) nonceAt(address common.Address, block uint64) uint64 {
+	history := c.nonces[address]
+	i := sort.Search(len(history), func(i int) bool {
+		return history[i].block > block
+	})
+	if i == 0 {
+		return 0
+	}
+	return history[i-1].nonce
+}
+
+func (c *Chain) setNonce(address common.Address, nonce, block uint64) {
+	c.nonces[address] = append(c.nonces[address], nonceAt{block: block, nonce: nonce})
+}
+
+func (c *Chain) blockNumber(number *big.Int) (uint64, error) {
+	latest := c.latest().Number.Uint64()
+	if number == nil {
+		return latest, nil
+	}
+	if !number.IsUint64() || number.Uint64() > latest {
+		return 0, ethereum.NotFound
+	}
+	return number.Uint64(), nil
+}
+
+func (c *Chain) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if acc, ok := c.accounts[contract]; ok {
+		return acc.code, nil
+	}
+	return nil, nil
+}
+
+func (c *Chain) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if call.To == nil {
+		return nil, errors.New("contract creation not supported")
+	}
+	number, err := c.blockNumber(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return c.call(&Env{chain: c, static: true, From: call.From, Self: *call.To, Block: number}, call.Data)
+}
+
+func (c *Chain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n, err := c.blockNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	return types.CopyHeader(c.headers[n]), nil
+}
+
+func (c *Chain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nonce(account), nil
+}
+
+func (c *Chain) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.gasPrice), nil
+}
+
+func (c *Chain) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if call.To != nil {
+		if _, ok := c.accounts[*call.To]; ok {
+			return callGas, nil
+		}
+	}
+	return transferGas, nil
+}
+
+// SendTransaction executes the transaction and mines it in a new block.
+func (c *Chain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if tx.To() == nil {
+		return errors.New("contract creation not supported")
+	}
+	from, err := types.Sender(c.signer, tx)
+	if err != nil {
+		return fmt.Errorf("invalid transaction signature: %w", err)
+	}
+	if _, ok := c.txs[tx.Hash()]; ok {
+		return errors.New("already known")
+	}
+	if nonce := c.nonce(from); tx.Nonce() != nonce {
+		return fmt.Errorf("%w: have %d, want %d", ErrNonceMismatch, tx.Nonce(), nonce)
+	}
+
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())
+	cost := new(big.Int).Add(fee, tx.Value())
+	if c.balance(from).Cmp(cost) < 0 {
+		return ErrInsufficientFunds
+	}
+
+	header := c.mine()
+	number := header.Number.Uint64()
+	c.setNonce(from, tx.Nonce()+1, number)
+	c.balances[from] = new(big.Int).Sub(c.balance(from), fee)
+
+	var logs []*types.Log
+	status := types.ReceiptStatusSuccessful
+	if _, ok := c.accounts[*tx.To()]; ok {
+		if tx.Value().Sign() > 0 {
+			status = types.ReceiptStatusFailed
+		} else if _, err := c.call(&Env{chain: c, logs: &logs, From: from, Self: *tx.To(), Block: number}, tx.Data()); err != nil {
+			status = types.ReceiptStatusFailed
+		}
+	} else {
+		c.balances[from] = new(big.Int).Sub(c.balance(from), tx.Value())
+		c.balances[*tx.To()] = new(big.Int).Add(c.balance(*tx.To()), tx.Value())
+	}
+	if status == types.ReceiptStatusFailed {
+		logs = nil
+	}
+
+	c.addLogs(header, tx.Hash(), 0, logs)
+	c.txs[tx.Hash()] = tx
+	c.receipts[tx.Hash()] = &types.Receipt{
+		Type:              tx.Type(),
+		Status:            status,
+		CumulativeGasUsed: tx.Gas(),
+		Logs:              logs,
+		TxHash:            tx.Hash(),
+		GasUsed:           tx.Gas(),
+		BlockHash:         header.Hash(),
+		BlockNumber:       header.Number,
+	}
+	return nil
+}
+
+func (c *Chain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	receipt, ok := c.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func (c *Chain) TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tx, ok := c.txs[hash]
+	if !ok {
+		return nil, false, ethereum.NotFound
+	}
+	return tx, false, nil
+}
+
+func (c *Chain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latest().Number.Uint64(), nil
+}
+
+func (c *Chain) BalanceAt(ctx context.Context, address common.Address, block *big.Int) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.balance(address)), nil
+}
+
+func (c *Chain) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n, err := c.blockNumber(blockNumber)
+	if err != nil {
+		return 0, err
+	}
+	return c.nonceAt(account, n), nil
+}
+
+func (c *Chain) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	from, to := uint64(0), c.latest().Number.Uint64()
+	if query.FromBlock != nil {
+		from = query.FromBlock.Uint64()
+	}
+	if query.ToBlock != nil {
+		to = query.ToBlock.Uint64()
+	}
+
+	var logs []types.Log
+	for _, l := range c.logs {
+		if l.BlockNumber < from || l.BlockNumber > to {
+			continue
+		}
+		if matchLog(l, query) {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func matchLog(l types.Log, query ethereum.FilterQuery) bool {
+	if len(query.Addresses) > 0 {
+		found := false
+		for _, a := range query.Addresses {
+			if a == l.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(query.Topics) > len(l.Topics) {
+		return false
+	}
+	for i, alternatives := range query.Topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range alternatives {
+			if topic == l.Topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Chain) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(c.chainID), nil
+}
+
+func (c *Chain) Close() {}
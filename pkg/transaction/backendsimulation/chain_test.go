@@ -0,0 +1,368 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package backendsimulation_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/logging"
+	"github.com/holisticode/bee/pkg/postage"
+	"github.com/holisticode/bee/pkg/postage/batchservice"
+	"github.com/holisticode/bee/pkg/postage/batchstore"
+	"github.com/holisticode/bee/pkg/postage/listener"
+	"github.com/holisticode/bee/pkg/postage/postagecontract"
+	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
+	"github.com/holisticode/bee/pkg/settlement/swap/erc20"
+	"github.com/holisticode/bee/pkg/statestore/leveldb"
+	"github.com/holisticode/bee/pkg/storage"
+	"github.com/holisticode/bee/pkg/transaction"
+	"github.com/holisticode/bee/pkg/transaction/backendsimulation"
+)
+
+const chainID = 1337
+
+var (
+	admin = common.HexToAddress("0xad")
+	eth   = big.NewInt(1000000000000000000)
+	bzz   = big.NewInt(1000000000000000000)
+)
+
+type node struct {
+	signer    crypto.Signer
+	address   common.Address
+	store     storage.StateStorer
+	txService transaction.Service
+	erc20     erc20.Service
+}
+
+func newNode(t *testing.T, logger logging.Logger, d *backendsimulation.Deployment) *node {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	address, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Fund(address, eth, bzz); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := leveldb.NewInMemoryStateStore(logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	monitor := transaction.NewMonitor(logger, d.Chain, address, 10*time.Millisecond, 6)
+	t.Cleanup(func() { monitor.Close() })
+	txService, err := transaction.NewService(logger, d.Chain, signer, store, big.NewInt(chainID), monitor, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { txService.Close() })
+
+	return &node{
+		signer:    signer,
+		address:   address,
+		store:     store,
+		txService: txService,
+		erc20:     erc20.New(d.Chain, txService, d.Token),
+	}
+}
+
+// mine mines blocks in the background until the test ends.
+func mine(t *testing.T, chain *backendsimulation.Chain) {
+	t.Helper()
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(quit)
+		<-done
+	})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-quit:
+				return
+			case <-time.After(5 * time.Millisecond):
+				chain.Mine()
+			}
+		}
+	}()
+}
+
+func waitFor(t *testing.T, msg string, f func() bool) {
+	t.Helper()
+	for i := 0; i < 500; i++ {
+		if f() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", msg)
+}
+
+type noopShutdowner struct{}
+
+func (noopShutdowner) Shutdown(context.Context) error { return nil }
+
+func TestChainTransactions(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.New(ioutil.Discard, 0)
+
+	chain := backendsimulation.NewChain(chainID)
+	d, err := backendsimulation.Deploy(chain, admin, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := newNode(t, logger, d)
+	recipient := common.HexToAddress("0xabcd")
+
+	txHash, err := sender.txService.Send(ctx, &transaction.TxRequest{
+		To:    &recipient,
+		Value: big.NewInt(10),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := sender.txService.WaitForReceipt(ctx, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != 1 {
+		t.Fatalf("got status %d, want 1", receipt.Status)
+	}
+
+	balance, err := chain.BalanceAt(ctx, recipient, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("got balance %d, want 10", balance)
+	}
+
+	// a transfer exceeding the token balance reverts
+	txHash, err = sender.erc20.Transfer(ctx, recipient, new(big.Int).Add(bzz, big.NewInt(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sender.txService.WaitForReceipt(ctx, txHash); err != nil {
+		t.Fatal(err)
+	}
+	receipt, err = chain.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != 0 || len(receipt.Logs) != 0 {
+		t.Fatalf("expected reverted transaction without logs, got status %d and %d logs", receipt.Status, len(receipt.Logs))
+	}
+
+	logs, err := chain.FilterLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{d.Token}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("got %d token logs, want the mint only", len(logs))
+	}
+
+	// state changing methods can not be called statically
+	mint, err := backendsimulation.MintData(sender.address, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.CallContract(ctx, ethereum.CallMsg{From: admin, To: &d.Token, Data: mint}, nil); !errors.Is(err, backendsimulation.ErrWriteProtection) {
+		t.Fatalf("got error %v, want %v", err, backendsimulation.ErrWriteProtection)
+	}
+}
+
+func TestChainPostage(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.New(ioutil.Discard, 0)
+
+	chain := backendsimulation.NewChain(chainID)
+	d, err := backendsimulation.Deploy(chain, admin, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := newNode(t, logger, d)
+
+	batchStore, err := batchstore.New(owner.store, func([]byte) error { return nil }, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, err := postage.NewService(owner.store, batchStore, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventListener := listener.New(logger, chain, d.PostageStamp, 0, noopShutdowner{}, time.Minute, 10*time.Millisecond)
+	t.Cleanup(func() { eventListener.Close() })
+	batchSvc, err := batchservice.New(owner.store, batchStore, logger, eventListener, owner.address.Bytes(), post, nil, 0, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mine(t, chain)
+	synced, err := batchSvc.Start(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-synced
+
+	erc20Address, err := postagecontract.LookupERC20Address(ctx, owner.txService, d.PostageStamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if erc20Address != d.Token {
+		t.Fatalf("got token %x, want %x", erc20Address, d.Token)
+	}
+
+	contract := postagecontract.New(owner.address, d.PostageStamp, d.Token, owner.txService, post, batchStore)
+
+	id, err := contract.CreateBatch(ctx, big.NewInt(100000), 20, false, "label")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var batch *postage.Batch
+	waitFor(t, "batch creation", func() bool {
+		batch, err = batchStore.Get(id)
+		return err == nil
+	})
+	if batch.Depth != 20 || common.BytesToAddress(batch.Owner) != owner.address {
+		t.Fatalf("got batch with depth %d and owner %x", batch.Depth, batch.Owner)
+	}
+	if price := batchStore.GetChainState().CurrentPrice; price.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("got price %d, want 1", price)
+	}
+
+	balance, err := owner.erc20.BalanceOf(ctx, owner.address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := new(big.Int).Sub(bzz, big.NewInt(100000<<20)); balance.Cmp(want) != 0 {
+		t.Fatalf("got token balance %d, want %d", balance, want)
+	}
+
+	value := batch.Value
+	if err := contract.TopUpBatch(ctx, id, big.NewInt(500)); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "batch top up", func() bool {
+		batch, err = batchStore.Get(id)
+		return err == nil && batch.Value.Cmp(value) != 0
+	})
+	if want := new(big.Int).Add(value, big.NewInt(500)); batch.Value.Cmp(want) != 0 {
+		t.Fatalf("got value %d, want %d", batch.Value, want)
+	}
+
+	value = batch.Value
+	if err := contract.DiluteBatch(ctx, id, 21); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "batch dilution", func() bool {
+		batch, err = batchStore.Get(id)
+		return err == nil && batch.Depth == 21
+	})
+	if batch.Value.Cmp(value) >= 0 {
+		t.Fatalf("got value %d, want less than %d after dilution", batch.Value, value)
+	}
+}
+
+func TestChainChequebook(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.New(ioutil.Discard, 0)
+
+	chain := backendsimulation.NewChain(chainID)
+	d, err := backendsimulation.Deploy(chain, admin, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := newNode(t, logger, d)
+	beneficiary := newNode(t, logger, d)
+
+	deposit := big.NewInt(10000)
+	factory := chequebook.NewFactory(chain, issuer.txService, d.Factory, nil)
+	chequebookService, err := chequebook.Init(ctx, factory, issuer.store, logger, deposit, issuer.txService, chain, chainID, issuer.address, chequebook.NewChequeSigner(issuer.signer, chainID), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balance, err := chequebookService.Balance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(deposit) != 0 {
+		t.Fatalf("got chequebook balance %d, want %d", balance, deposit)
+	}
+
+	var cheque *chequebook.SignedCheque
+	if _, err := chequebookService.Issue(ctx, beneficiary.address, big.NewInt(3000), func(c *chequebook.SignedCheque) error {
+		cheque = c
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	chequeStore := chequebook.NewChequeStore(beneficiary.store, chequebook.NewFactory(chain, beneficiary.txService, d.Factory, nil), chainID, beneficiary.address, beneficiary.txService, chequebook.RecoverCheque)
+	if _, err := chequeStore.ReceiveCheque(ctx, cheque, big.NewInt(1), big.NewInt(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	cashout := chequebook.NewCashoutService(beneficiary.store, chain, beneficiary.txService, chequeStore)
+	txHash, err := cashout.CashCheque(ctx, chequebookService.Address(), beneficiary.address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := beneficiary.txService.WaitForReceipt(ctx, txHash); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := cashout.CashoutStatus(ctx, chequebookService.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Last == nil || status.Last.Result == nil {
+		t.Fatal("expected cashout result")
+	}
+	if status.Last.Result.TotalPayout.Cmp(big.NewInt(3000)) != 0 || status.Last.Result.Bounced {
+		t.Fatalf("got payout %d (bounced %t), want 3000", status.Last.Result.TotalPayout, status.Last.Result.Bounced)
+	}
+
+	received, err := beneficiary.erc20.BalanceOf(ctx, beneficiary.address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := new(big.Int).Add(bzz, big.NewInt(3000)); received.Cmp(want) != 0 {
+		t.Fatalf("got beneficiary balance %d, want %d", received, want)
+	}
+
+	txHash, err = chequebookService.Withdraw(ctx, big.NewInt(7000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issuer.txService.WaitForReceipt(ctx, txHash); err != nil {
+		t.Fatal(err)
+	}
+	balance, err = chequebookService.Balance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Sign() != 0 {
+		t.Fatalf("got chequebook balance %d after withdrawal, want 0", balance)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package backendsimulation
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/go-sw3-abi/sw3abi"
+	"github.com/holisticode/bee/pkg/settlement/swap/chequebook"
+	"github.com/holisticode/bee/pkg/transaction"
+)
+
+var (
+	chequebookABI = transaction.ParseABIUnchecked(sw3abi.ERC20SimpleSwapABIv0_3_1)
+	factoryABI    = transaction.ParseABIUnchecked(sw3abi.SimpleSwapFactoryABIv0_4_0)
+
+	// FactoryCode is the code reported for simulated chequebook factories,
+	// it matches the bytecode the chequebook factory service verifies.
+	FactoryCode = common.FromHex(sw3abi.SimpleSwapFactoryDeployedBinv0_4_0)
+	// chequebookCode is the code reported for simulated chequebooks.
+	chequebookCode = []byte("simulated chequebook")
+)
+
+// Chequebook simulates an ERC20SimpleSwap chequebook without hard deposits.
+type Chequebook struct {
+	abiContract
+	issuer       common.Address
+	token        common.Address
+	timeout      *big.Int
+	paidOut      map[common.Address]*big.Int
+	totalPaidOut *big.Int
+	bounced      bool
+}
+
+// NewChequebook creates a simulated chequebook of the issuer paying out in
+// the given token.
+func NewChequebook(issuer, token common.Address, defaultHardDepositTimeout *big.Int) *Chequebook {
+	c := &Chequebook{
+		abiContract:  abiContract{abi: &chequebookABI},
+		issuer:       issuer,
+		token:        token,
+		timeout:      new(big.Int).Set(defaultHardDepositTimeout),
+		paidOut:      make(map[common.Address]*big.Int),
+		totalPaidOut: big.NewInt(0),
+	}
+	c.methods = map[string]method{
+		"issuer":                    c.getIssuer,
+		"token":                     c.getToken,
+		"balance":                   c.balance,
+		"liquidBalance":             c.balance,
+		"liquidBalanceFor":          c.balance,
+		"totalHardDeposit":          c.zero,
+		"defaultHardDepositTimeout": c.defaultHardDepositTimeout,
+		"paidOut":                   c.getPaidOut,
+		"totalPaidOut":              c.getTotalPaidOut,
+		"bounced":                   c.getBounced,
+		"cashChequeBeneficiary":     c.cashChequeBeneficiary,
+		"withdraw":                  c.withdraw,
+	}
+	return c
+}
+
+func (c *Chequebook) tokenBalance(env *Env) (*big.Int, error) {
+	callData, err := erc20ABI.Pack("balanceOf", env.Self)
+	if err != nil {
+		return nil, err
+	}
+	out, err := env.Call(c.token, callData)
+	if err != nil {
+		return nil, err
+	}
+	results, err := erc20ABI.Unpack("balanceOf", out)
+	if err != nil {
+		return nil, err
+	}
+	return results[0].(*big.Int), nil
+}
+
+func (c *Chequebook) tokenTransfer(env *Env, to common.Address, amount *big.Int) error {
+	callData, err := erc20ABI.Pack("transfer", to, amount)
+	if err != nil {
+		return err
+	}
+	_, err = env.Call(c.token, callData)
+	return err
+}
+
+func (c *Chequebook) paidOutTo(beneficiary common.Address) *big.Int {
+	if p, ok := c.paidOut[beneficiary]; ok {
+		return p
+	}
+	return big.NewInt(0)
+}
+
+func (c *Chequebook) getIssuer(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{c.issuer}, nil
+}
+
+func (c *Chequebook) getToken(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{c.token}, nil
+}
+
+func (c *Chequebook) balance(env *Env, args []interface{}) ([]interface{}, error) {
+	balance, err := c.tokenBalance(env)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{balance}, nil
+}
+
+func (c *Chequebook) zero(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{big.NewInt(0)}, nil
+}
+
+func (c *Chequebook) defaultHardDepositTimeout(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{new(big.Int).Set(c.timeout)}, nil
+}
+
+func (c *Chequebook) getPaidOut(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{new(big.Int).Set(c.paidOutTo(args[0].(common.Address)))}, nil
+}
+
+func (c *Chequebook) getTotalPaidOut(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{new(big.Int).Set(c.totalPaidOut)}, nil
+}
+
+func (c *Chequebook) getBounced(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{c.bounced}, nil
+}
+
+// cashChequeBeneficiary pays out a cheque issued to the caller. If the
+// chequebook does not cover the cheque the available balance is paid out
+// and the chequebook is marked as bounced.
+func (c *Chequebook) cashChequeBeneficiary(env *Env, args []interface{}) ([]interface{}, error) {
+	recipient, cumulativePayout, signature := args[0].(common.Address), args[1].(*big.Int), args[2].([]byte)
+
+	signer, err := chequebook.RecoverCheque(&chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Chequebook:       env.Self,
+			Beneficiary:      env.From,
+			CumulativePayout: cumulativePayout,
+		},
+		Signature: signature,
+	}, env.ChainID().Int64())
+	if err != nil || signer != c.issuer {
+		return nil, revert("invalid issuer signature")
+	}
+
+	paidOut := c.paidOutTo(env.From)
+	if cumulativePayout.Cmp(paidOut) <= 0 {
+		return nil, revert("cheque already cashed")
+	}
+	requestPayout := new(big.Int).Sub(cumulativePayout, paidOut)
+
+	balance, err := c.tokenBalance(env)
+	if err != nil {
+		return nil, err
+	}
+	totalPayout := requestPayout
+	if balance.Cmp(requestPayout) < 0 {
+		totalPayout = balance
+	}
+
+	if err := c.tokenTransfer(env, recipient, totalPayout); err != nil {
+		return nil, fmt.Errorf("pay out cheque: %w", err)
+	}
+	c.paidOut[env.From] = new(big.Int).Add(paidOut, totalPayout)
+	c.totalPaidOut = new(big.Int).Add(c.totalPaidOut, totalPayout)
+
+	if err := emit(env, &chequebookABI, "ChequeCashed", env.From, recipient, env.From, totalPayout, cumulativePayout, big.NewInt(0)); err != nil {
+		return nil, err
+	}
+	if totalPayout.Cmp(requestPayout) != 0 {
+		c.bounced = true
+		if err := emit(env, &chequebookABI, "ChequeBounced"); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (c *Chequebook) withdraw(env *Env, args []interface{}) ([]interface{}, error) {
+	amount := args[0].(*big.Int)
+	if env.From != c.issuer {
+		return nil, revert("not issuer")
+	}
+	balance, err := c.tokenBalance(env)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Cmp(balance) > 0 {
+		return nil, revert("liquid balance not sufficient")
+	}
+	if err := c.tokenTransfer(env, c.issuer, amount); err != nil {
+		return nil, err
+	}
+	return nil, emit(env, &chequebookABI, "Withdraw", amount)
+}
+
+// Factory simulates the SimpleSwapFactory deploying chequebooks.
+type Factory struct {
+	abiContract
+	token    common.Address
+	deployed map[common.Address]bool
+}
+
+// NewFactory creates a simulated chequebook factory for the given token.
+func NewFactory(token common.Address) *Factory {
+	f := &Factory{
+		abiContract: abiContract{abi: &factoryABI},
+		token:       token,
+		deployed:    make(map[common.Address]bool),
+	}
+	f.methods = map[string]method{
+		"ERC20Address":      f.erc20Address,
+		"deployedContracts": f.deployedContracts,
+		"deploySimpleSwap":  f.deploySimpleSwap,
+	}
+	return f
+}
+
+func (f *Factory) erc20Address(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{f.token}, nil
+}
+
+func (f *Factory) deployedContracts(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{f.deployed[args[0].(common.Address)]}, nil
+}
+
+func (f *Factory) deploySimpleSwap(env *Env, args []interface{}) ([]interface{}, error) {
+	issuer, timeout := args[0].(common.Address), args[1].(*big.Int)
+	address := env.Create(NewChequebook(issuer, f.token, timeout), chequebookCode)
+	f.deployed[address] = true
+	if err := emit(env, &factoryABI, "SimpleSwapDeployed", address); err != nil {
+		return nil, err
+	}
+	return []interface{}{address}, nil
+}
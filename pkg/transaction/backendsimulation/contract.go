@@ -0,0 +1,85 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package backendsimulation
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// method implements a single contract method on unpacked arguments.
+type method func(env *Env, args []interface{}) ([]interface{}, error)
+
+// abiContract dispatches calls to the methods of a contract ABI.
+type abiContract struct {
+	abi     *abi.ABI
+	methods map[string]method
+}
+
+func (c *abiContract) Call(env *Env, input []byte) ([]byte, error) {
+	if len(input) < 4 {
+		return nil, revert("missing method id")
+	}
+	m, err := c.abi.MethodById(input[:4])
+	if err != nil {
+		return nil, revert(err.Error())
+	}
+	fn, ok := c.methods[m.Name]
+	if !ok {
+		return nil, revert(fmt.Sprintf("method %s not supported", m.Name))
+	}
+	if env.Static() && !m.IsConstant() {
+		return nil, ErrWriteProtection
+	}
+	args, err := m.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, revert(err.Error())
+	}
+	out, err := fn(env, args)
+	if err != nil {
+		return nil, err
+	}
+	return m.Outputs.Pack(out...)
+}
+
+// emit packs the event arguments according to the ABI and records the log.
+func emit(env *Env, a *abi.ABI, name string, args ...interface{}) error {
+	event, ok := a.Events[name]
+	if !ok {
+		return fmt.Errorf("unknown event %s", name)
+	}
+	topics := []common.Hash{event.ID}
+	var (
+		data       []interface{}
+		nonIndexed abi.Arguments
+	)
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			data = append(data, args[i])
+			nonIndexed = append(nonIndexed, input)
+			continue
+		}
+		switch v := args[i].(type) {
+		case common.Address:
+			topics = append(topics, common.BytesToHash(v.Bytes()))
+		case [32]byte:
+			topics = append(topics, v)
+		default:
+			return fmt.Errorf("unsupported indexed argument %s", input.Name)
+		}
+	}
+	packed, err := nonIndexed.Pack(data...)
+	if err != nil {
+		return err
+	}
+	env.Emit(topics, packed)
+	return nil
+}
+
+func revert(reason string) error {
+	return fmt.Errorf("%w: %s", ErrExecutionReverted, reason)
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package backendsimulation
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Deployment is a set of simulated swarm contracts on a chain.
+type Deployment struct {
+	Chain        *Chain
+	Admin        common.Address // the token minter and postage price oracle
+	Token        common.Address
+	Factory      common.Address
+	PostageStamp common.Address
+}
+
+// Deploy deploys a token, a chequebook factory and a postage stamp contract
+// administered by admin and sets the initial postage price.
+func Deploy(c *Chain, admin common.Address, price *big.Int) (*Deployment, error) {
+	d := &Deployment{
+		Chain:        c,
+		Admin:        admin,
+		Token:        crypto.CreateAddress(admin, 0),
+		Factory:      crypto.CreateAddress(admin, 1),
+		PostageStamp: crypto.CreateAddress(admin, 2),
+	}
+	c.Deploy(d.Token, NewToken(admin), []byte("simulated token"))
+	c.Deploy(d.Factory, NewFactory(d.Token), FactoryCode)
+	c.Deploy(d.PostageStamp, NewPostageStamp(d.Token, admin), PostageStampCode)
+
+	if err := d.SetPrice(price); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Fund sets the native balance of the account and mints tokens to it.
+func (d *Deployment) Fund(account common.Address, eth, bzz *big.Int) error {
+	d.Chain.SetBalance(account, eth)
+	callData, err := MintData(account, bzz)
+	if err != nil {
+		return err
+	}
+	_, err = d.Chain.Exec(d.Admin, d.Token, callData)
+	return err
+}
+
+// SetPrice sets the postage price per chunk and block.
+func (d *Deployment) SetPrice(price *big.Int) error {
+	callData, err := postageStampABI.Pack("setPrice", price)
+	if err != nil {
+		return err
+	}
+	_, err = d.Chain.Exec(d.Admin, d.PostageStamp, callData)
+	return err
+}
@@ -0,0 +1,247 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package backendsimulation
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethersphere/go-storage-incentives-abi/postageabi"
+	"github.com/holisticode/bee/pkg/transaction"
+)
+
+var (
+	postageStampABI = transaction.ParseABIUnchecked(postageabi.PostageStampABIv0_3_0)
+
+	// PostageStampCode is the code reported for simulated postage stamp
+	// contracts.
+	PostageStampCode = common.FromHex(postageabi.PostageStampDeployedBinv0_3_0)
+
+	batchIDArguments = abi.Arguments{
+		{Type: mustNewType("address")},
+		{Type: mustNewType("bytes32")},
+	}
+)
+
+type stampBatch struct {
+	owner             common.Address
+	depth             uint8
+	immutable         bool
+	normalisedBalance *big.Int
+}
+
+// PostageStamp simulates the postage stamp contract. The price can only be
+// set by the price oracle given on construction.
+type PostageStamp struct {
+	abiContract
+	token            common.Address
+	oracle           common.Address
+	batches          map[[32]byte]*stampBatch
+	totalOutPayment  *big.Int
+	lastPrice        *big.Int
+	lastUpdatedBlock uint64
+}
+
+// NewPostageStamp creates a simulated postage stamp contract paid for in
+// the given token.
+func NewPostageStamp(token, oracle common.Address) *PostageStamp {
+	p := &PostageStamp{
+		abiContract:     abiContract{abi: &postageStampABI},
+		token:           token,
+		oracle:          oracle,
+		batches:         make(map[[32]byte]*stampBatch),
+		totalOutPayment: big.NewInt(0),
+		lastPrice:       big.NewInt(0),
+	}
+	p.methods = map[string]method{
+		"bzzToken":               p.bzzToken,
+		"batches":                p.getBatch,
+		"currentTotalOutPayment": p.getCurrentTotalOutPayment,
+		"totalOutPayment":        p.getTotalOutPayment,
+		"lastPrice":              p.getLastPrice,
+		"lastUpdatedBlock":       p.getLastUpdatedBlock,
+		"remainingBalance":       p.remainingBalance,
+		"paused":                 p.paused,
+		"createBatch":            p.createBatch,
+		"topUp":                  p.topUp,
+		"increaseDepth":          p.increaseDepth,
+		"setPrice":               p.setPrice,
+	}
+	return p
+}
+
+func mustNewType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
+
+func (p *PostageStamp) currentTotalOutPayment(block uint64) *big.Int {
+	blocks := new(big.Int).SetUint64(block - p.lastUpdatedBlock)
+	return new(big.Int).Add(p.totalOutPayment, new(big.Int).Mul(blocks, p.lastPrice))
+}
+
+func (p *PostageStamp) liveBatch(env *Env, id [32]byte) (*stampBatch, error) {
+	b, ok := p.batches[id]
+	if !ok {
+		return nil, revert("batch does not exist")
+	}
+	if b.normalisedBalance.Cmp(p.currentTotalOutPayment(env.Block)) <= 0 {
+		return nil, revert("batch already expired")
+	}
+	return b, nil
+}
+
+func (p *PostageStamp) pull(env *Env, amount *big.Int) error {
+	callData, err := erc20ABI.Pack("transferFrom", env.From, env.Self, amount)
+	if err != nil {
+		return err
+	}
+	if _, err := env.Call(p.token, callData); err != nil {
+		return fmt.Errorf("failed transfer: %w", err)
+	}
+	return nil
+}
+
+func (p *PostageStamp) bzzToken(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{p.token}, nil
+}
+
+func (p *PostageStamp) getBatch(env *Env, args []interface{}) ([]interface{}, error) {
+	b, ok := p.batches[args[0].([32]byte)]
+	if !ok {
+		return []interface{}{common.Address{}, uint8(0), false, big.NewInt(0)}, nil
+	}
+	return []interface{}{b.owner, b.depth, b.immutable, new(big.Int).Set(b.normalisedBalance)}, nil
+}
+
+func (p *PostageStamp) getCurrentTotalOutPayment(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{p.currentTotalOutPayment(env.Block)}, nil
+}
+
+func (p *PostageStamp) getTotalOutPayment(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{new(big.Int).Set(p.totalOutPayment)}, nil
+}
+
+func (p *PostageStamp) getLastPrice(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{new(big.Int).Set(p.lastPrice)}, nil
+}
+
+func (p *PostageStamp) getLastUpdatedBlock(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{new(big.Int).SetUint64(p.lastUpdatedBlock)}, nil
+}
+
+func (p *PostageStamp) remainingBalance(env *Env, args []interface{}) ([]interface{}, error) {
+	b, ok := p.batches[args[0].([32]byte)]
+	if !ok {
+		return nil, revert("batch does not exist")
+	}
+	remaining := new(big.Int).Sub(b.normalisedBalance, p.currentTotalOutPayment(env.Block))
+	if remaining.Sign() < 0 {
+		remaining.SetUint64(0)
+	}
+	return []interface{}{remaining}, nil
+}
+
+func (p *PostageStamp) paused(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{false}, nil
+}
+
+func (p *PostageStamp) createBatch(env *Env, args []interface{}) ([]interface{}, error) {
+	owner := args[0].(common.Address)
+	initialBalancePerChunk := args[1].(*big.Int)
+	depth, bucketDepth := args[2].(uint8), args[3].(uint8)
+	nonce, immutable := args[4].([32]byte), args[5].(bool)
+
+	if owner == (common.Address{}) {
+		return nil, revert("owner cannot be the zero address")
+	}
+	if bucketDepth == 0 || bucketDepth >= depth {
+		return nil, revert("invalid bucket depth")
+	}
+	encoded, err := batchIDArguments.Pack(env.From, nonce)
+	if err != nil {
+		return nil, err
+	}
+	var id [32]byte
+	copy(id[:], crypto.Keccak256(encoded))
+	if _, ok := p.batches[id]; ok {
+		return nil, revert("batch already exists")
+	}
+
+	totalAmount := new(big.Int).Lsh(initialBalancePerChunk, uint(depth))
+	if err := p.pull(env, totalAmount); err != nil {
+		return nil, err
+	}
+
+	b := &stampBatch{
+		owner:             owner,
+		depth:             depth,
+		immutable:         immutable,
+		normalisedBalance: new(big.Int).Add(p.currentTotalOutPayment(env.Block), initialBalancePerChunk),
+	}
+	p.batches[id] = b
+	return nil, emit(env, &postageStampABI, "BatchCreated", id, totalAmount, b.normalisedBalance, owner, depth, bucketDepth, immutable)
+}
+
+func (p *PostageStamp) topUp(env *Env, args []interface{}) ([]interface{}, error) {
+	id, topupAmountPerChunk := args[0].([32]byte), args[1].(*big.Int)
+
+	b, err := p.liveBatch(env, id)
+	if err != nil {
+		return nil, err
+	}
+	totalAmount := new(big.Int).Lsh(topupAmountPerChunk, uint(b.depth))
+	if err := p.pull(env, totalAmount); err != nil {
+		return nil, err
+	}
+
+	b.normalisedBalance = new(big.Int).Add(b.normalisedBalance, topupAmountPerChunk)
+	return nil, emit(env, &postageStampABI, "BatchTopUp", id, totalAmount, b.normalisedBalance)
+}
+
+func (p *PostageStamp) increaseDepth(env *Env, args []interface{}) ([]interface{}, error) {
+	id, newDepth := args[0].([32]byte), args[1].(uint8)
+
+	b, err := p.liveBatch(env, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.owner != env.From {
+		return nil, revert("not batch owner")
+	}
+	if newDepth <= b.depth {
+		return nil, revert("depth not increasing")
+	}
+	if b.immutable {
+		return nil, revert("batch is immutable")
+	}
+
+	current := p.currentTotalOutPayment(env.Block)
+	remaining := new(big.Int).Sub(b.normalisedBalance, current)
+	remaining.Rsh(remaining, uint(newDepth-b.depth))
+
+	b.depth = newDepth
+	b.normalisedBalance = new(big.Int).Add(current, remaining)
+	return nil, emit(env, &postageStampABI, "BatchDepthIncrease", id, newDepth, b.normalisedBalance)
+}
+
+func (p *PostageStamp) setPrice(env *Env, args []interface{}) ([]interface{}, error) {
+	price := args[0].(*big.Int)
+	if env.From != p.oracle {
+		return nil, revert("only price oracle can set the price")
+	}
+	if p.lastPrice.Sign() != 0 {
+		p.totalOutPayment = p.currentTotalOutPayment(env.Block)
+	}
+	p.lastPrice = new(big.Int).Set(price)
+	p.lastUpdatedBlock = env.Block
+	return nil, emit(env, &postageStampABI, "PriceUpdate", price)
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package backendsimulation
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/go-sw3-abi/sw3abi"
+	"github.com/holisticode/bee/pkg/transaction"
+)
+
+var (
+	erc20ABI = transaction.ParseABIUnchecked(sw3abi.ERC20ABIv0_3_1)
+	// mintABI describes the method used to create tokens in the simulation.
+	mintABI = transaction.ParseABIUnchecked(`[{"inputs":[{"name":"account","type":"address"},{"name":"amount","type":"uint256"}],"name":"mint","outputs":[],"stateMutability":"nonpayable","type":"function"}]`)
+)
+
+// Token simulates an ERC20 token. New tokens can be minted by the minter
+// with the mint(address,uint256) method.
+type Token struct {
+	abiContract
+	minter      common.Address
+	totalSupply *big.Int
+	balances    map[common.Address]*big.Int
+	allowances  map[[2]common.Address]*big.Int
+}
+
+// NewToken creates a simulated ERC20 token.
+func NewToken(minter common.Address) *Token {
+	t := &Token{
+		abiContract: abiContract{abi: &erc20ABI},
+		minter:      minter,
+		totalSupply: big.NewInt(0),
+		balances:    make(map[common.Address]*big.Int),
+		allowances:  make(map[[2]common.Address]*big.Int),
+	}
+	t.methods = map[string]method{
+		"balanceOf":    t.balanceOf,
+		"totalSupply":  t.getTotalSupply,
+		"decimals":     t.decimals,
+		"allowance":    t.allowance,
+		"approve":      t.approve,
+		"transfer":     t.transfer,
+		"transferFrom": t.transferFrom,
+	}
+	return t
+}
+
+// MintData returns the call data to mint amount tokens to account.
+func MintData(account common.Address, amount *big.Int) ([]byte, error) {
+	return mintABI.Pack("mint", account, amount)
+}
+
+// Call implements the Contract interface.
+func (t *Token) Call(env *Env, input []byte) ([]byte, error) {
+	if len(input) >= 4 && string(input[:4]) == string(mintABI.Methods["mint"].ID) {
+		if env.Static() {
+			return nil, ErrWriteProtection
+		}
+		if env.From != t.minter {
+			return nil, revert("caller is not the minter")
+		}
+		args, err := mintABI.Methods["mint"].Inputs.Unpack(input[4:])
+		if err != nil {
+			return nil, revert(err.Error())
+		}
+		account, amount := args[0].(common.Address), args[1].(*big.Int)
+		t.totalSupply = new(big.Int).Add(t.totalSupply, amount)
+		t.balances[account] = new(big.Int).Add(t.balance(account), amount)
+		return nil, emit(env, &erc20ABI, "Transfer", common.Address{}, account, amount)
+	}
+	return t.abiContract.Call(env, input)
+}
+
+func (t *Token) balance(account common.Address) *big.Int {
+	if b, ok := t.balances[account]; ok {
+		return b
+	}
+	return big.NewInt(0)
+}
+
+func (t *Token) allowanceOf(owner, spender common.Address) *big.Int {
+	if a, ok := t.allowances[[2]common.Address{owner, spender}]; ok {
+		return a
+	}
+	return big.NewInt(0)
+}
+
+func (t *Token) move(env *Env, from, to common.Address, amount *big.Int) error {
+	if t.balance(from).Cmp(amount) < 0 {
+		return revert("transfer amount exceeds balance")
+	}
+	t.balances[from] = new(big.Int).Sub(t.balance(from), amount)
+	t.balances[to] = new(big.Int).Add(t.balance(to), amount)
+	return emit(env, &erc20ABI, "Transfer", from, to, amount)
+}
+
+func (t *Token) balanceOf(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{new(big.Int).Set(t.balance(args[0].(common.Address)))}, nil
+}
+
+func (t *Token) getTotalSupply(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{new(big.Int).Set(t.totalSupply)}, nil
+}
+
+func (t *Token) decimals(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{uint8(16)}, nil
+}
+
+func (t *Token) allowance(env *Env, args []interface{}) ([]interface{}, error) {
+	return []interface{}{new(big.Int).Set(t.allowanceOf(args[0].(common.Address), args[1].(common.Address)))}, nil
+}
+
+func (t *Token) approve(env *Env, args []interface{}) ([]interface{}, error) {
+	spender, amount := args[0].(common.Address), args[1].(*big.Int)
+	t.allowances[[2]common.Address{env.From, spender}] = new(big.Int).Set(amount)
+	if err := emit(env, &erc20ABI, "Approval", env.From, spender, amount); err != nil {
+		return nil, err
+	}
+	return []interface{}{true}, nil
+}
+
+func (t *Token) transfer(env *Env, args []interface{}) ([]interface{}, error) {
+	if err := t.move(env, env.From, args[0].(common.Address), args[1].(*big.Int)); err != nil {
+		return nil, err
+	}
+	return []interface{}{true}, nil
+}
+
+func (t *Token) transferFrom(env *Env, args []interface{}) ([]interface{}, error) {
+	from, to, amount := args[0].(common.Address), args[1].(common.Address), args[2].(*big.Int)
+	allowance := t.allowanceOf(from, env.From)
+	if allowance.Cmp(amount) < 0 {
+		return nil, revert("transfer amount exceeds allowance")
+	}
+	if err := t.move(env, from, to, amount); err != nil {
+		return nil, err
+	}
+	t.allowances[[2]common.Address{from, env.From}] = new(big.Int).Sub(allowance, amount)
+	return []interface{}{true}, nil
+}
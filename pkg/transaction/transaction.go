@@ -17,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/holisticode/bee/pkg/crypto"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/sctx"
 	"github.com/holisticode/bee/pkg/storage"
@@ -98,10 +99,15 @@ type transactionService struct {
 	store   storage.StateStorer
 	chainID *big.Int
 	monitor Monitor
+
+	lowBalance *events.BalanceMonitor
 }
 
 // NewService creates a new transaction service.
-func NewService(logger logging.Logger, backend Backend, signer crypto.Signer, store storage.StateStorer, chainID *big.Int, monitor Monitor) (Service, error) {
+// If publisher and lowBalance are given, an event is published once the
+// native balance of the sender drops below lowBalance after a confirmed
+// transaction.
+func NewService(logger logging.Logger, backend Backend, signer crypto.Signer, store storage.StateStorer, chainID *big.Int, monitor Monitor, publisher events.Publisher, lowBalance *big.Int) (Service, error) {
 	senderAddress, err := signer.EthereumAddress()
 	if err != nil {
 		return nil, err
@@ -119,6 +125,8 @@ func NewService(logger logging.Logger, backend Backend, signer crypto.Signer, st
 		store:   store,
 		chainID: chainID,
 		monitor: monitor,
+
+		lowBalance: events.NewBalanceMonitor(publisher, events.WalletBalanceLow, senderAddress, lowBalance),
 	}
 
 	pendingTxs, err := t.PendingTransactions()
@@ -204,6 +212,7 @@ func (t *transactionService) waitForPendingTx(txHash common.Hash) {
 			}
 		} else {
 			t.logger.Tracef("pending transaction %x confirmed", txHash)
+			t.checkBalance()
 		}
 
 		err = t.store.Delete(pendingTransactionKey(txHash))
@@ -213,6 +222,20 @@ func (t *transactionService) waitForPendingTx(txHash common.Hash) {
 	}()
 }
 
+// checkBalance reports the native balance of the sender to the low balance
+// monitor, if any.
+func (t *transactionService) checkBalance() {
+	if t.lowBalance == nil {
+		return
+	}
+	balance, err := t.backend.BalanceAt(t.ctx, t.sender, nil)
+	if err != nil {
+		t.logger.Debugf("could not get wallet balance: %v", err)
+		return
+	}
+	t.lowBalance.Check(balance)
+}
+
 func (t *transactionService) Call(ctx context.Context, request *TxRequest) ([]byte, error) {
 	msg := ethereum.CallMsg{
 		From:     t.sender,
@@ -12,12 +12,14 @@ import (
 	"io"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/holisticode/bee/pkg/crypto"
 	signermock "github.com/holisticode/bee/pkg/crypto/mock"
+	"github.com/holisticode/bee/pkg/events"
 	"github.com/holisticode/bee/pkg/logging"
 	"github.com/holisticode/bee/pkg/sctx"
 	storemock "github.com/holisticode/bee/pkg/statestore/mock"
@@ -133,6 +135,8 @@ func TestTransactionSend(t *testing.T) {
 					return nil, nil, nil
 				}),
 			),
+			nil,
+			nil,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -243,6 +247,8 @@ func TestTransactionSend(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			nil,
+			nil,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -317,6 +323,8 @@ func TestTransactionSend(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			nil,
+			nil,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -384,6 +392,8 @@ func TestTransactionWaitForReceipt(t *testing.T) {
 				return receiptC, nil, nil
 			}),
 		),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -400,6 +410,91 @@ func TestTransactionWaitForReceipt(t *testing.T) {
 	}
 }
 
+func TestTransactionWalletLowBalance(t *testing.T) {
+	logger := logging.New(io.Discard, 0)
+	sender := common.HexToAddress("0xddff")
+	recipient := common.HexToAddress("0xabcd")
+	nonce := uint64(2)
+	chainID := big.NewInt(5)
+
+	signedTx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       &recipient,
+		Value:    big.NewInt(1),
+		Gas:      3,
+		GasPrice: big.NewInt(2),
+	})
+	store := storemock.NewStateStore()
+	err := store.Put(nonceKey(sender), nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := make(chan events.Event, 1)
+	publisher := events.PublisherFunc(func(e events.Event) {
+		published <- e
+	})
+
+	transactionService, err := transaction.NewService(logger,
+		backendmock.New(
+			backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+				return nil
+			}),
+			backendmock.WithPendingNonceAtFunc(func(ctx context.Context, account common.Address) (uint64, error) {
+				return nonce, nil
+			}),
+			backendmock.WithBalanceAtFunc(func(ctx context.Context, address common.Address, block *big.Int) (*big.Int, error) {
+				if address != sender {
+					t.Errorf("getting balance of wrong address. wanted %x, got %x", sender, address)
+				}
+				return big.NewInt(5), nil
+			}),
+		),
+		signerMockForTransaction(signedTx, sender, chainID, t),
+		store,
+		chainID,
+		monitormock.New(
+			monitormock.WithWatchTransactionFunc(func(txHash common.Hash, nonce uint64) (<-chan types.Receipt, <-chan error, error) {
+				receiptC := make(chan types.Receipt, 1)
+				receiptC <- types.Receipt{TxHash: txHash}
+				return receiptC, nil, nil
+			}),
+		),
+		publisher,
+		big.NewInt(10),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transactionService.Close()
+
+	_, err = transactionService.Send(context.Background(), &transaction.TxRequest{
+		To:       &recipient,
+		Value:    big.NewInt(1),
+		GasPrice: big.NewInt(2),
+		GasLimit: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case e := <-published:
+		if e.Type != events.WalletBalanceLow {
+			t.Fatalf("got event type %s, want %s", e.Type, events.WalletBalanceLow)
+		}
+		data := e.Data.(events.BalanceData)
+		if data.Address != sender {
+			t.Fatalf("got address %x, want %x", data.Address, sender)
+		}
+		if data.Balance.Cmp(big.NewInt(5)) != 0 {
+			t.Fatalf("got balance %d, want 5", data.Balance)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for low balance event")
+	}
+}
+
 func TestTransactionResend(t *testing.T) {
 	logger := logging.New(io.Discard, 0)
 	recipient := common.HexToAddress("0xbbbddd")
@@ -447,6 +542,8 @@ func TestTransactionResend(t *testing.T) {
 		store,
 		chainID,
 		monitormock.New(),
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -515,6 +612,8 @@ func TestTransactionCancel(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			nil,
+			nil,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -555,6 +654,8 @@ func TestTransactionCancel(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			nil,
+			nil,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -596,6 +697,8 @@ func TestTransactionCancel(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			nil,
+			nil,
 		)
 		if err != nil {
 			t.Fatal(err)